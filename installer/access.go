@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// Modes of access(2), the same on every Unix
+const (
+	accessExecute = 0x1
+	accessWrite   = 0x2
+)
+
+// canModify reports whether the current user can create files in dir,
+// asking the kernel rather than writing a probe file into it
+func canModify(dir string) bool {
+	return syscall.Access(dir, accessWrite|accessExecute) == nil
+}
@@ -0,0 +1,10 @@
+package main
+
+import "os"
+
+// canModify reports whether dir exists and is not read-only. Windows has
+// no access(2), and ACLs are left for the write itself to enforce.
+func canModify(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir() && info.Mode().Perm()&0200 != 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// AV_RECHECK_DELAY is how long after installing the binaries are hashed
+// again. Defender usually scans a new executable when it first runs, so
+// quarantines land within seconds of verification.
+const AV_RECHECK_DELAY = 10 * time.Second
+
+// DEFENDER_SUBMISSION_URL is where Microsoft takes false positive reports
+const DEFENDER_SUBMISSION_URL = "https://www.microsoft.com/en-us/wdsi/filesubmission"
+
+// FileSnapshot records the digests of installed files at a point in time
+type FileSnapshot struct {
+	Digests map[string]string // path -> sha256
+	TakenAt time.Time
+}
+
+// snapshotFiles hashes each path; unreadable files are left out
+func snapshotFiles(paths []string) FileSnapshot {
+	s := FileSnapshot{Digests: map[string]string{}, TakenAt: time.Now()}
+	for _, path := range paths {
+		if digest, _, err := fileSHA256(path); err == nil {
+			s.Digests[path] = digest
+		}
+	}
+	return s
+}
+
+// changedSince describes every file of a snapshot that has since been
+// removed or modified, as antivirus quarantine does
+func changedSince(s FileSnapshot) []string {
+	var changes []string
+	for path, was := range s.Digests {
+		digest, size, err := fileSHA256(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			changes = append(changes, fmt.Sprintf("%s was removed", path))
+		case err != nil:
+			changes = append(changes, fmt.Sprintf("%s can no longer be read: %v", path, err))
+		case size == 0:
+			changes = append(changes, fmt.Sprintf("%s was emptied", path))
+		case digest != was:
+			changes = append(changes, fmt.Sprintf("%s was modified (sha256 %s, installed %s)", path, short(digest), short(was)))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// recheckForAntivirus waits until AV_RECHECK_DELAY has passed since the
+// snapshot and reports the files that changed meanwhile. Only Windows
+// needs this; elsewhere nothing quarantines binaries behind our back.
+func recheckForAntivirus(s FileSnapshot) []string {
+	if runtime.GOOS != "windows" || len(s.Digests) == 0 {
+		return nil
+	}
+	if wait := AV_RECHECK_DELAY - time.Since(s.TakenAt); wait > 0 {
+		fmt.Printf("🛡️  Checking that antivirus left the installed files alone...\n")
+		time.Sleep(wait)
+	}
+	return changedSince(s)
+}
+
+// printAntivirusGuidance explains how to restore quarantined binaries,
+// keep them from being quarantined again and report the false positive
+func printAntivirusGuidance(changes []string, installPath string) {
+	fmt.Printf("❌ Installed files changed shortly after installation:\n")
+	for _, c := range changes {
+		fmt.Printf("   • %s\n", c)
+	}
+	fmt.Printf("   Antivirus software most likely quarantined them as a false positive.\n")
+	fmt.Printf("   • Review and restore them in Windows Security → Virus & threat protection → Protection history\n")
+	fmt.Printf("   • Exclude the install directory (as administrator):\n")
+	fmt.Printf("       Add-MpPreference -ExclusionPath \"%s\"\n", filepath.Clean(installPath))
+	fmt.Printf("   • Report the false positive to Microsoft: %s\n", DEFENDER_SUBMISSION_URL)
+	fmt.Printf("   • Then rerun the installer. With third-party antivirus, use its own exclusion settings.\n")
+}
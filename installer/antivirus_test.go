@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangedSince(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "vibe")
+	removed := filepath.Join(dir, "surreal")
+	modified := filepath.Join(dir, "code2prompt")
+	emptied := filepath.Join(dir, "rg")
+	for _, path := range []string{kept, removed, modified, emptied} {
+		os.WriteFile(path, []byte("binary "+path), 0755)
+	}
+
+	snapshot := snapshotFiles([]string{kept, removed, modified, emptied, filepath.Join(dir, "never-installed")})
+	if len(snapshot.Digests) != 4 {
+		t.Fatalf("snapshot has %d files, want 4", len(snapshot.Digests))
+	}
+	if changes := changedSince(snapshot); len(changes) != 0 {
+		t.Errorf("unchanged files reported: %v", changes)
+	}
+
+	os.Remove(removed)
+	os.WriteFile(modified, []byte("patched"), 0755)
+	os.WriteFile(emptied, nil, 0755)
+
+	changes := strings.Join(changedSince(snapshot), "\n")
+	for _, want := range []string{removed + " was removed", modified + " was modified", emptied + " was emptied"} {
+		if !strings.Contains(changes, want) {
+			t.Errorf("changes missing %q:\n%s", want, changes)
+		}
+	}
+	if strings.Contains(changes, kept) {
+		t.Errorf("untouched file reported:\n%s", changes)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/vhybzOS/.vibe/installer/platform"
+)
+
+// DEFAULT_ASSET_TEMPLATE names the release assets published by vibe. Forks
+// and custom builds set their own with --asset-template or the
+// release.asset_template setting.
+const DEFAULT_ASSET_TEMPLATE = "vibe-{{.Version}}-{{.OS}}-{{.Arch}}{{.Ext}}"
+
+// AssetNameData is the data available to asset name templates
+type AssetNameData struct {
+	Version string // release tag, e.g. v0.7.27
+	OS      string // release OS name: linux, macos, windows
+	Arch    string // release arch name: x86_64, arm64
+	Ext     string // .exe on Windows, empty elsewhere
+	GOOS    string // Go's name of the OS
+	GOARCH  string // Go's name of the architecture
+}
+
+// newAssetNameData describes a platform's asset for templates
+func newAssetNameData(goos, goarch, version string) AssetNameData {
+	target := platform.Target{OS: goos, Arch: goarch}
+	return AssetNameData{
+		Version: version,
+		OS:      target.AssetOS(),
+		Arch:    target.AssetArch(),
+		Ext:     target.Ext(),
+		GOOS:    goos,
+		GOARCH:  goarch,
+	}
+}
+
+// renderAssetName executes an asset name template for a platform
+func renderAssetName(text, goos, goarch, version string) (string, error) {
+	return renderAssetTemplate(text, newAssetNameData(goos, goarch, version))
+}
+
+// renderAssetTemplate executes an asset name template
+func renderAssetTemplate(text string, data AssetNameData) (string, error) {
+	tmpl, err := template.New("asset").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid asset template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid asset template: %w", err)
+	}
+	name := b.String()
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("asset template must produce a file name, got %q", name)
+	}
+	return name, nil
+}
+
+// validateAssetTemplate checks a template against a sample platform
+func validateAssetTemplate(text string) error {
+	_, err := renderAssetName(text, "linux", "amd64", "v1.0.0")
+	return err
+}
+
+// ASSET_ARCHIVE_EXTS are the archive formats releases have packed the
+// binary in
+var ASSET_ARCHIVE_EXTS = []string{".tar.gz", ".zip"}
+
+// historicalOSNames lists the names releases have used for an OS, current
+// name first
+func historicalOSNames(goos string) []string {
+	if goos == "darwin" {
+		return []string{"macos", "darwin"}
+	}
+	return []string{platform.Target{OS: goos}.AssetOS()}
+}
+
+// historicalArchNames lists the names releases have used for an
+// architecture, current name first
+func historicalArchNames(goarch string) []string {
+	switch goarch {
+	case "amd64":
+		return []string{"x86_64", "amd64"}
+	case "arm64":
+		return []string{"arm64", "aarch64"}
+	}
+	return []string{goarch}
+}
+
+// assetNameVariants returns the asset names a platform's binary may have
+// been published under: the configured name first, then the historical OS
+// and architecture spellings, each also as a .tar.gz or .zip archive.
+// suffix is appended to every name before any archive extension.
+func assetNameVariants(goos, goarch, version, suffix string) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	add(releaseAssetName(goos, goarch, version) + suffix)
+	for _, osName := range historicalOSNames(goos) {
+		for _, arch := range historicalArchNames(goarch) {
+			data := newAssetNameData(goos, goarch, version)
+			data.OS, data.Arch = osName, arch
+			if name, err := renderAssetTemplate(opts.AssetTemplate, data); err == nil {
+				add(name + suffix)
+			}
+		}
+	}
+	bases := append([]string{}, names...)
+	for _, base := range bases {
+		for _, ext := range ASSET_ARCHIVE_EXTS {
+			add(strings.TrimSuffix(base, ".exe") + ext)
+		}
+	}
+	return names
+}
+
+// ReleaseBinary is a binary every release publishes
+type ReleaseBinary struct {
+	Asset    string // asset name the installer downloads
+	Platform string // GOOS/GOARCH, with STATIC_ASSET_SUFFIX for a static build
+}
+
+// releaseBinaries lists the binaries of version under the names the
+// installer downloads them by: one per supported platform, and the static
+// build of each Linux platform
+func releaseBinaries(version string) []ReleaseBinary {
+	var binaries []ReleaseBinary
+	for _, p := range SUPPORTED_PLATFORMS {
+		suffixes := []string{""}
+		if p.GOOS == "linux" {
+			suffixes = append(suffixes, STATIC_ASSET_SUFFIX)
+		}
+		for _, suffix := range suffixes {
+			binaries = append(binaries, ReleaseBinary{
+				Asset:    assetNameVariants(p.GOOS, p.GOARCH, version, suffix)[0],
+				Platform: p.GOOS + "/" + p.GOARCH + suffix,
+			})
+		}
+	}
+	return binaries
+}
+
+// isArchiveAsset reports whether an asset name is a packed binary
+func isArchiveAsset(name string) bool {
+	for _, ext := range ASSET_ARCHIVE_EXTS {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchVerified downloads one release asset to destPath and checks it
+// against want, its published digest, and its cosign signature
+func fetchVerified(source Source, version, name, destPath, want string) error {
+	if err := source.FetchAsset(version, name, destPath); err != nil {
+		return err
+	}
+	if opts.ManifestKey != "" && want == "" {
+		return fmt.Errorf("the signed manifest of %s does not list %s", version, name)
+	}
+	if opts.Paranoid && want == "" {
+		return fmt.Errorf("%s of %s is not listed by two independent digest sources (--paranoid)", name, version)
+	}
+	if err := requireDigest(version, name, want); err != nil {
+		os.Remove(destPath)
+		return err
+	}
+	if err := verifyDigest(destPath, name, want); err != nil {
+		return err
+	}
+	return verifySignature(source, version, name, destPath)
+}
+
+// retryMismatch downloads an asset that failed its checksum once more,
+// bypassing the download caches, when --retry-mismatch is set or the user
+// agrees. A second mismatch is final: the release itself is then suspect.
+func retryMismatch(source Source, version, name, destPath, want string, mismatch error) error {
+	if c, ok := source.(cachedSource); ok {
+		c.forgetAsset(version, name)
+	}
+	if !opts.RetryMismatch && !confirm(fmt.Sprintf("%v. Download %s again?", mismatch, name)) {
+		if !isInteractive() {
+			return fmt.Errorf("%w (--retry-mismatch downloads it once more)", mismatch)
+		}
+		return mismatch
+	}
+	fmt.Printf("🔁 Downloading %s again, bypassing the cache\n", name)
+	if err := fetchVerified(uncached(source), version, name, destPath, want); err != nil {
+		return fmt.Errorf("%w (after downloading it again)", err)
+	}
+	return nil
+}
+
+// fetchBinaryAsset downloads the first of names the release has to
+// destPath, checking it against its published digest and unpacking binName
+// from archives. Only a missing asset moves on to the next name; any other
+// failure is returned at once. It returns the name that matched.
+func fetchBinaryAsset(source Source, version string, names []string, binName, destPath string, digests map[string]string) (string, error) {
+	for i, name := range names {
+		tempPath := destPath
+		if isArchiveAsset(name) {
+			var err error
+			if tempPath, err = quarantinePath(name); err != nil {
+				return "", err
+			}
+		}
+
+		err := fetchVerified(source, version, name, tempPath, digests[name])
+		if errors.Is(err, errAssetNotFound) {
+			if tempPath != destPath {
+				os.Remove(tempPath)
+			}
+			continue
+		}
+		if errors.Is(err, errChecksumMismatch) {
+			err = retryMismatch(source, version, name, tempPath, digests[name], err)
+		}
+		if err != nil {
+			if tempPath != destPath {
+				os.Remove(tempPath)
+			}
+			return "", err
+		}
+
+		if tempPath != destPath {
+			err = extractFile(tempPath, name, binName, destPath)
+			os.Remove(tempPath)
+			if err != nil {
+				return "", err
+			}
+		}
+		if i > 0 {
+			fmt.Printf("🔀 %s is not in release %s; using %s instead\n", names[0], version, name)
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("release %s has no %s (also tried %d historical asset names): %w", version, names[0], len(names)-1, errAssetNotFound)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRenderAssetName(t *testing.T) {
+	tests := []struct {
+		template string
+		goos     string
+		goarch   string
+		want     string
+	}{
+		{DEFAULT_ASSET_TEMPLATE, "darwin", "arm64", "vibe-v1.0.0-macos-arm64"},
+		{DEFAULT_ASSET_TEMPLATE, "windows", "amd64", "vibe-v1.0.0-windows-x86_64.exe"},
+		{"myvibe_{{.GOOS}}_{{.GOARCH}}{{.Ext}}", "linux", "amd64", "myvibe_linux_amd64"},
+		{`acme-{{.Version | printf "%.2s"}}-{{.OS}}`, "linux", "arm64", "acme-v1-linux"},
+	}
+	for _, tt := range tests {
+		got, err := renderAssetName(tt.template, tt.goos, tt.goarch, "v1.0.0")
+		if err != nil || got != tt.want {
+			t.Errorf("renderAssetName(%q, %s/%s) = %q, %v; want %q", tt.template, tt.goos, tt.goarch, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateAssetTemplate(t *testing.T) {
+	for _, bad := range []string{"vibe-{{.Version", "vibe-{{.Flavor}}", "{{.OS}}/{{.Arch}}", ""} {
+		if err := validateAssetTemplate(bad); err == nil {
+			t.Errorf("validateAssetTemplate(%q) accepted", bad)
+		}
+	}
+}
+
+func TestReleaseAssetNameUsesTemplate(t *testing.T) {
+	defer func(saved string) { opts.AssetTemplate = saved }(opts.AssetTemplate)
+	opts.AssetTemplate = "fork-{{.OS}}-{{.Arch}}"
+	if got := releaseAssetName("linux", "amd64", "v1.0.0"); got != "fork-linux-x86_64" {
+		t.Errorf("releaseAssetName = %q", got)
+	}
+}
+
+func TestAssetNameVariants(t *testing.T) {
+	names := assetNameVariants("darwin", "amd64", "v1.0.0", "")
+	if names[0] != "vibe-v1.0.0-macos-x86_64" {
+		t.Errorf("first variant = %q, want the configured name", names[0])
+	}
+	for _, want := range []string{"vibe-v1.0.0-darwin-amd64", "vibe-v1.0.0-macos-amd64", "vibe-v1.0.0-darwin-x86_64.tar.gz"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("variants missing %q: %v", want, names)
+		}
+	}
+
+	windows := assetNameVariants("windows", "amd64", "v1.0.0", "")
+	if !slices.Contains(windows, "vibe-v1.0.0-windows-x86_64.zip") {
+		t.Errorf("windows variants missing the zip: %v", windows)
+	}
+	static := assetNameVariants("linux", "arm64", "v1.0.0", STATIC_ASSET_SUFFIX)
+	if static[0] != "vibe-v1.0.0-linux-arm64-musl" || !slices.Contains(static, "vibe-v1.0.0-linux-aarch64-musl") {
+		t.Errorf("static variants = %v", static)
+	}
+}
+
+// variantSource has only the listed assets, failing the rest as missing
+type variantSource struct {
+	fakeSource
+	files   map[string][]byte
+	fetched []string
+}
+
+func (v *variantSource) FetchAsset(version, asset, destPath string) error {
+	v.fetched = append(v.fetched, asset)
+	data, ok := v.files[asset]
+	if !ok {
+		return fmt.Errorf("%s: %w", asset, errAssetNotFound)
+	}
+	return os.WriteFile(destPath, data, 0644)
+}
+
+func TestFetchBinaryAssetFallsBack(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts.AllowUnsigned = true
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "vibe-v1.0.0/vibe", Mode: 0755, Size: 6, Typeflag: tar.TypeReg})
+	tw.Write([]byte("binary"))
+	tw.Close()
+	gz.Close()
+
+	names := assetNameVariants("linux", "amd64", "v1.0.0", "")
+	source := &variantSource{files: map[string][]byte{"vibe-v1.0.0-linux-amd64.tar.gz": archive.Bytes()}}
+	dest := filepath.Join(t.TempDir(), "vibe")
+
+	digests := map[string]string{"vibe-v1.0.0-linux-amd64.tar.gz": sha256Hex(archive.String())}
+	matched, err := fetchBinaryAsset(source, "v1.0.0", names, "vibe", dest, digests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched != "vibe-v1.0.0-linux-amd64.tar.gz" {
+		t.Errorf("matched %q", matched)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "binary" {
+		t.Errorf("extracted %q", data)
+	}
+	if source.fetched[0] != names[0] {
+		t.Errorf("tried %q first, want the configured name", source.fetched[0])
+	}
+
+	source.files = nil
+	if _, err := fetchBinaryAsset(source, "v1.0.0", names, "vibe", dest, digests); !errors.Is(err, errAssetNotFound) {
+		t.Errorf("fetchBinaryAsset without any variant = %v", err)
+	}
+}
+
+func TestFetchVerifiedNeedsDigest(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts.AllowUnsigned = true
+	source := &variantSource{files: map[string][]byte{"vibe-linux": []byte("binary")}}
+	dest := filepath.Join(t.TempDir(), "vibe")
+
+	if err := fetchVerified(source, "v1.0.0", "vibe-linux", dest, ""); err == nil || !strings.Contains(err.Error(), "--allow-unverified") {
+		t.Errorf("fetchVerified without a digest = %v, want a refusal", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("the unverified download was left behind")
+	}
+
+	opts.AllowUnverified = true
+	if err := fetchVerified(source, "v1.0.0", "vibe-linux", dest, ""); err != nil {
+		t.Errorf("fetchVerified with --allow-unverified failed: %v", err)
+	}
+}
+
+func TestFetchBinaryAssetStopsOnOtherErrors(t *testing.T) {
+	source := fakeSource{missing: map[string]bool{"vibe-v1.0.0-linux-x86_64": true}}
+	names := assetNameVariants("linux", "amd64", "v1.0.0", "")
+	if _, err := fetchBinaryAsset(source, "v1.0.0", names, "vibe", filepath.Join(t.TempDir(), "vibe"), nil); err == nil || errors.Is(err, errAssetNotFound) {
+		t.Errorf("fetchBinaryAsset = %v, want the download failure", err)
+	}
+}
+
+// corruptOnceSource serves a corrupted asset on its first download
+type corruptOnceSource struct {
+	fakeSource
+	downloads *int
+}
+
+func (s corruptOnceSource) FetchAsset(version, asset, destPath string) error {
+	if *s.downloads++; *s.downloads == 1 {
+		return os.WriteFile(destPath, []byte("corrupted"), 0644)
+	}
+	return s.fakeSource.FetchAsset(version, asset, destPath)
+}
+
+func TestFetchBinaryAssetRetriesMismatch(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts = Options{CacheMaxSize: DEFAULT_CACHE_MAX_SIZE, NoVerify: true}
+
+	name := "vibe-v1.0.0-linux-x86_64"
+	want := sha256Hex("v1.0.0/" + name)
+	digests := map[string]string{name: want}
+	dest := filepath.Join(t.TempDir(), "vibe")
+
+	downloads := 0
+	source := cachedSource{corruptOnceSource{downloads: &downloads}}
+	if _, err := fetchBinaryAsset(source, "v1.0.0", []string{name}, "vibe", dest, digests); !errors.Is(err, errChecksumMismatch) {
+		t.Fatalf("fetchBinaryAsset = %v, want a checksum mismatch", err)
+	}
+	if index, _ := loadCacheIndex(); len(index.Entries) != 0 {
+		t.Errorf("the corrupted download stayed cached: %+v", index.Entries)
+	}
+
+	downloads = 0
+	opts.RetryMismatch = true
+	if _, err := fetchBinaryAsset(source, "v1.0.0", []string{name}, "vibe", dest, digests); err != nil {
+		t.Fatalf("fetchBinaryAsset with --retry-mismatch = %v", err)
+	}
+	if downloads != 2 {
+		t.Errorf("downloaded %d times, want 2", downloads)
+	}
+}
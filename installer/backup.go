@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupDataDir copies the data directory to data-backups/<version>-<timestamp>
+// next to it, returning the backup path. A missing data directory is not
+// an error and yields an empty path.
+func backupDataDir(installPath, version string) (string, error) {
+	dataDir := getDataDir(installPath)
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	backupDir := filepath.Join(getBackupDir(installPath), fmt.Sprintf("%s-%s", version, time.Now().Format("20060102-150405")))
+	fmt.Printf("💾 Backing up data directory to: %s\n", backupDir)
+
+	if err := copyDir(dataDir, backupDir); err != nil {
+		return "", fmt.Errorf("failed to back up data directory: %w", err)
+	}
+
+	fmt.Printf("✅ Data backup complete!\n")
+	return backupDir, nil
+}
+
+// getBackupDir returns the directory holding data backups for an install path
+func getBackupDir(installPath string) string {
+	if usesXDG() {
+		return filepath.Join(getVibeHome(), "data-backups")
+	}
+	return filepath.Join(installPath, "data-backups")
+}
+
+// copyDir recursively copies src to dst, preserving file modes
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// copyFile copies a single regular file
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	// OpenFile applies the umask, and never changes an existing file's mode
+	if posixModes() {
+		if err := out.Chmod(mode); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// blobSource reads releases mirrored to cloud object storage. The layout
+// under the source URL is <version>/<asset> for release assets and
+// grammars/<pkg>@<version>/<file> for grammar files.
+//
+// Credentials are never handled by the installer itself: each provider's
+// CLI is invoked so its ambient credential chain (environment, profiles,
+// instance metadata, managed identity) applies unchanged.
+type blobSource struct {
+	scheme    string // s3, gs or az
+	bucket    string // bucket name, or storage account for az
+	container string // az only
+	prefix    string // key prefix without leading/trailing slashes
+}
+
+// newBlobSource parses s3://bucket/prefix, gs://bucket/prefix or
+// az://account/container/prefix
+func newBlobSource(u *url.URL) (*blobSource, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("source %s is missing a bucket name", u.String())
+	}
+
+	b := &blobSource{
+		scheme: u.Scheme,
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+	}
+
+	if b.scheme == "az" {
+		parts := strings.SplitN(b.prefix, "/", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("az source must be az://<account>/<container>[/prefix]")
+		}
+		b.container = parts[0]
+		b.prefix = ""
+		if len(parts) == 2 {
+			b.prefix = parts[1]
+		}
+	}
+
+	return b, nil
+}
+
+func (b *blobSource) Name() string {
+	if b.scheme == "az" {
+		return fmt.Sprintf("az://%s/%s", b.bucket, path.Join(b.container, b.prefix))
+	}
+	return fmt.Sprintf("%s://%s", b.scheme, path.Join(b.bucket, b.prefix))
+}
+
+// key returns the object key for a path relative to the source root
+func (b *blobSource) key(rel string) string {
+	if b.prefix == "" {
+		return rel
+	}
+	return b.prefix + "/" + rel
+}
+
+// objectURL returns the provider URL of an object key
+func (b *blobSource) objectURL(key string) string {
+	if b.scheme == "az" {
+		return fmt.Sprintf("az://%s/%s/%s", b.bucket, b.container, key)
+	}
+	return fmt.Sprintf("%s://%s/%s", b.scheme, b.bucket, key)
+}
+
+func (b *blobSource) LatestVersion() (string, error) {
+	dir := b.key("")
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+
+	var cmd *exec.Cmd
+	switch b.scheme {
+	case "s3":
+		cmd = exec.CommandContext(runCtx, "aws", "s3", "ls", b.objectURL(dir))
+	case "gs":
+		cmd = exec.CommandContext(runCtx, "gcloud", "storage", "ls", b.objectURL(dir))
+	case "az":
+		cmd = exec.CommandContext(runCtx, "az", "storage", "blob", "list",
+			"--account-name", b.bucket, "--container-name", b.container,
+			"--prefix", dir, "--delimiter", "/", "--auth-mode", "login",
+			"--query", "[].name", "--output", "tsv")
+	}
+
+	out, err := b.run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to list versions in %s: %w", b.Name(), err)
+	}
+
+	version, ok := pickVersion(parseBlobListing(out), opts.IncludePrereleases)
+	if !ok {
+		return "", fmt.Errorf("no release versions found in %s", b.Name())
+	}
+	return version, nil
+}
+
+func (b *blobSource) FetchAsset(version, asset, destPath string) error {
+	return b.fetch(b.key(version+"/"+asset), destPath)
+}
+
+func (b *blobSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return b.fetch(b.key(fmt.Sprintf("grammars/%s@%s/%s", pkg, version, file)), destPath)
+}
+
+// fetch downloads a single object to destPath using the provider CLI
+func (b *blobSource) fetch(key, destPath string) error {
+	fmt.Printf("🔗 Downloading from: %s\n", b.objectURL(key))
+
+	var cmd *exec.Cmd
+	switch b.scheme {
+	case "s3":
+		cmd = exec.CommandContext(runCtx, "aws", "s3", "cp", "--only-show-errors", b.objectURL(key), destPath)
+	case "gs":
+		cmd = exec.CommandContext(runCtx, "gcloud", "storage", "cp", b.objectURL(key), destPath)
+	case "az":
+		cmd = exec.CommandContext(runCtx, "az", "storage", "blob", "download",
+			"--account-name", b.bucket, "--container-name", b.container,
+			"--name", key, "--file", destPath, "--auth-mode", "login", "--only-show-errors")
+	}
+
+	if _, err := b.run(cmd); err != nil {
+		return fmt.Errorf("failed to download %s: %w", b.objectURL(key), err)
+	}
+
+	fmt.Printf("✅ Download complete!\n")
+	return nil
+}
+
+// run executes a provider CLI command and returns its stdout
+func (b *blobSource) run(cmd *exec.Cmd) (string, error) {
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return "", fmt.Errorf("%s CLI not found in PATH (required for %s:// sources)", cmd.Args[0], b.scheme)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if blobNotFound(stderr.String()) {
+			return "", fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), errAssetNotFound)
+		}
+		os.Stderr.Write(stderr.Bytes())
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// blobNotFound reports whether a provider CLI failed because the object
+// does not exist, so callers can try other asset names or go on without
+// optional files
+func blobNotFound(stderr string) bool {
+	for _, marker := range []string{
+		"(404)",               // aws: An error occurred (404) when calling the HeadObject operation
+		"NoSuchKey",           // aws, with ListBucket permission
+		"matched no objects",  // gcloud: The following URLs matched no objects or files
+		"No URLs matched",     // gsutil-style message of older gcloud releases
+		"BlobNotFound",        // az: ErrorCode:BlobNotFound
+		"blob does not exist", // az: The specified blob does not exist.
+	} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBlobListing extracts the final path segment of every entry in a
+// provider listing: "PRE v0.7.27/" (aws), "gs://b/p/v0.7.27/" (gcloud) or
+// "p/v0.7.27/" (az)
+func parseBlobListing(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		entry := strings.TrimSuffix(fields[len(fields)-1], "/")
+		if entry == "" {
+			continue
+		}
+		names = append(names, path.Base(entry))
+	}
+	return names
+}
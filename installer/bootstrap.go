@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// INIT_ARGS run vibe's own first-run setup without questions: the default
+// workspace, the data directory and the SurrealDB schema
+var INIT_ARGS = []string{"init", "--defaults"}
+
+// INIT_TIMEOUT bounds the first-run setup, which starts SurrealDB once
+const INIT_TIMEOUT = 5 * time.Minute
+
+// initCommand is how to run the first-run setup by hand
+func initCommand(binaryPath string) string {
+	return shellJoin(append([]string{filepath.Base(binaryPath)}, INIT_ARGS...))
+}
+
+// firstRunInit runs `vibe init --defaults` once per machine, so vibe works
+// right after install without a second manual step. It runs unattended:
+// without stdin, with the installed binaries first on PATH and with
+// VIBE_DATA_DIR pointing at the data directory the installer manages.
+func firstRunInit(binaryPath string, receipt *Receipt) error {
+	dataDir := getUserDataDir(receipt)
+	if err := ensureDir(dataDir, MODE_DIR); err != nil {
+		return err
+	}
+
+	fmt.Printf("🌱 Setting up vibe: %s\n", initCommand(binaryPath))
+	ctx, cancel := context.WithTimeout(runCtx, INIT_TIMEOUT)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binaryPath, INIT_ARGS...)
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(binaryPath)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"VIBE_DATA_DIR="+dataDir,
+		"VIBE_NONINTERACTIVE=1")
+	cmd.Stdout = os.Stdout
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s timed out after %s", initCommand(binaryPath), INIT_TIMEOUT)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s failed: %s", initCommand(binaryPath), msg)
+		}
+		return fmt.Errorf("%s failed: %w", initCommand(binaryPath), err)
+	}
+
+	now := time.Now().UTC()
+	receipt.InitializedAt = &now
+	fmt.Printf("✅ vibe is set up, data in %s\n", dataDir)
+	return nil
+}
+
+// bootstrap runs the first-run setup unless --no-init was given or it ran
+// before. A failure leaves vibe installed, so it is only a warning.
+func bootstrap(binaryPath string, receipt *Receipt) {
+	if receipt.InitializedAt != nil {
+		return
+	}
+	if opts.NoInit {
+		fmt.Printf("⏭️  Skipping first-run setup (--no-init); run %s before using vibe\n", initCommand(binaryPath))
+		return
+	}
+	if err := firstRunInit(binaryPath, receipt); err != nil {
+		warnf("Could not set up vibe: %v\n   Run %s yourself once the problem is fixed", err, initCommand(binaryPath))
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBootstrap(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as binaries")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	saved := opts
+	defer func() { opts = saved }()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "vibe")
+	marker := filepath.Join(dir, "args")
+	os.WriteFile(binary, []byte("#!/bin/sh\necho \"$@ $VIBE_DATA_DIR\" >> "+marker+"\n"), 0755)
+
+	receipt := &Receipt{}
+	opts.NoInit = true
+	bootstrap(binary, receipt)
+	if _, err := os.Stat(marker); err == nil || receipt.InitializedAt != nil {
+		t.Fatal("--no-init still ran the first-run setup")
+	}
+
+	opts.NoInit = false
+	bootstrap(binary, receipt)
+	bootstrap(binary, receipt) // an upgrade does not set up again
+	data, _ := os.ReadFile(marker)
+	if want := "init --defaults " + getUserDataDir(receipt) + "\n"; string(data) != want {
+		t.Errorf("setup ran with %q, want %q once", data, want)
+	}
+	if receipt.InitializedAt == nil {
+		t.Error("a successful setup was not recorded")
+	}
+
+	os.WriteFile(binary, []byte("#!/bin/sh\necho 'unknown command init' >&2\nexit 2\n"), 0755)
+	err := firstRunInit(binary, &Receipt{})
+	if err == nil || !strings.Contains(err.Error(), "unknown command init") {
+		t.Errorf("failed setup reported %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set by ldflags during build, next to version; builds without them fall
+// back to the VCS stamp of go build
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo identifies the installer build, for bug reports and install
+// reports
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// versionFlags registers the flags of the version command
+func versionFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.JSON, "json", opts.JSON, "print the build information as JSON")
+}
+
+// newBuildInfo describes the running installer
+func newBuildInfo() *BuildInfo {
+	info := &BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		applyVCSStamp(info, bi.Settings)
+	}
+	return info
+}
+
+// applyVCSStamp fills what ldflags left empty from the vcs.* settings go
+// build embeds when building inside a checkout
+func applyVCSStamp(info *BuildInfo, settings []debug.BuildSetting) {
+	for _, s := range settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = info.Modified || s.Value == "true"
+		}
+	}
+}
+
+// runVersion implements `install-dotvibe version [--json]`
+func runVersion(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	info := newBuildInfo()
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Printf("install-dotvibe %s\n", info.Version)
+	commit := info.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	if info.Modified {
+		commit += " (modified)"
+	}
+	fmt.Printf("  commit:   %s\n", commit)
+	if info.Date != "" {
+		fmt.Printf("  built:    %s\n", info.Date)
+	}
+	fmt.Printf("  go:       %s\n", info.GoVersion)
+	fmt.Printf("  platform: %s\n", info.Platform)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestApplyVCSStamp(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "abc123"},
+		{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	info := &BuildInfo{}
+	applyVCSStamp(info, settings)
+	if info.Commit != "abc123" || info.Date != "2026-01-02T03:04:05Z" || !info.Modified {
+		t.Errorf("applyVCSStamp = %+v, want the VCS stamp", info)
+	}
+
+	// ldflags take precedence
+	info = &BuildInfo{Commit: "def456", Date: "2026-02-01T00:00:00Z"}
+	applyVCSStamp(info, settings)
+	if info.Commit != "def456" || info.Date != "2026-02-01T00:00:00Z" {
+		t.Errorf("applyVCSStamp overrode ldflags: %+v", info)
+	}
+}
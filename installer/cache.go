@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Download cache layout under the vibe home directory: blobs are stored
+// by content digest in cache/sha256/<digest>, and cache/index.json maps
+// each cached download to its blob
+const (
+	CACHE_DIR   = "cache"
+	CACHE_INDEX = "index.json"
+
+	DEFAULT_CACHE_MAX_SIZE = 2 << 30 // evict least recently used blobs beyond this
+)
+
+// CacheEntry records a cached download
+type CacheEntry struct {
+	Key      string    `json:"key"` // what was downloaded, e.g. <source>/<version>/<asset>
+	Digest   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// CacheIndex is the set of cached downloads
+type CacheIndex struct {
+	Entries []CacheEntry `json:"entries"`
+}
+
+// getCacheDir returns the download cache directory
+func getCacheDir() string {
+	if usesXDG() {
+		return filepath.Join(xdgCacheHome(), XDG_APP_DIR)
+	}
+	return filepath.Join(getVibeHome(), CACHE_DIR)
+}
+
+// cacheBlobPath returns where a blob with the given digest is stored
+func cacheBlobPath(digest string) string {
+	return filepath.Join(getCacheDir(), "sha256", digest)
+}
+
+// loadCacheIndex reads the cache index; a missing index is an empty cache
+func loadCacheIndex() (*CacheIndex, error) {
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), CACHE_INDEX))
+	if os.IsNotExist(err) {
+		return &CacheIndex{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+	var index CacheIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse cache index: %w", err)
+	}
+	return &index, nil
+}
+
+// save writes the cache index atomically
+func (c *CacheIndex) save() error {
+	path := filepath.Join(getCacheDir(), CACHE_INDEX)
+	if err := ensureDir(filepath.Dir(path), MODE_PRIVATE_DIR); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache index: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := writeFileMode(tmp, append(data, '\n'), MODE_DATA); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// find returns the entry for a key
+func (c *CacheIndex) find(key string) (*CacheEntry, bool) {
+	for i := range c.Entries {
+		if c.Entries[i].Key == key {
+			return &c.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// totalSize sums the size of every blob once, however many keys share it
+func (c *CacheIndex) totalSize() int64 {
+	seen := map[string]bool{}
+	var total int64
+	for _, e := range c.Entries {
+		if !seen[e.Digest] {
+			seen[e.Digest] = true
+			total += e.Size
+		}
+	}
+	return total
+}
+
+// evict drops least recently used entries until the cache fits in
+// maxSize, deleting blobs no remaining entry refers to
+func (c *CacheIndex) evict(maxSize int64) {
+	sort.Slice(c.Entries, func(i, j int) bool {
+		return c.Entries[i].LastUsed.After(c.Entries[j].LastUsed)
+	})
+	for c.totalSize() > maxSize && len(c.Entries) > 0 {
+		victim := c.Entries[len(c.Entries)-1]
+		c.Entries = c.Entries[:len(c.Entries)-1]
+		c.removeUnreferenced(victim.Digest)
+	}
+}
+
+// forget drops the entry of key, deleting its blob once unreferenced
+func (c *CacheIndex) forget(key string) bool {
+	for i, e := range c.Entries {
+		if e.Key == key {
+			c.Entries = append(c.Entries[:i], c.Entries[i+1:]...)
+			c.removeUnreferenced(e.Digest)
+			return true
+		}
+	}
+	return false
+}
+
+// removeUnreferenced deletes a blob once no entry points at it
+func (c *CacheIndex) removeUnreferenced(digest string) {
+	for _, e := range c.Entries {
+		if e.Digest == digest {
+			return
+		}
+	}
+	os.Remove(cacheBlobPath(digest))
+}
+
+// cacheFetch copies a cached download to destPath, reporting a hit. Blobs
+// are re-hashed so a corrupted cache is never installed.
+func cacheFetch(key, destPath string) bool {
+	index, err := loadCacheIndex()
+	if err != nil {
+		return false
+	}
+	entry, ok := index.find(key)
+	if !ok {
+		return false
+	}
+
+	blob := cacheBlobPath(entry.Digest)
+	if digest, _, err := fileSHA256(blob); err != nil || digest != entry.Digest {
+		return false
+	}
+	if err := copyFile(blob, destPath, MODE_DATA); err != nil {
+		return false
+	}
+
+	entry.LastUsed = time.Now().UTC()
+	index.save()
+	fmt.Printf("♻️  Using cached %s\n", filepath.Base(key))
+	return true
+}
+
+// cacheStore adds a downloaded file to the cache and evicts old entries
+func cacheStore(key, path string) error {
+	digest, size, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+
+	blob := cacheBlobPath(digest)
+	if err := ensureDir(filepath.Dir(blob), MODE_PRIVATE_DIR); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if _, err := os.Stat(blob); err != nil {
+		if err := copyFile(path, blob, MODE_DATA); err != nil {
+			return err
+		}
+	}
+
+	index, err := loadCacheIndex()
+	if err != nil {
+		return err
+	}
+	entry := CacheEntry{Key: key, Digest: digest, Size: size, LastUsed: time.Now().UTC()}
+	if existing, ok := index.find(key); ok {
+		old := existing.Digest
+		*existing = entry
+		index.removeUnreferenced(old)
+	} else {
+		index.Entries = append(index.Entries, entry)
+	}
+	index.evict(opts.CacheMaxSize)
+	return index.save()
+}
+
+// cachedSource serves release assets and grammars from the download
+// cache, filling it from the wrapped source on a miss. Versioned assets
+// never change, so entries do not expire.
+type cachedSource struct {
+	Source
+}
+
+// withCache wraps a source in the download cache unless it is disabled
+func withCache(source Source) Source {
+	if opts.NoCache {
+		return source
+	}
+	return cachedSource{source}
+}
+
+func (c cachedSource) FetchAsset(version, asset, destPath string) error {
+	return c.fetch(c.Source.Name()+"/"+version+"/"+asset, destPath, func() error {
+		return c.Source.FetchAsset(version, asset, destPath)
+	})
+}
+
+// forgetAsset drops a release asset from the cache, so a copy that failed
+// verification is not served again
+func (c cachedSource) forgetAsset(version, asset string) {
+	index, err := loadCacheIndex()
+	if err != nil {
+		return
+	}
+	if index.forget(c.Source.Name() + "/" + version + "/" + asset) {
+		index.save()
+	}
+}
+
+// uncached returns the source behind the local and shared download caches
+func uncached(source Source) Source {
+	for {
+		switch s := source.(type) {
+		case cachedSource:
+			source = s.Source
+		case sharedCacheSource:
+			source = s.Source
+		default:
+			return source
+		}
+	}
+}
+
+func (c cachedSource) FetchGrammar(pkg, version, file, destPath string) error {
+	// Grammars are pinned upstream packages, identical from every source
+	return c.fetch("grammars/"+pkg+"@"+version+"/"+file, destPath, func() error {
+		return c.Source.FetchGrammar(pkg, version, file, destPath)
+	})
+}
+
+// ReleaseNotes passes release notes through when the source has them
+func (c cachedSource) ReleaseNotes(version string) (string, error) {
+	notes, ok := c.Source.(ReleaseNotesSource)
+	if !ok {
+		return "", nil
+	}
+	return notes.ReleaseNotes(version)
+}
+
+func (c cachedSource) fetch(key, destPath string, download func() error) error {
+	if cacheFetch(key, destPath) {
+		logCacheHit(key)
+		return nil
+	}
+	if err := download(); err != nil {
+		return err
+	}
+	if err := cacheStore(key, destPath); err != nil {
+		warnf("Could not cache %s: %v", filepath.Base(key), err)
+	}
+	return nil
+}
+
+// runCache implements `install-dotvibe cache ls|clean`
+func runCache(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	index, err := loadCacheIndex()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "ls":
+		if len(index.Entries) == 0 {
+			fmt.Printf("📭 The download cache at %s is empty\n", getCacheDir())
+			return nil
+		}
+		sort.Slice(index.Entries, func(i, j int) bool { return index.Entries[i].Key < index.Entries[j].Key })
+		for _, e := range index.Entries {
+			fmt.Printf("%-60s %10s  %s  %s\n", e.Key, formatBytes(uint64(e.Size)), e.Digest[:12], e.LastUsed.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("\n%d entries, %s of %s\n", len(index.Entries), formatBytes(uint64(index.totalSize())), formatBytes(uint64(opts.CacheMaxSize)))
+		return nil
+
+	case "clean":
+		size := index.totalSize()
+		if err := os.RemoveAll(getCacheDir()); err != nil {
+			return fmt.Errorf("failed to clean cache: %w", err)
+		}
+		fmt.Printf("✅ Removed %d cached downloads (%s)\n", len(index.Entries), formatBytes(uint64(size)))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown cache command %q (expected ls or clean)", args[0])
+	}
+}
+
+// cacheFlags registers the flags controlling the download cache
+func cacheFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.NoCache, "no-cache", opts.NoCache, "do not read or fill the download cache")
+}
+
+// formatBytes renders a byte count in GiB, or MiB below one GiB
+func formatBytes(n uint64) string {
+	if n >= 1<<30 {
+		return formatDecimal(float64(n)/(1<<30), 1) + " GiB"
+	}
+	return mebibytes(int64(n))
+}
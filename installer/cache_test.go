@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingSource counts downloads reaching the wrapped source
+type countingSource struct {
+	fakeSource
+	fetches *int
+}
+
+func (c countingSource) FetchAsset(version, asset, destPath string) error {
+	*c.fetches++
+	return c.fakeSource.FetchAsset(version, asset, destPath)
+}
+
+func TestCachedSource(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	fetches := 0
+	source := withCache(countingSource{fakeSource: fakeSource{latest: "v1.0.0"}, fetches: &fetches})
+
+	for i := 0; i < 2; i++ {
+		dest := filepath.Join(t.TempDir(), "vibe")
+		if err := source.FetchAsset("v1.0.0", "vibe-linux", dest); err != nil {
+			t.Fatalf("FetchAsset failed: %v", err)
+		}
+		if data, _ := os.ReadFile(dest); string(data) != "v1.0.0/vibe-linux" {
+			t.Errorf("asset content = %q", data)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("source was hit %d times, want 1", fetches)
+	}
+
+	// A corrupted blob is refetched rather than installed
+	index, _ := loadCacheIndex()
+	os.WriteFile(cacheBlobPath(index.Entries[0].Digest), []byte("tampered"), 0644)
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := source.FetchAsset("v1.0.0", "vibe-linux", dest); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("corrupted cache entry was used")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	now := time.Now()
+	for i, name := range []string{"old", "new"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(name+" content"), 0644)
+		if err := cacheStore(name, path); err != nil {
+			t.Fatalf("cacheStore failed: %v", err)
+		}
+		index, _ := loadCacheIndex()
+		entry, _ := index.find(name)
+		entry.LastUsed = now.Add(time.Duration(i) * time.Hour)
+		index.save()
+	}
+
+	index, _ := loadCacheIndex()
+	oldEntry, _ := index.find("old")
+	oldBlob := cacheBlobPath(oldEntry.Digest)
+	index.evict(index.totalSize() - 1)
+	if _, ok := index.find("old"); ok {
+		t.Error("Least recently used entry was not evicted")
+	}
+	if _, ok := index.find("new"); !ok {
+		t.Error("Recent entry was evicted")
+	}
+	if _, err := os.Stat(oldBlob); !os.IsNotExist(err) {
+		t.Error("Evicted blob still on disk")
+	}
+
+	if err := runCache([]string{"clean"}); err != nil {
+		t.Fatalf("cache clean failed: %v", err)
+	}
+	if _, err := os.Stat(getCacheDir()); !os.IsNotExist(err) {
+		t.Error("cache clean left the cache directory behind")
+	}
+}
@@ -0,0 +1,256 @@
+//go:build !slim
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CARGO_MODULES are the built-in modules compiled with cargo. Slim builds
+// leave them out.
+var CARGO_MODULES = []Module{
+	{
+		Name:    "code2prompt",
+		Cargo:   true,
+		Version: CODE2PROMPT_VERSION,
+		Install: cargoModule("code2prompt", CODE2PROMPT_VERSION),
+		Command: "code2prompt",
+		Explain: func(string, Source) Explanation {
+			return explainCargo("code2prompt", CODE2PROMPT_VERSION, "code2prompt")
+		},
+
+		BuildTime: 3 * time.Minute,
+	},
+	{
+		Name:    "surrealdb",
+		Cargo:   true,
+		Version: SURREALDB_VERSION,
+		Install: cargoModule("surrealdb", SURREALDB_VERSION),
+		Command: "surreal",
+		Explain: func(string, Source) Explanation {
+			return explainCargo("surrealdb", SURREALDB_VERSION, "surreal")
+		},
+
+		BuildTime: 20 * time.Minute, // a large dependency tree, including RocksDB
+	},
+}
+
+// cargoFlags registers the flags controlling cargo builds
+func cargoFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.Prebuilt, "prebuilt", opts.Prebuilt, "download the official surrealdb binary instead of compiling it with cargo, on platforms with a pinned checksum")
+	fs.StringVar(&opts.CompileCache, "compile-cache", opts.CompileCache, "sccache use for cargo builds: auto, sccache (install if missing) or off")
+	fs.StringVar(&opts.CompileCacheDir, "compile-cache-dir", opts.CompileCacheDir, "sccache directory to share compiled crates between installs")
+	fs.StringVar(&opts.CargoRegistry, "cargo-registry", opts.CargoRegistry, "vendored crates directory or registry mirror URL (sparse+https://...) replacing crates.io")
+	fs.BoolVar(&opts.CargoPrefetch, "cargo-prefetch", opts.CargoPrefetch, "download crates with retries before compiling, so network failures do not cost a rebuild (on with --flaky-network)")
+	fs.BoolVar(&opts.CargoOffline, "cargo-offline", opts.CargoOffline, "run cargo with --offline, for air-gapped machines")
+}
+
+// prepareCargo makes sure cargo modules can be built: Rust is installed,
+// the machine has the resources to compile, and the compile cache is set up
+func prepareCargo() error {
+	if err := ensureRust(); err != nil {
+		return err
+	}
+	if err := checkBuildResources(); err != nil {
+		return err
+	}
+	if err := configureCompileCache(); err != nil {
+		warnf("Compile cache disabled: %v", err)
+	}
+	return nil
+}
+
+// checkRustInstallation verifies if Rust and Cargo are installed
+func checkRustInstallation() bool {
+	fmt.Printf("🔍 Checking Rust installation...\n")
+
+	cmd := exec.Command("cargo", "--version")
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("❌ Rust/Cargo not found\n")
+		return false
+	}
+
+	fmt.Printf("✅ Rust/Cargo is installed\n")
+	return true
+}
+
+// rustupCommand returns the command installing the Rust toolchain
+func rustupCommand() *exec.Cmd {
+	if isTermux() {
+		return installTermuxRust()
+	}
+	if runtime.GOOS == "windows" {
+		// Windows: Download and run rustup-init.exe
+		return exec.Command("powershell", "-Command",
+			"Invoke-WebRequest -Uri https://win.rustup.rs -OutFile rustup-init.exe; ./rustup-init.exe -y; Remove-Item rustup-init.exe")
+	}
+	// Unix-like: Use curl | sh pattern
+	return exec.Command("sh", "-c", "curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y")
+}
+
+// installRustToolchain installs Rust using rustup
+func installRustToolchain() error {
+	fmt.Printf("🦀 Installing Rust toolchain...\n")
+
+	cmd := rustupCommand()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install Rust: %w", err)
+	}
+
+	// Add Cargo to PATH for current session
+	if runtime.GOOS != "windows" && !isTermux() {
+		os.Setenv("PATH", os.Getenv("HOME")+"/.cargo/bin:"+os.Getenv("PATH"))
+	}
+
+	fmt.Printf("✅ Rust toolchain installed!\n")
+	return nil
+}
+
+// cargoInstallArgs returns the cargo arguments installing a package
+func cargoInstallArgs(packageName, version string) ([]string, error) {
+	args := []string{"install", packageName, "--version", version}
+	if cargoJobs > 0 {
+		args = append(args, "-j", strconv.Itoa(cargoJobs))
+	}
+	sourceArgs, err := cargoSourceArgs(opts.CargoRegistry, opts.CargoOffline)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, sourceArgs...), nil
+}
+
+// installCargoPackage installs a specific cargo package with version
+func installCargoPackage(packageName, version string) error {
+	fmt.Printf("📦 Installing %s v%s...\n", packageName, version)
+
+	args, err := cargoInstallArgs(packageName, version)
+	if err != nil {
+		return err
+	}
+	if err := cargoCommand(args...).Run(); err != nil {
+		return fmt.Errorf("failed to install %s: %w", packageName, err)
+	}
+
+	fmt.Printf("✅ %s v%s installed!\n", packageName, version)
+	return nil
+}
+
+// cargoModule installs a pinned cargo package and records it in the receipt
+func cargoModule(name, version string) func(string, Source, *Receipt) error {
+	return func(installPath string, source Source, receipt *Receipt) error {
+		if prefetchEnabled() {
+			if err := prefetchCrates(name, version); err != nil {
+				return err
+			}
+		}
+		if err := installCargoPackage(name, version); err != nil {
+			return err
+		}
+		receipt.recordPackage(InstalledPackage{Name: name, Version: version, Manager: "cargo"})
+		for _, bin := range cargoBinaries(name) {
+			receipt.recordFile(name, version, bin)
+		}
+		return nil
+	}
+}
+
+// cargoBinaries returns the executables cargo installed for a package,
+// from the install tracking file in $CARGO_HOME
+func cargoBinaries(name string) []string {
+	data, err := os.ReadFile(filepath.Join(cargoHome(), ".crates2.json"))
+	if err != nil {
+		return nil
+	}
+	var tracked struct {
+		Installs map[string]struct {
+			Bins []string `json:"bins"`
+		} `json:"installs"` // keyed by "<name> <version> (<source>)"
+	}
+	if err := json.Unmarshal(data, &tracked); err != nil {
+		return nil
+	}
+	var paths []string
+	for key, install := range tracked.Installs {
+		if pkg, _, _ := strings.Cut(key, " "); pkg == name {
+			for _, bin := range install.Bins {
+				paths = append(paths, filepath.Join(cargoHome(), "bin", bin))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// explainCargo describes installing a cargo package whose executable is bin
+func explainCargo(name, version, bin string) Explanation {
+	e := Explanation{Summary: fmt.Sprintf("compiles %s v%s from source with cargo", name, version)}
+	args, err := cargoInstallArgs(name, version)
+	if err != nil {
+		e.Notes = append(e.Notes, "cargo cannot run: "+err.Error())
+		return e
+	}
+	e.Commands = []string{shellJoin(append([]string{"cargo"}, args...))}
+	if opts.CargoRegistry == "" {
+		e.URLs = []string{"https://index.crates.io (crate index and sources)"}
+	}
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	e.Files = []string{filepath.Join(cargoHome(), "bin", bin), filepath.Join(cargoHome(), ".crates2.json")}
+	if opts.CompileCache != COMPILE_CACHE_OFF {
+		e.Notes = append(e.Notes, fmt.Sprintf("rustc runs through sccache when available (--compile-cache=%s)", opts.CompileCache))
+	}
+	if prefetchEnabled() {
+		e.Notes = append(e.Notes, fmt.Sprintf("crates are downloaded first with cargo fetch, retried up to %d times", CARGO_FETCH_RETRIES))
+	}
+	e.Notes = append(e.Notes, "parallel jobs may be capped when memory is short")
+	return e
+}
+
+// explainRust describes installing the Rust toolchain
+func explainRust() Explanation {
+	e := Explanation{Summary: "installs the Rust toolchain with rustup, when a cargo module needs it"}
+	cmd := rustupCommand()
+	e.Commands = []string{shellJoin(cmd.Args)}
+	switch {
+	case isTermux():
+		e.Files = []string{filepath.Join(termuxPrefix(), "bin", "cargo")}
+	default:
+		homeDir, _ := os.UserHomeDir()
+		e.URLs = []string{"https://sh.rustup.rs", "https://static.rust-lang.org"}
+		e.Files = []string{cargoHome(), filepath.Join(homeDir, ".rustup")}
+	}
+	if _, err := exec.LookPath("cargo"); err == nil {
+		e.Notes = append(e.Notes, "cargo is already on PATH, so this step is skipped")
+	}
+	return e
+}
+
+// ensureRust installs the Rust toolchain when cargo is missing
+func ensureRust() error {
+	if checkRustInstallation() {
+		return nil
+	}
+	if err := installRustToolchain(); err != nil {
+		return err
+	}
+
+	// Verify installation worked
+	if !checkRustInstallation() {
+		return fmt.Errorf("Rust installation verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//go:build !slim
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// CARGO_FETCH_RETRIES is how many more times a failed crate pre-fetch runs
+const CARGO_FETCH_RETRIES = 4
+
+// cargoFetchBackoff is the wait before the first pre-fetch retry, doubled
+// on each further failure
+var cargoFetchBackoff = 5 * time.Second
+
+// prefetchEnabled reports whether crates are downloaded before compiling:
+// with --cargo-prefetch or --flaky-network, unless cargo stays offline or
+// builds from a vendored directory, which need no downloads
+func prefetchEnabled() bool {
+	if opts.CargoOffline || opts.CargoRegistry != "" && !isRegistryURL(opts.CargoRegistry) {
+		return false
+	}
+	return opts.CargoPrefetch || opts.FlakyNetwork
+}
+
+// prefetchManifest is a throwaway crate depending on exactly the package
+// to install, so `cargo fetch` downloads the same dependency tree
+func prefetchManifest(name, version string) string {
+	return fmt.Sprintf(`[package]
+name = "vibe-prefetch"
+version = "0.0.0"
+edition = "2021"
+publish = false
+
+[lib]
+path = "lib.rs"
+
+[dependencies]
+%s = %s
+`, name, strconv.Quote("="+version))
+}
+
+// prefetchCrates downloads the crates a package builds from into cargo's
+// cache, retrying with backoff, so a network failure costs a download and
+// not a compilation. cargo install then finds the archives in the cache.
+func prefetchCrates(name, version string) error {
+	dir, err := os.MkdirTemp("", "vibe-prefetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "Cargo.toml")
+	if err := os.WriteFile(manifest, []byte(prefetchManifest(name, version)), MODE_DATA); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib.rs"), nil, MODE_DATA); err != nil {
+		return err
+	}
+	sourceArgs, err := cargoSourceArgs(opts.CargoRegistry, false)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"fetch", "--manifest-path", manifest}, sourceArgs...)
+
+	fmt.Printf("📥 Downloading the crates of %s v%s...\n", name, version)
+	delay := cargoFetchBackoff
+	for attempt := 1; ; attempt++ {
+		err = cargoCommand(args...).Run()
+		if err == nil {
+			return nil
+		}
+		if attempt > CARGO_FETCH_RETRIES || runCtx.Err() != nil {
+			return fmt.Errorf("failed to download the crates of %s after %d attempts: %w", name, attempt, err)
+		}
+		warnf("Downloading the crates of %s failed (%v), retrying in %s (%d/%d)", name, err, delay, attempt, CARGO_FETCH_RETRIES)
+		select {
+		case <-time.After(delay):
+		case <-runCtx.Done():
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !slim
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrefetchCrates(t *testing.T) {
+	saved, savedBackoff := opts, cargoFetchBackoff
+	defer func() { opts, cargoFetchBackoff = saved, savedBackoff }()
+	cargoFetchBackoff = time.Millisecond
+
+	// A fake cargo failing the first two fetches, keeping the manifest it saw
+	bin := t.TempDir()
+	state := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "cargo"), []byte(`#!/bin/sh
+cat "$3" > `+state+`/manifest
+echo x >> `+state+`/attempts
+[ $(wc -l < `+state+`/attempts) -gt 2 ]
+`), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if err := prefetchCrates("code2prompt", "1.2.3"); err != nil {
+		t.Fatalf("prefetch with two failures failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, "attempts")); strings.Count(string(data), "x") != 3 {
+		t.Errorf("cargo fetch ran %d times, want 3", strings.Count(string(data), "x"))
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, "manifest")); !strings.Contains(string(data), `code2prompt = "=1.2.3"`) {
+		t.Errorf("prefetch manifest does not pin the package:\n%s", data)
+	}
+
+	os.WriteFile(filepath.Join(bin, "cargo"), []byte("#!/bin/sh\nexit 101\n"), 0755)
+	if err := prefetchCrates("code2prompt", "1.2.3"); err == nil {
+		t.Error("a prefetch failing every attempt succeeded")
+	}
+}
+
+func TestPrefetchEnabled(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts = Options{FlakyNetwork: true}
+	if !prefetchEnabled() {
+		t.Error("--flaky-network does not pre-fetch crates")
+	}
+	opts = Options{CargoPrefetch: true, CargoRegistry: "sparse+https://crates.example.com/index/"}
+	if !prefetchEnabled() {
+		t.Error("a registry mirror does not pre-fetch crates")
+	}
+	for _, o := range []Options{{CargoPrefetch: true, CargoOffline: true}, {CargoPrefetch: true, CargoRegistry: t.TempDir()}, {}} {
+		opts = o
+		if prefetchEnabled() {
+			t.Errorf("prefetch enabled with %+v", o)
+		}
+	}
+}
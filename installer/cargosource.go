@@ -0,0 +1,50 @@
+//go:build !slim
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isRegistryURL reports whether a --cargo-registry value names a registry
+// mirror rather than a vendored crates directory
+func isRegistryURL(registry string) bool {
+	return strings.HasPrefix(registry, "sparse+") || strings.HasPrefix(registry, "https://") || strings.HasPrefix(registry, "http://")
+}
+
+// cargoSourceArgs returns the cargo install arguments that point cargo at
+// a vendored crates directory or an internal registry mirror instead of
+// crates.io, and keep it off the network in offline mode
+func cargoSourceArgs(registry string, offline bool) ([]string, error) {
+	var args []string
+	switch {
+	case registry == "":
+	case isRegistryURL(registry):
+		// Plain URLs are git indexes; sparse indexes carry the sparse+ prefix
+		args = append(args,
+			"--config", "source.crates-io.replace-with="+strconv.Quote("vibe-mirror"),
+			"--config", "source.vibe-mirror.registry="+strconv.Quote(registry),
+		)
+	default:
+		dir, err := filepath.Abs(registry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cargo registry path %q: %w", registry, err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("vendored crates directory %s does not exist", dir)
+		}
+		args = append(args,
+			"--config", "source.crates-io.replace-with="+strconv.Quote("vibe-vendored"),
+			"--config", "source.vibe-vendored.directory="+strconv.Quote(dir),
+		)
+	}
+
+	if offline {
+		args = append(args, "--offline")
+	}
+	return args, nil
+}
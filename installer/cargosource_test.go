@@ -0,0 +1,40 @@
+//go:build !slim
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCargoSourceArgs(t *testing.T) {
+	vendor := t.TempDir()
+
+	tests := []struct {
+		name     string
+		registry string
+		offline  bool
+		want     string
+		wantErr  bool
+	}{
+		{"crates.io", "", false, "", false},
+		{"offline only", "", true, "--offline", false},
+		{"sparse mirror", "sparse+https://crates.internal/index/", false,
+			`--config source.crates-io.replace-with="vibe-mirror" --config source.vibe-mirror.registry="sparse+https://crates.internal/index/"`, false},
+		{"vendored directory offline", vendor, true,
+			`--config source.crates-io.replace-with="vibe-vendored" --config source.vibe-vendored.directory="` + vendor + `" --offline`, false},
+		{"missing directory", vendor + "/missing", false, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := cargoSourceArgs(tt.registry, tt.offline)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cargoSourceArgs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("cargoSourceArgs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GITHUB_RELEASE_BY_TAG_URL is the GitHub API endpoint for a single release
+const GITHUB_RELEASE_BY_TAG_URL = "https://api.github.com/repos/vhybzOS/.vibe/releases/tags/"
+
+// CHANGELOG_MAX_LINES caps how much of a release body is printed
+const CHANGELOG_MAX_LINES = 15
+
+// Release notes display modes (upgrade.changelog setting)
+const (
+	CHANGELOG_SUMMARY       = "summary"
+	CHANGELOG_BREAKING_ONLY = "breaking-only"
+	CHANGELOG_NONE          = "none"
+)
+
+// ReleaseNotesSource is implemented by sources that publish release notes
+type ReleaseNotesSource interface {
+	ReleaseNotes(version string) (string, error)
+}
+
+// breakingMarkers flag changelog lines describing incompatible changes
+var breakingMarkers = []string{"breaking", "⚠️", "[!warning]", "migration required"}
+
+func (githubSource) ReleaseNotes(version string) (string, error) {
+	resp, err := apiGet(GITHUB_RELEASE_BY_TAG_URL + version)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch release notes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API error (%d)", resp.StatusCode)
+	}
+
+	var release struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse release notes: %w", err)
+	}
+	return release.Body, nil
+}
+
+// isBreakingLine reports whether a changelog line carries a breaking-change marker
+func isBreakingLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, marker := range breakingMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeChangelog reduces a markdown release body to its headings and
+// bullet points, capped at maxLines. Breaking-change lines are returned
+// separately so they are never cut off by the cap.
+func summarizeChangelog(body string, maxLines int) (summary, breaking []string) {
+	omitted := 0
+	for _, raw := range strings.Split(body, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if isBreakingLine(line) {
+			breaking = append(breaking, strings.TrimLeft(line, "-*# "))
+			continue
+		}
+
+		isHeading := strings.HasPrefix(line, "#")
+		isBullet := strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+		if !isHeading && !isBullet {
+			continue
+		}
+		if len(summary) >= maxLines {
+			omitted++
+			continue
+		}
+		summary = append(summary, line)
+	}
+
+	if omitted > 0 {
+		summary = append(summary, fmt.Sprintf("… and %d more", omitted))
+	}
+	return summary, breaking
+}
+
+// loadReleaseNotes fetches and summarizes the release notes of version when
+// the source publishes them
+func loadReleaseNotes(source Source, version string) (summary, breaking []string) {
+	notesSource, ok := source.(ReleaseNotesSource)
+	if !ok {
+		return nil, nil
+	}
+
+	body, err := notesSource.ReleaseNotes(version)
+	if err != nil {
+		fmt.Printf("⚠️  Could not load release notes: %v\n", err)
+		return nil, nil
+	}
+
+	return summarizeChangelog(body, CHANGELOG_MAX_LINES)
+}
+
+// printReleaseNotes displays a summarized changelog, breaking changes first
+func printReleaseNotes(version string, summary, breaking []string, major bool) {
+	if len(summary) == 0 && len(breaking) == 0 {
+		return
+	}
+
+	if len(breaking) > 0 {
+		fmt.Printf("\n🚨 Breaking changes in %s:\n", version)
+		for _, line := range breaking {
+			fmt.Printf("   ⚠️  %s\n", line)
+		}
+	}
+
+	if len(summary) > 0 {
+		fmt.Printf("\n📝 What's new in %s:\n", version)
+		for _, line := range summary {
+			fmt.Printf("   %s\n", line)
+		}
+	}
+
+	if major {
+		fmt.Printf("\n⚠️  %s is a major version upgrade; review the changes above before continuing.\n", version)
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeChangelog(t *testing.T) {
+	body := strings.Join([]string{
+		"## What's Changed",
+		"Some prose that should be dropped.",
+		"- Faster indexing",
+		"* New query flags",
+		"- **BREAKING:** data directory moved to ~/.vibe/data",
+		"",
+		"- Fix crash on empty repos",
+	}, "\r\n")
+
+	summary, breaking := summarizeChangelog(body, 3)
+
+	expected := []string{"## What's Changed", "- Faster indexing", "* New query flags", "… and 1 more"}
+	if strings.Join(summary, "|") != strings.Join(expected, "|") {
+		t.Errorf("summary = %q, want %q", summary, expected)
+	}
+	if len(breaking) != 1 || !strings.Contains(breaking[0], "data directory moved") {
+		t.Errorf("breaking = %q, want the data directory line", breaking)
+	}
+}
+
+func TestLoadReleaseNotesWithoutNotesSource(t *testing.T) {
+	summary, breaking := loadReleaseNotes(fakeSource{}, "v1.0.0")
+	if summary != nil || breaking != nil {
+		t.Errorf("loadReleaseNotes() = %v, %v, want nil for sources without release notes", summary, breaking)
+	}
+}
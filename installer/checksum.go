@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fileSHA256 returns the hex-encoded SHA256 digest and size of a file
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// parseChecksums reads a SHA256SUMS file into a map of file name to
+// digest. Both text ("digest  name") and binary ("digest *name") entries
+// are understood.
+func parseChecksums(data string) map[string]string {
+	sums := map[string]string{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CIEnv describes the continuous integration environment, if any
+type CIEnv struct {
+	Enabled       bool   // running under any CI system (CI=true)
+	GitHubActions bool   // running under GitHub Actions
+	StepSummary   string // $GITHUB_STEP_SUMMARY file, when set
+}
+
+// ci is the environment detected at startup
+var ci = detectCI()
+
+// detectCI inspects the standard CI environment variables
+func detectCI() CIEnv {
+	env := CIEnv{
+		GitHubActions: os.Getenv("GITHUB_ACTIONS") == "true",
+		StepSummary:   os.Getenv("GITHUB_STEP_SUMMARY"),
+	}
+	ciVar := strings.ToLower(os.Getenv("CI"))
+	env.Enabled = env.GitHubActions || (ciVar != "" && ciVar != "false" && ciVar != "0")
+	return env
+}
+
+// RunSummary is the machine-readable outcome of an install run
+type RunSummary struct {
+	Status      string            `json:"status"`
+	Installer   string            `json:"installer_version"`
+	Build       *BuildInfo        `json:"installer_build,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Platform    string            `json:"platform,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	InstallPath string            `json:"install_path,omitempty"`
+	Instance    string            `json:"instance_id,omitempty"` // from the data directory's instance.json
+	StartedAt   time.Time         `json:"started_at"`
+	Duration    float64           `json:"duration_seconds"`
+	Phases      []PhaseTiming     `json:"phases,omitempty"`
+	Components  map[string]string `json:"components,omitempty"`
+	Checksums   map[string]string `json:"checksums,omitempty"` // path -> sha256
+	Modules     []ModuleCheck     `json:"modules,omitempty"`   // post-install verification
+	Degraded    []string          `json:"degraded,omitempty"`  // optional modules the run completed without
+
+	SourceChoices []SourceChoice `json:"source_choices,omitempty"` // which mirror served each file
+	Hooks         []HookRun      `json:"hooks,omitempty"`          // installer plugins that ran
+	Error         string         `json:"error,omitempty"`
+	Warnings      []string       `json:"warnings,omitempty"`
+}
+
+// PhaseTiming is the wall-clock duration of one phase of the run
+type PhaseTiming struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+// runStart is when the run began on the monotonic clock. Durations are
+// measured from it rather than from StartedAt, whose UTC conversion drops
+// the monotonic reading, so wall-clock jumps (NTP, manual changes, time
+// zone fixes after resume) never distort them.
+var runStart = time.Now()
+
+// runSummary accumulates the outcome of the current run
+var runSummary = &RunSummary{Status: "running", Installer: version, Build: newBuildInfo(), StartedAt: runStart.UTC()}
+
+// escapeAnnotation encodes a message for a GitHub Actions workflow command
+func escapeAnnotation(msg string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(msg)
+}
+
+// annotate emits a GitHub Actions ::error:: or ::warning:: annotation
+func annotate(level, msg string) {
+	if ci.GitHubActions {
+		fmt.Printf("::%s::%s\n", level, escapeAnnotation(msg))
+	}
+}
+
+// beginGroup starts a timed phase, closing the previous one. In GitHub
+// Actions each phase is a collapsible log group.
+func beginGroup(name string) {
+	endGroup()
+	currentPhase, phaseStart = name, time.Now()
+	if ci.GitHubActions {
+		fmt.Printf("::group::%s\n", name)
+	}
+}
+
+// currentPhase is the phase in progress and phaseStart when it began
+var (
+	currentPhase string
+	phaseStart   time.Time
+)
+
+// endGroup closes the current phase, if any, recording its duration
+func endGroup() {
+	if currentPhase == "" {
+		return
+	}
+	runSummary.Phases = append(runSummary.Phases, PhaseTiming{currentPhase, time.Since(phaseStart).Seconds()})
+	currentPhase = ""
+	if ci.GitHubActions {
+		fmt.Printf("::endgroup::\n")
+	}
+}
+
+// warnf prints a warning, annotates it in CI and records it in the summary
+func warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Printf("⚠️  %s\n", msg)
+	annotate("warning", msg)
+	runSummary.Warnings = append(runSummary.Warnings, msg)
+}
+
+// completedStatus is the status of a run that completed: degraded when an
+// optional module is missing
+func completedStatus() string {
+	if len(runSummary.Degraded) > 0 {
+		return "degraded"
+	}
+	return "success"
+}
+
+// fatalf reports a failed run and exits. The error is printed outside any
+// log group so it stays visible in collapsed CI logs.
+func fatalf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	endGroup()
+	fmt.Printf("❌ %s\n", msg)
+	annotate("error", msg)
+
+	runSummary.Status = "failure"
+	runSummary.Error = msg
+	finishRun()
+	os.Exit(1)
+}
+
+// finishRun closes open log groups, writes the install report and
+// publishes the run summary
+func finishRun() {
+	endGroup()
+	releaseRunLock()
+	runSummary.Duration = time.Since(runStart).Seconds()
+
+	if err := writeInstallReport(getReportPath(), runSummary); err != nil {
+		fmt.Printf("⚠️  Failed to write install report: %v\n", err)
+	}
+	if opts.MetricsPushURL != "" {
+		if err := pushMetrics(opts.MetricsPushURL, runSummary); err != nil {
+			fmt.Printf("⚠️  Failed to push install metrics: %v\n", err)
+		}
+	}
+
+	if ci.StepSummary == "" {
+		return
+	}
+	if err := appendStepSummary(ci.StepSummary, runSummary); err != nil {
+		fmt.Printf("⚠️  Failed to write step summary: %v\n", err)
+	}
+}
+
+// appendStepSummary adds the run summary to a GitHub step summary file
+func appendStepSummary(path string, summary *RunSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	icon := "✅"
+	switch summary.Status {
+	case "success", "unchanged":
+	case "degraded":
+		icon = "⚠️"
+	default:
+		icon = "❌"
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	duration := time.Duration(summary.Duration * float64(time.Second))
+	_, err = fmt.Fprintf(f, "### %s dotvibe install: %s in %s\n\n```json\n%s\n```\n", icon, summary.Status, formatDuration(duration), data)
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		ci, actions string
+		enabled     bool
+		github      bool
+	}{
+		{"", "", false, false},
+		{"true", "", true, false},
+		{"1", "", true, false},
+		{"false", "", false, false},
+		{"", "true", true, true},
+		{"true", "true", true, true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("CI", test.ci)
+		t.Setenv("GITHUB_ACTIONS", test.actions)
+		got := detectCI()
+		if got.Enabled != test.enabled || got.GitHubActions != test.github {
+			t.Errorf("detectCI() with CI=%q GITHUB_ACTIONS=%q = %+v, want enabled=%v github=%v",
+				test.ci, test.actions, got, test.enabled, test.github)
+		}
+	}
+}
+
+func TestEscapeAnnotation(t *testing.T) {
+	got := escapeAnnotation("100% failed\nretry\r")
+	want := "100%25 failed%0Aretry%0D"
+	if got != want {
+		t.Errorf("escapeAnnotation() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	os.WriteFile(path, []byte("previous step\n"), 0644)
+
+	summary := &RunSummary{Status: "failure", Version: "v1.0.0", Error: "Download failed"}
+	if err := appendStepSummary(path, summary); err != nil {
+		t.Fatalf("appendStepSummary failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.HasPrefix(content, "previous step\n") {
+		t.Errorf("existing summary content was overwritten: %q", content)
+	}
+
+	start := strings.Index(content, "```json\n")
+	end := strings.LastIndex(content, "```")
+	if start < 0 || end <= start {
+		t.Fatalf("summary has no JSON block: %q", content)
+	}
+	var decoded RunSummary
+	if err := json.Unmarshal([]byte(content[start+len("```json\n"):end]), &decoded); err != nil {
+		t.Fatalf("summary JSON is invalid: %v", err)
+	}
+	if decoded.Status != "failure" || decoded.Error != "Download failed" {
+		t.Errorf("decoded summary = %+v", decoded)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// STALE_STAGING_AGE is how old a staging file must be to count as left over
+// from an interrupted run rather than one in progress
+const STALE_STAGING_AGE = time.Hour
+
+// VERSION_PROBE_TIMEOUT bounds running a found binary to identify it
+const VERSION_PROBE_TIMEOUT = 5 * time.Second
+
+// vibeVersionPattern matches the first line of dotvibe's `vibe --version`
+var vibeVersionPattern = regexp.MustCompile(`^(?:dot)?vibe v?\d+\.\d+\.\d+`)
+
+// Leftover is a file or directory the scanner believes is safe to delete
+type Leftover struct {
+	Path   string
+	Reason string
+}
+
+// cleanupFlags registers the flags of the cleanup command
+func cleanupFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.Scan, "scan", opts.Scan, "scan for orphaned binaries, staging files and duplicate grammars")
+}
+
+// runCleanup implements `install-dotvibe cleanup --scan [--yes]`
+func runCleanup(args []string) error {
+	if !opts.Scan || len(args) > 0 {
+		return errUsage
+	}
+
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧹 Scanning for leftovers...\n")
+	leftovers := scanLeftovers(receipt, candidateBinDirs())
+	if len(leftovers) == 0 {
+		fmt.Printf("✅ No leftovers found\n")
+		return nil
+	}
+
+	for _, l := range leftovers {
+		fmt.Printf("   • %s (%s)\n", l.Path, l.Reason)
+	}
+	if !opts.Yes && !confirm(fmt.Sprintf("Delete these %d item(s)?", len(leftovers))) {
+		fmt.Printf("⏭️  Nothing deleted\n")
+		return nil
+	}
+
+	failed := 0
+	for _, l := range leftovers {
+		if err := os.RemoveAll(l.Path); err != nil {
+			fmt.Printf("❌ %s: %v\n", l.Path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d item(s)", failed)
+	}
+
+	fmt.Printf("✅ Removed %d leftover(s)\n", len(leftovers))
+	return nil
+}
+
+// candidateBinDirs lists directories where older installers or manual
+// installs commonly left vibe binaries
+func candidateBinDirs() []string {
+	dirs := []string{getInstallPath(), filepath.Join(getVibeHome(), "bin")}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "bin"))
+	}
+	if runtime.GOOS != "windows" {
+		dirs = append(dirs, "/usr/local/bin")
+	}
+	return dirs
+}
+
+// scanLeftovers finds orphaned vibe binaries in binDirs, stale staging
+// files and duplicate grammars. Without a receipt nothing tells which
+// install is live, so the binary at the default install path is kept.
+func scanLeftovers(receipt *Receipt, binDirs []string) []Leftover {
+	var leftovers []Leftover
+	_, _, filename := detectPlatform()
+
+	// 1. Orphaned vibe binaries outside the recorded install path
+	activeDir := receipt.InstallPath
+	if activeDir == "" {
+		activeDir = getInstallPath()
+	}
+	active := filepath.Clean(filepath.Join(activeDir, filename))
+	seen := map[string]bool{}
+	for _, dir := range binDirs {
+		path := filepath.Clean(filepath.Join(dir, filename))
+		if seen[path] || path == active {
+			continue
+		}
+		seen[path] = true
+		if isUserWritableFile(path) && isVibeBinary(receipt, path) {
+			leftovers = append(leftovers, Leftover{path, "vibe binary not managed by the install receipt"})
+		}
+	}
+
+	// 2. Staging files from interrupted runs
+	staging := []string{filepath.Join(os.TempDir(), filename)}
+	if matches, err := filepath.Glob(filepath.Join(getVibeHome(), "*.tmp")); err == nil {
+		staging = append(staging, matches...)
+	}
+	if matches, err := filepath.Glob(filepath.Join(getQuarantineDir(), "*")); err == nil {
+		staging = append(staging, matches...)
+	}
+	for _, path := range staging {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > STALE_STAGING_AGE {
+			leftovers = append(leftovers, Leftover{path, "stale staging file"})
+		}
+	}
+
+	// 3. Grammar copies outside the active data directory
+	leftovers = append(leftovers, findDuplicateGrammars(receipt, binDirs)...)
+	return leftovers
+}
+
+// isVibeBinary reports whether a file named like vibe really is dotvibe,
+// and not another tool that happens to share the name: it is a path or a
+// copy of a binary the receipt records, or its --version output says so
+func isVibeBinary(receipt *Receipt, path string) bool {
+	digest, _, err := fileSHA256(path)
+	if err != nil {
+		return false
+	}
+	for _, f := range receipt.Files {
+		if f.Component != "vibe" {
+			continue
+		}
+		if filepath.Clean(f.Path) == path || f.SHA256 != "" && strings.EqualFold(f.SHA256, digest) {
+			return true
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), VERSION_PROBE_TIMEOUT)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return false
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return vibeVersionPattern.MatchString(strings.TrimSpace(line))
+}
+
+// findDuplicateGrammars reports grammar files in data directories other
+// than the active one that duplicate a grammar the active one holds
+func findDuplicateGrammars(receipt *Receipt, binDirs []string) []Leftover {
+	if receipt.InstallPath == "" {
+		return nil
+	}
+	activeDir := filepath.Clean(getDataDir(receipt.InstallPath))
+
+	var leftovers []Leftover
+	seen := map[string]bool{activeDir: true}
+	dirs := []string{getUserDataDir(receipt)}
+	for _, dir := range binDirs {
+		dirs = append(dirs, adjacentDataDir(dir))
+	}
+
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		for _, g := range GRAMMARS {
+			path := filepath.Join(dir, g.File)
+			if _, err := os.Stat(filepath.Join(activeDir, g.File)); err != nil {
+				continue
+			}
+			if isUserWritableFile(path) {
+				leftovers = append(leftovers, Leftover{path, "duplicate of " + filepath.Join(activeDir, g.File)})
+			}
+		}
+	}
+	return leftovers
+}
+
+// isUserWritableFile reports whether path is a regular file in a directory
+// the current user can modify (so deleting it will not need sudo)
+func isUserWritableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	return canModify(filepath.Dir(path))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanLeftovers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake vibe binary is a shell script")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	_, _, filename := detectPlatform()
+
+	activeDir := t.TempDir()
+	oldDir := t.TempDir()
+	copyDir := t.TempDir()
+	otherDir := t.TempDir()
+	receipt := &Receipt{Version: "v0.8.0", InstallPath: activeDir}
+
+	// Active install with its grammar, and a stray copy of its binary
+	os.WriteFile(filepath.Join(activeDir, filename), []byte("new"), 0755)
+	receipt.recordFile("vibe", "v0.8.0", filepath.Join(activeDir, filename))
+	os.WriteFile(filepath.Join(copyDir, filename), []byte("new"), 0755)
+	os.MkdirAll(getDataDir(activeDir), 0755)
+	os.WriteFile(filepath.Join(getDataDir(activeDir), GRAMMARS[0].File), []byte("\x00asm"), 0644)
+
+	// Leftovers from an older layout, identified by their version output
+	os.WriteFile(filepath.Join(oldDir, filename), []byte("#!/bin/sh\necho 'vibe 0.7.0'\n"), 0755)
+	// Another tool that happens to be called vibe is not ours to delete
+	os.WriteFile(filepath.Join(otherDir, filename), []byte("#!/bin/sh\necho 'vibe-check 2.0'\n"), 0755)
+	os.MkdirAll(adjacentDataDir(oldDir), 0755)
+	os.WriteFile(filepath.Join(adjacentDataDir(oldDir), GRAMMARS[0].File), []byte("\x00asm"), 0644)
+
+	// Staging files
+	staleTmp := filepath.Join(getVibeHome(), "install-state.json.tmp")
+	os.WriteFile(staleTmp, []byte("{"), 0644)
+	old := time.Now().Add(-2 * STALE_STAGING_AGE)
+	os.Chtimes(staleTmp, old, old)
+
+	leftovers := scanLeftovers(receipt, []string{activeDir, oldDir, copyDir, otherDir})
+
+	found := map[string]string{}
+	for _, l := range leftovers {
+		found[l.Path] = l.Reason
+	}
+
+	expected := []string{
+		filepath.Join(oldDir, filename),
+		filepath.Join(copyDir, filename),
+		filepath.Join(adjacentDataDir(oldDir), GRAMMARS[0].File),
+		staleTmp,
+	}
+	for _, path := range expected {
+		if _, ok := found[path]; !ok {
+			t.Errorf("expected leftover %s, got %v", path, found)
+		}
+	}
+
+	for path := range found {
+		if strings.HasPrefix(path, activeDir) {
+			t.Errorf("active install flagged as leftover: %s", path)
+		}
+		if strings.HasPrefix(path, otherDir) {
+			t.Errorf("another tool flagged as leftover: %s", path)
+		}
+	}
+}
+
+func TestScanLeftoversWithoutReceipt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake vibe binary is a shell script")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	_, _, filename := detectPlatform()
+
+	// An install whose receipt was lost still has its binary
+	vibe := []byte("#!/bin/sh\necho 'vibe 0.8.0'\n")
+	installDir := getInstallPath()
+	os.MkdirAll(installDir, 0755)
+	os.WriteFile(filepath.Join(installDir, filename), vibe, 0755)
+	strayDir := t.TempDir()
+	os.WriteFile(filepath.Join(strayDir, filename), vibe, 0755)
+
+	leftovers := scanLeftovers(&Receipt{}, []string{installDir, strayDir})
+	if len(leftovers) != 1 || leftovers[0].Path != filepath.Join(strayDir, filename) {
+		t.Errorf("leftovers = %v, want only the binary in %s", leftovers, strayDir)
+	}
+}
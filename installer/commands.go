@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is an installer subcommand
+type Command struct {
+	Name       string
+	Summary    string
+	Usage      string   // positional arguments, for help text
+	Help       string   // longer description shown by help <command>
+	Words      []string // positional arguments offered by shell completion
+	SkipConfig bool     // run without loading the config file
+	Flags      func(fs *flag.FlagSet)
+	Run        func(args []string) error // receives the arguments left after flags
+}
+
+// DEFAULT_COMMAND runs when no command is given, so `install-dotvibe
+// --yes` keeps installing
+const DEFAULT_COMMAND = "install"
+
+// errUsage is returned by commands called with the wrong arguments; the
+// command's help is printed instead of an error
+var errUsage = errors.New("invalid arguments")
+
+// commands lists every subcommand. It is populated in init because
+// completions and help refer back to it.
+var commands []Command
+
+func init() {
+	commands = []Command{
+		{Name: "install", Summary: "install the latest vibe and its dependencies (the default)", Flags: installFlags, Run: runInstall},
+		{Name: "upgrade", Summary: "upgrade an existing installation to the latest release", Flags: installFlags, Run: runUpgrade},
+		{Name: "update", Summary: "upgrade in place, replacing only the binary and modules that changed", Flags: installFlags, Run: runUpdate},
+		{Name: "self-update", Summary: "replace this installer with the newest release's build; --check only reports it", Flags: selfUpdateFlags, Run: runSelfUpdate},
+		{Name: "list", Summary: "list what is installed and its versions", Run: runList},
+		{Name: "grammars", Summary: "update the grammars to the newest catalog versions, leaving vibe alone", Usage: "update", Words: []string{"update"}, Flags: addSourceFlags, Run: runGrammars},
+		{Name: "latest", Summary: "print the latest version; --check exits 10 when an update is available", Flags: latestFlags, Run: runLatest},
+		{Name: "list-remote", Summary: "list the released versions of vibe", Flags: addSourceFlags, Run: runListRemote},
+		{Name: "diff", Summary: "compare two releases: binary sizes, grammars, requirements and migrations", Usage: "<from> <to>",
+			Help:  "Compares two releases of vibe to help decide whether to switch. Either version\nmay be \"installed\". Grammars and requirements come from the release manifests.",
+			Flags: addSourceFlags, Run: runDiff},
+		{Name: "matrix", Summary: "check that a release has every platform asset (for maintainers)", Usage: "validate <tag>", Words: []string{"validate"}, Flags: addSourceFlags, Run: runMatrix},
+		{Name: "mirror", Summary: "download every asset of a release for static hosting", Usage: "<dir>",
+			Help:  "Downloads every asset of a release into <dir> for static hosting.\nPoint the installer at the hosted directory with --base-url.",
+			Flags: mirrorFlags, Run: runMirror},
+		{Name: "plugin", Summary: "write an asdf/mise plugin for managing vibe versions", Usage: "<dir>",
+			Help:  "Writes an asdf plugin for vibe into <dir>. Use it with:\n  asdf plugin add vibe <dir>   or   mise plugin link vibe <dir>",
+			Flags: pluginFlags, Run: runPlugin},
+		{Name: "config", Summary: "get, set or unset installer settings", Usage: "get <key> | set <key> <value> | unset <key> | list", Words: []string{"get", "set", "unset", "list"}, SkipConfig: true, Run: runConfig},
+		{Name: "auth", Summary: "store or remove credentials in the OS keychain", Usage: "login | logout [github | <host>]",
+			Help:  "Stores a GitHub token, or the credential of a mirror, registry or Pushgateway host\n(user:password, or a bearer token), in the OS keychain. On a terminal, auth login\nsigns in to GitHub in the browser; otherwise credentials are read from stdin.\nGITHUB_TOKEN and VIBE_REGISTRY_TOKEN take precedence when set.",
+			Words: []string{"login", "logout"}, SkipConfig: true, Flags: authFlags, Run: runAuth},
+		{Name: "uninstall", Summary: "remove everything the installer created", SkipConfig: true, Flags: uninstallFlags, Run: runUninstall},
+		{Name: "export-setup", Summary: "write the installed versions, grammars and settings for install --from-setup", Usage: "[file]",
+			Help:       "Writes a portable description of this installation: the vibe release, grammar and\npackage versions and installer settings, leaving out paths of this machine. Without\n<file> it goes to stdout. Reproduce it elsewhere with install --from-setup <file>.",
+			SkipConfig: true, Run: runExportSetup},
+		{Name: "doctor", Summary: "diagnose problems with the installation", Run: runDoctor},
+		{Name: "explain", Summary: "show what one install step would download, run and change, without doing it", Usage: "<step>",
+			Help:  "Shows the URLs, commands and files of one install step for this machine and config.\nSteps: " + strings.Join(explainSteps(), ", ") + ".\nTakes the install flags, so e.g. explain --prebuilt surrealdb shows the download instead.",
+			Words: explainSteps(), Flags: installFlags, Run: runExplain},
+		{Name: "why", Summary: "show how the installer picks the version of vibe or a module", Usage: "[vibe | <module>]",
+			Help:  "Shows the source, channel, policy, releases and pins deciding what an install would\nput in place, like apt policy. Takes the install flags, so e.g. why --include-prereleases.",
+			Words: whyTargets(), Flags: installFlags, Run: runWhy},
+		{Name: "data", Summary: "move, unlock or lock vibe's indexes and databases", Usage: "move <path> | unlock | lock", Words: []string{"move", "unlock", "lock"}, Run: runData},
+		{Name: "cleanup", Summary: "find and delete leftovers of older installs", SkipConfig: true, Flags: cleanupFlags, Run: runCleanup},
+		{Name: "cache", Summary: "list or clean the download cache", Usage: "ls | clean", Words: []string{"ls", "clean"}, Run: runCache},
+		{Name: "completions", Summary: "print a shell completion script", Usage: strings.Join(COMPLETION_SHELLS, " | "), Words: COMPLETION_SHELLS, SkipConfig: true, Run: runCompletions},
+		{Name: "version", Summary: "print the installer's version, commit, build date and Go version", SkipConfig: true, Flags: versionFlags, Run: runVersion},
+		{Name: "help", Summary: "show the commands, or the flags of one command", Usage: "[command]", SkipConfig: true, Run: runHelp},
+	}
+	for _, c := range commands {
+		if c.Name != "help" {
+			commands[len(commands)-1].Words = append(commands[len(commands)-1].Words, c.Name)
+		}
+	}
+}
+
+// globalFlags registers the flags every command accepts
+func globalFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.Yes, "yes", opts.Yes, "answer yes to every confirmation prompt")
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "overall time limit of the run, e.g. 15m (default none)")
+	fs.DurationVar(&opts.APITimeout, "api-timeout", opts.APITimeout, "time limit of each release metadata request")
+	fs.DurationVar(&opts.DownloadTimeout, "download-timeout", opts.DownloadTimeout, "time limit of each file download")
+	fs.DurationVar(&opts.StallTimeout, "stall-timeout", opts.StallTimeout, "retry a download after this long without receiving data, 0 to wait for --download-timeout")
+	fs.BoolVar(&opts.FlakyNetwork, "flaky-network", opts.FlakyNetwork, "for satellite and mobile links: download in small resumable chunks with many retries and a longer stall timeout")
+	fs.Func("user-agent-suffix", "text appended to the User-Agent of every request, e.g. \"acme-build/42\"", func(v string) error {
+		if err := validateHeaderValue(v); err != nil {
+			return err
+		}
+		opts.UserAgentSuffix = v
+		return nil
+	})
+	fs.Func("org-tag", "organisation tag sent in the "+ORG_TAG_HEADER+" header of every request, for mirror and proxy logs", func(v string) error {
+		if err := validateHeaderValue(v); err != nil {
+			return err
+		}
+		opts.OrgTag = v
+		return nil
+	})
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (Command, bool) {
+	for _, c := range commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// newFlagSet creates a flag set with the global flags and a command's
+// flags registered
+func newFlagSet(name string, register func(fs *flag.FlagSet)) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	globalFlags(fs)
+	if register != nil {
+		register(fs)
+	}
+	return fs
+}
+
+// commandFlagSet creates the flag set of a command, including the hidden
+// flags, printing its help on -h and on invalid flags
+func commandFlagSet(cmd Command) *flag.FlagSet {
+	fs := newFlagSet(cmd.Name, cmd.Flags)
+	hiddenFlags(fs)
+	fs.Usage = func() { printCommandHelp(fs.Output(), cmd) }
+	return fs
+}
+
+// flagNames returns the flags a command accepts, for completion
+func flagNames(register func(fs *flag.FlagSet)) []string {
+	fs := newFlagSet("", register)
+	fs.SetOutput(io.Discard)
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "--"+f.Name)
+	})
+	return names
+}
+
+// printUsage describes the installer and lists its commands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: install-dotvibe [command] [flags] [arguments]\n\n")
+	fmt.Fprintf(w, "Installs .vibe and its dependencies. Without a command, installs the\nlatest release.\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintf(w, "\nGlobal flags:\n")
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SetOutput(w)
+	globalFlags(fs)
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nRun 'install-dotvibe help <command>' for the flags of a command.\n")
+}
+
+// printCommandHelp shows the usage, description and flags of a command
+func printCommandHelp(w io.Writer, cmd Command) {
+	synopsis := "install-dotvibe " + cmd.Name + " [flags]"
+	if cmd.Usage != "" {
+		synopsis += " " + cmd.Usage
+	}
+	fmt.Fprintf(w, "Usage: %s\n\n", synopsis)
+	if cmd.Help != "" {
+		fmt.Fprintf(w, "%s\n\n", cmd.Help)
+	} else {
+		fmt.Fprintf(w, "%s%s.\n\n", strings.ToUpper(cmd.Summary[:1]), cmd.Summary[1:])
+	}
+	fmt.Fprintf(w, "Flags:\n")
+	fs := newFlagSet(cmd.Name, cmd.Flags)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// runHelp implements `install-dotvibe help [command]`
+func runHelp(args []string) error {
+	switch len(args) {
+	case 0:
+		printUsage(os.Stdout)
+		return nil
+	case 1:
+		cmd, ok := findCommand(args[0])
+		if !ok {
+			return unknownCommandError(args[0])
+		}
+		printCommandHelp(os.Stdout, cmd)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+// unknownCommandError reports a command that does not exist, suggesting
+// the one that was probably meant
+func unknownCommandError(name string) error {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
+	}
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		return fmt.Errorf("unknown command %q; did you mean %q?", name, suggestion)
+	}
+	return fmt.Errorf("unknown command %q; run 'install-dotvibe help' for the list of commands", name)
+}
+
+// commandError is a failure of a command that ran, as opposed to a
+// command line that could not be run
+type commandError struct {
+	name string
+	err  error
+}
+
+func (e commandError) Error() string { return e.name + " failed: " + e.err.Error() }
+
+func (e commandError) Unwrap() error { return e.err }
+
+// execute runs the command named by the first argument, or the default
+// command when the arguments start with a flag. Global and command flags
+// are parsed after the config file is applied, so they override it.
+func execute(args []string) error {
+	name := DEFAULT_COMMAND
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		return unknownCommandError(name)
+	}
+
+	if !cmd.SkipConfig {
+		if err := applyConfig(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	fs := commandFlagSet(cmd)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage // the flag package has printed the error and the help
+	}
+	applyNetworkProfile()
+	if cmd.Name != "help" {
+		// Nothing touches the disk or the network before the policy allows
+		// the command line
+		if err := enforcePolicy(); err != nil {
+			return err
+		}
+		configureSystemProxy()
+	}
+	if err := cmd.Run(fs.Args()); err != nil {
+		if err == errUsage {
+			fs.Usage()
+			return err
+		}
+		return commandError{cmd.Name, err}
+	}
+	return nil
+}
+
+// EXIT_USAGE is the exit status of a command line that cannot be run as
+// given: a usage, config or policy error. It is sysexits' EX_USAGE, since
+// 2 is EXIT_CHANGES_PENDING.
+const EXIT_USAGE = 64
+
+// runCommand executes the command line and exits with its status: 1 when
+// the command failed, EXIT_USAGE when it could not be run as given
+func runCommand(args []string) {
+	err := execute(args)
+	var status exitStatus
+	var failed commandError
+	switch {
+	case err == nil || err == flag.ErrHelp:
+		return
+	case errors.As(err, &status):
+		os.Exit(int(status))
+	case err == errUsage:
+		os.Exit(EXIT_USAGE)
+	case errors.As(err, &failed):
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	default:
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+}
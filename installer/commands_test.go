@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+
+	if err := execute([]string{"instal"}); err == nil || !strings.Contains(err.Error(), `did you mean "install"`) {
+		t.Errorf("execute(instal) = %v, want a suggestion", err)
+	}
+	if err := execute([]string{"cache", "ls", "extra"}); err != errUsage {
+		t.Errorf("execute(cache ls extra) = %v, want errUsage", err)
+	}
+	if err := execute([]string{"cache", "--no-such-flag"}); err != errUsage {
+		t.Errorf("execute(cache --no-such-flag) = %v, want errUsage", err)
+	}
+	if err := execute([]string{"cache", "bogus"}); !errors.As(err, new(commandError)) {
+		t.Errorf("execute(cache bogus) = %v, want a command failure", err)
+	}
+
+	// Global flags are accepted by every command
+	defer func() { opts.Yes = false }()
+	if err := execute([]string{"cache", "--yes", "ls"}); err != nil || !opts.Yes {
+		t.Errorf("execute(cache --yes ls) = %v, yes = %v", err, opts.Yes)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	var usage bytes.Buffer
+	printUsage(&usage)
+	for _, c := range commands {
+		if !strings.Contains(usage.String(), c.Name) {
+			t.Errorf("usage does not list %s", c.Name)
+		}
+	}
+	if !strings.Contains(usage.String(), "-yes") {
+		t.Error("usage does not list the global flags")
+	}
+
+	mirror, _ := findCommand("mirror")
+	var help bytes.Buffer
+	printCommandHelp(&help, mirror)
+	for _, want := range []string{"install-dotvibe mirror [flags] <dir>", "-base-url string", "-timeout"} {
+		if !strings.Contains(help.String(), want) {
+			t.Errorf("mirror help missing %q:\n%s", want, help.String())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//go:build !slim
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// SCCACHE_VERSION is the sccache release installed for --compile-cache sccache
+const SCCACHE_VERSION = "0.8.2"
+
+// cargoEnv holds extra environment variables for cargo invocations
+var cargoEnv []string
+
+// cargoCommand prepares a cargo invocation with the installer's cargo
+// environment applied
+func cargoCommand(args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(runCtx, "cargo", args...)
+	cmd.Env = append(os.Environ(), cargoEnv...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// compileCacheEnv returns the environment that routes rustc through
+// sccache, sharing cacheDir when set
+func compileCacheEnv(sccache, cacheDir string) []string {
+	env := []string{"RUSTC_WRAPPER=" + sccache}
+	if cacheDir != "" {
+		env = append(env, "SCCACHE_DIR="+cacheDir)
+	}
+	return env
+}
+
+// configureCompileCache sets cargo up to reuse compiled artifacts through
+// sccache according to opts.CompileCache. A RUSTC_WRAPPER the user already
+// exported is left alone.
+func configureCompileCache() error {
+	if opts.CompileCache == COMPILE_CACHE_OFF {
+		return nil
+	}
+	if wrapper := os.Getenv("RUSTC_WRAPPER"); wrapper != "" {
+		fmt.Printf("🗄️  Using existing RUSTC_WRAPPER=%s\n", wrapper)
+		return nil
+	}
+
+	sccache, err := exec.LookPath("sccache")
+	if err != nil && opts.CompileCache == COMPILE_CACHE_SCCACHE {
+		fmt.Printf("📦 Installing sccache v%s for cached builds...\n", SCCACHE_VERSION)
+		if err := cargoCommand("install", "sccache", "--version", SCCACHE_VERSION, "--locked").Run(); err != nil {
+			return fmt.Errorf("failed to install sccache: %w", err)
+		}
+		sccache, err = exec.LookPath("sccache")
+		if err != nil {
+			// cargo's bin directory may not be on PATH in this session
+			sccache = filepath.Join(cargoHome(), "bin", "sccache")
+			if _, statErr := os.Stat(sccache); statErr != nil {
+				return fmt.Errorf("sccache installed but not found: %w", statErr)
+			}
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil // auto mode without sccache: compile normally
+	}
+
+	cacheDir := opts.CompileCacheDir
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return fmt.Errorf("failed to create compile cache directory: %w", err)
+		}
+	}
+
+	cargoEnv = append(cargoEnv, compileCacheEnv(sccache, cacheDir)...)
+	if cacheDir != "" {
+		fmt.Printf("🗄️  Caching compiled crates with sccache in %s\n", cacheDir)
+	} else {
+		fmt.Printf("🗄️  Caching compiled crates with sccache\n")
+	}
+	return nil
+}
+
+// cargoHome returns $CARGO_HOME, defaulting to ~/.cargo
+func cargoHome() string {
+	if home := os.Getenv("CARGO_HOME"); home != "" {
+		return home
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cargo")
+}
@@ -0,0 +1,53 @@
+//go:build !slim
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileCacheEnv(t *testing.T) {
+	env := compileCacheEnv("/usr/bin/sccache", "/mnt/shared/sccache")
+	want := []string{"RUSTC_WRAPPER=/usr/bin/sccache", "SCCACHE_DIR=/mnt/shared/sccache"}
+	if strings.Join(env, " ") != strings.Join(want, " ") {
+		t.Errorf("compileCacheEnv = %v, want %v", env, want)
+	}
+	if env := compileCacheEnv("sccache", ""); len(env) != 1 {
+		t.Errorf("compileCacheEnv without dir = %v", env)
+	}
+}
+
+func TestConfigureCompileCache(t *testing.T) {
+	defer func() { cargoEnv = nil; opts.CompileCache = COMPILE_CACHE_AUTO; opts.CompileCacheDir = "" }()
+
+	// A fake sccache on PATH is picked up in auto mode
+	bin := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "sccache"), []byte("#!/bin/sh\n"), 0755)
+	t.Setenv("PATH", bin)
+	t.Setenv("RUSTC_WRAPPER", "")
+
+	opts.CompileCacheDir = filepath.Join(t.TempDir(), "cache")
+	if err := configureCompileCache(); err != nil {
+		t.Fatalf("configureCompileCache failed: %v", err)
+	}
+	if len(cargoEnv) != 2 || !strings.HasPrefix(cargoEnv[0], "RUSTC_WRAPPER=") {
+		t.Errorf("cargoEnv = %v", cargoEnv)
+	}
+	if _, err := os.Stat(opts.CompileCacheDir); err != nil {
+		t.Errorf("cache dir not created: %v", err)
+	}
+
+	// Off and a user-provided wrapper leave cargo untouched
+	cargoEnv = nil
+	opts.CompileCache = COMPILE_CACHE_OFF
+	configureCompileCache()
+	opts.CompileCache = COMPILE_CACHE_AUTO
+	t.Setenv("RUSTC_WRAPPER", "ccache")
+	configureCompileCache()
+	if len(cargoEnv) != 0 {
+		t.Errorf("cargoEnv = %v, want none", cargoEnv)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// COMPLETION_SHELLS are the shells completion scripts can be generated for
+var COMPLETION_SHELLS = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionWords maps each subcommand ("" for the install command) to the
+// flags and positional words it completes
+func completionWords() map[string][]string {
+	words := map[string][]string{}
+	for _, c := range commands {
+		words[""] = append(words[""], c.Name)
+		words[c.Name] = append(append([]string{}, c.Words...), flagNames(c.Flags)...)
+	}
+	words[""] = append(words[""], flagNames(installFlags)...)
+	return words
+}
+
+// runCompletions implements `install-dotvibe completions bash|zsh|fish|powershell`
+func runCompletions(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	script, err := completionScript(args[0], "install-dotvibe")
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// completionScript generates the completion script for a shell
+func completionScript(shell, program string) (string, error) {
+	words := completionWords()
+	names := make([]string, 0, len(words))
+	for name := range words {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(program)
+	var b strings.Builder
+
+	switch shell {
+	case "bash", "zsh":
+		if shell == "zsh" {
+			fmt.Fprintf(&b, "#compdef %s\nautoload -U +X bashcompinit && bashcompinit\n", program)
+		}
+		fmt.Fprintf(&b, "%s() {\n", fn)
+		b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" words=\"\"\n")
+		b.WriteString("    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+		fmt.Fprintf(&b, "        words=%q\n", strings.Join(words[""], " "))
+		b.WriteString("    else\n        case \"${COMP_WORDS[1]}\" in\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "            %s) words=%q ;;\n", name, strings.Join(words[name], " "))
+		}
+		fmt.Fprintf(&b, "            *) words=%q ;;\n", strings.Join(flagNames(installFlags), " "))
+		b.WriteString("        esac\n    fi\n")
+		b.WriteString("    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n}\n")
+		fmt.Fprintf(&b, "complete -F %s %s\n", fn, program)
+
+	case "fish":
+		for _, c := range commands {
+			fmt.Fprintf(&b, "complete -c %s -f -n '__fish_use_subcommand' -a %s -d %q\n", program, c.Name, c.Summary)
+		}
+		for _, flagName := range flagNames(installFlags) {
+			fmt.Fprintf(&b, "complete -c %s -n '__fish_use_subcommand' -l %s\n", program, strings.TrimPrefix(flagName, "--"))
+		}
+		for _, name := range names {
+			for _, word := range words[name] {
+				if strings.HasPrefix(word, "--") {
+					fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from %s' -l %s\n", program, name, strings.TrimPrefix(word, "--"))
+				} else {
+					fmt.Fprintf(&b, "complete -c %s -f -n '__fish_seen_subcommand_from %s' -a %s\n", program, name, word)
+				}
+			}
+		}
+
+	case "powershell":
+		b.WriteString("$vibeInstallerWords = @{\n")
+		fmt.Fprintf(&b, "    '' = @(%s)\n", psList(words[""]))
+		for _, name := range names {
+			fmt.Fprintf(&b, "    '%s' = @(%s)\n", name, psList(words[name]))
+		}
+		b.WriteString("}\n")
+		fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName '%s', '%s.exe' -ScriptBlock {\n", program, program)
+		b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+		b.WriteString("    $elements = $commandAst.CommandElements\n")
+		b.WriteString("    $sub = ''\n")
+		b.WriteString("    if ($elements.Count -gt 1 -and ($elements.Count -gt 2 -or $wordToComplete -eq '')) { $sub = [string]$elements[1] }\n")
+		b.WriteString("    if (-not $vibeInstallerWords.ContainsKey($sub)) { $sub = '' }\n")
+		b.WriteString("    $vibeInstallerWords[$sub] | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+		b.WriteString("        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+		b.WriteString("    }\n}\n")
+
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(COMPLETION_SHELLS, ", "))
+	}
+
+	return b.String(), nil
+}
+
+// psList formats words as a PowerShell array body
+func psList(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = "'" + w + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range COMPLETION_SHELLS {
+		t.Run(shell, func(t *testing.T) {
+			script, err := completionScript(shell, "install-dotvibe")
+			if err != nil {
+				t.Fatalf("completionScript(%s) failed: %v", shell, err)
+			}
+			for _, want := range []string{"mirror", "uninstall", "purge", "source"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("%s script missing %q", shell, want)
+				}
+			}
+		})
+	}
+
+	if _, err := completionScript("tcsh", "install-dotvibe"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
+
+func TestBashCompletionSyntax(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	script, _ := completionScript("bash", "install-dotvibe")
+	path := filepath.Join(t.TempDir(), "completion.bash")
+	os.WriteFile(path, []byte(script), 0644)
+
+	// Complete `install-dotvibe uninstall --p`
+	probe := "source " + path + "; COMP_WORDS=(install-dotvibe uninstall --p); COMP_CWORD=2; _install_dotvibe; echo ${COMPREPLY[@]}"
+	out, err := exec.Command(bash, "-c", probe).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash completion failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) != "--purge" {
+		t.Errorf("completion of `uninstall --p` = %q, want --purge", out)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// SKIP_SETTING persists the optional components left out in the menu, so
+// upgrades install the same selection
+const SKIP_SETTING = "install.skip"
+
+// menuItem is one optional component of the selection menu
+type menuItem struct {
+	Name    string
+	Detail  string // size or build time estimate and description
+	Checked bool
+}
+
+// isSkipped reports whether an optional component was deselected
+func isSkipped(name string) bool {
+	for _, s := range opts.Skip {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutSkipped drops the optional modules deselected in the menu
+func withoutSkipped(modules []Module) []Module {
+	var kept []Module
+	for _, m := range modules {
+		if !m.Optional || !isSkipped(m.Name) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// componentEstimate describes what installing a module costs: the size of
+// its download when the release manifest lists it, or its build time
+func componentEstimate(m Module, manifest *MirrorManifest) string {
+	if m.Cargo {
+		return "compiled, " + formatEstimate(buildTime(m, runtime.NumCPU()))
+	}
+	if manifest != nil {
+		for _, a := range manifest.Assets {
+			if strings.HasPrefix(a.Path, "grammars/"+m.Name+"@") {
+				return mebibytes(a.Size)
+			}
+		}
+	}
+	return "size unknown"
+}
+
+// componentMenu lists the optional modules with their estimates, checked
+// unless skipped
+func componentMenu(source Source, version string) []menuItem {
+	manifest, _ := fetchReleaseManifest(source, version)
+	var items []menuItem
+	for _, m := range candidateModules() {
+		if !m.Optional {
+			continue
+		}
+		detail := componentEstimate(m, manifest)
+		if m.Description != "" {
+			detail += ", " + m.Description
+		}
+		items = append(items, menuItem{Name: m.Name, Detail: detail, Checked: !isSkipped(m.Name)})
+	}
+	return items
+}
+
+// chooseComponents shows the checkbox menu until the user accepts it with
+// an empty line, toggling the numbered components entered, and returns
+// the names left unchecked
+func chooseComponents(in io.Reader, items []menuItem) []string {
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Printf("🧩 Optional components:\n")
+		for i, item := range items {
+			mark := " "
+			if item.Checked {
+				mark = "x"
+			}
+			fmt.Printf("   %d. [%s] %-28s %s\n", i+1, mark, item.Name, item.Detail)
+		}
+		fmt.Printf("❓ Toggle by number (e.g. 1 3), Enter to continue: ")
+		line, err := reader.ReadString('\n')
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			break
+		}
+		for _, f := range fields {
+			n, convErr := strconv.Atoi(f)
+			if convErr != nil || n < 1 || n > len(items) {
+				fmt.Printf("⚠️  No component %q\n", f)
+				continue
+			}
+			items[n-1].Checked = !items[n-1].Checked
+		}
+		if err != nil {
+			break // input ended without an empty line
+		}
+	}
+
+	skipped := []string{}
+	for _, item := range items {
+		if !item.Checked {
+			skipped = append(skipped, item.Name)
+		}
+	}
+	return skipped
+}
+
+// selectComponents offers the component menu on interactive installs that
+// have not chosen yet, or with --choose-components, and saves the choice
+// to the config file
+func selectComponents(source Source, version string) error {
+	if !opts.ChooseComponents && (opts.ComponentsChosen || opts.Yes || !isInteractive()) {
+		return nil
+	}
+	items := componentMenu(source, version)
+	if len(items) == 0 {
+		return nil
+	}
+	opts.Skip = chooseComponents(os.Stdin, items)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg[SKIP_SETTING] = strings.Join(opts.Skip, ",")
+	if err := cfg.save(); err != nil {
+		return err
+	}
+	opts.ComponentsChosen = true
+	fmt.Printf("💾 Saved as %s in %s; upgrades keep this selection (--choose-components to change it)\n", SKIP_SETTING, getConfigPath())
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChooseComponents(t *testing.T) {
+	items := func() []menuItem {
+		return []menuItem{{Name: "grammar", Checked: true}, {Name: "lsp", Checked: false}, {Name: "docs", Checked: true}}
+	}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"\n", []string{"lsp"}},
+		{"1 2\n\n", []string{"grammar"}},
+		{"3\nx 9\n3\n\n", []string{"lsp"}},
+		{"1", []string{"grammar", "lsp"}}, // input ends without an empty line
+		{"", []string{"lsp"}},
+	}
+	for _, tt := range tests {
+		if got := chooseComponents(strings.NewReader(tt.input), items()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chooseComponents(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSkippedComponents(t *testing.T) {
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	if err := (Config{SKIP_SETTING: "grammar, vibe"}).apply("test"); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !opts.ComponentsChosen || !reflect.DeepEqual(opts.Skip, []string{"grammar", "vibe"}) {
+		t.Fatalf("Skip = %v, chosen %v", opts.Skip, opts.ComponentsChosen)
+	}
+
+	// Only optional modules can be skipped
+	modules := withoutSkipped([]Module{{Name: "grammar", Optional: true}, {Name: "vibe"}, {Name: "docs", Optional: true}})
+	if len(modules) != 2 || modules[0].Name != "vibe" || modules[1].Name != "docs" {
+		t.Errorf("withoutSkipped = %v, want vibe and docs", modules)
+	}
+}
+
+func TestComponentEstimate(t *testing.T) {
+	manifest := &MirrorManifest{Assets: []MirrorAsset{
+		{Path: "grammars/tree-sitter-typescript@0.23.2/tree-sitter-typescript.wasm", Size: 3 << 20},
+	}}
+	grammar := Module{Name: "tree-sitter-typescript", Optional: true}
+	if got := componentEstimate(grammar, manifest); got != mebibytes(3<<20) {
+		t.Errorf("estimate = %q, want the manifest size", got)
+	}
+	if got := componentEstimate(grammar, nil); got != "size unknown" {
+		t.Errorf("estimate without a manifest = %q", got)
+	}
+	if got := componentEstimate(Module{Name: "lsp", Cargo: true}, nil); !strings.HasPrefix(got, "compiled, ") {
+		t.Errorf("estimate of a cargo module = %q, want its build time", got)
+	}
+}
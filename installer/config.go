@@ -0,0 +1,820 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CONFIG_FILE is the installer configuration kept in the vibe home directory
+const CONFIG_FILE = "config.toml"
+
+// Config holds settings as dotted keys ("section.key") mapped to their
+// canonical string form; the schema determines how each is typed in TOML
+type Config map[string]string
+
+// settingKind is the value type of a configuration setting
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindBool
+	kindEnum
+	kindURL
+	kindDuration
+	kindInt
+	kindTemplate
+	kindHeader // text sent in an HTTP header
+)
+
+// Setting describes a configuration key and how its values are validated
+type Setting struct {
+	Key         string
+	Kind        settingKind
+	Default     string
+	Description string
+	Values      []string // allowed values for enums
+	Schemes     []string // allowed URL schemes for URLs
+	apply       func(value string)
+}
+
+// CONFIG_SCHEMA lists every supported setting
+var CONFIG_SCHEMA = []Setting{
+	{
+		Key:         "release.source",
+		Kind:        kindURL,
+		Description: "release source URL; empty means GitHub releases",
+		Schemes:     []string{"s3", "gs", "az", "oci", "oci+http", "http", "https", "github+https", "gitea+https", "forgejo+https", "gitlab+https"},
+		apply:       func(v string) { opts.Source = v },
+	},
+	{
+		Key:         "release.base_url",
+		Kind:        kindURL,
+		Description: "base URL of a static release mirror",
+		Schemes:     []string{"http", "https"},
+		apply:       func(v string) { opts.BaseURL = v },
+	},
+	{
+		Key:         "release.mirrors",
+		Kind:        kindString,
+		Description: "comma-separated extra release sources; each file comes from the fastest healthy one",
+		apply: func(v string) {
+			opts.Mirrors = nil
+			for _, m := range strings.Split(v, ",") {
+				if m = strings.TrimSpace(m); m != "" {
+					opts.Mirrors = append(opts.Mirrors, m)
+				}
+			}
+		},
+	},
+	{
+		Key:         "release.manifest_key",
+		Kind:        kindString,
+		Description: "PEM ed25519 public key release manifests must be signed with",
+		apply:       func(v string) { opts.ManifestKey = v },
+	},
+	{
+		Key:         "release.paranoid",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "only install files whose digest two of SHA256SUMS, the release manifest and the release API agree on",
+		apply:       func(v string) { opts.Paranoid = v == "true" },
+	},
+	{
+		Key:         "release.allow_unsigned",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "install assets the release publishes no cosign signature for",
+		apply:       func(v string) { opts.AllowUnsigned = v == "true" },
+	},
+	{
+		Key:         "release.allow_unverified",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "install assets and grammars no checksum is published for",
+		apply:       func(v string) { opts.AllowUnverified = v == "true" },
+	},
+	{
+		Key:         "release.cosign_identity",
+		Kind:        kindString,
+		Default:     DEFAULT_COSIGN_IDENTITY,
+		Description: "regexp the certificate identity of release cosign signatures must match",
+		apply:       func(v string) { opts.CosignIdentity = v },
+	},
+	{
+		Key:         "release.include_prereleases",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "consider prerelease versions when resolving the latest release",
+		apply:       func(v string) { opts.IncludePrereleases = v == "true" },
+	},
+	{
+		Key:         "release.asset_template",
+		Kind:        kindTemplate,
+		Default:     DEFAULT_ASSET_TEMPLATE,
+		Description: "Go template naming release assets; fields: Version, OS, Arch, Ext, GOOS, GOARCH",
+		apply:       func(v string) { opts.AssetTemplate = v },
+	},
+	{
+		Key:         "release.forge_api",
+		Kind:        kindURL,
+		Description: "release API base of a github+, gitea+, forgejo+ or gitlab+ source, e.g. https://git.example.com/api/v1",
+		Schemes:     []string{"http", "https"},
+		apply:       func(v string) { opts.ForgeAPI = v },
+	},
+	{
+		Key:         "release.forge_releases_path",
+		Kind:        kindString,
+		Description: "forge API path listing releases; {repo} is the repository",
+		apply:       func(v string) { opts.ForgeReleasesPath = v },
+	},
+	{
+		Key:         "release.forge_latest_path",
+		Kind:        kindString,
+		Description: "forge API path of the latest release; {repo} is the repository",
+		apply:       func(v string) { opts.ForgeLatestPath = v },
+	},
+	{
+		Key:         "release.forge_release_path",
+		Kind:        kindString,
+		Description: "forge API path of one release; {repo} is the repository, {tag} the version",
+		apply:       func(v string) { opts.ForgeReleasePath = v },
+	},
+	{
+		Key:         "upgrade.changelog",
+		Kind:        kindEnum,
+		Default:     CHANGELOG_SUMMARY,
+		Description: "release notes shown when upgrading",
+		Values:      []string{CHANGELOG_SUMMARY, CHANGELOG_BREAKING_ONLY, CHANGELOG_NONE},
+		apply:       func(v string) { opts.Changelog = v },
+	},
+	{
+		Key:         "upgrade.allow_breaking",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "upgrade across major or breaking releases without confirmation",
+		apply:       func(v string) { opts.AllowBreaking = v == "true" },
+	},
+	{
+		Key:         "upgrade.skip_migrations",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "do not run upgrade migration steps",
+		apply:       func(v string) { opts.SkipMigrations = v == "true" },
+	},
+	{
+		Key:         "upgrade.rollback_on_error",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "restore the previous binary when an upgrade fails its smoke test or validation; data and receipt are not rolled back",
+		apply:       func(v string) { opts.RollbackOnError = v == "true" },
+	},
+	{
+		Key:         "upgrade.validate_command",
+		Kind:        kindString,
+		Description: "command validating an upgrade, e.g. vibe index --dry-run",
+		apply:       func(v string) { opts.ValidateCommand = v },
+	},
+	{
+		Key:         "build.compile_cache",
+		Kind:        kindEnum,
+		Default:     COMPILE_CACHE_AUTO,
+		Description: "sccache use for cargo builds",
+		Values:      []string{COMPILE_CACHE_AUTO, COMPILE_CACHE_SCCACHE, COMPILE_CACHE_OFF},
+		apply:       func(v string) { opts.CompileCache = v },
+	},
+	{
+		Key:         "build.compile_cache_dir",
+		Kind:        kindString,
+		Description: "shared sccache directory",
+		apply:       func(v string) { opts.CompileCacheDir = v },
+	},
+	{
+		Key:         "build.cargo_registry",
+		Kind:        kindString,
+		Description: "vendored crates directory or registry mirror URL replacing crates.io",
+		apply:       func(v string) { opts.CargoRegistry = v },
+	},
+	{
+		Key:         "build.cargo_offline",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "run cargo with --offline",
+		apply:       func(v string) { opts.CargoOffline = v == "true" },
+	},
+	{
+		Key:         "build.cargo_prefetch",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "download crates with retries before compiling them (on with network.flaky)",
+		apply:       func(v string) { opts.CargoPrefetch = v == "true" },
+	},
+	{
+		Key:         "network.timeout",
+		Kind:        kindDuration,
+		Default:     "0s",
+		Description: "overall time limit of an install run, 0s for none",
+		apply:       func(v string) { opts.Timeout, _ = time.ParseDuration(v) },
+	},
+	{
+		Key:         "network.api_timeout",
+		Kind:        kindDuration,
+		Default:     DEFAULT_API_TIMEOUT.String(),
+		Description: "time limit of each release metadata request",
+		apply:       func(v string) { opts.APITimeout, _ = time.ParseDuration(v) },
+	},
+	{
+		Key:         "network.download_timeout",
+		Kind:        kindDuration,
+		Default:     DEFAULT_DOWNLOAD_TIMEOUT.String(),
+		Description: "time limit of each file download",
+		apply:       func(v string) { opts.DownloadTimeout, _ = time.ParseDuration(v) },
+	},
+	{
+		Key:         "network.stall_timeout",
+		Kind:        kindDuration,
+		Default:     DEFAULT_STALL_TIMEOUT.String(),
+		Description: "retry a download after this long without data, 0s to disable",
+		apply:       func(v string) { opts.StallTimeout, _ = time.ParseDuration(v) },
+	},
+	{
+		Key:         "network.flaky",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "download in small resumable chunks with many retries, for satellite and mobile links",
+		apply:       func(v string) { opts.FlakyNetwork = v == "true" },
+	},
+	{
+		Key:         "network.user_agent_suffix",
+		Kind:        kindHeader,
+		Description: "text appended to the User-Agent of every request",
+		apply:       func(v string) { opts.UserAgentSuffix = v },
+	},
+	{
+		Key:         "network.org_tag",
+		Kind:        kindHeader,
+		Description: "organisation tag sent in the " + ORG_TAG_HEADER + " header of every request",
+		apply:       func(v string) { opts.OrgTag = v },
+	},
+	{
+		Key:         "network.metered",
+		Kind:        kindEnum,
+		Default:     METERED_AUTO,
+		Description: "whether the connection is metered: auto detects it on Linux (NetworkManager) and Windows",
+		Values:      []string{METERED_AUTO, METERED_ON, METERED_OFF},
+		apply:       func(v string) { opts.Metered = v },
+	},
+	{
+		Key:         "network.metered_threshold_mb",
+		Kind:        kindInt,
+		Default:     strconv.Itoa(DEFAULT_METERED_THRESHOLD >> 20),
+		Description: "largest download made on a metered connection without confirmation",
+		apply: func(v string) {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			opts.MeteredThreshold = n << 20
+		},
+	},
+	{
+		Key:         "network.p2p",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "download large assets from peers when the release publishes torrents",
+		apply:       func(v string) { opts.P2P = v == "true" },
+	},
+	{
+		Key:         "cache.enabled",
+		Kind:        kindBool,
+		Default:     "true",
+		Description: "keep downloads in a content-addressed cache for reuse",
+		apply:       func(v string) { opts.NoCache = v != "true" },
+	},
+	{
+		Key:         "cache.max_size_mb",
+		Kind:        kindInt,
+		Default:     strconv.Itoa(DEFAULT_CACHE_MAX_SIZE >> 20),
+		Description: "size above which least recently used downloads are evicted",
+		apply: func(v string) {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			opts.CacheMaxSize = n << 20
+		},
+	},
+	{
+		Key:         "cache.shared_url",
+		Kind:        kindString,
+		Description: "shared cache URL or network path consulted before downloading (VIBE_CACHE_URL)",
+		apply:       func(v string) { opts.SharedCache = v },
+	},
+	{
+		Key:         "install.container",
+		Kind:        kindEnum,
+		Default:     CONTAINER_AUTO,
+		Description: "container defaults: no prompts or PATH edits, /usr/local/bin as root",
+		Values:      []string{CONTAINER_AUTO, CONTAINER_ON, CONTAINER_OFF},
+		apply:       func(v string) { opts.Container = v },
+	},
+	{
+		Key:         "install.init",
+		Kind:        kindBool,
+		Default:     "true",
+		Description: "run vibe's first-run setup (vibe init --defaults) after the first install",
+		apply:       func(v string) { opts.NoInit = v != "true" },
+	},
+	{
+		Key:         SKIP_SETTING,
+		Kind:        kindString,
+		Description: "comma-separated optional components not to install, as chosen in the component menu",
+		apply: func(v string) {
+			opts.Skip = nil
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					opts.Skip = append(opts.Skip, name)
+				}
+			}
+			opts.ComponentsChosen = true
+		},
+	},
+	{
+		Key:         "data.encrypt",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "keep vibe's indexes and databases in an encrypted container",
+		apply:       func(v string) { opts.EncryptData = v == "true" },
+	},
+	{
+		Key:         "database.endpoint",
+		Kind:        kindURL,
+		Description: "SurrealDB server vibe uses, health checked after setup and by doctor, e.g. http://127.0.0.1:8000",
+		Schemes:     []string{"http", "https"},
+		apply:       func(v string) { opts.DBEndpoint = v },
+	},
+	{
+		Key:         "report.path",
+		Kind:        kindString,
+		Description: "where to write the JSON install report",
+		apply:       func(v string) { opts.Report = v },
+	},
+	{
+		Key:         "report.metrics_push_url",
+		Kind:        kindURL,
+		Description: "Prometheus Pushgateway URL to report install metrics to",
+		Schemes:     []string{"http", "https"},
+		apply:       func(v string) { opts.MetricsPushURL = v },
+	},
+
+	// User-defined modules, one [module.<name>] table each
+	{
+		Key:         "module.*.source",
+		Kind:        kindEnum,
+		Default:     MODULE_SOURCE_CARGO,
+		Description: "how the module is installed",
+		Values:      []string{MODULE_SOURCE_CARGO, MODULE_SOURCE_URL, MODULE_SOURCE_ARCHIVE},
+	},
+	{
+		Key:         "module.*.version",
+		Kind:        kindString,
+		Description: "module version, substituted for {version} in its URL",
+	},
+	{
+		Key:         "module.*.package",
+		Kind:        kindString,
+		Description: "cargo package name, if different from the module name",
+	},
+	{
+		Key:         "module.*.url",
+		Kind:        kindURL,
+		Description: "download URL of a url or archive module; {version}, {os} and {arch} are expanded",
+		Schemes:     []string{"http", "https"},
+	},
+	{
+		Key:         "module.*.bin",
+		Kind:        kindString,
+		Description: "executable installed next to vibe, defaults to the module name",
+	},
+	{
+		Key:         "module.*.sha256",
+		Kind:        kindString,
+		Description: "sha256 of the download of a url or archive module, required unless release.allow_unverified is set",
+	},
+	{
+		Key:         "module.*.verify",
+		Kind:        kindString,
+		Description: "command that must succeed after installation",
+	},
+	{
+		Key:         "module.*.optional",
+		Kind:        kindBool,
+		Default:     "false",
+		Description: "warn and complete the install without the module when it fails",
+	},
+}
+
+// getConfigPath returns the location of the configuration file
+func getConfigPath() string {
+	if usesXDG() {
+		return filepath.Join(xdgConfigHome(), XDG_APP_DIR, CONFIG_FILE)
+	}
+	return filepath.Join(getVibeHome(), CONFIG_FILE)
+}
+
+// findSetting looks up a key in the schema. A "*" segment in a schema key
+// matches any single segment; the returned setting carries the actual key.
+func findSetting(key string) (Setting, bool) {
+	for _, s := range CONFIG_SCHEMA {
+		if matchKey(s.Key, key) {
+			s.Key = key
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// matchKey reports whether a dotted key matches a schema key pattern
+func matchKey(pattern, key string) bool {
+	want, got := strings.Split(pattern, "."), strings.Split(key, ".")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] || got[i] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks a raw value against the setting and returns its
+// canonical form
+func (s Setting) validate(value string) (string, error) {
+	switch s.Kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false, got %q", s.Key, value)
+		}
+		return strconv.FormatBool(b), nil
+
+	case kindEnum:
+		for _, allowed := range s.Values {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		msg := fmt.Sprintf("%s must be one of: %s", s.Key, strings.Join(s.Values, ", "))
+		if suggestion := closestMatch(value, s.Values); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		return "", fmt.Errorf("%s", msg)
+
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("%s must be a non-negative whole number, got %q", s.Key, value)
+		}
+		return strconv.Itoa(n), nil
+
+	case kindTemplate:
+		if err := validateAssetTemplate(value); err != nil {
+			return "", fmt.Errorf("%s: %w", s.Key, err)
+		}
+		return value, nil
+
+	case kindHeader:
+		if err := validateHeaderValue(value); err != nil {
+			return "", fmt.Errorf("%s: %w", s.Key, err)
+		}
+		return value, nil
+
+	case kindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return "", fmt.Errorf("%s must be a duration like 90s or 15m, got %q", s.Key, value)
+		}
+		return d.String(), nil
+
+	case kindURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("%s must be a URL like %s://host/path, got %q", s.Key, s.Schemes[0], value)
+		}
+		for _, scheme := range s.Schemes {
+			if u.Scheme == scheme {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%s does not support %s:// URLs (supported: %s)", s.Key, u.Scheme, strings.Join(s.Schemes, ", "))
+	}
+
+	return value, nil
+}
+
+// unknownKeyError reports an unknown setting with the nearest known key
+func unknownKeyError(key string) error {
+	keys := make([]string, len(CONFIG_SCHEMA))
+	for i, s := range CONFIG_SCHEMA {
+		keys[i] = s.Key
+	}
+	if suggestion := closestMatch(key, keys); suggestion != "" {
+		return fmt.Errorf("unknown setting %q (did you mean %q?)", key, suggestion)
+	}
+	return fmt.Errorf("unknown setting %q (run `install-dotvibe config list` to see all settings)", key)
+}
+
+// closestMatch returns the candidate within a small edit distance of s
+func closestMatch(s string, candidates []string) string {
+	best, bestDistance := "", len(s)/2+2
+	for _, c := range candidates {
+		if d := editDistance(s, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// loadConfig reads the configuration file; a missing file is empty config.
+// Until migrateToXDG has moved it, the file in ~/.vibe is read instead.
+func loadConfig() (Config, error) {
+	path := getConfigPath()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && usesXDG() {
+		path = filepath.Join(legacyVibeHome(), CONFIG_FILE)
+		f, err = os.Open(path)
+	}
+	if os.IsNotExist(err) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := parseConfig(bufio.NewScanner(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseConfig parses the TOML subset written by save: [section] headers
+// and key = value pairs with string, boolean or integer values
+func parseConfig(scanner *bufio.Scanner) (Config, error) {
+	cfg := Config{}
+	section := ""
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, raw, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if section != "" {
+			key = section + "." + key
+		}
+
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		cfg[key] = value
+	}
+	return cfg, scanner.Err()
+}
+
+// parseTOMLValue decodes a basic string, boolean or integer TOML value,
+// ignoring trailing comments
+func parseTOMLValue(raw string) (string, error) {
+	if strings.HasPrefix(raw, "\"") {
+		end := strings.LastIndex(raw, "\"")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		return strconv.Unquote(raw[:end+1])
+	}
+
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	if raw == "true" || raw == "false" {
+		return raw, nil
+	}
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return raw, nil
+	}
+	return "", fmt.Errorf("unsupported value %s", raw)
+}
+
+// save writes the configuration grouped by section in key order
+func (c Config) save() error {
+	sections := map[string][]string{}
+	for key := range c {
+		section, name := "", key
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			section, name = key[:i], key[i+1:]
+		}
+		sections[section] = append(sections[section], name)
+	}
+
+	names := make([]string, 0, len(sections))
+	for section := range sections {
+		names = append(names, section)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# dotvibe installer configuration, managed by `install-dotvibe config`\n")
+	for _, section := range names {
+		b.WriteString("\n")
+		prefix := ""
+		if section != "" {
+			fmt.Fprintf(&b, "[%s]\n", section)
+			prefix = section + "."
+		}
+		keys := sections[section]
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s = %s\n", key, formatTOMLValue(prefix+key, c[prefix+key]))
+		}
+	}
+
+	path := getConfigPath()
+	if err := ensureDir(filepath.Dir(path), MODE_PRIVATE_DIR); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := writeFileMode(path, []byte(b.String()), MODE_DATA); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// formatTOMLValue encodes a value according to its setting's kind
+func formatTOMLValue(key, value string) string {
+	if s, ok := findSetting(key); ok && (s.Kind == kindBool || s.Kind == kindInt) {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// applyConfig loads the configuration file into opts. Command-line flags
+// are parsed afterwards and take precedence.
+func applyConfig() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return cfg.apply(getConfigPath())
+}
+
+// apply validates settings read from origin and sets them in opts
+func (c Config) apply(origin string) error {
+	for key, value := range c {
+		setting, ok := findSetting(key)
+		if !ok {
+			fmt.Printf("⚠️  Ignoring %v in %s\n", unknownKeyError(key), origin)
+			continue
+		}
+		canonical, err := setting.validate(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", origin, err)
+		}
+		if setting.apply != nil {
+			setting.apply(canonical)
+		}
+	}
+
+	var err error
+	opts.Modules, err = parseCustomModules(c)
+	return err
+}
+
+// runConfig implements `install-dotvibe config get|set|unset|list`
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 2 {
+			return errUsage
+		}
+		setting, ok := findSetting(args[1])
+		if !ok {
+			return unknownKeyError(args[1])
+		}
+		if value, ok := cfg[setting.Key]; ok {
+			fmt.Println(value)
+		} else {
+			fmt.Println(setting.Default)
+		}
+		return nil
+
+	case "set":
+		if len(args) != 3 {
+			return errUsage
+		}
+		setting, ok := findSetting(args[1])
+		if !ok {
+			return unknownKeyError(args[1])
+		}
+		value, err := setting.validate(args[2])
+		if err != nil {
+			return err
+		}
+		cfg[setting.Key] = value
+		if err := cfg.save(); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s = %s\n", setting.Key, value)
+		return nil
+
+	case "unset":
+		if len(args) != 2 {
+			return errUsage
+		}
+		setting, ok := findSetting(args[1])
+		if !ok {
+			return unknownKeyError(args[1])
+		}
+		delete(cfg, setting.Key)
+		if err := cfg.save(); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s reset to default\n", setting.Key)
+		return nil
+
+	case "list":
+		for _, setting := range CONFIG_SCHEMA {
+			if strings.Contains(setting.Key, "*") {
+				listPatternSettings(cfg, setting)
+				continue
+			}
+			value, set := cfg[setting.Key]
+			if !set {
+				value = setting.Default
+			}
+			marker := " "
+			if set {
+				marker = "*"
+			}
+			fmt.Printf("%s %-26s = %-20q # %s\n", marker, setting.Key, value, setting.Description)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown config command %q (expected get, set, unset or list)", args[0])
+	}
+}
+
+// listPatternSettings prints the configured keys matching a wildcard setting
+func listPatternSettings(cfg Config, setting Setting) {
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		if matchKey(setting.Key, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("* %-26s = %-20q # %s\n", key, cfg[key], setting.Description)
+	}
+}
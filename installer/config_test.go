@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSettingValidate(t *testing.T) {
+	tests := []struct {
+		key       string
+		value     string
+		expected  string
+		wantErr   string
+		wantValid bool
+	}{
+		{key: "upgrade.allow_breaking", value: "TRUE", expected: "true", wantValid: true},
+		{key: "upgrade.allow_breaking", value: "maybe", wantErr: "true or false"},
+		{key: "upgrade.changelog", value: "none", expected: "none", wantValid: true},
+		{key: "upgrade.changelog", value: "sumary", wantErr: `did you mean "summary"`},
+		{key: "release.source", value: "s3://bucket/vibe", expected: "s3://bucket/vibe", wantValid: true},
+		{key: "release.source", value: "ftp://host/vibe", wantErr: "does not support ftp://"},
+		{key: "release.base_url", value: "mirror.local", wantErr: "must be a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			setting, ok := findSetting(tt.key)
+			if !ok {
+				t.Fatalf("setting %s not in schema", tt.key)
+			}
+			got, err := setting.validate(tt.value)
+			if tt.wantValid {
+				if err != nil || got != tt.expected {
+					t.Errorf("validate(%s) = %v, %v, want %v", tt.value, got, err, tt.expected)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate(%s) error = %v, want containing %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnknownKeySuggestion(t *testing.T) {
+	err := unknownKeyError("upgrade.allow_braking")
+	if !strings.Contains(err.Error(), `did you mean "upgrade.allow_breaking"`) {
+		t.Errorf("unknownKeyError() = %v, want suggestion", err)
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+
+	if err := runConfig([]string{"set", "release.source", "oci://ghcr.io/acme/vibe"}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+	if err := runConfig([]string{"set", "upgrade.allow_breaking", "yes"}); err == nil {
+		t.Error("Expected invalid bool to be rejected")
+	}
+	if err := runConfig([]string{"set", "upgrade.allow_breaking", "true"}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(getConfigPath())
+	for _, want := range []string{"[release]\nsource = \"oci://ghcr.io/acme/vibe\"", "[upgrade]\nallow_breaking = true"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config file missing %q:\n%s", want, data)
+		}
+	}
+
+	saved := opts
+	defer func() { opts = saved }()
+	if err := applyConfig(); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if opts.Source != "oci://ghcr.io/acme/vibe" || !opts.AllowBreaking {
+		t.Errorf("applyConfig() opts = %+v", opts)
+	}
+
+	if err := runConfig([]string{"unset", "release.source"}); err != nil {
+		t.Fatalf("config unset failed: %v", err)
+	}
+	cfg, _ := loadConfig()
+	if _, ok := cfg["release.source"]; ok {
+		t.Error("release.source still set after unset")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	input := "# comment\n[upgrade]\nchangelog = \"none\" # inline\nskip_migrations = false\n"
+	cfg, err := parseConfig(bufio.NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	if cfg["upgrade.changelog"] != "none" || cfg["upgrade.skip_migrations"] != "false" {
+		t.Errorf("parseConfig() = %v", cfg)
+	}
+
+	if _, err := parseConfig(bufio.NewScanner(strings.NewReader("novalue\n"))); err == nil {
+		t.Error("Expected error for line without value")
+	}
+}
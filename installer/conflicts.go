@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathConflict is another vibe executable reachable through PATH
+type PathConflict struct {
+	Path    string
+	Version string // empty when the binary could not report one
+	Shadows bool   // true when it wins over the new install in PATH lookup
+}
+
+// findPathConflicts lists vibe executables in pathList other than the one
+// in installPath, noting whether each precedes installPath in lookup order
+func findPathConflicts(pathList, installPath, filename string) []PathConflict {
+	var conflicts []PathConflict
+	installed := filepath.Clean(filepath.Join(installPath, filename))
+	seen := map[string]bool{installed: true}
+	beforeInstall := true
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		if filepath.Clean(dir) == filepath.Clean(installPath) {
+			beforeInstall = false
+			continue
+		}
+
+		candidate := filepath.Clean(filepath.Join(dir, filename))
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		// Symlinks to the new install (e.g. shims) are not conflicts
+		if resolved, err := filepath.EvalSymlinks(candidate); err == nil && resolved == installed {
+			continue
+		}
+
+		version, _ := installedVersion(candidate)
+		conflicts = append(conflicts, PathConflict{Path: candidate, Version: version, Shadows: beforeInstall})
+	}
+	return conflicts
+}
+
+// removalHint suggests how to remove a conflicting binary the installer
+// cannot delete itself
+func removalHint(path string) string {
+	switch {
+	case strings.Contains(path, "Cellar") || strings.HasPrefix(path, "/opt/homebrew") || strings.Contains(path, "linuxbrew"):
+		return "brew uninstall vibe"
+	case strings.Contains(path, filepath.Join(".cargo", "bin")):
+		return "cargo uninstall vibe"
+	default:
+		return "sudo rm " + path
+	}
+}
+
+// checkPathConflicts reports other vibe binaries on PATH after install and
+// offers to remove the ones shadowing the new install
+func checkPathConflicts(installPath, filename string) {
+	conflicts := findPathConflicts(os.Getenv("PATH"), installPath, filename)
+	if len(conflicts) == 0 {
+		return
+	}
+
+	fmt.Printf("\n⚠️  Found other vibe executables on your PATH:\n")
+	for _, c := range conflicts {
+		version := c.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		status := "shadowed by the new install"
+		if c.Shadows {
+			status = "SHADOWS the new install"
+		}
+		fmt.Printf("   • %s (%s) — %s\n", c.Path, version, status)
+	}
+
+	for _, c := range conflicts {
+		if !c.Shadows {
+			continue
+		}
+		if !isUserWritableFile(c.Path) {
+			fmt.Printf("💡 Remove %s with `%s`, or move %s earlier in PATH\n", c.Path, removalHint(c.Path), installPath)
+			continue
+		}
+		if !confirm(fmt.Sprintf("Remove %s so the new install is used?", c.Path)) {
+			fmt.Printf("💡 `vibe` will keep resolving to %s until it is removed or %s moves earlier in PATH\n", c.Path, installPath)
+			continue
+		}
+		if err := os.Remove(c.Path); err != nil {
+			fmt.Printf("❌ Failed to remove %s: %v\n", c.Path, err)
+			continue
+		}
+		fmt.Printf("✅ Removed %s\n", c.Path)
+	}
+}
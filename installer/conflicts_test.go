@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindPathConflicts(t *testing.T) {
+	_, _, filename := detectPlatform()
+	before, install, after, empty := t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{before, install, after} {
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte("#!/bin/sh\necho vibe 0.7.1\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pathList := strings.Join([]string{empty, before, install, after, before}, string(os.PathListSeparator))
+	conflicts := findPathConflicts(pathList, install, filename)
+
+	if len(conflicts) != 2 {
+		t.Fatalf("findPathConflicts() = %+v, want 2 conflicts", conflicts)
+	}
+	if conflicts[0].Path != filepath.Join(before, filename) || !conflicts[0].Shadows {
+		t.Errorf("first conflict = %+v, want shadowing binary in %s", conflicts[0], before)
+	}
+	if conflicts[1].Path != filepath.Join(after, filename) || conflicts[1].Shadows {
+		t.Errorf("second conflict = %+v, want shadowed binary in %s", conflicts[1], after)
+	}
+	if runtime.GOOS != "windows" && conflicts[0].Version != "v0.7.1" {
+		t.Errorf("conflict version = %q, want v0.7.1", conflicts[0].Version)
+	}
+}
+
+func TestRemovalHint(t *testing.T) {
+	tests := map[string]string{
+		"/opt/homebrew/bin/vibe":  "brew uninstall vibe",
+		"/home/u/.cargo/bin/vibe": "cargo uninstall vibe",
+		"/usr/local/bin/vibe":     "sudo rm /usr/local/bin/vibe",
+	}
+	for path, expected := range tests {
+		if got := removalHint(path); got != expected {
+			t.Errorf("removalHint(%s) = %v, want %v", path, got, expected)
+		}
+	}
+}
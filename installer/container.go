@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Container modes (--container, install.container setting)
+const (
+	CONTAINER_AUTO = "auto" // container defaults when a container is detected
+	CONTAINER_ON   = "on"
+	CONTAINER_OFF  = "off"
+)
+
+// containerMode is set when the run uses container defaults
+var containerMode bool
+
+// detectContainer names the container runtime the installer runs under,
+// or returns "" on a regular machine. Paths are rooted at root for tests.
+func detectContainer(root string, getenv func(string) string) string {
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(root, path))
+		return err == nil
+	}
+	switch {
+	case getenv("KUBERNETES_SERVICE_HOST") != "" || exists("var/run/secrets/kubernetes.io/serviceaccount"):
+		return "Kubernetes"
+	case exists("run/.containerenv") || getenv("container") == "podman":
+		return "Podman"
+	case exists(".dockerenv"):
+		return "Docker"
+	}
+
+	// cgroup v1 paths name the runtime; cgroup v2 hides them, hence the files above
+	cgroup, _ := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup"))
+	switch s := string(cgroup); {
+	case strings.Contains(s, "kubepods"):
+		return "Kubernetes"
+	case strings.Contains(s, "libpod"):
+		return "Podman"
+	case strings.Contains(s, "docker"), strings.Contains(s, "containerd"):
+		return "Docker"
+	}
+	return ""
+}
+
+// applyContainerDefaults makes `RUN install-dotvibe` work in a Dockerfile:
+// no prompts, no shell profile edits, progress kept out of build logs, and
+// an install into /usr/local/bin when building as root
+func applyContainerDefaults(mode, runtime string) {
+	if mode == CONTAINER_OFF || mode == CONTAINER_AUTO && runtime == "" {
+		return
+	}
+	if runtime == "" {
+		runtime = "a container"
+	}
+	containerMode = true
+	opts.Yes = true
+	opts.NoModifyPath = true
+	if runningAsRoot() {
+		opts.AllowRoot = true
+	}
+	fmt.Printf("📦 %s detected, using container defaults (--container=off to disable)\n", runtime)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectContainer(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	for name, tc := range map[string]struct {
+		file, content string
+		env           map[string]string
+		want          string
+	}{
+		"docker":         {file: ".dockerenv", want: "Docker"},
+		"podman":         {file: "run/.containerenv", want: "Podman"},
+		"serviceaccount": {file: "var/run/secrets/kubernetes.io/serviceaccount/token", want: "Kubernetes"},
+		"kubernetes env": {env: map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, want: "Kubernetes"},
+		"cgroup v1":      {file: "proc/1/cgroup", content: "12:pids:/kubepods/burstable/pod1234/abcd\n", want: "Kubernetes"},
+		"cgroup docker":  {file: "proc/1/cgroup", content: "4:memory:/docker/0123456789ab\n", want: "Docker"},
+		"host":           {file: "proc/1/cgroup", content: "0::/init.scope\n", want: ""},
+	} {
+		root := t.TempDir()
+		if tc.file != "" {
+			os.MkdirAll(filepath.Join(root, filepath.Dir(tc.file)), 0755)
+			os.WriteFile(filepath.Join(root, tc.file), []byte(tc.content), 0644)
+		}
+		getenv := noEnv
+		if tc.env != nil {
+			getenv = func(key string) string { return tc.env[key] }
+		}
+		if got := detectContainer(root, getenv); got != tc.want {
+			t.Errorf("%s: detectContainer = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestApplyContainerDefaults(t *testing.T) {
+	saved := opts
+	defer func() { opts, containerMode = saved, false }()
+
+	applyContainerDefaults(CONTAINER_AUTO, "")
+	if containerMode || opts.Yes || opts.NoModifyPath {
+		t.Errorf("container defaults applied outside a container")
+	}
+	applyContainerDefaults(CONTAINER_OFF, "Docker")
+	if containerMode {
+		t.Errorf("--container=off applied container defaults")
+	}
+	applyContainerDefaults(CONTAINER_AUTO, "Docker")
+	if !containerMode || !opts.Yes || !opts.NoModifyPath {
+		t.Errorf("container defaults = yes %v, no-modify-path %v; want both", opts.Yes, opts.NoModifyPath)
+	}
+	if runningAsRoot() && !opts.AllowRoot {
+		t.Errorf("root in a container did not install into %s", ROOT_INSTALL_PATH)
+	}
+}
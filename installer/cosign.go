@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// The release workflow signs every asset with cosign's keyless flow and
+// publishes <asset>.sig and <asset>.pem next to it: the signature and the
+// short-lived Fulcio certificate binding it to the workflow's GitHub OIDC
+// identity. Verification is left to the cosign CLI, which also checks the
+// certificate chain and the Rekor transparency log entry. An asset is only
+// installed once cosign accepts its signature: a missing signature is
+// refused like a bad one, since whoever can replace an asset can delete its
+// signature too. --allow-unsigned lets releases from before signing
+// through, and --no-verify skips the check altogether.
+const (
+	COSIGN_CLIENT          = "cosign"
+	COSIGN_SIGNATURE_EXT   = ".sig"
+	COSIGN_CERTIFICATE_EXT = ".pem"
+	COSIGN_OIDC_ISSUER     = "https://token.actions.githubusercontent.com"
+
+	// DEFAULT_COSIGN_IDENTITY matches the certificate identity of the
+	// release workflows of vhybzOS/.vibe; forks set their own with
+	// --cosign-identity
+	DEFAULT_COSIGN_IDENTITY = `^https://github\.com/vhybzOS/\.vibe/\.github/workflows/.+@refs/tags/v.+$`
+)
+
+// cosignFiles returns the signature and certificate assets of asset
+func cosignFiles(asset string) []string {
+	return []string{asset + COSIGN_SIGNATURE_EXT, asset + COSIGN_CERTIFICATE_EXT}
+}
+
+// verifySignature checks the cosign signature the release publishes for a
+// staged asset, doing nothing with --no-verify. Without a signature the
+// asset is refused, unless --allow-unsigned accepts that.
+func verifySignature(source Source, version, name, path string) error {
+	if opts.NoVerify {
+		return nil
+	}
+
+	var staged []string
+	defer func() {
+		for _, p := range staged {
+			os.Remove(p)
+		}
+	}()
+	for i, file := range cosignFiles(name) {
+		p, err := quarantinePath(file)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, p)
+		err = source.FetchAsset(version, file, p)
+		if errors.Is(err, errAssetNotFound) && i == 0 {
+			if opts.AllowUnsigned {
+				warnf("Release %s publishes no cosign signature for %s; installing it unsigned (--allow-unsigned)", version, name)
+				return nil
+			}
+			return fmt.Errorf("release %s publishes no cosign signature for %s; pass --allow-unsigned to install releases from before signing", version, name)
+		}
+		if err != nil && i == 0 {
+			return fmt.Errorf("cannot check whether %s is signed (pass --no-verify to skip): %w", name, err)
+		}
+		if err != nil {
+			return fmt.Errorf("%s is signed, but its %s could not be downloaded: %w", name, file, err)
+		}
+	}
+
+	if _, err := exec.LookPath(COSIGN_CLIENT); err != nil {
+		return fmt.Errorf("%s of %s is signed; install %s to verify it, or pass --no-verify", name, version, COSIGN_CLIENT)
+	}
+	ctx, cancel := requestContext(opts.APITimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, COSIGN_CLIENT, "verify-blob",
+		"--signature", staged[0],
+		"--certificate", staged[1],
+		"--certificate-identity-regexp", opts.CosignIdentity,
+		"--certificate-oidc-issuer", COSIGN_OIDC_ISSUER,
+		path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cosign signature of %s does not verify: %v\n%s", name, err, strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("🔏 Verified the cosign signature of %s\n", name)
+	return nil
+}
+
+// mirrorSignatures copies the cosign files of asset into dir when the
+// release publishes them, so installs from the mirror verify them too.
+// The signature is useless without its certificate, so both are mirrored
+// or neither.
+func mirrorSignatures(source Source, version, asset, dir string) error {
+	files := cosignFiles(asset)
+	for _, file := range files {
+		err := source.FetchAsset(version, file, filepath.Join(dir, file))
+		if err == nil {
+			continue
+		}
+		for _, f := range files {
+			os.Remove(filepath.Join(dir, f))
+		}
+		if errors.Is(err, errAssetNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to mirror %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeCosign puts a cosign into PATH that records its arguments and exits
+// with status
+func fakeCosign(t *testing.T, status string) string {
+	t.Helper()
+	bin := t.TempDir()
+	args := filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + args + "\nexit " + status + "\n"
+	if err := os.WriteFile(filepath.Join(bin, COSIGN_CLIENT), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	return args
+}
+
+func TestVerifySignature(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cosign is a shell script")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts = Options{CosignIdentity: DEFAULT_COSIGN_IDENTITY}
+
+	asset := filepath.Join(t.TempDir(), "vibe")
+	os.WriteFile(asset, []byte("binary"), 0644)
+	name := "vibe-v1.0.0-linux-x86_64"
+
+	unsigned := &variantSource{}
+	if err := verifySignature(unsigned, "v1.0.0", name, asset); err == nil || !strings.Contains(err.Error(), "--allow-unsigned") {
+		t.Errorf("an unsigned release was accepted: %v", err)
+	}
+	opts.AllowUnsigned = true
+	if err := verifySignature(unsigned, "v1.0.0", name, asset); err != nil {
+		t.Errorf("an unsigned release was refused with --allow-unsigned: %v", err)
+	}
+	opts.AllowUnsigned = false
+
+	t.Setenv("PATH", t.TempDir())
+	if err := verifySignature(fakeSource{}, "v1.0.0", name, asset); err == nil || !strings.Contains(err.Error(), "--no-verify") {
+		t.Errorf("a signed asset was accepted without cosign: %v", err)
+	}
+
+	args := fakeCosign(t, "0")
+	if err := verifySignature(fakeSource{}, "v1.0.0", name, asset); err != nil {
+		t.Fatalf("verifySignature failed: %v", err)
+	}
+	data, _ := os.ReadFile(args)
+	for _, want := range []string{"verify-blob", "--certificate-identity-regexp " + DEFAULT_COSIGN_IDENTITY, "--certificate-oidc-issuer " + COSIGN_OIDC_ISSUER, asset} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("cosign ran with %q, missing %q", data, want)
+		}
+	}
+
+	fakeCosign(t, "1")
+	if err := verifySignature(fakeSource{}, "v1.0.0", name, asset); err == nil {
+		t.Error("an asset cosign rejected was accepted")
+	}
+	opts.NoVerify = true
+	if err := verifySignature(fakeSource{}, "v1.0.0", name, asset); err != nil {
+		t.Errorf("--no-verify still verified: %v", err)
+	}
+}
+
+func TestMirrorSignaturesPaired(t *testing.T) {
+	asset := "vibe-v1.0.0-linux-x86_64"
+	sig, cert := asset+COSIGN_SIGNATURE_EXT, asset+COSIGN_CERTIFICATE_EXT
+
+	dir := t.TempDir()
+	signed := &variantSource{files: map[string][]byte{sig: []byte("signature"), cert: []byte("certificate")}}
+	if err := mirrorSignatures(signed, "v1.0.0", asset, dir); err != nil {
+		t.Fatalf("mirrorSignatures failed: %v", err)
+	}
+	for _, file := range []string{sig, cert} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("%s not mirrored: %v", file, err)
+		}
+	}
+
+	// A signature without its certificate is left out altogether
+	dir = t.TempDir()
+	partial := &variantSource{files: map[string][]byte{sig: []byte("signature")}}
+	if err := mirrorSignatures(partial, "v1.0.0", asset, dir); err != nil {
+		t.Fatalf("mirrorSignatures failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("mirrored a partial signature: %v", entries)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Credentials live in the OS keychain, never in the config file. The
+// credentials file under the vibe home only names the stored accounts, so
+// requests to hosts without one never wake the keychain. Environment
+// variables (GITHUB_TOKEN, VIBE_REGISTRY_USER/VIBE_REGISTRY_TOKEN) still
+// take precedence, for CI.
+const (
+	CREDENTIALS_FILE = "credentials.json"
+
+	CRED_GITHUB      = "github" // GitHub token for the API rate limit and private releases
+	CRED_HOST_PREFIX = "host:"  // "user:password" or a bearer token for a mirror, registry or Pushgateway
+)
+
+// credentialIndex lists the accounts stored in the keychain
+type credentialIndex struct {
+	Accounts []string `json:"accounts"`
+}
+
+// getCredentialIndexPath returns where the list of stored accounts is kept
+func getCredentialIndexPath() string {
+	return filepath.Join(getVibeHome(), CREDENTIALS_FILE)
+}
+
+// loadCredentialIndex reads the stored accounts, empty when there are none
+func loadCredentialIndex() credentialIndex {
+	var index credentialIndex
+	if data, err := os.ReadFile(getCredentialIndexPath()); err == nil {
+		json.Unmarshal(data, &index)
+	}
+	return index
+}
+
+func (c credentialIndex) has(account string) bool {
+	for _, a := range c.Accounts {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
+func (c credentialIndex) save() error {
+	path := getCredentialIndexPath()
+	if err := ensureDir(filepath.Dir(path), MODE_PRIVATE_DIR); err != nil {
+		return err
+	}
+	sort.Strings(c.Accounts)
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileMode(path, append(data, '\n'), MODE_DATA)
+}
+
+// credentialCache keeps keychain lookups to one per account and run
+var credentialCache = struct {
+	sync.Mutex
+	values map[string]string
+}{values: map[string]string{}}
+
+// storedCredential returns a secret from the keychain, or "" when none is
+// stored or the keychain cannot be read
+func storedCredential(account string) string {
+	credentialCache.Lock()
+	defer credentialCache.Unlock()
+	if secret, ok := credentialCache.values[account]; ok {
+		return secret
+	}
+	secret := ""
+	if loadCredentialIndex().has(account) {
+		var err error
+		if secret, err = keychainGet(account); err != nil {
+			warnf("Could not read the %s credential: %v", account, err)
+		}
+	}
+	credentialCache.values[account] = secret
+	return secret
+}
+
+// forgetCredential drops an account from the lookup cache after it changed
+func forgetCredential(account string) {
+	credentialCache.Lock()
+	delete(credentialCache.values, account)
+	credentialCache.Unlock()
+}
+
+// githubToken returns the GitHub token of GITHUB_TOKEN or `auth login`
+func githubToken() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return storedCredential(CRED_GITHUB)
+}
+
+// hostCredential returns the credential stored for a host with `auth login <host>`
+func hostCredential(host string) string {
+	return storedCredential(CRED_HOST_PREFIX + strings.ToLower(host))
+}
+
+// authorize adds the stored credential of the request's host, as basic
+// auth for "user:password" and as a bearer token otherwise. Requests that
+// already carry credentials are left alone.
+func authorize(req *http.Request) {
+	if req.Header.Get("Authorization") != "" || req.URL.User != nil {
+		return
+	}
+	secret := hostCredential(req.URL.Host)
+	if secret == "" {
+		return
+	}
+	if user, password, ok := strings.Cut(secret, ":"); ok {
+		req.SetBasicAuth(user, password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+}
+
+// credentialAccount maps an `auth` target to its keychain account
+func credentialAccount(target string) (string, error) {
+	if target == CRED_GITHUB {
+		return CRED_GITHUB, nil
+	}
+	if target == "" || strings.ContainsAny(target, "/ ") {
+		return "", fmt.Errorf("%q is not github or a host[:port]", target)
+	}
+	return CRED_HOST_PREFIX + strings.ToLower(target), nil
+}
+
+// readSecret reads a secret from stdin, prompting when it is a terminal
+func readSecret(prompt string) (string, error) {
+	if isInteractive() {
+		fmt.Print(prompt)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("no credential on stdin")
+	}
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		return "", fmt.Errorf("empty credential")
+	}
+	return secret, nil
+}
+
+// authFlags registers the flags of the auth command
+func authFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.WithToken, "with-token", opts.WithToken, "read a GitHub token from stdin instead of logging in through the browser")
+	fs.StringVar(&opts.AuthScope, "scope", opts.AuthScope, "OAuth scopes to request from GitHub; repo reaches private releases")
+}
+
+// authLogin stores a credential in the keychain. GitHub tokens come from
+// the device flow on a terminal; everything else is read from stdin.
+func authLogin(target string) error {
+	account, err := credentialAccount(target)
+	if err != nil {
+		return err
+	}
+	var secret string
+	switch {
+	case account == CRED_GITHUB && !opts.WithToken && isInteractive():
+		secret, err = githubLoginToken()
+	case account == CRED_GITHUB:
+		secret, err = readSecret("🔑 Paste a GitHub token: ")
+	default:
+		secret, err = readSecret(fmt.Sprintf("🔑 Credential for %s (user:password, or a token): ", target))
+	}
+	if err != nil {
+		return err
+	}
+	if err := keychainSet(account, secret); err != nil {
+		return err
+	}
+	forgetCredential(account)
+
+	index := loadCredentialIndex()
+	if !index.has(account) {
+		index.Accounts = append(index.Accounts, account)
+		if err := index.save(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✅ Stored the %s credential in the OS keychain\n", target)
+	return nil
+}
+
+// authLogout removes a credential from the keychain
+func authLogout(target string) error {
+	account, err := credentialAccount(target)
+	if err != nil {
+		return err
+	}
+	index := loadCredentialIndex()
+	if !index.has(account) {
+		return fmt.Errorf("no %s credential is stored", target)
+	}
+	if err := keychainDelete(account); err != nil {
+		warnf("%v", err)
+	}
+	forgetCredential(account)
+
+	kept := index.Accounts[:0]
+	for _, a := range index.Accounts {
+		if a != account {
+			kept = append(kept, a)
+		}
+	}
+	index.Accounts = kept
+	if err := index.save(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Removed the %s credential\n", target)
+	return nil
+}
+
+// runAuth implements `install-dotvibe auth login|logout [github | <host>]`
+func runAuth(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errUsage
+	}
+	target := CRED_GITHUB
+	if len(args) == 2 {
+		target = args[1]
+	}
+	switch args[0] {
+	case "login":
+		return authLogin(target)
+	case "logout":
+		return authLogout(target)
+	}
+	return errUsage
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// withStdin feeds input to code reading os.Stdin
+func withStdin(t *testing.T, input string) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString(input)
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin; r.Close() })
+}
+
+func TestAuthLoginLogout(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes the Secret Service's secret-tool")
+	}
+	keyring, _ := fakeKeyring(t)
+	t.Setenv("VIBE_HOME", t.TempDir())
+	t.Setenv("GITHUB_TOKEN", "")
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	host := mustHost(t, server.URL)
+
+	// Hosts without a stored credential are requested anonymously
+	resp, err := httpGet(server.URL, opts.APITimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if gotAuth != "" {
+		t.Errorf("anonymous request sent Authorization %q", gotAuth)
+	}
+
+	withStdin(t, "ghp_example\n")
+	if err := runAuth([]string{"login"}); err != nil {
+		t.Fatalf("auth login failed: %v", err)
+	}
+	if token := githubToken(); token != "ghp_example" {
+		t.Errorf("githubToken() = %q, want the stored token", token)
+	}
+
+	withStdin(t, "alice:s3cret\n")
+	if err := runAuth([]string{"login", host}); err != nil {
+		t.Fatalf("auth login %s failed: %v", host, err)
+	}
+	resp, err = httpGet(server.URL, opts.APITimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.SetBasicAuth("alice", "s3cret")
+	if gotAuth != req.Header.Get("Authorization") {
+		t.Errorf("mirror request sent Authorization %q, want basic auth of the stored credential", gotAuth)
+	}
+
+	// The config directory only names the accounts; secrets stay in the keychain
+	index, _ := os.ReadFile(getCredentialIndexPath())
+	if strings.Contains(string(index), "s3cret") || strings.Contains(string(index), "ghp_example") {
+		t.Errorf("credential index holds a secret: %s", index)
+	}
+
+	if err := runAuth([]string{"logout", host}); err != nil {
+		t.Fatalf("auth logout failed: %v", err)
+	}
+	if secret := hostCredential(host); secret != "" {
+		t.Errorf("credential survived logout: %q", secret)
+	}
+	if _, err := os.Stat(keyring + "/" + CRED_HOST_PREFIX + host); !os.IsNotExist(err) {
+		t.Errorf("keychain entry survived logout")
+	}
+	if err := runAuth([]string{"logout", host}); err == nil {
+		t.Errorf("logging out twice succeeded")
+	}
+}
+
+func mustHost(t *testing.T, raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
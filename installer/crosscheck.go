@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// digestOrigin is one publisher of a release's sha256 digests. Origins are
+// independent when compromising one does not let an attacker rewrite the
+// other: SHA256SUMS is uploaded by the release job, the manifest is
+// written (and possibly signed) by whoever mirrors the release, and the
+// forge computes the release API digest itself on upload.
+type digestOrigin struct {
+	Name    string
+	Digests map[string]string // file name -> lowercase hex digest
+}
+
+// apiDigests returns the digests the source's release API reports for the
+// assets of version, empty when it reports none
+func apiDigests(source Source, version string) map[string]string {
+	digests := map[string]string{}
+	lister, ok := originSource(source).(AssetLister)
+	if !ok {
+		return digests
+	}
+	assets, err := lister.ReleaseAssets(version)
+	if err != nil {
+		return digests
+	}
+	for _, a := range assets {
+		if hex, ok := strings.CutPrefix(a.Digest, "sha256:"); ok {
+			digests[a.Name] = strings.ToLower(hex)
+		}
+	}
+	return digests
+}
+
+// originSource returns the source behind the download caches and the
+// peer-to-peer transport, which alone can answer release API queries
+func originSource(source Source) Source {
+	for {
+		switch s := source.(type) {
+		case cachedSource:
+			source = s.Source
+		case sharedCacheSource:
+			source = s.Source
+		case *p2pSource:
+			source = s.Source
+		default:
+			return source
+		}
+	}
+}
+
+// crossCheckDigests keeps the digests listed by at least two origins,
+// failing when any origins disagree about a file: a single compromised
+// origin can then neither substitute an asset nor vouch for it alone
+func crossCheckDigests(version string, origins []digestOrigin) (map[string]string, error) {
+	var available []string
+	listedBy := map[string][]digestOrigin{}
+	for _, o := range origins {
+		if len(o.Digests) == 0 {
+			continue
+		}
+		available = append(available, o.Name)
+		for name := range o.Digests {
+			listedBy[name] = append(listedBy[name], o)
+		}
+	}
+	if len(available) < 2 {
+		return nil, fmt.Errorf("--paranoid needs digests from two independent sources, but release %s only has %s",
+			version, describeOrigins(available))
+	}
+
+	names := make([]string, 0, len(listedBy))
+	for name := range listedBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	digests := map[string]string{}
+	for _, name := range names {
+		first := listedBy[name][0]
+		for _, o := range listedBy[name][1:] {
+			if o.Digests[name] != first.Digests[name] {
+				return nil, fmt.Errorf("digests of %s in %s disagree: %s says %s, %s says %s",
+					name, version, first.Name, short(first.Digests[name]), o.Name, short(o.Digests[name]))
+			}
+		}
+		if len(listedBy[name]) >= 2 {
+			digests[name] = first.Digests[name]
+		}
+	}
+	return digests, nil
+}
+
+// describeOrigins joins origin names for a message
+func describeOrigins(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, " and ")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// apiDigestSource serves release files and reports forge-computed digests
+type apiDigestSource struct {
+	matrixSource
+	digests map[string]string
+}
+
+func (s apiDigestSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	var assets []ReleaseAsset
+	for name, digest := range s.digests {
+		assets = append(assets, ReleaseAsset{Name: name, Digest: "sha256:" + digest})
+	}
+	return assets, nil
+}
+
+func TestParanoidDigests(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts = Options{Paranoid: true}
+
+	asset := releaseAssetName("linux", "amd64", "v1.0.0")
+	good, evil := sha256Hex("vibe"), sha256Hex("evil")
+	files := map[string]string{"SHA256SUMS": good + "  " + asset + "\n" + good + "  notes.txt\n"}
+
+	digests, err := releaseDigests(apiDigestSource{matrixSource{files: files}, map[string]string{asset: good}}, "v1.0.0")
+	if err != nil {
+		t.Fatalf("releaseDigests failed: %v", err)
+	}
+	if digests[asset] != good {
+		t.Errorf("digest of %s = %q, want the agreed one", asset, digests[asset])
+	}
+	if _, ok := digests["notes.txt"]; ok {
+		t.Error("a digest listed by one source was trusted")
+	}
+
+	// Installs wrap the source in the download caches
+	opts.NoCache = false
+	opts.CacheMaxSize = DEFAULT_CACHE_MAX_SIZE
+	wrapped := withCache(sharedCacheSource{Source: apiDigestSource{matrixSource{files: files}, map[string]string{asset: good}}, location: t.TempDir()})
+	if digests, err := releaseDigests(wrapped, "v1.0.0"); err != nil || digests[asset] != good {
+		t.Errorf("releaseDigests through the caches = %v, %v; want the agreed digest", digests, err)
+	}
+
+	_, err = releaseDigests(apiDigestSource{matrixSource{files: files}, map[string]string{asset: evil}}, "v1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "disagree") {
+		t.Errorf("releaseDigests = %v, want a disagreement", err)
+	}
+
+	_, err = releaseDigests(matrixSource{files: files}, "v1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "only has SHA256SUMS") {
+		t.Errorf("releaseDigests = %v, want a refusal for a single source", err)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// How a user-defined module is installed
+const (
+	MODULE_SOURCE_CARGO   = "cargo"   // cargo install <package> --version <version>
+	MODULE_SOURCE_URL     = "url"     // a single executable downloaded as-is
+	MODULE_SOURCE_ARCHIVE = "archive" // a .tar.gz or .zip containing the executable
+)
+
+// CHANGE_FILE is a receipt change for a file the installer created; Path is
+// the file
+const CHANGE_FILE = "file"
+
+// CustomModule is an extra tool declared in a [module.<name>] config table
+type CustomModule struct {
+	Name     string
+	Source   string
+	Version  string
+	Package  string
+	URL      string
+	Bin      string
+	SHA256   string
+	Verify   string
+	Optional bool
+}
+
+// parseCustomModules collects the [module.<name>] tables of the config into
+// modules, checking each has what its source type needs
+func parseCustomModules(cfg Config) ([]CustomModule, error) {
+	byName := map[string]*CustomModule{}
+	for key, value := range cfg {
+		parts := strings.Split(key, ".")
+		if len(parts) != 3 || parts[0] != "module" {
+			continue
+		}
+		m, ok := byName[parts[1]]
+		if !ok {
+			m = &CustomModule{Name: parts[1], Source: MODULE_SOURCE_CARGO}
+			byName[parts[1]] = m
+		}
+		switch parts[2] {
+		case "source":
+			m.Source = value
+		case "version":
+			m.Version = value
+		case "package":
+			m.Package = value
+		case "url":
+			m.URL = value
+		case "bin":
+			m.Bin = value
+		case "sha256":
+			m.SHA256 = value
+		case "verify":
+			m.Verify = value
+		case "optional":
+			m.Optional, _ = strconv.ParseBool(value) // validated with the other settings
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	modules := make([]CustomModule, 0, len(names))
+	for _, name := range names {
+		m := byName[name]
+		for _, builtin := range MODULES {
+			if builtin.Name == name {
+				return nil, fmt.Errorf("module.%s: %s is a built-in module", name, name)
+			}
+		}
+		switch m.Source {
+		case MODULE_SOURCE_CARGO:
+			if m.Version == "" {
+				return nil, fmt.Errorf("module.%s: cargo modules need a version", name)
+			}
+		case MODULE_SOURCE_URL, MODULE_SOURCE_ARCHIVE:
+			if m.URL == "" {
+				return nil, fmt.Errorf("module.%s: %s modules need a url", name, m.Source)
+			}
+			if _, err := hex.DecodeString(m.SHA256); err != nil || (m.SHA256 != "" && len(m.SHA256) != 64) {
+				return nil, fmt.Errorf("module.%s: sha256 must be 64 hex digits", name)
+			}
+		}
+		modules = append(modules, *m)
+	}
+	return modules, nil
+}
+
+// allModules returns the modules to install: the candidates without the
+// optional ones deselected in the component menu
+func allModules() []Module {
+	return withoutSkipped(candidateModules())
+}
+
+// candidateModules returns the built-in modules followed by user-defined
+// ones, with --prebuilt replacing compiled modules by their official
+// binaries
+func candidateModules() []Module {
+	modules := append([]Module{}, MODULES...)
+	if opts.Prebuilt {
+		modules = withPrebuilt(modules)
+	}
+	if isTermux() {
+		modules = termuxSupported(modules)
+	}
+	for _, m := range opts.Modules {
+		modules = append(modules, m.module())
+	}
+	return modules
+}
+
+// module adapts a user-defined module to the install pipeline
+func (m CustomModule) module() Module {
+	module := Module{Name: m.Name, Version: m.Version, Cargo: m.Source == MODULE_SOURCE_CARGO, Optional: m.Optional}
+
+	switch m.Source {
+	case MODULE_SOURCE_CARGO:
+		pkg := m.Package
+		if pkg == "" {
+			pkg = m.Name
+		}
+		module.Install = cargoModule(pkg, m.Version)
+		module.Explain = func(string, Source) Explanation {
+			bin := m.Bin
+			if bin == "" {
+				bin = pkg
+			}
+			return explainCargo(pkg, m.Version, bin)
+		}
+	default:
+		module.Install = m.installDownload
+		module.Explain = m.explainDownload
+	}
+
+	if fields := strings.Fields(m.Verify); len(fields) > 0 {
+		module.Verify = func() error {
+			if out, err := exec.Command(fields[0], fields[1:]...).CombinedOutput(); err != nil {
+				return fmt.Errorf("verification failed for %s: %s", m.Name, strings.TrimSpace(string(out)))
+			}
+			return nil
+		}
+	}
+	return module
+}
+
+// binName returns the executable file name of a url or archive module
+func (m CustomModule) binName() string {
+	bin := m.Bin
+	if bin == "" {
+		bin = m.Name
+	}
+	if runtime.GOOS == "windows" && !strings.HasSuffix(bin, ".exe") {
+		bin += ".exe"
+	}
+	return bin
+}
+
+// expandURL substitutes {version}, {os} and {arch} in the module URL
+func (m CustomModule) expandURL() string {
+	return strings.NewReplacer(
+		"{version}", m.Version,
+		"{os}", runtime.GOOS,
+		"{arch}", runtime.GOARCH,
+	).Replace(m.URL)
+}
+
+// explainDownload describes installDownload
+func (m CustomModule) explainDownload(installPath string, source Source) Explanation {
+	e := Explanation{
+		Summary: fmt.Sprintf("downloads %s from the url of [module.%s]", m.binName(), m.Name),
+		URLs:    []string{m.expandURL()},
+		Files:   []string{filepath.Join(installPath, m.binName())},
+	}
+	if m.Source == MODULE_SOURCE_ARCHIVE {
+		e.Summary = fmt.Sprintf("downloads the archive of [module.%s] and extracts %s", m.Name, m.binName())
+	}
+	return e
+}
+
+// installDownload installs a url or archive module next to vibe
+func (m CustomModule) installDownload(installPath string, source Source, receipt *Receipt) error {
+	url := m.expandURL()
+	if err := checkPolicyURL("module "+m.Name, url); err != nil {
+		return err
+	}
+	fmt.Printf("📥 Downloading %s from %s...\n", m.Name, url)
+
+	tempPath, err := quarantinePath("module-" + m.Name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempPath)
+	if err := downloadFile(url, tempPath, opts.DownloadTimeout); err != nil {
+		return err
+	}
+	version := m.Version
+	if version == "" {
+		version = "[module." + m.Name + "]"
+	}
+	if err := requireDigest(version, m.Name, m.SHA256); err != nil {
+		return fmt.Errorf("module.%s.sha256 is not set: %w", m.Name, err)
+	}
+	if err := verifyDigest(tempPath, m.Name, m.SHA256); err != nil {
+		return err
+	}
+
+	dest := filepath.Join(installPath, m.binName())
+	if m.Source == MODULE_SOURCE_ARCHIVE {
+		staged := tempPath + ".bin"
+		defer os.Remove(staged)
+		if err := extractFile(tempPath, url, m.binName(), staged); err != nil {
+			return err
+		}
+		tempPath = staged
+	}
+	if err := installBinary(tempPath, dest); err != nil {
+		return err
+	}
+
+	receipt.recordChange(EnvChange{Kind: CHANGE_FILE, Path: dest})
+	receipt.recordFile(m.Name, m.Version, dest)
+	fmt.Printf("✅ %s installed to %s\n", m.Name, dest)
+	return nil
+}
+
+// extractFile copies the archive member whose base name is name to dest.
+// The archive format is taken from the URL it was downloaded from.
+func extractFile(archivePath, url, name, dest string) error {
+	var member io.Reader
+	switch {
+	case strings.HasSuffix(url, ".zip"):
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if !f.FileInfo().IsDir() && filepath.Base(f.Name) == name {
+				rc, err := f.Open()
+				if err != nil {
+					return fmt.Errorf("failed to read %s from archive: %w", name, err)
+				}
+				defer rc.Close()
+				member = rc
+				break
+			}
+		}
+
+	case strings.HasSuffix(url, ".tar.gz"), strings.HasSuffix(url, ".tgz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read archive: %w", err)
+			}
+			if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+				member = tr
+				break
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported archive format %s (expected .tar.gz, .tgz or .zip)", filepath.Base(url))
+	}
+
+	if member == nil {
+		return fmt.Errorf("%s not found in %s", name, filepath.Base(url))
+	}
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, MODE_BINARY)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, member); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	return nil
+}
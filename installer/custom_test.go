@@ -0,0 +1,173 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCustomModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+		want    CustomModule
+	}{
+		{
+			name:   "cargo with defaults",
+			config: "[module.ripgrep]\nversion = \"14.1.0\"\nverify = \"rg --version\"\n",
+			want:   CustomModule{Name: "ripgrep", Source: MODULE_SOURCE_CARGO, Version: "14.1.0", Verify: "rg --version"},
+		},
+		{
+			name:   "archive",
+			config: "[module.agent]\nsource = \"archive\"\nurl = \"https://example.com/agent-{os}.tar.gz\"\nsha256 = \"" + strings.Repeat("ab", 32) + "\"\n",
+			want:   CustomModule{Name: "agent", Source: MODULE_SOURCE_ARCHIVE, URL: "https://example.com/agent-{os}.tar.gz", SHA256: strings.Repeat("ab", 32)},
+		},
+		{
+			name:   "optional",
+			config: "[module.lsp]\nversion = \"1.0.0\"\noptional = true\n",
+			want:   CustomModule{Name: "lsp", Source: MODULE_SOURCE_CARGO, Version: "1.0.0", Optional: true},
+		},
+		{name: "cargo without version", config: "[module.rg]\npackage = \"ripgrep\"\n", wantErr: "need a version"},
+		{name: "url without url", config: "[module.rg]\nsource = \"url\"\n", wantErr: "need a url"},
+		{name: "malformed sha256", config: "[module.rg]\nsource = \"url\"\nurl = \"https://example.com/rg\"\nsha256 = \"abc\"\n", wantErr: "64 hex digits"},
+		{name: "built-in name", config: "[module.tree-sitter-typescript]\nversion = \"1.0.0\"\n", wantErr: "built-in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseConfig(bufio.NewScanner(strings.NewReader(tt.config)))
+			if err != nil {
+				t.Fatalf("parseConfig failed: %v", err)
+			}
+			modules, err := parseCustomModules(cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseCustomModules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(modules) != 1 || modules[0] != tt.want {
+				t.Errorf("parseCustomModules() = %+v, %v, want %+v", modules, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleKeysInSchema(t *testing.T) {
+	setting, ok := findSetting("module.agent.source")
+	if !ok || setting.Key != "module.agent.source" {
+		t.Fatalf("findSetting(module.agent.source) = %+v, %v", setting, ok)
+	}
+	if _, err := setting.validate("tarball"); err == nil {
+		t.Errorf("validate(tarball) succeeded, want an error")
+	}
+	if _, ok := findSetting("module.source"); ok {
+		t.Errorf("findSetting(module.source) matched a module.*.* setting")
+	}
+}
+
+// agentArchive serves a .tar.gz holding an agent executable, returning
+// the server and the archive's sha256
+func agentArchive(t *testing.T) (*httptest.Server, string) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho agent\n")
+	tw.WriteHeader(&tar.Header{Name: "agent-1.0/bin/agent", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server, sha256Hex(archive.String())
+}
+
+func TestInstallArchiveModule(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	server, digest := agentArchive(t)
+
+	m := CustomModule{Name: "agent", Source: MODULE_SOURCE_ARCHIVE, Version: "1.0", URL: server.URL + "/agent-{version}.tar.gz", Bin: "agent", SHA256: digest}
+	installPath := t.TempDir()
+	receipt := &Receipt{}
+	if err := m.module().Install(installPath, fakeSource{}, receipt); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	dest := filepath.Join(installPath, m.binName())
+	data, err := os.ReadFile(dest)
+	if err != nil || !strings.Contains(string(data), "echo agent") {
+		t.Errorf("extracted binary = %q, %v", data, err)
+	}
+	if len(receipt.Changes) != 1 || receipt.Changes[0].Kind != CHANGE_FILE || receipt.Changes[0].Path != dest {
+		t.Errorf("receipt changes = %+v, want the installed file", receipt.Changes)
+	}
+}
+
+func TestInstallModuleChecksDigest(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	server, digest := agentArchive(t)
+
+	tests := []struct {
+		name            string
+		sha256          string
+		allowUnverified bool
+		wantErr         string
+	}{
+		{name: "mismatch", sha256: strings.Repeat("0", 64), wantErr: "checksum"},
+		{name: "mismatch with --allow-unverified", sha256: strings.Repeat("0", 64), allowUnverified: true, wantErr: "checksum"},
+		{name: "missing", wantErr: "module.agent.sha256 is not set"},
+		{name: "missing with --allow-unverified", allowUnverified: true},
+		{name: "matching", sha256: digest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts.AllowUnverified = tt.allowUnverified
+			m := CustomModule{Name: "agent", Source: MODULE_SOURCE_ARCHIVE, Version: "1.0", URL: server.URL + "/agent.tar.gz", SHA256: tt.sha256}
+			installPath := t.TempDir()
+			err := m.module().Install(installPath, fakeSource{}, &Receipt{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Install failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Install error = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(installPath, m.binName())); !os.IsNotExist(err) {
+				t.Errorf("a rejected download was installed: %v", err)
+			}
+		})
+	}
+}
+
+func TestInstallModuleChecksPolicy(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedPolicy := policy
+	defer func() { policy = savedPolicy }()
+	policy = Policy{Sources: []string{"https://mirror.corp/vibe"}}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+	defer server.Close()
+
+	m := CustomModule{Name: "agent", Source: MODULE_SOURCE_URL, URL: server.URL + "/agent", SHA256: strings.Repeat("0", 64)}
+	err := m.module().Install(t.TempDir(), fakeSource{}, &Receipt{})
+	if err == nil || !strings.Contains(err.Error(), "blocked by the policy") {
+		t.Errorf("Install from a forbidden URL = %v, want a policy error", err)
+	}
+	if requests != 0 {
+		t.Errorf("a forbidden module URL was fetched %d times", requests)
+	}
+}
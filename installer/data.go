@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// USER_DATA_DIR holds vibe's indexes and databases under the vibe home. After
+// `data move` it is a link to the new location, so vibe finds its data
+// without configuration.
+const USER_DATA_DIR = "data"
+
+// getUserDataDir returns where vibe's indexes and databases are stored
+func getUserDataDir(receipt *Receipt) string {
+	if receipt.DataDir != "" {
+		return receipt.DataDir
+	}
+	return filepath.Join(getVibeHome(), USER_DATA_DIR)
+}
+
+// treeDigests hashes every regular file under dir by relative path
+func treeDigests(dir string) (map[string]string, error) {
+	digests := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		digests[rel], _, err = fileSHA256(path)
+		return err
+	})
+	return digests, err
+}
+
+// linkDir makes link point at the directory target: a symlink, or a
+// junction on Windows, which needs no privileges
+func linkDir(target, link string) error {
+	if runtime.GOOS == "windows" {
+		out, err := exec.Command("cmd", "/C", "mklink", "/J", link, target).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("mklink failed: %v: %s", err, out)
+		}
+		return nil
+	}
+	return os.Symlink(target, link)
+}
+
+// moveDataDir copies the data directory to dest, checks every file arrived
+// intact, records the new location and removes the old copy, leaving a
+// link at the default location. Moving back to the default location
+// replaces that link.
+func moveDataDir(receipt *Receipt, dest string) error {
+	if !filepath.IsAbs(dest) {
+		return fmt.Errorf("%s is not an absolute path", dest)
+	}
+	from := getUserDataDir(receipt)
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("no data directory at %s", from)
+	}
+	if filepath.Clean(from) == filepath.Clean(dest) {
+		return fmt.Errorf("the data directory is already at %s", dest)
+	}
+	// Copying into itself would never finish, and removing the old copy
+	// would delete the new one
+	if rel, err := filepath.Rel(from, dest); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is inside the data directory %s; choose a directory outside it", dest, from)
+	}
+	link := filepath.Join(getVibeHome(), USER_DATA_DIR)
+	target, err := os.Readlink(link)
+	movingBack := filepath.Clean(dest) == filepath.Clean(link) && err == nil && filepath.Clean(target) == filepath.Clean(from)
+	if _, err := os.Lstat(dest); err == nil && !movingBack {
+		return fmt.Errorf("%s already exists; choose a new directory", dest)
+	}
+
+	if !opts.Yes && !confirm(fmt.Sprintf("Move %s to %s? Stop vibe first so the databases are not written during the move.", from, dest)) {
+		return fmt.Errorf("move cancelled")
+	}
+
+	// abort drops the partial copy, putting back the link it replaced
+	abort := func(err error) error {
+		os.RemoveAll(dest)
+		if movingBack {
+			linkDir(from, link)
+		}
+		return err
+	}
+	if movingBack {
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("failed to remove the link %s: %w", link, err)
+		}
+	}
+
+	fmt.Printf("📦 Copying %s to %s...\n", from, dest)
+	if err := ensureDir(filepath.Dir(dest), MODE_DIR); err != nil {
+		return abort(err)
+	}
+	if err := copyDir(from, dest); err != nil {
+		return abort(fmt.Errorf("failed to copy the data directory: %w", err))
+	}
+
+	want, err := treeDigests(from)
+	if err != nil {
+		return abort(err)
+	}
+	got, err := treeDigests(dest)
+	if err != nil {
+		return abort(err)
+	}
+	for rel, digest := range want {
+		if got[rel] != digest {
+			return abort(fmt.Errorf("%s did not copy intact; %s is unchanged", rel, from))
+		}
+	}
+	fmt.Printf("🔐 Verified %d files\n", len(want))
+
+	receipt.DataDir = dest
+	if movingBack {
+		receipt.DataDir = "" // the default location again
+	}
+	if err := receipt.save(); err != nil {
+		return abort(err)
+	}
+
+	if err := os.RemoveAll(from); err != nil {
+		warnf("Could not remove the old data directory %s: %v", from, err)
+	}
+	if !movingBack {
+		if _, err := os.Lstat(link); err == nil {
+			os.Remove(link) // the link of an earlier move
+		}
+		if err := linkDir(dest, link); err != nil {
+			warnf("Could not link %s to the new location; vibe will not find its data there: %v", link, err)
+		}
+	}
+
+	fmt.Printf("✅ Moved the data directory to %s\n", dest)
+	return nil
+}
+
+// runData implements `install-dotvibe data move <path> | unlock | lock`
+func runData(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	switch {
+	case args[0] == "move" && len(args) == 2:
+		if receipt.DataEncryption != "" && receipt.DataEncryption != ENCRYPTION_EFS {
+			return fmt.Errorf("the data directory is encrypted; moving it would leave its container behind")
+		}
+		dest, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		return moveDataDir(receipt, dest)
+	case (args[0] == "unlock" || args[0] == "lock") && len(args) == 1:
+		if receipt.DataEncryption == "" {
+			return fmt.Errorf("the data directory is not encrypted; install with --encrypt-data")
+		}
+		if args[0] == "lock" {
+			return lockData(receipt)
+		}
+		return unlockData(receipt)
+	}
+	return errUsage
+}
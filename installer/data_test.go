@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMoveDataDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("junctions need cmd")
+	}
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+	opts.Yes = true
+	defer func() { opts.Yes = false }()
+
+	old := filepath.Join(home, USER_DATA_DIR)
+	os.MkdirAll(filepath.Join(old, "db", "ns"), 0700)
+	os.WriteFile(filepath.Join(old, "db", "ns", "table.db"), []byte("records"), 0600)
+	os.WriteFile(filepath.Join(old, "index.json"), []byte("{}"), 0644)
+
+	receipt := &Receipt{Version: "v1.0.0"}
+	dest := filepath.Join(t.TempDir(), "vibe-data")
+	if err := moveDataDir(receipt, dest); err != nil {
+		t.Fatalf("moveDataDir failed: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dest, "db", "ns", "table.db")); err != nil || string(data) != "records" {
+		t.Errorf("moved database = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "db", "ns", "table.db")); err == nil && posixModes() && info.Mode().Perm() != 0600 {
+		t.Errorf("moved database mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The old location is now a link vibe follows to the new one
+	if target, err := os.Readlink(old); err != nil || target != dest {
+		t.Errorf("%s links to %q, %v; want %s", old, target, err, dest)
+	}
+	if data, _ := os.ReadFile(filepath.Join(old, "index.json")); string(data) != "{}" {
+		t.Errorf("data is not reachable through the old location")
+	}
+
+	saved, _ := loadReceipt()
+	if saved.DataDir != dest || getUserDataDir(saved) != dest {
+		t.Errorf("receipt data dir = %q, want %s", saved.DataDir, dest)
+	}
+
+	// Moving again replaces the link and removes the previous copy
+	again := filepath.Join(t.TempDir(), "elsewhere")
+	if err := moveDataDir(saved, again); err != nil {
+		t.Fatalf("second move failed: %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after the second move", dest)
+	}
+	if target, _ := os.Readlink(old); target != again {
+		t.Errorf("%s links to %q, want %s", old, target, again)
+	}
+
+	// Moving back to the default location replaces the link with the data
+	saved, _ = loadReceipt()
+	if err := moveDataDir(saved, old); err != nil {
+		t.Fatalf("move back failed: %v", err)
+	}
+	if info, err := os.Lstat(old); err != nil || !info.IsDir() {
+		t.Fatalf("%s is not a directory after moving back: %v, %v", old, info, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(old, "db", "ns", "table.db")); err != nil || string(data) != "records" {
+		t.Errorf("database after moving back = %q, %v", data, err)
+	}
+	if _, err := os.Stat(again); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after moving back", again)
+	}
+	saved, _ = loadReceipt()
+	if saved.DataDir != "" || getUserDataDir(saved) != old {
+		t.Errorf("receipt data dir after moving back = %q, want the default", saved.DataDir)
+	}
+}
+
+func TestMoveDataDirRefusesExistingTarget(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+	os.MkdirAll(filepath.Join(home, USER_DATA_DIR), 0700)
+
+	if err := moveDataDir(&Receipt{}, t.TempDir()); err == nil {
+		t.Error("moved onto an existing directory")
+	}
+	if err := moveDataDir(&Receipt{}, "relative/path"); err == nil {
+		t.Error("moved to a relative path")
+	}
+	inside := filepath.Join(home, USER_DATA_DIR, "moved")
+	if err := moveDataDir(&Receipt{}, inside); err == nil {
+		t.Error("moved into the data directory itself")
+	}
+	if _, err := os.Stat(inside); !os.IsNotExist(err) {
+		t.Errorf("the refused move created %s", inside)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DB_PROBE_TIMEOUT bounds each request of the database health probe
+const DB_PROBE_TIMEOUT = 10 * time.Second
+
+// DB_PROBE_QUERY is the trivial query proving the database answers queries,
+// not just health checks
+const DB_PROBE_QUERY = "RETURN 1;"
+
+// dbQueryResult is one statement result of SurrealDB's /sql endpoint
+type dbQueryResult struct {
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result"`
+}
+
+// dbRequest sends a request for path to the database at endpoint and
+// returns the body, explaining the failures users can fix
+func dbRequest(endpoint, method, path, body string) ([]byte, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(endpoint, "/")+path, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpDo(req, DB_PROBE_TIMEOUT)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("nothing listens at %s; start SurrealDB there or fix database.endpoint", endpoint)
+		}
+		return nil, fmt.Errorf("cannot reach %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s%s: %w", endpoint, path, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		host := endpoint
+		if u, err := url.Parse(endpoint); err == nil {
+			host = u.Host
+		}
+		return nil, fmt.Errorf("%s %s was refused (%s); store the database user with: install-dotvibe auth login %s", method, path, resp.Status, host)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// probeDatabase checks that SurrealDB at endpoint is healthy, answers a
+// query and runs a version vibe was built for. It returns the version the
+// server reports.
+func probeDatabase(endpoint string) (string, error) {
+	if _, err := dbRequest(endpoint, http.MethodGet, "/health", ""); err != nil {
+		return "", err
+	}
+
+	data, err := dbRequest(endpoint, http.MethodGet, "/version", "")
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(string(data)), "surrealdb-")
+	running, ok := parseVersion(version)
+	if !ok {
+		return "", fmt.Errorf("%s reports an unrecognized version %q", endpoint, data)
+	}
+	if want, _ := parseVersion(SURREALDB_VERSION); running.Major != want.Major {
+		return version, fmt.Errorf("%s runs SurrealDB %s, but vibe needs %d.x (installed: %s)", endpoint, version, want.Major, SURREALDB_VERSION)
+	}
+
+	data, err = dbRequest(endpoint, http.MethodPost, "/sql", DB_PROBE_QUERY)
+	if err != nil {
+		return version, err
+	}
+	var results []dbQueryResult
+	if err := json.Unmarshal(data, &results); err != nil || len(results) != 1 {
+		return version, fmt.Errorf("%s answered %q with %q", endpoint, DB_PROBE_QUERY, data)
+	}
+	if results[0].Status != "OK" || strings.TrimSpace(string(results[0].Result)) != "1" {
+		return version, fmt.Errorf("%s failed %q: %s", endpoint, DB_PROBE_QUERY, results[0].Result)
+	}
+	return version, nil
+}
+
+// checkDatabase runs the health probe after setup, when vibe is configured
+// to use a SurrealDB server. vibe stays installed when it fails.
+func checkDatabase() {
+	if opts.DBEndpoint == "" {
+		return
+	}
+	fmt.Printf("🩺 Checking the database at %s...\n", opts.DBEndpoint)
+	version, err := probeDatabase(opts.DBEndpoint)
+	if err != nil {
+		warnf("vibe is installed but cannot use its database: %v", err)
+		return
+	}
+	fmt.Printf("✅ SurrealDB %s is healthy and answers queries\n", version)
+}
+
+// checkDatabaseHealth is the doctor check of the configured database
+func checkDatabaseHealth(*Receipt) []string {
+	if opts.DBEndpoint == "" {
+		return nil
+	}
+	if _, err := probeDatabase(opts.DBEndpoint); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbeDatabase(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	version := "surrealdb-" + SURREALDB_VERSION
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+		case "/version":
+			fmt.Fprint(w, version)
+		case "/sql":
+			if user, _, _ := r.BasicAuth(); user != "root" {
+				http.Error(w, "no credentials", http.StatusUnauthorized)
+				return
+			}
+			query, _ := io.ReadAll(r.Body)
+			if string(query) != DB_PROBE_QUERY {
+				http.Error(w, "unexpected query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `[{"result": 1, "status": "OK", "time": "10µs"}]`)
+		}
+	}))
+	defer server.Close()
+
+	if _, err := probeDatabase(server.URL); err == nil || !strings.Contains(err.Error(), "auth login") {
+		t.Errorf("unauthenticated query reported %v, want a hint to store credentials", err)
+	}
+	url := strings.Replace(server.URL, "http://", "http://root:secret@", 1)
+	if got, err := probeDatabase(url); err != nil || got != SURREALDB_VERSION {
+		t.Errorf("healthy database probed as %q, %v", got, err)
+	}
+
+	version = "surrealdb-1.5.4"
+	if _, err := probeDatabase(url); err == nil || !strings.Contains(err.Error(), "1.5.4") {
+		t.Errorf("old SurrealDB reported %v", err)
+	}
+
+	server.Close()
+	if _, err := probeDatabase(server.URL); err == nil || !strings.Contains(err.Error(), "nothing listens") {
+		t.Errorf("stopped database reported %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// releaseFacts is what diff compares between two releases
+type releaseFacts struct {
+	Version  string
+	Sizes    map[string]int64  // binary asset -> size in bytes
+	Grammars map[string]string // grammar package -> version, from the manifest
+	MinGlibc string            // from the manifest
+	Manifest bool              // whether the release publishes a manifest
+}
+
+// gatherRelease collects the assets, grammars and requirements of a
+// release from its manifest, or only the asset sizes when it has none
+func gatherRelease(source Source, version string) (releaseFacts, error) {
+	facts := releaseFacts{Version: version, Sizes: map[string]int64{}, Grammars: map[string]string{}}
+	if manifest, err := fetchReleaseManifest(source, version); err == nil {
+		facts.Manifest = true
+		facts.MinGlibc = manifest.MinGlibc
+		for _, a := range manifest.Assets {
+			dir, file := path.Split(a.Path)
+			if pkg, ok := strings.CutPrefix(dir, "grammars/"); ok {
+				name, grammarVersion, _ := strings.Cut(strings.TrimSuffix(pkg, "/"), "@")
+				facts.Grammars[name] = grammarVersion
+			} else if p2pEligible(file) {
+				facts.Sizes[file] = a.Size
+			}
+		}
+		return facts, nil
+	}
+
+	lister, ok := source.(AssetLister)
+	if !ok {
+		return facts, fmt.Errorf("%s publishes no manifest for %s and cannot list its assets", source.Name(), version)
+	}
+	assets, err := lister.ReleaseAssets(version)
+	if err != nil {
+		return facts, err
+	}
+	for _, a := range assets {
+		if p2pEligible(a.Name) {
+			facts.Sizes[a.Name] = a.Size
+		}
+	}
+	return facts, nil
+}
+
+// sizeChange describes how an asset's size changed
+func sizeChange(from, to int64, inFrom, inTo bool) string {
+	switch {
+	case !inFrom:
+		return "new, " + mebibytes(to)
+	case !inTo:
+		return "removed"
+	case from == to:
+		return mebibytes(to)
+	}
+	sign := "+"
+	if to < from {
+		sign = "-"
+	}
+	delta := to - from
+	if delta < 0 {
+		delta = -delta
+	}
+	return fmt.Sprintf("%s → %s (%s%s)", mebibytes(from), mebibytes(to), sign, mebibytes(delta))
+}
+
+// diffReleases describes what changes between two releases. The binary
+// of this platform is marked.
+func diffReleases(from, to releaseFacts, thisPlatform map[string]bool) []string {
+	lines := []string{fmt.Sprintf("vibe %s → %s", from.Version, to.Version)}
+	if isMajorUpgrade(from.Version, to.Version) {
+		lines = append(lines, "  ⚠️  major upgrade: needs confirmation or --allow-breaking")
+	}
+
+	lines = append(lines, "", "Binaries:")
+	names := map[string]bool{}
+	for name := range from.Sizes {
+		names[name] = true
+	}
+	for name := range to.Sizes {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		mark := " "
+		if thisPlatform[name] {
+			mark = "*"
+		}
+		f, inFrom := from.Sizes[name]
+		t, inTo := to.Sizes[name]
+		lines = append(lines, fmt.Sprintf(" %s %-32s %s", mark, name, sizeChange(f, t, inFrom, inTo)))
+	}
+
+	lines = append(lines, "", "Grammars:")
+	if !from.Manifest || !to.Manifest {
+		lines = append(lines, "  unknown: only releases with a manifest list their grammars")
+	} else {
+		grammars := map[string]bool{}
+		for pkg := range from.Grammars {
+			grammars[pkg] = true
+		}
+		for pkg := range to.Grammars {
+			grammars[pkg] = true
+		}
+		changed := 0
+		for _, pkg := range sortedKeys(grammars) {
+			if from.Grammars[pkg] != to.Grammars[pkg] {
+				lines = append(lines, fmt.Sprintf("  %-32s %s → %s", pkg, orNone(from.Grammars[pkg]), orNone(to.Grammars[pkg])))
+				changed++
+			}
+		}
+		if changed == 0 {
+			lines = append(lines, "  unchanged")
+		}
+	}
+
+	lines = append(lines, "", "Requirements:")
+	if from.MinGlibc != to.MinGlibc {
+		lines = append(lines, fmt.Sprintf("  glibc %s → %s (Linux)", orNone(from.MinGlibc), orNone(to.MinGlibc)))
+	} else {
+		lines = append(lines, "  unchanged")
+	}
+
+	lines = append(lines, "", "Migrations:")
+	steps := pendingMigrations(MIGRATIONS, &Receipt{}, from.Version, to.Version)
+	for _, m := range steps {
+		lines = append(lines, fmt.Sprintf("  %s (%s): %s", m.ID, m.Introduced, m.Description))
+	}
+	if len(steps) == 0 {
+		lines = append(lines, "  none")
+	}
+	return lines
+}
+
+// orNone shows an absent value
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runDiff implements `install-dotvibe diff <from> <to>`; either version
+// may be "installed"
+func runDiff(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	for i, v := range args {
+		if v != "installed" {
+			continue
+		}
+		if receipt.Version == "" {
+			return fmt.Errorf("vibe is not installed")
+		}
+		args[i] = receipt.Version
+	}
+
+	source, err := newSources(opts.sourceSpec(), opts.Mirrors)
+	if err != nil {
+		return err
+	}
+	from, err := gatherRelease(source, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := gatherRelease(source, args[1])
+	if err != nil {
+		return err
+	}
+
+	thisPlatform := map[string]bool{}
+	for _, version := range args {
+		for _, name := range assetNameVariants(runtime.GOOS, runtime.GOARCH, version, "") {
+			thisPlatform[name] = true
+		}
+	}
+	fmt.Println(strings.Join(diffReleases(from, to, thisPlatform), "\n"))
+	return nil
+}
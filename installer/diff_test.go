@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// manifestSource publishes a manifest per release
+type manifestSource struct {
+	fakeSource
+	manifests map[string]MirrorManifest
+}
+
+func (m manifestSource) FetchAsset(version, asset, destPath string) error {
+	if asset != MIRROR_MANIFEST {
+		return m.fakeSource.FetchAsset(version, asset, destPath)
+	}
+	data, _ := json.Marshal(m.manifests[version])
+	return os.WriteFile(destPath, data, 0644)
+}
+
+func TestDiffReleases(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	source := manifestSource{manifests: map[string]MirrorManifest{
+		"v0.7.20": {Version: "v0.7.20", MinGlibc: "2.28", Assets: []MirrorAsset{
+			{Path: "v0.7.20/vibe-linux-x86_64", Size: 10 << 20},
+			{Path: "v0.7.20/vibe-windows.exe", Size: 11 << 20},
+			{Path: "v0.7.20/SHA256SUMS", Size: 300},
+			{Path: "grammars/tree-sitter-typescript@0.23.0/tree-sitter-typescript.wasm", Size: 1 << 20},
+		}},
+		"v0.8.0": {Version: "v0.8.0", MinGlibc: "2.31", Assets: []MirrorAsset{
+			{Path: "v0.8.0/vibe-linux-x86_64", Size: 12 << 20},
+			{Path: "v0.8.0/vibe-linux-aarch64", Size: 12 << 20},
+			{Path: "grammars/tree-sitter-typescript@0.23.2/tree-sitter-typescript.wasm", Size: 1 << 20},
+		}},
+	}}
+	defer func(saved []Migration) { MIGRATIONS = saved }(MIGRATIONS)
+	MIGRATIONS = []Migration{{ID: "index-v2", Introduced: "v0.8.0", Description: "rebuild indexes"}}
+	defer func(saved string) { decimalSeparator = saved }(decimalSeparator)
+	decimalSeparator = "."
+
+	from, err := gatherRelease(source, "v0.7.20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := gatherRelease(source, "v0.8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := diffReleases(from, to, map[string]bool{"vibe-linux-x86_64": true})
+	out := strings.Join(strings.Fields(strings.Join(lines, "\n")), " ")
+	for _, want := range []string{
+		"major upgrade",
+		"* vibe-linux-x86_64 10.0 MiB → 12.0 MiB (+2.0 MiB)",
+		"vibe-linux-aarch64 new, 12.0 MiB",
+		"vibe-windows.exe removed",
+		"tree-sitter-typescript 0.23.0 → 0.23.2",
+		"glibc 2.28 → 2.31",
+		"index-v2 (v0.8.0): rebuild indexes",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff lacks %q:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	if strings.Contains(out, "SHA256SUMS") {
+		t.Errorf("diff lists checksums as a binary:\n%s", strings.Join(lines, "\n"))
+	}
+}
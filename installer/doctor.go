@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DoctorCheck is one diagnosis run by the doctor command
+type DoctorCheck struct {
+	Name string
+	Run  func(receipt *Receipt) []string // problems found, nil when healthy
+}
+
+// DOCTOR_CHECKS lists the diagnoses in the order they are reported
+var DOCTOR_CHECKS = []DoctorCheck{
+	{Name: "installed binaries match the install handshake", Run: checkHandshake},
+	{Name: "no world-writable installation files", Run: checkPermissions},
+	{Name: "SELinux contexts of installed files", Run: checkSELinux},
+	{Name: "the configured database is healthy", Run: checkDatabaseHealth},
+	{Name: "installed files match their recorded provenance", Run: checkProvenance},
+	{Name: "every file the installer wrote is in place", Run: checkInstalledFiles},
+}
+
+// runDoctor implements `install-dotvibe doctor`
+func runDoctor(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	if receipt.InstallPath == "" {
+		receipt.InstallPath = getInstallPath()
+	}
+
+	problems := 0
+	for _, check := range DOCTOR_CHECKS {
+		found := check.Run(receipt)
+		if len(found) == 0 {
+			fmt.Printf("✅ %s\n", check.Name)
+			continue
+		}
+		fmt.Printf("❌ %s\n", check.Name)
+		for _, p := range found {
+			fmt.Printf("   • %s\n", p)
+		}
+		problems += len(found)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	return nil
+}
+
+// checkHandshake detects binaries replaced or modified outside the
+// installer by repeating the install handshake
+func checkHandshake(receipt *Receipt) []string {
+	if receipt.Handshake == nil {
+		return nil // installed before handshakes were recorded
+	}
+	_, _, filename := detectPlatform()
+	current, err := takeHandshake(filepath.Join(receipt.InstallPath, filename), checkModules(allModules()))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return handshakeDrift(receipt.Handshake, current)
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// E2E_CHILD_ENV makes the test binary run the installer instead of the
+// tests, with the arguments in E2E_ARGS_ENV. The install flow exits
+// through fatalf, so end-to-end runs need a process of their own.
+const (
+	E2E_CHILD_ENV = "VIBE_E2E_CHILD"
+	E2E_ARGS_ENV  = "VIBE_E2E_ARGS"
+	E2E_REPO      = "vhybzOS/.vibe"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(E2E_CHILD_ENV) != "" {
+		runE2EChild()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runE2EChild runs the installer with hermetic modules: nothing compiles
+// with cargo and no grammar is fetched from unpkg. Installs as root stay
+// in the fake home as well.
+func runE2EChild() {
+	homeDir, _ := os.UserHomeDir()
+	ROOT_INSTALL_PATH = filepath.Join(homeDir, ".local", "bin")
+	GRAMMARS = nil
+	MODULES = []Module{{
+		Name:    "hermetic-tool",
+		Version: "v1.0.0",
+		Install: func(installPath string, source Source, receipt *Receipt) error {
+			path := filepath.Join(getDataDir(installPath), "hermetic-tool")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte("tool"), 0644); err != nil {
+				return err
+			}
+			receipt.recordFile("hermetic-tool", "v1.0.0", path)
+			return nil
+		},
+	}}
+	var args []string
+	json.Unmarshal([]byte(os.Getenv(E2E_ARGS_ENV)), &args)
+	runCommand(args)
+}
+
+// fakeRelease is a release published by fakeReleaseServer
+type fakeRelease struct {
+	Tag    string
+	Assets map[string][]byte
+}
+
+// fakeReleaseServer mimics the GitHub Enterprise release API and asset
+// downloads, publishing a vibe binary for every supported platform in
+// each release
+type fakeReleaseServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	releases  []fakeRelease // newest first
+	downloads []string      // asset paths served, in order
+}
+
+// fakeVibe is a vibe binary that reports its version to any command
+func fakeVibe(version string) []byte {
+	return []byte(fmt.Sprintf("#!/bin/sh\necho \"vibe %s\"\n", version))
+}
+
+func newFakeReleaseServer(t *testing.T) *fakeReleaseServer {
+	s := &fakeReleaseServer{}
+	mux := http.NewServeMux()
+	api := "/api/v3/repos/" + E2E_REPO + "/releases"
+	mux.HandleFunc(api, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var list []githubRelease
+		for _, rel := range s.releases {
+			list = append(list, s.githubRelease(rel))
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc(api+"/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		rest := strings.TrimPrefix(r.URL.Path, api+"/")
+		for _, rel := range s.releases {
+			if rest == "latest" || rest == "tags/"+rel.Tag {
+				json.NewEncoder(w).Encode(s.githubRelease(rel))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		tag, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/download/"), "/")
+		for _, rel := range s.releases {
+			if data, ok := rel.Assets[name]; ok && rel.Tag == tag {
+				s.downloads = append(s.downloads, tag+"/"+name)
+				w.Write(data)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// publish adds a release with every platform binary, the static Linux
+// builds, SHA256SUMS and a signature for each binary
+func (s *fakeReleaseServer) publish(version string) {
+	assets := map[string][]byte{}
+	for _, p := range SUPPORTED_PLATFORMS {
+		assets[releaseAssetName(p.GOOS, p.GOARCH, version)] = fakeVibe(version)
+		if p.GOOS == "linux" {
+			assets[assetNameVariants(p.GOOS, p.GOARCH, version, STATIC_ASSET_SUFFIX)[0]] = fakeVibe(version)
+		}
+	}
+	var names []string
+	for name := range assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sums strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sums, "%s  %s\n", sha256Hex(string(assets[name])), name)
+	}
+	assets["SHA256SUMS"] = []byte(sums.String())
+	for _, name := range names {
+		// fakeCosignScript accepts a signature holding the blob's digest
+		assets[name+COSIGN_SIGNATURE_EXT] = []byte(sha256Hex(string(assets[name])))
+		assets[name+COSIGN_CERTIFICATE_EXT] = []byte("certificate")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = append([]fakeRelease{{Tag: version, Assets: assets}}, s.releases...)
+}
+
+// tamper replaces an asset without updating SHA256SUMS or the API digest
+func (s *fakeReleaseServer) tamper(version, name string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rel := range s.releases {
+		if rel.Tag == version {
+			rel.Assets[name] = data
+		}
+	}
+}
+
+// remove deletes an asset from a release, as an attacker stripping its
+// signature would
+func (s *fakeReleaseServer) remove(version, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rel := range s.releases {
+		if rel.Tag == version {
+			delete(rel.Assets, name)
+		}
+	}
+}
+
+// githubRelease renders a release in the GitHub API schema; the digests
+// are those of the published files, as GitHub computes them on upload
+func (s *fakeReleaseServer) githubRelease(rel fakeRelease) githubRelease {
+	r := githubRelease{TagName: rel.Tag, Name: rel.Tag}
+	for name, data := range rel.Assets {
+		r.Assets = append(r.Assets, struct {
+			Name   string `json:"name"`
+			Size   int64  `json:"size"`
+			URL    string `json:"browser_download_url"`
+			Digest string `json:"digest"`
+		}{name, int64(len(data)), s.URL + "/download/" + rel.Tag + "/" + name, "sha256:" + sha256Hex(string(data))})
+	}
+	return r
+}
+
+// fakeCosignScript stands in for cosign verify-blob, accepting a blob
+// whose signature file holds its sha256 digest
+const fakeCosignScript = `#!/bin/sh
+while [ $# -gt 1 ]; do
+	[ "$1" = --signature ] && sig=$2
+	shift
+done
+sum=$( (sha256sum "$1" 2>/dev/null || shasum -a 256 "$1") | cut -d' ' -f1)
+[ "$(cat "$sig")" = "$sum" ] || { echo "invalid signature" >&2; exit 1; }
+`
+
+// e2eMachine is a simulated user account the installer runs against
+type e2eMachine struct {
+	home string
+	bin  string   // tools in PATH ahead of the system's, such as cosign
+	env  []string // extra environment, such as a simulated platform
+}
+
+func newE2EMachine(t *testing.T) *e2eMachine {
+	m := &e2eMachine{home: t.TempDir(), bin: t.TempDir()}
+	if err := os.WriteFile(filepath.Join(m.bin, COSIGN_CLIENT), []byte(fakeCosignScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func (m *e2eMachine) vibeHome() string { return filepath.Join(m.home, ".vibe") }
+
+// run runs the installer with args in its own process, returning its
+// output and exit status. Requests to anything but the fake server fail
+// through an unreachable proxy, keeping the run hermetic.
+func (m *e2eMachine) run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	encoded, _ := json.Marshal(args)
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append([]string{
+		E2E_CHILD_ENV + "=1",
+		E2E_ARGS_ENV + "=" + string(encoded),
+		"HOME=" + m.home,
+		"VIBE_HOME=" + m.vibeHome(),
+		"PATH=" + m.bin + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"HTTP_PROXY=http://127.0.0.1:1",
+		"HTTPS_PROXY=http://127.0.0.1:1",
+	}, m.env...)
+	out, err := cmd.CombinedOutput()
+	status := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		status = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running the installer: %v", err)
+	}
+	return string(out), status
+}
+
+// installArgs are the flags of a non-interactive install from server
+func installArgs(server *fakeReleaseServer, extra ...string) []string {
+	return append([]string{"install", "--yes", "--no-modify-path", "--allow-root", "--source", "github+" + server.URL + "/" + E2E_REPO}, extra...)
+}
+
+func TestEndToEnd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake vibe binary is a shell script")
+	}
+	server := newFakeReleaseServer(t)
+	server.publish("v1.0.0")
+	machine := newE2EMachine(t)
+
+	// Resolve, download, verify and install
+	out, status := machine.run(t, installArgs(server)...)
+	if status != 0 {
+		t.Fatalf("install exited %d:\n%s", status, out)
+	}
+	_, _, filename := detectPlatform()
+	binary := filepath.Join(machine.home, ".local", "bin", filename)
+	if data, err := os.ReadFile(binary); err != nil || string(data) != string(fakeVibe("v1.0.0")) {
+		t.Fatalf("installed binary = %q, %v\n%s", data, err, out)
+	}
+	if !strings.Contains(out, "🔐 Verified") || !strings.Contains(out, "🔏 Verified the cosign signature") {
+		t.Errorf("the download was not verified:\n%s", out)
+	}
+
+	var receipt Receipt
+	data, err := os.ReadFile(filepath.Join(machine.vibeHome(), RECEIPT_FILE))
+	if err != nil || json.Unmarshal(data, &receipt) != nil {
+		t.Fatalf("reading the receipt: %v", err)
+	}
+	if receipt.Version != "v1.0.0" || len(receipt.Files) != 2 || receipt.InitializedAt == nil {
+		t.Errorf("receipt = version %s, %d file(s), initialized %v", receipt.Version, len(receipt.Files), receipt.InitializedAt)
+	}
+
+	// A rerun changes nothing
+	served := len(server.downloads)
+	if out, status := machine.run(t, installArgs(server)...); status != 0 || !strings.Contains(out, "nothing to do") {
+		t.Errorf("rerun exited %d:\n%s", status, out)
+	}
+	if len(server.downloads) != served {
+		t.Errorf("the rerun downloaded %v", server.downloads[served:])
+	}
+
+	// --check reports the pending upgrade without running plugins
+	server.publish("v1.1.0")
+	hooks := filepath.Join(machine.vibeHome(), HOOKS_DIR)
+	marker := filepath.Join(machine.home, "hook-ran")
+	os.MkdirAll(hooks, 0755)
+	os.WriteFile(filepath.Join(hooks, "touch"), []byte("#!/bin/sh\ntouch "+marker+"\n"), 0755)
+	if out, status := machine.run(t, installArgs(server, "--check")...); status != EXIT_CHANGES_PENDING {
+		t.Errorf("install --check exited %d, want %d:\n%s", status, EXIT_CHANGES_PENDING, out)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("install --check ran a plugin")
+	}
+	os.RemoveAll(hooks)
+	if out, status := machine.run(t, installArgs(server, "unexpected")...); status != EXIT_USAGE {
+		t.Errorf("install with a stray argument exited %d, want %d:\n%s", status, EXIT_USAGE, out)
+	}
+
+	// Upgrade to the new release
+	if out, status := machine.run(t, installArgs(server)...); status != 0 || !strings.Contains(out, "Upgrading from v1.0.0 to v1.1.0") {
+		t.Fatalf("upgrade exited %d:\n%s", status, out)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != string(fakeVibe("v1.1.0")) {
+		t.Errorf("binary after the upgrade = %q", data)
+	}
+
+	// A tampered release is refused and leaves the installed binary alone
+	server.publish("v1.2.0")
+	asset := assetNameVariants(runtime.GOOS, runtime.GOARCH, "v1.2.0", "")[0]
+	server.tamper("v1.2.0", asset, []byte("#!/bin/sh\necho pwned\n"))
+	if out, status := machine.run(t, installArgs(server)...); status == 0 || !strings.Contains(out, "checksum mismatch") {
+		t.Errorf("tampered install exited %d:\n%s", status, out)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != string(fakeVibe("v1.1.0")) {
+		t.Errorf("binary after the refused install = %q", data)
+	}
+
+	// So is a release whose signature was stripped
+	server.publish("v1.3.0")
+	asset = assetNameVariants(runtime.GOOS, runtime.GOARCH, "v1.3.0", "")[0]
+	server.remove("v1.3.0", asset+COSIGN_SIGNATURE_EXT)
+	if out, status := machine.run(t, installArgs(server)...); status == 0 || !strings.Contains(out, "no cosign signature") {
+		t.Errorf("unsigned install exited %d:\n%s", status, out)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != string(fakeVibe("v1.1.0")) {
+		t.Errorf("binary after the refused unsigned install = %q", data)
+	}
+}
+
+func TestEndToEndSimulatedPlatforms(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the platforms simulated are Linux variants")
+	}
+	server := newFakeReleaseServer(t)
+	server.publish("v1.0.0")
+	static := assetNameVariants(runtime.GOOS, runtime.GOARCH, "v1.0.0", STATIC_ASSET_SUFFIX)[0]
+
+	for _, tt := range []struct {
+		name       string
+		env        func(home string) []string
+		args       []string
+		binDir     string // relative to the home directory
+		wantStatic bool
+	}{
+		{"glibc", nil, nil, ".local/bin", false},
+		{"static build", nil, []string{"--static"}, ".local/bin", true},
+		{"termux", func(home string) []string {
+			return []string{"TERMUX_VERSION=0.118.0", "PREFIX=" + filepath.Join(home, "usr")}
+		}, nil, "usr/bin", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			machine := newE2EMachine(t)
+			if tt.env != nil {
+				machine.env = tt.env(machine.home)
+			}
+			server.mu.Lock()
+			server.downloads = nil
+			server.mu.Unlock()
+
+			if out, status := machine.run(t, installArgs(server, tt.args...)...); status != 0 {
+				t.Fatalf("install exited %d:\n%s", status, out)
+			}
+			if _, err := os.Stat(filepath.Join(machine.home, tt.binDir, "vibe")); err != nil {
+				t.Errorf("vibe is not in %s: %v", tt.binDir, err)
+			}
+			gotStatic := false
+			for _, d := range server.downloads {
+				gotStatic = gotStatic || strings.HasSuffix(d, "/"+static)
+			}
+			if gotStatic != tt.wantStatic {
+				t.Errorf("downloads = %v, static build wanted: %v", server.downloads, tt.wantStatic)
+			}
+		})
+	}
+}
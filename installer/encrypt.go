@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SurrealDB has no encryption at rest, so --encrypt-data keeps the data
+// directory in an encrypted container mounted in its place: a gocryptfs
+// file system on Linux and an encrypted sparse bundle on macOS, whose
+// random key lives in the OS keychain. Windows encrypts the directory
+// with EFS, which ties the key to the user's login instead.
+const (
+	ENCRYPTION_GOCRYPTFS    = "gocryptfs"
+	ENCRYPTION_SPARSEBUNDLE = "sparsebundle"
+	ENCRYPTION_EFS          = "efs"
+
+	DATA_KEY_ACCOUNT       = "data-encryption-key"
+	DATA_SPARSEBUNDLE_SIZE = "200g" // upper bound; the bundle grows as data is written
+)
+
+// dataEncryptionMethod returns how the data directory is encrypted on goos
+func dataEncryptionMethod(goos string) string {
+	switch goos {
+	case "darwin":
+		return ENCRYPTION_SPARSEBUNDLE
+	case "windows":
+		return ENCRYPTION_EFS
+	default:
+		return ENCRYPTION_GOCRYPTFS
+	}
+}
+
+// encryptedContainerPath returns where the container of a data directory
+// is kept, next to it
+func encryptedContainerPath(dataDir, method string) string {
+	switch method {
+	case ENCRYPTION_GOCRYPTFS:
+		return dataDir + ".encrypted"
+	case ENCRYPTION_SPARSEBUNDLE:
+		return dataDir + ".sparsebundle"
+	}
+	return ""
+}
+
+// gocryptfsExtpass makes gocryptfs read the key from the keychain itself,
+// so it never passes through a file or the command line
+func gocryptfsExtpass() []string {
+	var args []string
+	for _, word := range []string{"secret-tool", "lookup", "service", KEYCHAIN_SERVICE, "account", DATA_KEY_ACCOUNT} {
+		args = append(args, "-extpass", word)
+	}
+	return args
+}
+
+// runEncryptionTool runs a container tool, feeding it input
+func runEncryptionTool(input string, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found in PATH; it is needed for --encrypt-data", name)
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// createContainer creates an encrypted container locked with key
+func createContainer(method, container, key string) error {
+	switch method {
+	case ENCRYPTION_GOCRYPTFS:
+		if err := ensureDir(container, MODE_PRIVATE_DIR); err != nil {
+			return err
+		}
+		return runEncryptionTool("", "gocryptfs", append(append([]string{"-init", "-q"}, gocryptfsExtpass()...), container)...)
+	case ENCRYPTION_SPARSEBUNDLE:
+		return runEncryptionTool(key+"\x00", "hdiutil", "create", "-size", DATA_SPARSEBUNDLE_SIZE, "-type", "SPARSEBUNDLE",
+			"-fs", "APFS", "-encryption", "AES-256", "-stdinpass", "-volname", "vibe-data", container)
+	}
+	return fmt.Errorf("unknown encryption method %q", method)
+}
+
+// mountContainer mounts a container on the data directory
+func mountContainer(method, container, dataDir string) error {
+	switch method {
+	case ENCRYPTION_GOCRYPTFS:
+		return runEncryptionTool("", "gocryptfs", append(append([]string{"-q"}, gocryptfsExtpass()...), container, dataDir)...)
+	case ENCRYPTION_SPARSEBUNDLE:
+		key, err := keychainGet(DATA_KEY_ACCOUNT)
+		if err != nil {
+			return err
+		}
+		return runEncryptionTool(key+"\x00", "hdiutil", "attach", "-stdinpass", "-nobrowse", "-mountpoint", dataDir, container)
+	}
+	return fmt.Errorf("unknown encryption method %q", method)
+}
+
+// unmountContainer unmounts the data directory
+func unmountContainer(method, dataDir string) error {
+	switch method {
+	case ENCRYPTION_GOCRYPTFS:
+		return runEncryptionTool("", "fusermount", "-u", dataDir)
+	case ENCRYPTION_SPARSEBUNDLE:
+		return runEncryptionTool("", "hdiutil", "detach", dataDir)
+	}
+	return nil
+}
+
+// isEmptyDir reports whether dir is missing or has no entries
+func isEmptyDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err != nil || len(entries) == 0
+}
+
+// setupDataEncryption encrypts the data directory. Data already in it is
+// moved into the container and checked before the plaintext is deleted.
+func setupDataEncryption(receipt *Receipt) error {
+	if receipt.DataEncryption != "" {
+		return unlockData(receipt)
+	}
+	method := dataEncryptionMethod(runtime.GOOS)
+	dataDir := getUserDataDir(receipt)
+
+	if method == ENCRYPTION_EFS {
+		if err := ensureDir(dataDir, MODE_PRIVATE_DIR); err != nil {
+			return err
+		}
+		if err := runEncryptionTool("", "cipher", "/E", "/S:"+dataDir); err != nil {
+			return fmt.Errorf("EFS is not available (Windows Home editions lack it): %w", err)
+		}
+		receipt.DataEncryption = method
+		fmt.Printf("🔒 %s is encrypted with EFS\n", dataDir)
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	key := hex.EncodeToString(raw)
+	if err := keychainSet(DATA_KEY_ACCOUNT, key); err != nil {
+		return fmt.Errorf("failed to store the data key: %w", err)
+	}
+
+	plain := ""
+	if !isEmptyDir(dataDir) {
+		plain = dataDir + ".plain"
+		if err := os.Rename(dataDir, plain); err != nil {
+			return fmt.Errorf("failed to set aside %s: %w", dataDir, err)
+		}
+	}
+	restore := func() {
+		if plain != "" {
+			os.RemoveAll(dataDir)
+			os.Rename(plain, dataDir)
+		}
+	}
+
+	container := encryptedContainerPath(dataDir, method)
+	if err := createContainer(method, container, key); err != nil {
+		os.RemoveAll(container)
+		restore()
+		return err
+	}
+	if err := ensureDir(dataDir, MODE_PRIVATE_DIR); err != nil {
+		restore()
+		return err
+	}
+	if err := mountContainer(method, container, dataDir); err != nil {
+		os.RemoveAll(container)
+		restore()
+		return err
+	}
+	receipt.DataEncryption = method
+
+	if plain != "" {
+		fmt.Printf("📦 Moving existing data into the encrypted container...\n")
+		if err := copyDir(plain, dataDir); err != nil {
+			return fmt.Errorf("failed to copy %s into the container; the plaintext copy is kept: %w", plain, err)
+		}
+		want, err := treeDigests(plain)
+		if err != nil {
+			return err
+		}
+		got, err := treeDigests(dataDir)
+		if err != nil {
+			return err
+		}
+		for rel, digest := range want {
+			if got[rel] != digest {
+				return fmt.Errorf("%s did not copy intact; the plaintext copy is kept at %s", rel, plain)
+			}
+		}
+		if err := os.RemoveAll(plain); err != nil {
+			warnf("Could not delete the plaintext copy %s: %v", plain, err)
+		}
+	}
+
+	fmt.Printf("🔒 %s is encrypted (%s, key in the OS keychain)\n", dataDir, method)
+	return nil
+}
+
+// unlockData mounts the encrypted data directory unless it already is
+func unlockData(receipt *Receipt) error {
+	if receipt.DataEncryption == "" || receipt.DataEncryption == ENCRYPTION_EFS {
+		return nil
+	}
+	dataDir := getUserDataDir(receipt)
+	if !isEmptyDir(dataDir) {
+		return nil // mounted
+	}
+	if err := ensureDir(dataDir, MODE_PRIVATE_DIR); err != nil {
+		return err
+	}
+	return mountContainer(receipt.DataEncryption, encryptedContainerPath(dataDir, receipt.DataEncryption), dataDir)
+}
+
+// lockData unmounts the encrypted data directory
+func lockData(receipt *Receipt) error {
+	if receipt.DataEncryption == "" || receipt.DataEncryption == ENCRYPTION_EFS || isEmptyDir(getUserDataDir(receipt)) {
+		return nil
+	}
+	return unmountContainer(receipt.DataEncryption, getUserDataDir(receipt))
+}
+
+// removeDataEncryption locks and deletes the container and its key, for
+// uninstall --purge
+func removeDataEncryption(receipt *Receipt) error {
+	if err := lockData(receipt); err != nil {
+		return err
+	}
+	if container := encryptedContainerPath(getUserDataDir(receipt), receipt.DataEncryption); container != "" {
+		if err := os.RemoveAll(container); err != nil {
+			return err
+		}
+		if err := keychainDelete(DATA_KEY_ACCOUNT); err != nil {
+			warnf("Could not delete the data key from the keychain: %v", err)
+		}
+	}
+	return nil
+}
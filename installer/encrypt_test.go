@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeKeyring puts secret-tool and gocryptfs stand-ins on PATH. The keyring
+// is a directory of files and gocryptfs logs its arguments.
+func fakeKeyring(t *testing.T) (keyring, log string) {
+	dir := t.TempDir()
+	keyring = filepath.Join(dir, "keyring")
+	log = filepath.Join(dir, "gocryptfs.log")
+	os.Mkdir(keyring, 0700)
+	secretTool := `#!/bin/sh
+op=$1
+for last; do :; done
+case $op in
+store) cat > "` + keyring + `/$last" ;;
+lookup) cat "` + keyring + `/$last" ;;
+clear) rm -f "` + keyring + `/$last" ;;
+esac
+`
+	gocryptfs := `#!/bin/sh
+echo "$@" >> "` + log + `"
+if [ "$1" = "-init" ]; then
+	for last; do :; done
+	touch "$last/gocryptfs.conf"
+fi
+`
+	os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(secretTool), 0755)
+	os.WriteFile(filepath.Join(dir, "gocryptfs"), []byte(gocryptfs), 0755)
+	os.WriteFile(filepath.Join(dir, "fusermount"), []byte("#!/bin/sh\n"), 0755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return keyring, log
+}
+
+func TestSetupDataEncryption(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("gocryptfs is the Linux method")
+	}
+	keyring, log := fakeKeyring(t)
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+
+	dataDir := filepath.Join(home, USER_DATA_DIR)
+	os.MkdirAll(filepath.Join(dataDir, "db"), 0700)
+	os.WriteFile(filepath.Join(dataDir, "db", "table.db"), []byte("records"), 0600)
+
+	receipt := &Receipt{Version: "v1.0.0"}
+	if err := setupDataEncryption(receipt); err != nil {
+		t.Fatalf("setupDataEncryption failed: %v", err)
+	}
+	if receipt.DataEncryption != ENCRYPTION_GOCRYPTFS {
+		t.Errorf("receipt encryption = %q, want %s", receipt.DataEncryption, ENCRYPTION_GOCRYPTFS)
+	}
+
+	key, err := os.ReadFile(filepath.Join(keyring, DATA_KEY_ACCOUNT))
+	if err != nil || len(key) != 64 {
+		t.Fatalf("keyring holds %q, %v; want a 256-bit hex key", key, err)
+	}
+	args, _ := os.ReadFile(log)
+	if strings.Contains(string(args), string(key)) {
+		t.Errorf("the key was passed on the gocryptfs command line: %s", args)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir+".encrypted", "gocryptfs.conf")); err != nil {
+		t.Errorf("container was not initialised: %v", err)
+	}
+
+	// Existing data is copied into the mounted container and the plaintext removed
+	if data, err := os.ReadFile(filepath.Join(dataDir, "db", "table.db")); err != nil || string(data) != "records" {
+		t.Errorf("database in the container = %q, %v", data, err)
+	}
+	if _, err := os.Stat(dataDir + ".plain"); !os.IsNotExist(err) {
+		t.Errorf("plaintext copy was left behind")
+	}
+
+	// Purging deletes the container and the key
+	if err := removeDataEncryption(receipt); err != nil {
+		t.Fatalf("removeDataEncryption failed: %v", err)
+	}
+	if _, err := os.Stat(dataDir + ".encrypted"); !os.IsNotExist(err) {
+		t.Errorf("container survived the purge")
+	}
+	if _, err := os.Stat(filepath.Join(keyring, DATA_KEY_ACCOUNT)); !os.IsNotExist(err) {
+		t.Errorf("key survived the purge")
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/vhybzOS/.vibe/installer/platform"
+)
+
+// Explanation describes what one install step would do on this machine
+// with the current config, without doing it
+type Explanation struct {
+	Summary  string
+	URLs     []string // what is downloaded
+	Commands []string // what is run
+	Files    []string // what is created or changed
+	Notes    []string
+}
+
+// Steps explain knows besides the modules
+const (
+	STEP_RUST = "rust"
+	STEP_VIBE = "vibe"
+	STEP_PATH = "path"
+)
+
+// explainSteps lists the steps of an install in order
+func explainSteps() []string {
+	steps := []string{STEP_RUST}
+	for _, m := range allModules() {
+		steps = append(steps, m.Name)
+	}
+	return append(steps, STEP_VIBE, STEP_PATH)
+}
+
+// shellJoin renders a command line, quoting arguments the shell would split
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'|;&$*?<>()") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// sourceURLs lists the URLs a source would fetch a file from, mirrors in
+// configured order, or names the source when it does not download over
+// plain HTTP
+func sourceURLs(source Source, urlOf func(URLSource) string) []string {
+	sources := []Source{source}
+	if multi, ok := source.(*multiSource); ok {
+		sources = multi.sources
+	}
+	var urls []string
+	for _, s := range sources {
+		if u, ok := s.(URLSource); ok {
+			urls = append(urls, urlOf(u))
+		} else {
+			urls = append(urls, "via "+s.Name())
+		}
+	}
+	return urls
+}
+
+// explainVibe describes downloading and installing the vibe binary. The
+// latest version is resolved, which needs the network; when that fails
+// the URLs show a placeholder.
+func explainVibe(installPath string, source Source) Explanation {
+	goos, goarch, filename := detectPlatform()
+	if goos == "android" {
+		goos = "linux"
+	}
+	e := Explanation{Summary: "downloads, verifies and installs the vibe binary"}
+
+	version, err := resolveLatest(source)
+	if err != nil {
+		version = "<version>"
+		e.Notes = append(e.Notes, "could not resolve the latest version: "+err.Error())
+	}
+	suffix := ""
+	if (opts.Static || platform.Detect().Static()) && goos == "linux" {
+		suffix = STATIC_ASSET_SUFFIX
+	}
+	names := assetNameVariants(goos, goarch, version, suffix)
+	e.URLs = sourceURLs(source, func(u URLSource) string { return u.AssetURL(version, names[0]) })
+	e.URLs = append(e.URLs, sourceURLs(source, func(u URLSource) string { return u.AssetURL(version, "SHA256SUMS") })...)
+	if len(names) > 1 {
+		e.Notes = append(e.Notes, fmt.Sprintf("when %s is missing, %d other asset names are tried: %s", names[0], len(names)-1, strings.Join(names[1:], ", ")))
+	}
+	e.Files = []string{
+		filepath.Join(installPath, filename),
+		getQuarantineDir() + " (staging until verified)",
+		getReceiptPath(),
+	}
+	return e
+}
+
+// explainPath describes putting the install directory on PATH
+func explainPath(installPath string) Explanation {
+	e := Explanation{Summary: fmt.Sprintf("puts %s on PATH", installPath)}
+	switch {
+	case opts.NoModifyPath:
+		e.Notes = append(e.Notes, "--no-modify-path is set, so this step is skipped")
+		return e
+	case pathContains(os.Getenv("PATH"), installPath):
+		e.Notes = append(e.Notes, installPath+" is already on PATH, so this step is skipped")
+		return e
+	case runtime.GOOS == "windows":
+		e.Files = []string{`HKCU\Environment (user Path)`}
+		return e
+	}
+
+	profile := getShellProfile()
+	if profile == "" {
+		e.Notes = append(e.Notes, "no shell profile found; PATH has to be changed manually")
+		return e
+	}
+	existing, _ := os.ReadFile(profile)
+	_, diff, changed := planProfileEdit(string(existing), profileBlock(profile, installPath))
+	if !changed {
+		e.Notes = append(e.Notes, profile+" already has the PATH block")
+		return e
+	}
+	e.Files = []string{profile}
+	e.Notes = append(e.Notes, "proposed change, confirmed before it is applied:\n"+strings.TrimRight(diff, "\n"))
+	return e
+}
+
+// explainStep describes one step of explainSteps
+func explainStep(step, installPath string, source Source) (Explanation, error) {
+	switch step {
+	case STEP_RUST:
+		return explainRust(), nil
+	case STEP_VIBE:
+		return explainVibe(installPath, source), nil
+	case STEP_PATH:
+		return explainPath(installPath), nil
+	}
+	for _, m := range allModules() {
+		if m.Name != step {
+			continue
+		}
+		if m.Explain == nil {
+			return Explanation{Summary: "installs " + m.Name}, nil
+		}
+		return m.Explain(installPath, source), nil
+	}
+	return Explanation{}, fmt.Errorf("unknown step %q; steps are: %s", step, strings.Join(explainSteps(), ", "))
+}
+
+// print shows an explanation under its step name
+func (e Explanation) print(step string) {
+	fmt.Printf("🔎 %s: %s\n", step, e.Summary)
+	for _, group := range []struct {
+		label string
+		lines []string
+	}{{"URL", e.URLs}, {"Command", e.Commands}, {"File", e.Files}, {"Note", e.Notes}} {
+		for _, line := range group.lines {
+			line = strings.ReplaceAll(line, "\n", "\n            ")
+			fmt.Printf("   %-8s %s\n", group.label+":", line)
+		}
+	}
+}
+
+// runExplain shows what an install step would do here, without doing
+// it: a dry run of one step, for when only that step fails
+func runExplain(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	source, err := newSources(opts.sourceSpec(), opts.Mirrors)
+	if err != nil {
+		return err
+	}
+	installPath := getInstallPath()
+	if receipt, err := loadReceipt(); err == nil && receipt.InstallPath != "" {
+		installPath = receipt.InstallPath
+	}
+
+	e, err := explainStep(args[0], installPath, source)
+	if err != nil {
+		return err
+	}
+	e.print(args[0])
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"cargo", "install", "--config", `source.x.registry="a b"`, "it's"})
+	want := `cargo install --config 'source.x.registry="a b"' 'it'\''s'`
+	if got != want {
+		t.Errorf("shellJoin = %s, want %s", got, want)
+	}
+}
+
+func TestExplainStep(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell profiles are not used on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VIBE_HOME", filepath.Join(home, ".vibe"))
+	t.Setenv("SHELL", "/bin/zsh")
+	t.Setenv("PATH", "/usr/bin")
+
+	saved := opts
+	defer func() { opts = saved }()
+	installPath := filepath.Join(home, ".local", "bin")
+	source := mirrorSource{baseURL: "https://mirror.example"}
+
+	e, err := explainStep(STEP_PATH, installPath, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Files) != 1 || e.Files[0] != filepath.Join(home, ".zshrc") || len(e.Notes) != 1 || !strings.Contains(e.Notes[0], installPath) {
+		t.Errorf("path step = %+v", e)
+	}
+	opts.NoModifyPath = true
+	if e, _ := explainStep(STEP_PATH, installPath, source); len(e.Files) != 0 {
+		t.Errorf("path step with --no-modify-path changes %v", e.Files)
+	}
+
+	e, err = explainStep("tree-sitter-typescript", installPath, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := GRAMMARS[0]
+	if want := source.GrammarURL(g.Package, g.Version, g.File); len(e.URLs) != 1 || e.URLs[0] != want {
+		t.Errorf("grammar URLs = %v, want %s", e.URLs, want)
+	}
+
+	opts.Modules = []CustomModule{{Name: "jq", Source: MODULE_SOURCE_URL, Version: "1.7", URL: "https://example.com/jq-{version}-{os}"}}
+	e, err = explainStep("jq", installPath, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://example.com/jq-1.7-" + runtime.GOOS; len(e.URLs) != 1 || e.URLs[0] != want {
+		t.Errorf("custom module URLs = %v, want %s", e.URLs, want)
+	}
+
+	if _, err := explainStep("nope", installPath, source); err == nil || !strings.Contains(err.Error(), "jq") {
+		t.Errorf("unknown step error = %v, want the list of steps", err)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tuning of --flaky-network, for satellite and mobile links that drop a
+// connection every few megabytes
+const (
+	FLAKY_CHUNK_SIZE    = 1 << 20         // bytes requested per range request
+	FLAKY_RETRIES       = 20              // failures in a row of one chunk before giving up
+	FLAKY_STALL_TIMEOUT = 2 * time.Minute // tolerate long pauses before reconnecting
+	FLAKY_API_TIMEOUT   = 2 * time.Minute
+	FLAKY_BACKOFF_STEPS = 5 // doublings of the retry wait, up to 32s
+)
+
+// flakyBackoff is the wait before the first retry, doubled on each
+// further failure up to FLAKY_BACKOFF_STEPS times
+var flakyBackoff = time.Second
+
+// FLAKY_CARGO_ENV makes cargo retry and wait longer as well, unless the
+// user set these variables
+var FLAKY_CARGO_ENV = map[string]string{
+	"CARGO_NET_RETRY":         "10",
+	"CARGO_HTTP_TIMEOUT":      "120",
+	"CARGO_HTTP_MULTIPLEXING": "false", // one stalled HTTP/2 stream stalls them all
+}
+
+// applyNetworkProfile tunes timeouts for --flaky-network. Only values left
+// at their defaults change, so explicit timeouts still win.
+func applyNetworkProfile() {
+	if !opts.FlakyNetwork {
+		return
+	}
+	if opts.StallTimeout == DEFAULT_STALL_TIMEOUT {
+		opts.StallTimeout = FLAKY_STALL_TIMEOUT
+	}
+	if opts.APITimeout == DEFAULT_API_TIMEOUT {
+		opts.APITimeout = FLAKY_API_TIMEOUT
+	}
+	for name, value := range FLAKY_CARGO_ENV {
+		if os.Getenv(name) == "" {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// errPermanent marks a response retrying cannot fix
+type errPermanent struct{ err error }
+
+func (e errPermanent) Error() string { return e.err.Error() }
+func (e errPermanent) Unwrap() error { return e.err }
+
+// contentRangeTotal returns the complete length from a Content-Range
+// header such as "bytes 0-1023/4096", or -1 when it is unknown
+func contentRangeTotal(header string) int64 {
+	_, size, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// fetchRange requests up to FLAKY_CHUNK_SIZE bytes of url from offset and
+// writes them into out. A server ignoring ranges sends the whole file,
+// which replaces whatever out held. It returns the new offset and the
+// file's total size, -1 while unknown, also when the transfer broke off.
+func fetchRange(url string, out *os.File, offset int64, pw *ProgressWriter) (int64, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return offset, -1, errPermanent{err}
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+FLAKY_CHUNK_SIZE-1))
+	resp, err := httpDo(req, opts.DownloadTimeout)
+	if err != nil {
+		return offset, -1, err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+	case resp.StatusCode == http.StatusOK:
+		offset = 0 // no range support: start over with the full body
+		if err := out.Truncate(0); err != nil {
+			return offset, -1, errPermanent{err}
+		}
+		total = resp.ContentLength
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		return offset, offset, nil // the previous chunk ended exactly at the end
+	case resp.StatusCode == http.StatusNotFound:
+		return offset, -1, errPermanent{fmt.Errorf("download failed with status: %s: %w", resp.Status, errAssetNotFound)}
+	case resp.StatusCode/100 == 4:
+		return offset, -1, errPermanent{fmt.Errorf("download failed with status: %s", resp.Status)}
+	default:
+		return offset, -1, fmt.Errorf("download failed with status: %s", resp.Status)
+	}
+
+	if err := checkContentType(resp); err != nil {
+		return offset, -1, errPermanent{err}
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return offset, total, errPermanent{err}
+	}
+	pw.Writer = out
+	n, err := io.Copy(pw, resp.Body)
+	offset += n
+	if err == nil && (resp.StatusCode == http.StatusOK || total < 0 && n < FLAKY_CHUNK_SIZE) {
+		total = offset // the whole file, or its last chunk, arrived
+	}
+	return offset, total, err
+}
+
+// downloadResumable fetches url into destPath in FLAKY_CHUNK_SIZE ranges,
+// resuming from the last byte received after every failure. The download
+// is given up once a chunk fails FLAKY_RETRIES times without progress.
+func downloadResumable(url, destPath string, showProgress bool) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
+	}
+	defer out.Close()
+
+	pw := &ProgressWriter{}
+	if showProgress {
+		pw.task = progress.download(path.Base(url), 0)
+	}
+	var offset int64
+	total := int64(-1)
+	for failures := 0; total < 0 || offset < total; {
+		next, size, err := fetchRange(url, out, offset, pw)
+		if size >= 0 {
+			total = size
+			if pw.task != nil {
+				pw.task.setTotal(total)
+			}
+		}
+		if err == nil && next == offset && total < 0 {
+			err = errors.New("server sent no data")
+		}
+		if next > offset {
+			failures = 0 // progress was made, however little
+		}
+		offset = next
+		if err == nil {
+			continue
+		}
+
+		failures++
+		var permanent errPermanent
+		if errors.As(err, &permanent) || failures > FLAKY_RETRIES || runCtx.Err() != nil {
+			if pw.task != nil {
+				pw.task.finish(err)
+			}
+			return fmt.Errorf("failed to download %s: %w", url, err)
+		}
+		delay := flakyBackoff << min(failures-1, FLAKY_BACKOFF_STEPS)
+		warnf("Download of %s interrupted at %s (%v), resuming in %s (%d/%d)", path.Base(url), mebibytes(offset), err, delay, failures, FLAKY_RETRIES)
+		select {
+		case <-time.After(delay):
+		case <-runCtx.Done():
+		}
+	}
+
+	if pw.task != nil {
+		pw.task.finish(nil)
+	}
+	return nil
+}
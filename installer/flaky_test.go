@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// droppingServer serves data with range support, but cuts every other
+// response off halfway through
+func droppingServer(t *testing.T, data []byte, ranges bool) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		start, end := 0, len(data)-1
+		if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok && ranges {
+			from, to, _ := strings.Cut(spec, "-")
+			start, _ = strconv.Atoi(from)
+			if e, err := strconv.Atoi(to); err == nil && e < end {
+				end = e
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		chunk := data[start : end+1]
+		if n%2 == 1 {
+			w.Write(chunk[:len(chunk)/2])
+			panic(http.ErrAbortHandler) // drop the connection mid-transfer
+		}
+		w.Write(chunk)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestDownloadResumable(t *testing.T) {
+	saved, savedBackoff := opts, flakyBackoff
+	defer func() { opts, flakyBackoff = saved, savedBackoff }()
+	flakyBackoff = time.Millisecond
+
+	data := make([]byte, 3*FLAKY_CHUNK_SIZE+12345)
+	rand.New(rand.NewSource(1)).Read(data)
+	server, requests := droppingServer(t, data, true)
+
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := downloadResumable(server.URL+"/vibe", dest, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data) {
+		t.Fatalf("downloaded %d bytes that differ from the %d served", len(got), len(data))
+	}
+	// Every other response was cut off halfway and resumed from there
+	if n := requests.Load(); n != 6 {
+		t.Errorf("made %d requests, want 6", n)
+	}
+
+	// A server without range support starts over until one response completes
+	server, _ = droppingServer(t, data[:1000], false)
+	if err := downloadResumable(server.URL+"/vibe", dest, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, data[:1000]) {
+		t.Errorf("download without ranges is corrupt")
+	}
+}
+
+func TestDownloadResumableGivesUp(t *testing.T) {
+	saved, savedBackoff := opts, flakyBackoff
+	defer func() { opts, flakyBackoff = saved, savedBackoff }()
+	flakyBackoff = time.Millisecond
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := downloadResumable(server.URL, dest, false); err == nil || !strings.Contains(err.Error(), "404") || requests.Load() != 1 {
+		t.Errorf("404 gave %v after %d requests, want no retry", err, requests.Load())
+	}
+	requests.Store(1)
+	if err := downloadResumable(server.URL, dest, false); err == nil || requests.Load() != 1+FLAKY_RETRIES+1 {
+		t.Errorf("503 gave %v after %d requests, want %d attempts", err, requests.Load()-1, FLAKY_RETRIES+1)
+	}
+}
+
+func TestApplyNetworkProfile(t *testing.T) {
+	saved := opts
+	defer func() { opts = saved }()
+	t.Setenv("CARGO_NET_RETRY", "3")
+	t.Setenv("CARGO_HTTP_TIMEOUT", "")
+	t.Setenv("CARGO_HTTP_MULTIPLEXING", "")
+
+	opts.FlakyNetwork = true
+	opts.APITimeout = time.Minute // set explicitly
+	applyNetworkProfile()
+	if opts.StallTimeout != FLAKY_STALL_TIMEOUT || opts.APITimeout != time.Minute {
+		t.Errorf("stall timeout %s, API timeout %s", opts.StallTimeout, opts.APITimeout)
+	}
+	if os.Getenv("CARGO_NET_RETRY") != "3" || os.Getenv("CARGO_HTTP_TIMEOUT") != "120" {
+		t.Errorf("cargo env CARGO_NET_RETRY=%s CARGO_HTTP_TIMEOUT=%s", os.Getenv("CARGO_NET_RETRY"), os.Getenv("CARGO_HTTP_TIMEOUT"))
+	}
+}
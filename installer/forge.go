@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Forges whose release APIs can serve releases, selected by the scheme
+// prefix of --source, e.g. gitea+https://codeberg.org/owner/dotvibe
+const (
+	FORGE_GITHUB  = "github" // GitHub Enterprise, or a fork on github.com
+	FORGE_GITEA   = "gitea"
+	FORGE_FORGEJO = "forgejo" // speaks the Gitea API
+	FORGE_GITLAB  = "gitlab"
+)
+
+// forgeAPI is where a forge serves its release API. Paths are relative to
+// Base and may use {repo} and {tag}.
+type forgeAPI struct {
+	Base     string
+	Releases string // every release, newest first
+	Latest   string // the newest stable release
+	Release  string // the release of {tag}
+	Page     string // query asking for the largest page
+}
+
+// defaultForgeAPI returns the release API of a forge kind hosted at origin
+// (scheme://host)
+func defaultForgeAPI(kind, origin string) forgeAPI {
+	switch kind {
+	case FORGE_GITLAB:
+		return forgeAPI{
+			Base:     origin + "/api/v4",
+			Releases: "/projects/{repo}/releases",
+			Latest:   "/projects/{repo}/releases/permalink/latest",
+			Release:  "/projects/{repo}/releases/{tag}",
+			Page:     "per_page=100",
+		}
+	case FORGE_GITEA, FORGE_FORGEJO:
+		return forgeAPI{
+			Base:     origin + "/api/v1",
+			Releases: "/repos/{repo}/releases",
+			Latest:   "/repos/{repo}/releases/latest",
+			Release:  "/repos/{repo}/releases/tags/{tag}",
+			Page:     "limit=50",
+		}
+	}
+	base := origin + "/api/v3" // GitHub Enterprise Server
+	if origin == "https://github.com" {
+		base = "https://api.github.com"
+	}
+	return forgeAPI{
+		Base:     base,
+		Releases: "/repos/{repo}/releases",
+		Latest:   "/repos/{repo}/releases/latest",
+		Release:  "/repos/{repo}/releases/tags/{tag}",
+		Page:     "per_page=100",
+	}
+}
+
+// withOverrides applies the API base and path templates set in the config
+func (a forgeAPI) withOverrides(o Options) forgeAPI {
+	for _, override := range []struct {
+		value  string
+		target *string
+	}{
+		{o.ForgeAPI, &a.Base},
+		{o.ForgeReleasesPath, &a.Releases},
+		{o.ForgeLatestPath, &a.Latest},
+		{o.ForgeReleasePath, &a.Release},
+	} {
+		if override.value != "" {
+			*override.target = override.value
+		}
+	}
+	a.Base = strings.TrimSuffix(a.Base, "/")
+	return a
+}
+
+// forgeRelease is a release normalized from any forge's schema
+type forgeRelease struct {
+	Tag        string
+	Name       string
+	Notes      string
+	Draft      bool
+	Prerelease bool
+	Assets     []forgeAsset
+}
+
+// forgeAsset is a downloadable file of a release
+type forgeAsset struct {
+	Name   string
+	Size   int64 // 0 when the forge does not say
+	URL    string
+	Digest string // "sha256:<hex>", empty when the forge does not say
+}
+
+// githubRelease is the release schema of GitHub, which Gitea and Forgejo
+// copy
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name   string `json:"name"`
+		Size   int64  `json:"size"`
+		URL    string `json:"browser_download_url"`
+		Digest string `json:"digest"`
+	} `json:"assets"`
+}
+
+func (r githubRelease) normalize() forgeRelease {
+	release := forgeRelease{Tag: r.TagName, Name: r.Name, Notes: r.Body, Draft: r.Draft, Prerelease: r.Prerelease}
+	for _, a := range r.Assets {
+		release.Assets = append(release.Assets, forgeAsset{Name: a.Name, Size: a.Size, URL: a.URL, Digest: a.Digest})
+	}
+	return release
+}
+
+// gitlabRelease is the release schema of GitLab: assets are links without
+// sizes, and there is no prerelease flag besides the tag itself
+type gitlabRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Upcoming    bool   `json:"upcoming_release"`
+	Assets      struct {
+		Links []struct {
+			Name      string `json:"name"`
+			URL       string `json:"url"`
+			DirectURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+func (r gitlabRelease) normalize() forgeRelease {
+	release := forgeRelease{Tag: r.TagName, Name: r.Name, Notes: r.Description, Draft: r.Upcoming}
+	for _, l := range r.Assets.Links {
+		link := l.DirectURL
+		if link == "" {
+			link = l.URL
+		}
+		release.Assets = append(release.Assets, forgeAsset{Name: l.Name, URL: link})
+	}
+	return release
+}
+
+// forgeSource resolves releases through a forge's release API and
+// downloads the assets it links to. Grammars still come from unpkg.
+type forgeSource struct {
+	kind   string
+	origin string // scheme://host of the forge
+	repo   string // owner/repo, or group/subgroup/project on GitLab
+	api    forgeAPI
+
+	mu       sync.Mutex
+	releases map[string]forgeRelease // by tag, as fetched
+}
+
+// newForgeSource parses a <forge>+https://host/owner/repo source URL
+func newForgeSource(kind string, u *url.URL) (*forgeSource, error) {
+	repo := strings.Trim(u.Path, "/")
+	if strings.Count(repo, "/") < 1 {
+		return nil, fmt.Errorf("%s source %q needs the repository path, e.g. %s+https://host/owner/repo", kind, u.String(), kind)
+	}
+	scheme := strings.TrimPrefix(u.Scheme, kind+"+")
+	origin := scheme + "://" + u.Host
+	return &forgeSource{
+		kind:     kind,
+		origin:   origin,
+		repo:     repo,
+		api:      defaultForgeAPI(kind, origin).withOverrides(opts),
+		releases: map[string]forgeRelease{},
+	}, nil
+}
+
+func (s *forgeSource) Name() string {
+	return fmt.Sprintf("%s releases of %s/%s", s.kind, s.origin, s.repo)
+}
+
+// endpoint expands a path template of the API
+func (s *forgeSource) endpoint(path, tag string) string {
+	repo := s.repo
+	if s.kind == FORGE_GITLAB {
+		repo = url.PathEscape(repo) // GitLab takes the project path as one segment
+	}
+	return s.api.Base + strings.NewReplacer("{repo}", repo, "{tag}", url.PathEscape(tag)).Replace(path)
+}
+
+// decode parses a release or a list of releases in the forge's schema
+func (s *forgeSource) decode(resp *http.Response, list bool) ([]forgeRelease, error) {
+	var releases []forgeRelease
+	if s.kind == FORGE_GITLAB {
+		var batch []gitlabRelease
+		if err := decodeOneOrMany(resp, list, &batch); err != nil {
+			return nil, err
+		}
+		for _, r := range batch {
+			releases = append(releases, r.normalize())
+		}
+		return releases, nil
+	}
+	var batch []githubRelease
+	if err := decodeOneOrMany(resp, list, &batch); err != nil {
+		return nil, err
+	}
+	for _, r := range batch {
+		releases = append(releases, r.normalize())
+	}
+	return releases, nil
+}
+
+// decodeOneOrMany decodes a JSON array, or a single object into a
+// one-element slice
+func decodeOneOrMany[T any](resp *http.Response, list bool, into *[]T) error {
+	if list {
+		return json.NewDecoder(resp.Body).Decode(into)
+	}
+	var one T
+	if err := json.NewDecoder(resp.Body).Decode(&one); err != nil {
+		return err
+	}
+	*into = []T{one}
+	return nil
+}
+
+// get requests an API endpoint, returning errAssetNotFound on 404
+func (s *forgeSource) get(endpoint string) (*http.Response, error) {
+	resp, err := httpGet(endpoint, opts.APITimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", s.origin, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errAssetNotFound
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s API error (%d) for %s", s.kind, resp.StatusCode, endpoint)
+	}
+	return resp, nil
+}
+
+// remember caches fetched releases by tag
+func (s *forgeSource) remember(releases []forgeRelease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range releases {
+		s.releases[r.Tag] = r
+	}
+}
+
+// listForgeReleases walks the paginated release list
+func (s *forgeSource) listForgeReleases() ([]forgeRelease, error) {
+	var releases []forgeRelease
+	next := s.endpoint(s.api.Releases, "") + "?" + s.api.Page
+	for page := 0; next != "" && page < GITHUB_MAX_PAGES; page++ {
+		resp, err := s.get(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+		batch, err := s.decode(resp, true)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse releases: %w", err)
+		}
+		releases = append(releases, batch...)
+		next = nextPageURL(resp.Header.Get("Link"))
+	}
+	s.remember(releases)
+	return releases, nil
+}
+
+// release returns the release of a tag, fetching it once
+func (s *forgeSource) release(tag string) (forgeRelease, error) {
+	s.mu.Lock()
+	r, ok := s.releases[tag]
+	s.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	resp, err := s.get(s.endpoint(s.api.Release, tag))
+	if err != nil {
+		return forgeRelease{}, fmt.Errorf("release %s: %w", tag, err)
+	}
+	defer resp.Body.Close()
+	releases, err := s.decode(resp, false)
+	if err != nil {
+		return forgeRelease{}, fmt.Errorf("failed to parse release %s: %w", tag, err)
+	}
+	s.remember(releases)
+	return releases[0], nil
+}
+
+func (s *forgeSource) ListReleases() ([]GitHubRelease, error) {
+	releases, err := s.listForgeReleases()
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]GitHubRelease, len(releases))
+	for i, r := range releases {
+		listed[i] = GitHubRelease{TagName: r.Tag, Name: r.Name, Draft: r.Draft, Prerelease: r.Prerelease}
+		for _, a := range r.Assets {
+			listed[i].Assets = append(listed[i].Assets, ReleaseAsset{Name: a.Name, Size: a.Size})
+		}
+	}
+	return listed, nil
+}
+
+// LatestVersion asks the latest endpoint, falling back to the release list
+// on forges too old to have one
+func (s *forgeSource) LatestVersion() (string, error) {
+	if !opts.IncludePrereleases {
+		resp, err := s.get(s.endpoint(s.api.Latest, ""))
+		if err == nil {
+			defer resp.Body.Close()
+			releases, err := s.decode(resp, false)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse the latest release: %w", err)
+			}
+			s.remember(releases)
+			return releases[0].Tag, nil
+		}
+		if !errors.Is(err, errAssetNotFound) {
+			return "", err
+		}
+	}
+	releases, err := s.ListReleases()
+	if err != nil {
+		return "", err
+	}
+	versions := releaseVersions(releases, opts.IncludePrereleases)
+	if len(versions) == 0 {
+		return "", fmt.Errorf("%s lists no releases", s.Name())
+	}
+	return versions[0], nil
+}
+
+func (s *forgeSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	r, err := s.release(version)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]ReleaseAsset, len(r.Assets))
+	for i, a := range r.Assets {
+		assets[i] = ReleaseAsset{Name: a.Name, Size: a.Size, Digest: a.Digest}
+	}
+	return assets, nil
+}
+
+func (s *forgeSource) ReleaseNotes(version string) (string, error) {
+	r, err := s.release(version)
+	return r.Notes, err
+}
+
+// assetURL finds where the release links an asset
+func (s *forgeSource) assetURL(version, asset string) (string, error) {
+	r, err := s.release(version)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range r.Assets {
+		if a.Name == asset {
+			return a.URL, nil
+		}
+	}
+	return "", errAssetNotFound
+}
+
+func (s *forgeSource) FetchAsset(version, asset, destPath string) error {
+	link, err := s.assetURL(version, asset)
+	if err != nil {
+		return err
+	}
+	return downloadBinary(link, destPath)
+}
+
+func (s *forgeSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return githubSource{}.FetchGrammar(pkg, version, file, destPath)
+}
+
+// AssetURL looks the asset up in the release, or guesses the forge's
+// download path when the API cannot be reached
+func (s *forgeSource) AssetURL(version, asset string) string {
+	if link, err := s.assetURL(version, asset); err == nil {
+		return link
+	}
+	if s.kind == FORGE_GITLAB {
+		return fmt.Sprintf("%s/%s/-/releases/%s/downloads/%s", s.origin, s.repo, version, asset)
+	}
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", s.origin, s.repo, version, asset)
+}
+
+func (s *forgeSource) GrammarURL(pkg, version, file string) string {
+	return githubSource{}.GrammarURL(pkg, version, file)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestForgeSourceGitea(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	var server *httptest.Server
+	release := func(tag string, prerelease bool) string {
+		return fmt.Sprintf(`{"tag_name": %q, "prerelease": %t, "body": "notes of %s", "assets": [
+			{"name": "vibe-linux", "size": 6, "browser_download_url": "%s/fork/dotvibe/releases/download/%s/vibe-linux"}]}`,
+			tag, prerelease, tag, server.URL, tag)
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/fork/dotvibe/releases/latest":
+			fmt.Fprint(w, release("v1.0.0", false))
+		case "/api/v1/repos/fork/dotvibe/releases":
+			fmt.Fprintf(w, "[%s, %s]", release("v1.1.0-rc.1", true), release("v1.0.0", false))
+		case "/fork/dotvibe/releases/download/v1.0.0/vibe-linux":
+			fmt.Fprint(w, "binary")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	saved := opts
+	defer func() { opts = saved }()
+	source, err := newSource("gitea+" + server.URL + "/fork/dotvibe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := source.LatestVersion()
+	if err != nil || latest != "v1.0.0" {
+		t.Fatalf("LatestVersion = %q, %v; want v1.0.0", latest, err)
+	}
+	opts.IncludePrereleases = true
+	if latest, err := source.LatestVersion(); err != nil || latest != "v1.1.0-rc.1" {
+		t.Errorf("LatestVersion with prereleases = %q, %v; want v1.1.0-rc.1", latest, err)
+	}
+
+	if notes, err := source.(ReleaseNotesSource).ReleaseNotes("v1.0.0"); err != nil || notes != "notes of v1.0.0" {
+		t.Errorf("ReleaseNotes = %q, %v", notes, err)
+	}
+	assets, err := source.(AssetLister).ReleaseAssets("v1.0.0")
+	if err != nil || len(assets) != 1 || assets[0].Size != 6 {
+		t.Errorf("ReleaseAssets = %+v, %v", assets, err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := source.FetchAsset("v1.0.0", "vibe-linux", dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "binary" {
+		t.Errorf("downloaded %q, want binary", data)
+	}
+	if err := source.FetchAsset("v1.0.0", "vibe-windows.exe", dest); err == nil {
+		t.Errorf("fetching an asset the release lacks succeeded")
+	}
+}
+
+func TestForgeSourceGitLab(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsub%2Fdotvibe/releases/permalink/latest":
+			http.NotFound(w, r) // GitLab before 15.4
+		case "/api/v4/projects/group%2Fsub%2Fdotvibe/releases":
+			fmt.Fprintf(w, `[{"tag_name": "v2.0.0", "description": "notes", "assets": {"links": [
+				{"name": "vibe-linux", "url": "%[1]s/other", "direct_asset_url": "%[1]s/direct/vibe-linux"}]}},
+				{"tag_name": "v1.9.0", "assets": {"links": []}}]`, server.URL)
+		case "/direct/vibe-linux":
+			fmt.Fprint(w, "binary")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	saved := opts
+	defer func() { opts = saved }()
+	source, err := newSource("gitlab+" + server.URL + "/group/sub/dotvibe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := source.LatestVersion()
+	if err != nil || latest != "v2.0.0" {
+		t.Fatalf("LatestVersion = %q, %v; want v2.0.0 from the release list", latest, err)
+	}
+	// The release list filled the cache, so no per-tag request is needed
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := source.FetchAsset("v2.0.0", "vibe-linux", dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "binary" {
+		t.Errorf("downloaded %q, want binary from the direct asset URL", data)
+	}
+}
+
+func TestForgeAPIOverrides(t *testing.T) {
+	api := defaultForgeAPI(FORGE_GITHUB, "https://ghe.example.com")
+	if api.Base != "https://ghe.example.com/api/v3" {
+		t.Errorf("GitHub Enterprise API base = %s", api.Base)
+	}
+	if api := defaultForgeAPI(FORGE_GITHUB, "https://github.com"); api.Base != "https://api.github.com" {
+		t.Errorf("github.com API base = %s", api.Base)
+	}
+
+	api = api.withOverrides(Options{ForgeAPI: "https://proxy.example.com/gh/", ForgeLatestPath: "/r/{repo}/latest"})
+	if api.Base != "https://proxy.example.com/gh" || api.Latest != "/r/{repo}/latest" || api.Releases != "/repos/{repo}/releases" {
+		t.Errorf("overridden API = %+v", api)
+	}
+
+	if _, err := newSource("gitea+https://codeberg.org/dotvibe"); err == nil {
+		t.Errorf("a forge source without owner/repo was accepted")
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GITHUB_RELEASES_API lists every release of the repository, newest first
+const GITHUB_RELEASES_API = "https://api.github.com/repos/vhybzOS/.vibe/releases"
+
+// GITHUB_MAX_PAGES bounds release listing at 1000 releases
+const GITHUB_MAX_PAGES = 10
+
+// ReleaseLister is implemented by sources that can enumerate releases
+type ReleaseLister interface {
+	ListReleases() ([]GitHubRelease, error)
+}
+
+// decodedBody undoes the Content-Encoding of a response body
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d decodedBody) Close() error {
+	var first error
+	for _, c := range d.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// apiGet requests a GitHub API URL with compression enabled. Setting
+// Accept-Encoding ourselves disables Go's transparent gzip handling, so
+// the body is decoded here; HTTP's deflate is zlib-wrapped, not raw flate.
+// A GitHub token raises the rate limit when set.
+func apiGet(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	if token := githubToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpDo(req, opts.APITimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress GitHub API response: %w", err)
+		}
+		resp.Body = decodedBody{Reader: gz, closers: []io.Closer{gz, resp.Body}}
+	case "deflate":
+		zr, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress GitHub API response: %w", err)
+		}
+		resp.Body = decodedBody{Reader: zr, closers: []io.Closer{zr, resp.Body}}
+	}
+	resp.Header.Del("Content-Encoding")
+	return resp, nil
+}
+
+// linkNextPattern matches the rel="next" entry of a Link header
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextPageURL returns the URL of the next page from a Link header, or ""
+// on the last page
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		if m := linkNextPattern.FindStringSubmatch(part); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// listGitHubReleases walks the paginated releases list starting at url
+func listGitHubReleases(url string) ([]GitHubRelease, error) {
+	var releases []GitHubRelease
+	for page := 0; url != "" && page < GITHUB_MAX_PAGES; page++ {
+		resp, err := apiGet(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("GitHub API error (%d)", resp.StatusCode)
+		}
+
+		var batch []GitHubRelease
+		err = json.NewDecoder(resp.Body).Decode(&batch)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse releases: %w", err)
+		}
+
+		releases = append(releases, batch...)
+		url = nextPageURL(resp.Header.Get("Link"))
+	}
+	return releases, nil
+}
+
+// latestPrerelease returns the newest release including prereleases;
+// /releases/latest only ever reports stable releases
+func latestPrerelease(lister ReleaseLister) (string, error) {
+	releases, err := lister.ListReleases()
+	if err != nil {
+		return "", err
+	}
+	versions := releaseVersions(releases, true)
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no releases found")
+	}
+	return versions[0], nil
+}
+
+func (githubSource) ListReleases() ([]GitHubRelease, error) {
+	return listGitHubReleases(GITHUB_RELEASES_API + "?per_page=100")
+}
+
+// releaseVersions returns the published release tags, newest first.
+// Prereleases, flagged by GitHub or by their tag, are only included on
+// request.
+func releaseVersions(releases []GitHubRelease, prereleases bool) []string {
+	type tagged struct {
+		tag string
+		v   semver
+	}
+	var versions []tagged
+	for _, r := range releases {
+		v, ok := parseVersion(r.TagName)
+		if !ok || r.Draft || ((r.Prerelease || v.Prerelease != "") && !prereleases) {
+			continue
+		}
+		versions = append(versions, tagged{r.TagName, v})
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i].v, versions[j].v) > 0 })
+
+	tags := make([]string, len(versions))
+	for i, t := range versions {
+		tags[i] = t.tag
+	}
+	return tags
+}
+
+// runListRemote implements `install-dotvibe list-remote`
+func runListRemote(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	source, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+
+	lister, ok := source.(ReleaseLister)
+	if !ok {
+		// Sources without a release list only know their latest version
+		latest, err := source.LatestVersion()
+		if err != nil {
+			return err
+		}
+		fmt.Println(latest)
+		return nil
+	}
+
+	releases, err := lister.ListReleases()
+	if err != nil {
+		return err
+	}
+	for _, tag := range releaseVersions(releases, opts.IncludePrereleases) {
+		fmt.Println(tag)
+	}
+	return nil
+}
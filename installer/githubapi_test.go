@@ -0,0 +1,92 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/releases?page=2>; rel="next", <https://api.github.com/repositories/1/releases?page=5>; rel="last"`
+	if got := nextPageURL(link); got != "https://api.github.com/repositories/1/releases?page=2" {
+		t.Errorf("nextPageURL = %q", got)
+	}
+	last := `<https://api.github.com/repositories/1/releases?page=1>; rel="first", <https://api.github.com/repositories/1/releases?page=4>; rel="prev"`
+	if got := nextPageURL(last); got != "" {
+		t.Errorf("nextPageURL on last page = %q, want empty", got)
+	}
+}
+
+func TestListGitHubReleases(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+
+		body := `[{"tag_name":"v0.8.0-rc.1","prerelease":true},{"tag_name":"v0.7.27"}]`
+		if r.URL.Query().Get("page") == "2" {
+			body = `[{"tag_name":"v0.7.9"},{"tag_name":"v0.9.0","draft":true},{"tag_name":"v0.7.10"}]`
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/releases?page=2>; rel="next"`, server.URL))
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	releases, err := listGitHubReleases(server.URL + "/releases?per_page=100")
+	if err != nil {
+		t.Fatalf("listGitHubReleases failed: %v", err)
+	}
+	if len(releases) != 5 {
+		t.Fatalf("got %d releases across pages, want 5", len(releases))
+	}
+
+	got := strings.Join(releaseVersions(releases, false), " ")
+	if got != "v0.7.27 v0.7.10 v0.7.9" {
+		t.Errorf("releaseVersions = %q", got)
+	}
+
+	got = strings.Join(releaseVersions(releases, true), " ")
+	if got != "v0.8.0-rc.1 v0.7.27 v0.7.10 v0.7.9" {
+		t.Errorf("releaseVersions with prereleases = %q", got)
+	}
+}
+
+func TestAPIGetDeflate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "deflate")
+		zw := zlib.NewWriter(w)
+		zw.Write([]byte(`{"tag_name":"v1.0.0"}`))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	resp, err := apiGet(server.URL)
+	if err != nil {
+		t.Fatalf("apiGet failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != `{"tag_name":"v1.0.0"}` {
+		t.Errorf("body = %q, %v", body, err)
+	}
+}
+
+func TestPickVersionPrereleases(t *testing.T) {
+	tags := []string{"v0.7.27", "v0.8.0-rc.1", "v0.8.0-beta.2", "latest"}
+	if got, _ := pickVersion(tags, false); got != "v0.7.27" {
+		t.Errorf("pickVersion(stable) = %v, want v0.7.27", got)
+	}
+	if got, _ := pickVersion(tags, true); got != "v0.8.0-rc.1" {
+		t.Errorf("pickVersion(prereleases) = %v, want v0.8.0-rc.1", got)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// GITHUB_LOGIN_URL serves GitHub's OAuth device flow: the installer shows a
+// code, the user enters it at github.com/login/device, and the installer
+// polls until the token is granted
+const GITHUB_LOGIN_URL = "https://github.com/login"
+
+// githubClientID is the client ID of the project's GitHub OAuth app, set by
+// ldflags during build; VIBE_GITHUB_CLIENT_ID overrides it
+var githubClientID = ""
+
+// deviceCode is GitHub's answer to a device code request
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// deviceToken is GitHub's answer to a token poll; Error is set while the
+// user has not finished
+type deviceToken struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+	Interval    int    `json:"interval"`
+}
+
+// postForm posts a form to GitHub's login endpoints and decodes the JSON reply
+func postForm(endpoint string, form url.Values, out interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpDo(req, opts.APITimeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// githubDeviceLogin runs the device flow against loginURL and returns the
+// granted token
+func githubDeviceLogin(loginURL, clientID, scope string) (string, error) {
+	var code deviceCode
+	if err := postForm(loginURL+"/device/code", url.Values{"client_id": {clientID}, "scope": {scope}}, &code); err != nil {
+		return "", fmt.Errorf("failed to start GitHub login: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return "", fmt.Errorf("GitHub did not issue a device code (is %s an OAuth app with device flow enabled?)", clientID)
+	}
+
+	fmt.Printf("🔑 Open %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+	fmt.Printf("⏳ Waiting for authorization...\n")
+
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-runCtx.Done():
+			return "", runCtx.Err()
+		case <-time.After(interval):
+		}
+
+		var token deviceToken
+		err := postForm(loginURL+"/oauth/access_token", url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &token)
+		if err != nil {
+			return "", err
+		}
+		switch token.Error {
+		case "":
+			if token.AccessToken == "" {
+				return "", fmt.Errorf("GitHub granted an empty token")
+			}
+			return token.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval = time.Duration(token.Interval) * time.Second
+		case "access_denied":
+			return "", fmt.Errorf("authorization was denied")
+		default:
+			return "", fmt.Errorf("GitHub login failed: %s %s", token.Error, token.Description)
+		}
+	}
+	return "", fmt.Errorf("the code expired before it was entered; run auth login again")
+}
+
+// githubLoginToken obtains a GitHub token through the device flow
+func githubLoginToken() (string, error) {
+	clientID := githubClientID
+	if id := os.Getenv("VIBE_GITHUB_CLIENT_ID"); id != "" {
+		clientID = id
+	}
+	if clientID == "" {
+		return "", fmt.Errorf("this build has no GitHub OAuth app; pipe a token to `auth login --with-token` or set VIBE_GITHUB_CLIENT_ID")
+	}
+	return githubDeviceLogin(GITHUB_LOGIN_URL, clientID, opts.AuthScope)
+}
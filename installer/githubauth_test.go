@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGithubDeviceLogin(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("client_id") != "Iv1.test" {
+			t.Errorf("%s: client_id = %q", r.URL.Path, r.Form.Get("client_id"))
+		}
+		switch r.URL.Path {
+		case "/device/code":
+			if r.Form.Get("scope") != "repo" {
+				t.Errorf("scope = %q, want repo", r.Form.Get("scope"))
+			}
+			w.Write([]byte(`{"device_code":"dev123","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":60,"interval":0}`))
+		case "/oauth/access_token":
+			if r.Form.Get("device_code") != "dev123" {
+				t.Errorf("device_code = %q", r.Form.Get("device_code"))
+			}
+			polls++
+			if polls < 3 {
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"gho_granted","token_type":"bearer"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	token, err := githubDeviceLogin(server.URL, "Iv1.test", "repo")
+	if err != nil {
+		t.Fatalf("githubDeviceLogin failed: %v", err)
+	}
+	if token != "gho_granted" || polls != 3 {
+		t.Errorf("token = %q after %d polls, want gho_granted after 3", token, polls)
+	}
+}
+
+func TestGithubDeviceLoginDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/device/code" {
+			w.Write([]byte(`{"device_code":"dev123","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":60,"interval":0}`))
+			return
+		}
+		w.Write([]byte(`{"error":"access_denied"}`))
+	}))
+	defer server.Close()
+
+	if _, err := githubDeviceLogin(server.URL, "Iv1.test", ""); err == nil {
+		t.Errorf("a denied login succeeded")
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// NPM_REGISTRY_URL publishes the grammar packages unpkg serves files from
+const NPM_REGISTRY_URL = "https://registry.npmjs.org"
+
+// GrammarCatalog is implemented by sources that know the newest published
+// version of a grammar package
+type GrammarCatalog interface {
+	LatestGrammar(pkg string) (string, error)
+}
+
+// LatestGrammar asks the npm registry for the latest version of a package
+func (githubSource) LatestGrammar(pkg string) (string, error) {
+	resp, err := httpGet(NPM_REGISTRY_URL+"/"+pkg+"/latest", opts.APITimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to query the npm registry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("npm registry returned status %d for %s", resp.StatusCode, pkg)
+	}
+
+	var latest struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return "", fmt.Errorf("failed to parse npm registry response: %w", err)
+	}
+	return latest.Version, nil
+}
+
+// LatestGrammar returns the newest version of a package in the index
+func (s *indexSource) LatestGrammar(pkg string) (string, error) {
+	var newest string
+	var newestVersion semver
+	for _, g := range s.index.Grammars {
+		v, ok := parseVersion(g.Version)
+		if g.Package != pkg || !ok {
+			continue
+		}
+		if newest == "" || compareVersions(v, newestVersion) > 0 {
+			newest, newestVersion = g.Version, v
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("release index at %s lists no %s", s.baseURL, pkg)
+	}
+	return newest, nil
+}
+
+// grammarDigest returns the published sha256 of a grammar: the one a
+// release index lists, or the one in the SHA256SUMS of release, which
+// covers the grammar versions the installer pins. It is "" when nothing
+// publishes one.
+func grammarDigest(source Source, release string, g Grammar) (string, error) {
+	if index, ok := originSource(source).(*indexSource); ok {
+		for _, listed := range index.index.Grammars {
+			if listed.Package == g.Package && listed.Version == g.Version && listed.File == g.File {
+				return listed.SHA256, nil
+			}
+		}
+	}
+	pinned := false
+	for _, p := range GRAMMARS {
+		pinned = pinned || p == g
+	}
+	if !pinned || release == "" {
+		return "", nil
+	}
+	digests, err := releaseDigests(source, release)
+	if err != nil {
+		return "", err
+	}
+	return digests[g.File], nil
+}
+
+// installGrammar downloads a grammar into quarantine, checks that it is
+// WebAssembly matching want, its published digest, and moves it into
+// dataDir, returning its path and digest
+func installGrammar(dataDir string, source Source, g Grammar, want string) (string, string, error) {
+	if err := ensureDir(dataDir, MODE_DIR); err != nil {
+		return "", "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	staged, err := quarantinePath(g.File)
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(staged)
+	forgetFetches()
+	if err := source.FetchGrammar(g.Package, g.Version, g.File, staged); err != nil {
+		return "", "", fmt.Errorf("failed to download %s %s: %w", g.Package, g.Version, err)
+	}
+	if err := requireDigest(g.Package+"@"+g.Version, g.File, want); err != nil {
+		return "", "", err
+	}
+	if err := verifyWasm(staged, g.File); err != nil {
+		return "", "", err
+	}
+	if err := verifyDigest(staged, g.File, want); err != nil {
+		return "", "", err
+	}
+	digest, _, err := fileSHA256(staged)
+	if err != nil {
+		return "", "", err
+	}
+
+	path := filepath.Join(dataDir, g.File)
+	if err := promote(staged, path, MODE_DATA); err != nil {
+		return "", "", fmt.Errorf("failed to install %s: %w", g.File, err)
+	}
+	return path, digest, nil
+}
+
+// runGrammars implements `install-dotvibe grammars update`: it brings the
+// grammars in the data directory up to the newest versions in the
+// catalog, leaving the vibe binary alone
+func runGrammars(args []string) error {
+	if len(args) != 1 || args[0] != "update" {
+		return errUsage
+	}
+
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	if receipt.InstallPath == "" {
+		return fmt.Errorf("vibe is not installed (no receipt at %s)", getReceiptPath())
+	}
+
+	base, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+	catalog, ok := base.(GrammarCatalog)
+	if !ok {
+		return fmt.Errorf("%s does not publish a grammar catalog", base.Name())
+	}
+	source := withCache(withSharedCache(base))
+
+	dataDir := getDataDir(receipt.InstallPath)
+	updated := 0
+	for _, pinned := range GRAMMARS {
+		g := receipt.grammar(pinned)
+		latest, err := catalog.LatestGrammar(g.Package)
+		if err != nil {
+			return err
+		}
+		current, okCurrent := parseVersion(g.Version)
+		next, okNext := parseVersion(latest)
+		if !okCurrent || !okNext || compareVersions(next, current) <= 0 {
+			fmt.Printf("✅ %s %s is up to date\n", g.Package, g.Version)
+			continue
+		}
+
+		fmt.Printf("⬆️  Updating %s from %s to %s\n", g.Package, g.Version, latest)
+		g.Version = latest
+		want, err := grammarDigest(source, receipt.Version, g)
+		if err != nil {
+			return err
+		}
+		path, digest, err := installGrammar(dataDir, source, g, want)
+		if err != nil {
+			return err
+		}
+		receipt.recordGrammar(InstalledGrammar{Package: g.Package, Version: g.Version, File: g.File, SHA256: digest})
+		receipt.recordProvenance(newProvenance(g.Package, g.File, path, source, digest, ""))
+		receipt.recordFile(g.Package, g.Version, path)
+		updated++
+	}
+
+	if updated == 0 {
+		return nil
+	}
+	if err := receipt.save(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated %d grammar(s) in %s\n", updated, dataDir)
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGrammarsUpdate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+	defer func() { opts.Source = "" }()
+
+	g := GRAMMARS[0]
+	newer := string(wasmMagic) + "newer grammar"
+	index := ReleaseIndex{
+		Schema:   RELEASE_INDEX_SCHEMA,
+		Releases: []IndexRelease{{Version: "v1.0.0"}},
+		Grammars: []IndexGrammar{
+			{Package: g.Package, Version: g.Version, File: g.File, SHA256: sha256Hex(string(wasmMagic))},
+			{Package: g.Package, Version: "99.0.0", File: g.File, URL: "new.wasm", SHA256: sha256Hex(newer)},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + RELEASE_INDEX_FILE:
+			json.NewEncoder(w).Encode(index)
+		case "/new.wasm":
+			w.Write([]byte(newer))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	opts.Source = server.URL
+
+	installPath := filepath.Join(home, "bin")
+	binary := filepath.Join(installPath, "vibe")
+	os.MkdirAll(installPath, 0755)
+	os.WriteFile(binary, []byte("binary"), 0755)
+	receipt := &Receipt{Version: "v1.0.0", InstallPath: installPath}
+	if err := receipt.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGrammars([]string{"update"}); err != nil {
+		t.Fatalf("grammars update failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(getDataDir(installPath), g.File))
+	if err != nil || string(data) != newer {
+		t.Errorf("grammar = %q, %v; want the 99.0.0 file", data, err)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != "binary" {
+		t.Error("grammars update touched the vibe binary")
+	}
+
+	receipt, _ = loadReceipt()
+	if got := receipt.grammar(g); got.Version != "99.0.0" {
+		t.Errorf("receipt grammar version = %s, want 99.0.0", got.Version)
+	}
+	if len(receipt.Grammars) != 1 || receipt.Grammars[0].SHA256 != sha256Hex(newer) {
+		t.Errorf("receipt grammars = %+v", receipt.Grammars)
+	}
+
+	// The pinned version never replaces a newer one on reinstall
+	if got := receipt.grammar(Grammar{Package: g.Package, Version: "0.1.0", File: g.File}); got.Version != "99.0.0" {
+		t.Errorf("reinstall would use %s, want the updated 99.0.0", got.Version)
+	}
+}
+
+func TestInstallGrammarVerifiesDigest(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	dataDir := t.TempDir()
+
+	// The release's SHA256SUMS lists the pinned grammar
+	g := GRAMMARS[0]
+	wasm := string(wasmMagic) + g.Package + "@" + g.Version + "/" + g.File
+	source := &variantSource{files: map[string][]byte{"SHA256SUMS": []byte(sha256Hex(wasm) + "  " + g.File + "\n")}}
+	want, err := grammarDigest(source, "v1.0.0", g)
+	if err != nil || want != sha256Hex(wasm) {
+		t.Fatalf("grammarDigest = %q, %v; want the SHA256SUMS entry", want, err)
+	}
+	if _, _, err := installGrammar(dataDir, source, g, want); err != nil {
+		t.Errorf("installGrammar with its digest failed: %v", err)
+	}
+	if _, _, err := installGrammar(dataDir, source, g, sha256Hex("other")); !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("installGrammar with another digest = %v, want a checksum mismatch", err)
+	}
+
+	// Nothing lists a newer version, which needs --allow-unverified
+	newer := Grammar{Package: g.Package, Version: "99.0.0", File: g.File}
+	if want, err := grammarDigest(source, "v1.0.0", newer); err != nil || want != "" {
+		t.Errorf("grammarDigest of an unpinned version = %q, %v; want none", want, err)
+	}
+	if _, _, err := installGrammar(dataDir, source, newer, ""); err == nil || !strings.Contains(err.Error(), "--allow-unverified") {
+		t.Errorf("installGrammar without a digest = %v, want a refusal", err)
+	}
+	opts.AllowUnverified = true
+	if _, _, err := installGrammar(dataDir, source, newer, ""); err != nil {
+		t.Errorf("installGrammar with --allow-unverified failed: %v", err)
+	}
+}
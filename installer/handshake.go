@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Handshake is what the installed binaries reported right after install.
+// Comparing it with what they report later reveals binaries replaced or
+// modified outside the installer.
+type Handshake struct {
+	VersionOutput string            `json:"version_output"`         // first line of `vibe --version`
+	Commit        string            `json:"commit,omitempty"`       // git commit, when the binary reports one
+	SHA256        string            `json:"sha256"`                 // digest of the vibe binary
+	Dependencies  map[string]string `json:"dependencies,omitempty"` // module -> reported version
+	RecordedAt    time.Time         `json:"recorded_at"`
+}
+
+// commitPattern finds a git commit in version output, as "commit abc1234",
+// "rev: abc1234" or "(abc1234)"
+var commitPattern = regexp.MustCompile(`(?i)(?:commit|rev(?:ision)?|sha)[:= ]+([0-9a-f]{7,40})\b|\(([0-9a-f]{7,40})[ )]`)
+
+// extractCommit returns the git commit in version output, if any
+func extractCommit(output string) string {
+	m := commitPattern.FindStringSubmatch(output)
+	if m == nil {
+		return ""
+	}
+	if m[1] != "" {
+		return m[1]
+	}
+	return m[2]
+}
+
+// versionOutput runs `command --version` and returns its first line
+func versionOutput(command string) (string, error) {
+	out, err := exec.Command(command, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// takeHandshake records what vibe reports about itself, and what the
+// modules reported when they were verified
+func takeHandshake(binaryPath string, checks []ModuleCheck) (*Handshake, error) {
+	output, err := versionOutput(binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s --version: %w", binaryPath, err)
+	}
+	digest, _, err := fileSHA256(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Handshake{
+		VersionOutput: output,
+		Commit:        extractCommit(output),
+		SHA256:        digest,
+		Dependencies:  map[string]string{},
+		RecordedAt:    time.Now().UTC(),
+	}
+	for _, c := range checks {
+		if c.Reported != "" {
+			h.Dependencies[c.Name] = c.Reported
+		}
+	}
+	return h, nil
+}
+
+// handshakeDrift compares a recorded handshake with the current one and
+// describes every difference
+func handshakeDrift(recorded, current *Handshake) []string {
+	var drift []string
+	if recorded.SHA256 != current.SHA256 {
+		drift = append(drift, fmt.Sprintf("vibe binary changed since install (sha256 %s, was %s)", short(current.SHA256), short(recorded.SHA256)))
+	}
+	if recorded.VersionOutput != current.VersionOutput {
+		drift = append(drift, fmt.Sprintf("vibe reports %q, installer recorded %q", current.VersionOutput, recorded.VersionOutput))
+	}
+	for name, was := range recorded.Dependencies {
+		now, ok := current.Dependencies[name]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("%s no longer reports a version (was %s)", name, was))
+		case now != was:
+			drift = append(drift, fmt.Sprintf("%s is now %s, installer recorded %s", name, now, was))
+		}
+	}
+	return drift
+}
+
+// short abbreviates a digest for display
+func short(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
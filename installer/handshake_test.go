@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExtractCommit(t *testing.T) {
+	tests := map[string]string{
+		"vibe 0.7.27 (a1b2c3d 2025-06-01)":     "a1b2c3d",
+		"vibe v0.7.27 commit 0123456789abcdef": "0123456789abcdef",
+		"vibe 0.7.27":                          "",
+	}
+	for output, want := range tests {
+		if got := extractCommit(output); got != want {
+			t.Errorf("extractCommit(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestHandshakeDetectsReplacedBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as binaries")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "vibe")
+	dep := filepath.Join(dir, "surreal")
+	os.WriteFile(binary, []byte("#!/bin/sh\necho 'vibe 0.7.27 (a1b2c3d)'\n"), 0755)
+	os.WriteFile(dep, []byte("#!/bin/sh\necho 'surreal 2.3.5 for linux'\n"), 0755)
+	modules := []Module{{Name: "surrealdb", Command: dep}}
+
+	recorded, err := takeHandshake(binary, checkModules(modules))
+	if err != nil {
+		t.Fatalf("takeHandshake failed: %v", err)
+	}
+	if recorded.Commit != "a1b2c3d" || recorded.Dependencies["surrealdb"] != "v2.3.5" {
+		t.Errorf("handshake = %+v", recorded)
+	}
+
+	current, _ := takeHandshake(binary, checkModules(modules))
+	if drift := handshakeDrift(recorded, current); len(drift) != 0 {
+		t.Errorf("Unchanged install reported drift: %v", drift)
+	}
+
+	os.WriteFile(binary, []byte("#!/bin/sh\necho 'vibe 0.6.0'\n"), 0755)
+	os.WriteFile(dep, []byte("#!/bin/sh\necho 'surreal 2.4.0'\n"), 0755)
+	current, _ = takeHandshake(binary, checkModules(modules))
+	drift := strings.Join(handshakeDrift(recorded, current), "\n")
+	for _, want := range []string{"binary changed", "vibe 0.6.0", "surrealdb is now v2.4.0"} {
+		if !strings.Contains(drift, want) {
+			t.Errorf("drift missing %q:\n%s", want, drift)
+		}
+	}
+}
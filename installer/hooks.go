@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Executables in <vibe home>/installer-plugins are run at fixed points of an
+// install with a HookEvent as JSON on stdin, so organisations can add policy
+// checks without forking the installer. They run in name order; a plugin
+// exiting non-zero stops the install, and what it prints to stderr is shown
+// as the reason.
+const (
+	HOOKS_DIR    = "installer-plugins"
+	HOOK_TIMEOUT = 60 * time.Second
+
+	HOOK_PRE_RESOLVE   = "pre-resolve"   // before the release is chosen
+	HOOK_POST_DOWNLOAD = "post-download" // binary downloaded and verified, not yet installed
+	HOOK_POST_INSTALL  = "post-install"  // everything installed and verified
+)
+
+// HookEvent is what a plugin receives on stdin
+type HookEvent struct {
+	Event       string `json:"event"`
+	Installer   string `json:"installer_version"`
+	Platform    string `json:"platform"`
+	Source      string `json:"source,omitempty"`
+	Version     string `json:"version,omitempty"`
+	InstallPath string `json:"install_path,omitempty"`
+	Asset       string `json:"asset,omitempty"`
+	Path        string `json:"path,omitempty"` // downloaded or installed binary
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+// HookRun records a plugin invocation in the report
+type HookRun struct {
+	Plugin  string  `json:"plugin"`
+	Event   string  `json:"event"`
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// getHooksDir returns the plugin directory
+func getHooksDir() string {
+	return filepath.Join(getVibeHome(), HOOKS_DIR)
+}
+
+// isHookExecutable reports whether a plugin directory entry should be run:
+// executable files on Unix, programs and scripts cmd can start on Windows
+func isHookExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".cmd", ".bat":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// findHooks lists the plugins in dir in the order they run
+func findHooks(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var hooks []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, entry.Name()))
+		if err == nil && isHookExecutable(info) {
+			hooks = append(hooks, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
+
+// runHooks sends event to every plugin, stopping at the first that rejects it
+func runHooks(event HookEvent) error {
+	hooks := findHooks(getHooksDir())
+	if len(hooks) == 0 {
+		return nil
+	}
+	event.Installer = version
+	event.Platform = runSummary.Platform
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		name := filepath.Base(hook)
+		fmt.Printf("🔌 Running plugin %s (%s)\n", name, event.Event)
+		start := time.Now()
+		err := runHook(hook, event.Event, payload)
+		run := HookRun{Plugin: name, Event: event.Event, Seconds: time.Since(start).Seconds()}
+		if err != nil {
+			run.Error = err.Error()
+		}
+		runSummary.Hooks = append(runSummary.Hooks, run)
+		if err != nil {
+			return fmt.Errorf("plugin %s rejected %s: %w", name, event.Event, err)
+		}
+	}
+	return nil
+}
+
+// runHook runs one plugin with the event on stdin
+func runHook(hook, event string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(runCtx, HOOK_TIMEOUT)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, hook, event)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "VIBE_HOOK_EVENT="+event)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", HOOK_TIMEOUT)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+	dir := filepath.Join(home, HOOKS_DIR)
+	os.MkdirAll(dir, 0755)
+	events := filepath.Join(home, "events")
+
+	// 10-record saves every event; 20-policy rejects unapproved versions;
+	// the README is not executable and must be skipped
+	os.WriteFile(filepath.Join(dir, "10-record"), []byte("#!/bin/sh\ncat >> "+events+"\necho >> "+events+"\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "20-policy"), []byte("#!/bin/sh\ngrep -q '\"version\":\"v9.9.9\"' && { echo 'v9.9.9 is not approved' >&2; exit 1; }\nexit 0\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644)
+
+	before := len(runSummary.Hooks)
+	if err := runHooks(HookEvent{Event: HOOK_POST_DOWNLOAD, Version: "v1.0.0", Asset: "vibe-v1.0.0-linux-x86_64"}); err != nil {
+		t.Fatalf("runHooks failed: %v", err)
+	}
+	if got := len(runSummary.Hooks) - before; got != 2 {
+		t.Errorf("recorded %d plugin runs, want 2", got)
+	}
+
+	data, err := os.ReadFile(events)
+	if err != nil {
+		t.Fatalf("plugin did not receive the event: %v", err)
+	}
+	var event HookEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("event is not JSON: %v", err)
+	}
+	if event.Event != HOOK_POST_DOWNLOAD || event.Version != "v1.0.0" || event.Installer != version {
+		t.Errorf("event = %+v", event)
+	}
+
+	err = runHooks(HookEvent{Event: HOOK_PRE_RESOLVE, Version: "v9.9.9"})
+	if err == nil || !strings.Contains(err.Error(), "20-policy") || !strings.Contains(err.Error(), "not approved") {
+		t.Errorf("runHooks(v9.9.9) = %v, want the policy plugin's rejection", err)
+	}
+}
+
+func TestRunHooksWithoutPlugins(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	if err := runHooks(HookEvent{Event: HOOK_POST_INSTALL}); err != nil {
+		t.Errorf("runHooks without a plugin directory = %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// pendingWork compares the receipt and the files on disk with the state
+// installing version into installPath would produce, returning what still
+// differs. Nothing pending makes a rerun a no-op, so config management can
+// run the installer on every pass.
+func pendingWork(receipt *Receipt, installPath, binaryPath, version string) []string {
+	var pending []string
+	switch {
+	case receipt.Version == "":
+		return []string{"vibe is not installed"}
+	case receipt.Version != version:
+		pending = append(pending, fmt.Sprintf("vibe %s is installed, %s wanted", receipt.Version, version))
+	case receipt.InstallPath != installPath:
+		pending = append(pending, fmt.Sprintf("vibe is installed in %s, not %s", receipt.InstallPath, installPath))
+	}
+	if len(receipt.FailedModules) > 0 {
+		pending = append(pending, "failed earlier: "+strings.Join(receipt.FailedModules, ", "))
+	}
+	if opts.EncryptData && receipt.DataEncryption == "" {
+		pending = append(pending, "the data directory is not encrypted")
+	}
+	if !opts.NoInit && receipt.InitializedAt == nil {
+		pending = append(pending, "vibe init has not run")
+	}
+
+	// Files must still be the ones downloaded
+	recorded := map[string]Provenance{}
+	for _, p := range receipt.Provenance {
+		recorded[p.Component] = p
+	}
+	files := [][2]string{{"vibe", binaryPath}} // component, path
+	for _, pinned := range GRAMMARS {
+		g := receipt.grammar(pinned)
+		if isSkipped(g.Package) {
+			continue
+		}
+		if installedGrammar(receipt, g.Package) != g.Version {
+			pending = append(pending, fmt.Sprintf("%s %s is not installed", g.Package, g.Version))
+			continue
+		}
+		files = append(files, [2]string{g.Package, filepath.Join(getDataDir(installPath), g.File)})
+	}
+	checked := map[string]bool{}
+	for _, f := range files {
+		checked[f[1]] = true
+		p, ok := recorded[f[0]]
+		if !ok || p.Path != f[1] {
+			pending = append(pending, "no checksum recorded for "+f[1])
+			continue
+		}
+		if digest, _, err := fileSHA256(p.Path); err != nil || digest != p.SHA256 {
+			pending = append(pending, fmt.Sprintf("%s is missing or changed", p.Path))
+		}
+	}
+	// Including what modules wrote, such as cargo-built executables
+	for _, f := range receipt.Files {
+		if checked[f.Path] || f.SHA256 == "" || isSkipped(f.Component) {
+			continue
+		}
+		if digest, _, err := fileSHA256(f.Path); err != nil || digest != f.SHA256 {
+			pending = append(pending, fmt.Sprintf("%s is missing or changed", f.Path))
+		}
+	}
+	if len(pending) > 0 {
+		return pending
+	}
+
+	// Modules last: running them is the slowest check
+	for _, c := range checkModules(allModules()) {
+		if c.Status != VERIFY_OK {
+			pending = append(pending, fmt.Sprintf("%s is %s", c.Name, strings.ReplaceAll(c.Status, "_", " ")))
+		}
+	}
+	return pending
+}
+
+// EXIT_CHANGES_PENDING is the exit status of `install --check` when an
+// install would change the system. No other outcome exits with it, so
+// configuration management can tell pending changes from usage, config and
+// policy errors (EXIT_USAGE) and failures (1).
+const EXIT_CHANGES_PENDING = 2
+
+// checkInstall implements `install --check`: it reports what installing
+// version would change, without changing anything
+func checkInstall(receipt *Receipt, installPath, binaryPath, version string) error {
+	pending := pendingWork(receipt, installPath, binaryPath, version)
+	if len(pending) == 0 {
+		fmt.Printf("✅ vibe %s and its dependencies are installed and unchanged\n", version)
+		return nil
+	}
+	fmt.Printf("🔎 Installing vibe %s would change:\n", version)
+	for _, reason := range pending {
+		fmt.Printf("   • %s\n", reason)
+	}
+	return exitStatus(EXIT_CHANGES_PENDING)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPendingWork(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts, savedModules := opts, MODULES
+	defer func() { opts, MODULES = savedOpts, savedModules }()
+	opts = Options{}
+	toolErr := error(nil)
+	MODULES = []Module{{Name: "tool", Verify: func() error { return toolErr }}}
+
+	installPath := t.TempDir()
+	binary := filepath.Join(installPath, "vibe")
+	os.WriteFile(binary, []byte("binary"), 0755)
+	binaryDigest, _, _ := fileSHA256(binary)
+	g := GRAMMARS[0]
+	grammar := filepath.Join(getDataDir(installPath), g.File)
+	os.MkdirAll(filepath.Dir(grammar), 0755)
+	os.WriteFile(grammar, wasmMagic, 0644)
+	grammarDigest, _, _ := fileSHA256(grammar)
+
+	toolBinary := filepath.Join(t.TempDir(), "tool")
+	os.WriteFile(toolBinary, []byte("tool"), 0755)
+
+	now := time.Now()
+	receipt := &Receipt{
+		Version:       "v1.0.0",
+		InstallPath:   installPath,
+		InitializedAt: &now,
+		Grammars:      []InstalledGrammar{{Package: g.Package, Version: g.Version, File: g.File, SHA256: grammarDigest}},
+		Provenance: []Provenance{
+			{Component: "vibe", Path: binary, SHA256: binaryDigest},
+			{Component: g.Package, Path: grammar, SHA256: grammarDigest},
+		},
+	}
+	receipt.recordFile("tool", "v1.0.0", toolBinary)
+	if pending := pendingWork(receipt, installPath, binary, "v1.0.0"); len(pending) != 0 {
+		t.Fatalf("pendingWork = %v, want nothing for a matching install", pending)
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		change  func()
+		want    string
+	}{
+		{"newer release", "v1.1.0", func() {}, "vibe v1.0.0 is installed, v1.1.0 wanted"},
+		{"binary replaced", "v1.0.0", func() { os.WriteFile(binary, []byte("other"), 0755) }, binary + " is missing or changed"},
+		{"grammar deleted", "v1.0.0", func() { os.Remove(grammar) }, grammar + " is missing or changed"},
+		{"failed module", "v1.0.0", func() { receipt.FailedModules = []string{"tool"} }, "failed earlier: tool"},
+		{"init pending", "v1.0.0", func() { receipt.InitializedAt = nil }, "vibe init has not run"},
+		{"broken module", "v1.0.0", func() { toolErr = fmt.Errorf("missing") }, "tool is failed"},
+		{"module file deleted", "v1.0.0", func() { os.Remove(toolBinary) }, toolBinary + " is missing or changed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := *receipt
+			defer func() {
+				*receipt = saved
+				toolErr = nil
+				os.WriteFile(binary, []byte("binary"), 0755)
+				os.WriteFile(grammar, wasmMagic, 0644)
+				os.WriteFile(toolBinary, []byte("tool"), 0755)
+			}()
+			tt.change()
+			pending := pendingWork(receipt, installPath, binary, tt.version)
+			if !strings.Contains(strings.Join(pending, "\n"), tt.want) {
+				t.Errorf("pendingWork = %v, want %q", pending, tt.want)
+			}
+		})
+	}
+
+	if pending := pendingWork(&Receipt{}, installPath, binary, "v1.0.0"); len(pending) != 1 || pending[0] != "vibe is not installed" {
+		t.Errorf("pendingWork on a fresh machine = %v", pending)
+	}
+}
+
+func TestCheckInstall(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+	err := checkInstall(&Receipt{}, installPath, filepath.Join(installPath, "vibe"), "v1.0.0")
+	if err != exitStatus(EXIT_CHANGES_PENDING) {
+		t.Errorf("checkInstall on a fresh machine = %v, want exit status %d", err, EXIT_CHANGES_PENDING)
+	}
+	if _, err := os.Stat(getReceiptPath()); !os.IsNotExist(err) {
+		t.Errorf("checkInstall wrote the receipt")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Self-hosted release servers publish a JSON index at <source>/index.json
+// and are used with --source https://releases.example.com/vibe, without
+// the GitHub API:
+//
+//	{
+//	  "schema": 1,
+//	  "latest": "v1.2.0",
+//	  "releases": [{
+//	    "version": "v1.2.0",
+//	    "prerelease": false,
+//	    "published_at": "2025-06-01T12:00:00Z",
+//	    "notes": "release notes in markdown",
+//	    "assets": [{"name": "vibe-v1.2.0-linux-x86_64", "url": "v1.2.0/vibe-v1.2.0-linux-x86_64", "size": 52428800, "sha256": "…"}]
+//	  }],
+//	  "grammars": [{"package": "tree-sitter-typescript", "version": "0.23.2", "file": "tree-sitter-typescript.wasm", "url": "…", "sha256": "…"}]
+//	}
+//
+// Asset and grammar URLs may be relative to the index. "latest" is
+// optional; without it the newest stable release is used. Every download
+// is checked against its sha256. Grammars missing from the index are read
+// from grammars/<pkg>@<version>/<file>, the mirror layout.
+const (
+	RELEASE_INDEX_FILE   = "index.json"
+	RELEASE_INDEX_SCHEMA = 1
+)
+
+// ReleaseIndex is the document served by a self-hosted release server
+type ReleaseIndex struct {
+	Schema   int            `json:"schema"`
+	Latest   string         `json:"latest,omitempty"`
+	Releases []IndexRelease `json:"releases"`
+	Grammars []IndexGrammar `json:"grammars,omitempty"`
+}
+
+// IndexRelease is a published version and its files
+type IndexRelease struct {
+	Version     string       `json:"version"`
+	Prerelease  bool         `json:"prerelease,omitempty"`
+	PublishedAt time.Time    `json:"published_at,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
+	Assets      []IndexAsset `json:"assets"`
+}
+
+// IndexAsset is a downloadable file with its digest
+type IndexAsset struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"` // defaults to <version>/<name>
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+// IndexGrammar is a grammar file hosted by the release server
+type IndexGrammar struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	File    string `json:"file"`
+	URL     string `json:"url,omitempty"` // defaults to grammars/<pkg>@<version>/<file>
+	SHA256  string `json:"sha256"`
+}
+
+// indexSource reads releases from a server publishing a ReleaseIndex
+type indexSource struct {
+	baseURL string // without trailing slash
+	index   *ReleaseIndex
+}
+
+// fetchReleaseIndex downloads and parses <baseURL>/index.json. A server
+// without an index reports errAssetNotFound: static hosts answer missing
+// files with 404, 403 or a catch-all page, so any unsuccessful status or
+// body that is not JSON counts as no index.
+func fetchReleaseIndex(baseURL string) (*ReleaseIndex, error) {
+	resp, err := httpGet(baseURL+"/"+RELEASE_INDEX_FILE, opts.APITimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errAssetNotFound
+	}
+
+	var index ReleaseIndex
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, errAssetNotFound
+	}
+	if index.Schema != RELEASE_INDEX_SCHEMA {
+		return nil, fmt.Errorf("release index uses schema %d; this installer understands schema %d", index.Schema, RELEASE_INDEX_SCHEMA)
+	}
+	return &index, nil
+}
+
+// newHTTPSource returns an index source when the server publishes a
+// release index, and a static mirror source otherwise
+func newHTTPSource(u *url.URL) (Source, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(u.String(), "/"+RELEASE_INDEX_FILE), "/")
+	index, err := fetchReleaseIndex(base)
+	if errors.Is(err, errAssetNotFound) {
+		return newMirrorSource(u), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &indexSource{baseURL: base, index: index}, nil
+}
+
+func (s *indexSource) Name() string {
+	return s.baseURL
+}
+
+func (s *indexSource) LatestVersion() (string, error) {
+	if s.index.Latest != "" && !opts.IncludePrereleases {
+		return s.index.Latest, nil
+	}
+	releases, _ := s.ListReleases()
+	versions := releaseVersions(releases, opts.IncludePrereleases)
+	if len(versions) == 0 {
+		return "", fmt.Errorf("release index at %s lists no releases", s.baseURL)
+	}
+	return versions[0], nil
+}
+
+// ListReleases reports the indexed releases in GitHub's form
+func (s *indexSource) ListReleases() ([]GitHubRelease, error) {
+	releases := make([]GitHubRelease, len(s.index.Releases))
+	for i, r := range s.index.Releases {
+		releases[i] = GitHubRelease{TagName: r.Version, Name: r.Version, Prerelease: r.Prerelease}
+		for _, a := range r.Assets {
+			releases[i].Assets = append(releases[i].Assets, ReleaseAsset{Name: a.Name, Size: a.Size})
+		}
+	}
+	return releases, nil
+}
+
+func (s *indexSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	release, ok := s.release(version)
+	if !ok {
+		return nil, fmt.Errorf("release %s not found", version)
+	}
+	var assets []ReleaseAsset
+	for _, a := range release.Assets {
+		assets = append(assets, ReleaseAsset{Name: a.Name, Size: a.Size})
+	}
+	return assets, nil
+}
+
+func (s *indexSource) ReleaseNotes(version string) (string, error) {
+	release, ok := s.release(version)
+	if !ok {
+		return "", fmt.Errorf("release %s not found", version)
+	}
+	return release.Notes, nil
+}
+
+// release finds an indexed release by tag
+func (s *indexSource) release(version string) (IndexRelease, bool) {
+	for _, r := range s.index.Releases {
+		if r.Version == version {
+			return r, true
+		}
+	}
+	return IndexRelease{}, false
+}
+
+func (s *indexSource) FetchAsset(version, asset, destPath string) error {
+	release, ok := s.release(version)
+	if !ok {
+		return fmt.Errorf("release %s is not in the index: %w", version, errAssetNotFound)
+	}
+	for _, a := range release.Assets {
+		if a.Name != asset {
+			continue
+		}
+		ref := a.URL
+		if ref == "" {
+			ref = version + "/" + asset
+		}
+		return s.download(ref, a.SHA256, destPath, func(src string) error { return downloadBinary(src, destPath) })
+	}
+	return fmt.Errorf("release %s has no asset %s: %w", version, asset, errAssetNotFound)
+}
+
+func (s *indexSource) FetchGrammar(pkg, version, file, destPath string) error {
+	ref, digest := fmt.Sprintf("grammars/%s@%s/%s", pkg, version, file), ""
+	for _, g := range s.index.Grammars {
+		if g.Package == pkg && g.Version == version && g.File == file {
+			if g.URL != "" {
+				ref = g.URL
+			}
+			digest = g.SHA256
+			break
+		}
+	}
+	return s.download(ref, digest, destPath, func(src string) error {
+		return downloadFile(src, destPath, opts.DownloadTimeout)
+	})
+}
+
+// download fetches ref, resolved against the index, and checks its digest.
+// A file that does not match is deleted.
+func (s *indexSource) download(ref, digest, destPath string, get func(src string) error) error {
+	base, err := url.Parse(s.baseURL + "/")
+	if err != nil {
+		return err
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q in release index: %w", ref, err)
+	}
+	if err := get(u.String()); err != nil {
+		return err
+	}
+	if digest == "" {
+		return nil
+	}
+
+	got, _, err := fileSHA256(destPath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, digest) {
+		os.Remove(destPath)
+		return fmt.Errorf("checksum mismatch for %s: got %s, index lists %s", u, short(got), short(digest))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIndexSource(t *testing.T) {
+	index := ReleaseIndex{
+		Schema: RELEASE_INDEX_SCHEMA,
+		Releases: []IndexRelease{
+			{Version: "v1.1.0-rc.1", Prerelease: true},
+			{Version: "v1.0.0", Notes: "First release", Assets: []IndexAsset{
+				{Name: "vibe-linux", SHA256: sha256Hex("binary")},
+				{Name: "vibe-macos", URL: "/files/vibe-macos", SHA256: sha256Hex("mac binary")},
+				{Name: "vibe-tampered", SHA256: sha256Hex("original")},
+			}},
+		},
+		Grammars: []IndexGrammar{{Package: "tree-sitter-typescript", Version: "0.23.2", File: "ts.wasm", URL: "wasm/ts.wasm", SHA256: sha256Hex("wasm")}},
+	}
+	files := map[string]string{
+		"/vibe/v1.0.0/vibe-linux":    "binary",
+		"/files/vibe-macos":          "mac binary",
+		"/vibe/v1.0.0/vibe-tampered": "tampered",
+		"/vibe/wasm/ts.wasm":         "wasm",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/vibe/"+RELEASE_INDEX_FILE {
+			json.NewEncoder(w).Encode(index)
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	source, err := newSource(server.URL + "/vibe/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := source.(*indexSource); !ok {
+		t.Fatalf("newSource returned %T, want an index source", source)
+	}
+
+	if latest, err := source.LatestVersion(); err != nil || latest != "v1.0.0" {
+		t.Errorf("LatestVersion = %q, %v", latest, err)
+	}
+	defer func(saved bool) { opts.IncludePrereleases = saved }(opts.IncludePrereleases)
+	opts.IncludePrereleases = true
+	if latest, _ := source.LatestVersion(); latest != "v1.1.0-rc.1" {
+		t.Errorf("LatestVersion with prereleases = %q", latest)
+	}
+
+	dir := t.TempDir()
+	for asset, want := range map[string]string{"vibe-linux": "binary", "vibe-macos": "mac binary"} {
+		dest := filepath.Join(dir, asset)
+		if err := source.FetchAsset("v1.0.0", asset, dest); err != nil {
+			t.Errorf("FetchAsset(%s) failed: %v", asset, err)
+		} else if data, _ := os.ReadFile(dest); string(data) != want {
+			t.Errorf("FetchAsset(%s) wrote %q", asset, data)
+		}
+	}
+
+	tampered := filepath.Join(dir, "vibe-tampered")
+	if err := source.FetchAsset("v1.0.0", "vibe-tampered", tampered); err == nil {
+		t.Error("FetchAsset accepted a file with the wrong digest")
+	}
+	if _, err := os.Stat(tampered); !os.IsNotExist(err) {
+		t.Error("file with the wrong digest was kept")
+	}
+	if err := source.FetchAsset("v1.0.0", "vibe-windows.exe", filepath.Join(dir, "x")); !errors.Is(err, errAssetNotFound) {
+		t.Errorf("FetchAsset of an unlisted asset = %v, want errAssetNotFound", err)
+	}
+
+	if err := source.FetchGrammar("tree-sitter-typescript", "0.23.2", "ts.wasm", filepath.Join(dir, "ts.wasm")); err != nil {
+		t.Errorf("FetchGrammar failed: %v", err)
+	}
+	if notes, _ := source.(ReleaseNotesSource).ReleaseNotes("v1.0.0"); notes != "First release" {
+		t.Errorf("ReleaseNotes = %q", notes)
+	}
+}
+
+func TestHTTPSourceWithoutIndexIsMirror(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	source, err := newSource(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := source.(mirrorSource); !ok {
+		t.Errorf("newSource returned %T, want a static mirror", source)
+	}
+}
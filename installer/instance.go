@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// INSTANCE_FILE describes this installation in the data directory, for the
+// vibe CLI and fleet tooling: licensing, support and, when the user opts
+// in to metrics, correlating reports of one machine
+const INSTANCE_FILE = "instance.json"
+
+// Instance is the metadata of one installation. The ID is random, so it
+// reveals nothing about the machine, and stays the same across upgrades
+// and reinstalls that keep the data directory.
+type Instance struct {
+	ID          string    `json:"id"`
+	Platform    string    `json:"platform"`
+	Channel     string    `json:"channel"`
+	Version     string    `json:"version"`
+	Installer   string    `json:"installer_version"`
+	InstalledAt time.Time `json:"installed_at"` // first install
+	UpdatedAt   time.Time `json:"updated_at"`   // last install or upgrade
+}
+
+// getInstancePath returns where the instance metadata is kept
+func getInstancePath(receipt *Receipt) string {
+	return filepath.Join(getUserDataDir(receipt), INSTANCE_FILE)
+}
+
+// newInstanceID returns a random RFC 4122 version 4 UUID
+func newInstanceID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// loadInstance reads the instance metadata, nil when there is none yet
+func loadInstance(receipt *Receipt) (*Instance, error) {
+	data, err := os.ReadFile(getInstancePath(receipt))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var inst Instance
+	if err := json.Unmarshal(data, &inst); err != nil || inst.ID == "" {
+		return nil, fmt.Errorf("invalid %s", getInstancePath(receipt))
+	}
+	return &inst, nil
+}
+
+// recordInstance writes the metadata of the installation of version,
+// creating the ID on the first install
+func recordInstance(receipt *Receipt, version string) (*Instance, error) {
+	inst, err := loadInstance(receipt)
+	if err != nil {
+		warnf("Replacing unreadable instance metadata: %v", err)
+	}
+	now := time.Now().UTC()
+	if inst == nil {
+		id, err := newInstanceID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate an instance ID: %w", err)
+		}
+		inst = &Instance{ID: id, InstalledAt: now}
+	}
+	inst.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	inst.Channel = CHANNEL_STABLE
+	if v, ok := parseVersion(version); ok && v.Prerelease != "" {
+		inst.Channel = CHANNEL_PRERELEASE
+	}
+	inst.Version = version
+	inst.Installer = runSummary.Installer
+	inst.UpdatedAt = now
+
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureDir(getUserDataDir(receipt), MODE_DIR); err != nil {
+		return nil, err
+	}
+	if err := writeFileMode(getInstancePath(receipt), append(data, '\n'), MODE_DATA); err != nil {
+		return nil, fmt.Errorf("failed to write instance metadata: %w", err)
+	}
+	return inst, nil
+}
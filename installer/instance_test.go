@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestRecordInstance(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	receipt := &Receipt{}
+
+	first, err := recordInstance(receipt, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(first.ID) {
+		t.Errorf("instance ID %q is not a version 4 UUID", first.ID)
+	}
+	if first.Channel != CHANNEL_STABLE {
+		t.Errorf("channel = %s, want %s", first.Channel, CHANNEL_STABLE)
+	}
+
+	second, err := recordInstance(receipt, "v1.1.0-rc.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || !second.InstalledAt.Equal(first.InstalledAt) {
+		t.Errorf("an upgrade changed the instance: %+v, was %+v", second, first)
+	}
+	if second.Version != "v1.1.0-rc.1" || second.Channel != CHANNEL_PRERELEASE {
+		t.Errorf("upgraded instance = %+v", second)
+	}
+
+	os.WriteFile(getInstancePath(receipt), []byte("not json"), MODE_DATA)
+	if replaced, err := recordInstance(receipt, "v1.1.0"); err != nil || replaced.ID == first.ID {
+		t.Errorf("unreadable metadata was not replaced: %+v, %v", replaced, err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// KEYCHAIN_SERVICE names the installer's entries in the OS credential store:
+// the macOS Keychain, the Secret Service (libsecret) on Linux and the
+// Windows Credential Manager
+const KEYCHAIN_SERVICE = "dotvibe"
+
+// keychainCommand returns the command storing, reading or deleting a
+// secret. Secrets go to its stdin, never into arguments other users can
+// see.
+func keychainCommand(op, account string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		switch op {
+		case "set":
+			// -w as the last option prompts for the secret on stdin
+			return exec.Command("security", "add-generic-password", "-U", "-s", KEYCHAIN_SERVICE, "-a", account, "-w"), nil
+		case "get":
+			return exec.Command("security", "find-generic-password", "-s", KEYCHAIN_SERVICE, "-a", account, "-w"), nil
+		default:
+			return exec.Command("security", "delete-generic-password", "-s", KEYCHAIN_SERVICE, "-a", account), nil
+		}
+	case "windows":
+		vault := "$v = New-Object Windows.Security.Credentials.PasswordVault; "
+		load := "[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; "
+		var script string
+		switch op {
+		case "set":
+			script = load + vault + fmt.Sprintf("$v.Add((New-Object Windows.Security.Credentials.PasswordCredential('%s', '%s', [Console]::In.ReadToEnd())))", KEYCHAIN_SERVICE, account)
+		case "get":
+			script = load + vault + fmt.Sprintf("$c = $v.Retrieve('%s', '%s'); $c.RetrievePassword(); $c.Password", KEYCHAIN_SERVICE, account)
+		default:
+			script = load + vault + fmt.Sprintf("$v.Remove($v.Retrieve('%s', '%s'))", KEYCHAIN_SERVICE, account)
+		}
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, fmt.Errorf("secret-tool not found in PATH; install libsecret-tools for the Secret Service keyring")
+		}
+		switch op {
+		case "set":
+			return exec.Command("secret-tool", "store", "--label", KEYCHAIN_SERVICE+" "+account, "service", KEYCHAIN_SERVICE, "account", account), nil
+		case "get":
+			return exec.Command("secret-tool", "lookup", "service", KEYCHAIN_SERVICE, "account", account), nil
+		default:
+			return exec.Command("secret-tool", "clear", "service", KEYCHAIN_SERVICE, "account", account), nil
+		}
+	}
+}
+
+// runKeychain runs a credential store command, feeding it input
+func runKeychain(op, account, input string) (string, error) {
+	cmd, err := keychainCommand(op, account)
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("keychain %s %s: %s", op, account, msg)
+		}
+		return "", fmt.Errorf("keychain %s %s: %w", op, account, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// keychainSet stores a secret in the OS credential store
+func keychainSet(account, secret string) error {
+	input := secret
+	if runtime.GOOS == "darwin" {
+		input = secret + "\n" + secret + "\n" // security asks twice
+	}
+	_, err := runKeychain("set", account, input)
+	return err
+}
+
+// keychainGet reads a secret from the OS credential store
+func keychainGet(account string) (string, error) {
+	secret, err := runKeychain("get", account, "")
+	if err == nil && secret == "" {
+		return "", fmt.Errorf("no %s secret in the keychain", account)
+	}
+	return secret, err
+}
+
+// keychainDelete removes a secret from the OS credential store
+func keychainDelete(account string) error {
+	_, err := runKeychain("delete", account, "")
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+)
+
+// EXIT_UPDATE_AVAILABLE is the exit status of `latest --check` when the
+// installed vibe is older than the latest release (or missing)
+const EXIT_UPDATE_AVAILABLE = 10
+
+// exitStatus is an error that ends the installer with a specific exit
+// status and no error message
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// latestFlags registers the flags of the latest command
+func latestFlags(fs *flag.FlagSet) {
+	addSourceFlags(fs)
+	fs.BoolVar(&opts.Check, "check", opts.Check, "exit 0 when the installed vibe is up to date and 10 when an update is available")
+}
+
+// resolveLatest returns the latest release without printing anything,
+// so the output stays machine-readable
+func resolveLatest(source Source) (string, error) {
+	lister, ok := source.(ReleaseLister)
+	if !ok {
+		return source.LatestVersion()
+	}
+	releases, err := lister.ListReleases()
+	if err != nil {
+		return "", err
+	}
+	versions := releaseVersions(releases, opts.IncludePrereleases)
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no releases found")
+	}
+	return versions[0], nil
+}
+
+// updateAvailable reports whether latest is newer than the installed
+// version; a missing or unreadable installation always needs an update
+func updateAvailable(installed string, found bool, latest string) bool {
+	if !found {
+		return true
+	}
+	i, okInstalled := parseVersion(installed)
+	l, okLatest := parseVersion(latest)
+	if !okInstalled || !okLatest {
+		return installed != latest
+	}
+	return compareVersions(i, l) < 0
+}
+
+// runLatest implements `install-dotvibe latest [--check]`: it prints the
+// latest applicable version and nothing else
+func runLatest(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	source, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+	latest, err := resolveLatest(source)
+	if err != nil {
+		return err
+	}
+	fmt.Println(latest)
+
+	if !opts.Check {
+		return nil
+	}
+
+	installPath := getInstallPath()
+	if receipt, err := loadReceipt(); err == nil && receipt.InstallPath != "" {
+		installPath = receipt.InstallPath
+	}
+	_, _, filename := detectPlatform()
+	installed, found := installedVersion(filepath.Join(installPath, filename))
+	if updateAvailable(installed, found, latest) {
+		return exitStatus(EXIT_UPDATE_AVAILABLE)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestUpdateAvailable(t *testing.T) {
+	tests := []struct {
+		installed string
+		found     bool
+		latest    string
+		want      bool
+	}{
+		{"v0.7.27", true, "v0.7.27", false},
+		{"v0.7.26", true, "v0.7.27", true},
+		{"v0.8.0-rc.1", true, "v0.7.27", false},
+		{"", false, "v0.7.27", true},
+	}
+
+	for _, tt := range tests {
+		if got := updateAvailable(tt.installed, tt.found, tt.latest); got != tt.want {
+			t.Errorf("updateAvailable(%q, %v, %q) = %v, want %v", tt.installed, tt.found, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestLatestCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the installed binary")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v0.7.27\n"))
+	}))
+	defer server.Close()
+
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+	receipt := &Receipt{InstallPath: installPath}
+	receipt.save()
+
+	opts.BaseURL, opts.Check = server.URL, true
+	defer func() { opts.BaseURL, opts.Check = "", false }()
+
+	binary := filepath.Join(installPath, "vibe")
+	for _, tt := range []struct {
+		reported string
+		want     error
+	}{
+		{"vibe 0.7.27", nil},
+		{"vibe 0.7.20", exitStatus(EXIT_UPDATE_AVAILABLE)},
+	} {
+		os.WriteFile(binary, []byte("#!/bin/sh\necho "+tt.reported+"\n"), 0755)
+		err := runLatest(nil)
+		var status exitStatus
+		if tt.want == nil && err != nil || tt.want != nil && (!errors.As(err, &status) || status != tt.want) {
+			t.Errorf("installed %s: runLatest() = %v, want %v", tt.reported, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// LegacyMove relocates one file or directory of an older layout; an empty
+// To deletes From
+type LegacyMove struct {
+	From     string
+	To       string
+	PruneDir bool // remove From's directory once it is empty
+}
+
+// String describes the move for the migration prompt
+func (m LegacyMove) String() string {
+	if m.To == "" {
+		return "remove " + m.From
+	}
+	return fmt.Sprintf("move %s → %s", m.From, m.To)
+}
+
+// LegacyLayout is a file layout written by an older installer version
+type LegacyLayout struct {
+	Name        string
+	Description string
+	Detect      func(installPath string, receipt *Receipt) []LegacyMove // nil when absent
+}
+
+// LEGACY_LAYOUTS lists the layouts the migration assistant recognises.
+// ~/.vibe itself is moved unconditionally by migrateToXDG, since the
+// configuration could not be found otherwise.
+var LEGACY_LAYOUTS = []LegacyLayout{
+	{Name: "adjacent-data", Description: "grammars stored in a data/ directory next to the vibe binary", Detect: detectAdjacentData},
+	{Name: "versioned-dirs", Description: "versioned dotvibe/<version>/ directories with a symlinked vibe", Detect: detectVersionedDirs},
+}
+
+// LEGACY_MIGRATION_PREFIX marks completed layout migrations in the receipt
+const LEGACY_MIGRATION_PREFIX = "layout/"
+
+// legacyVersionRoots lists where older installers kept versioned installs
+func legacyVersionRoots() []string {
+	var roots []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, filepath.Join(homeDir, ".local", "dotvibe"))
+	}
+	if runtime.GOOS != "windows" {
+		roots = append(roots, "/usr/local/dotvibe")
+	}
+	return roots
+}
+
+// grammarMoves moves the grammar files of dataDir into the current data
+// directory, deleting those already present there
+func grammarMoves(dataDir, installPath string) []LegacyMove {
+	target := getDataDir(installPath)
+	if filepath.Clean(dataDir) == filepath.Clean(target) {
+		return nil
+	}
+	var moves []LegacyMove
+	for _, g := range GRAMMARS {
+		from := filepath.Join(dataDir, g.File)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		to := filepath.Join(target, g.File)
+		if _, err := os.Stat(to); err == nil {
+			to = ""
+		}
+		moves = append(moves, LegacyMove{From: from, To: to, PruneDir: true})
+	}
+	return moves
+}
+
+// detectAdjacentData finds grammars next to the binary, at the current
+// install path or the one in the receipt, when they now live elsewhere
+func detectAdjacentData(installPath string, receipt *Receipt) []LegacyMove {
+	dirs := []string{installPath}
+	if receipt.InstallPath != "" && filepath.Clean(receipt.InstallPath) != filepath.Clean(installPath) {
+		dirs = append(dirs, receipt.InstallPath)
+	}
+	var moves []LegacyMove
+	for _, dir := range dirs {
+		moves = append(moves, grammarMoves(adjacentDataDir(dir), installPath)...)
+	}
+	return moves
+}
+
+// detectVersionedDirs finds installs of the form dotvibe/<version>/vibe
+// with vibe symlinked into the bin directory. The symlink must go before
+// installing: writing through it would overwrite the versioned binary.
+func detectVersionedDirs(installPath string, receipt *Receipt) []LegacyMove {
+	_, _, filename := detectPlatform()
+	var moves []LegacyMove
+	for _, root := range legacyVersionRoots() {
+		entries, err := os.ReadDir(root)
+		if err != nil || !canModify(root) || !canModify(filepath.Dir(root)) {
+			continue
+		}
+
+		// Only a root holding at least one <version>/vibe is an old install;
+		// anything else named dotvibe is left alone
+		var versions []string
+		for _, entry := range entries {
+			if _, err := os.Stat(filepath.Join(root, entry.Name(), filename)); entry.IsDir() && err == nil {
+				versions = append(versions, filepath.Join(root, entry.Name()))
+			}
+		}
+		if len(versions) == 0 {
+			continue
+		}
+
+		link := filepath.Join(installPath, filename)
+		if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Readlink(link); err == nil && within(target, root) {
+				moves = append(moves, LegacyMove{From: link})
+			}
+		}
+		for _, dir := range versions {
+			moves = append(moves, grammarMoves(adjacentDataDir(dir), installPath)...)
+		}
+		moves = append(moves, LegacyMove{From: root})
+	}
+	return moves
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// applyLegacyMoves performs the moves in order, then prunes the
+// directories they left empty
+func applyLegacyMoves(moves []LegacyMove) error {
+	prune := map[string]bool{}
+	for _, m := range moves {
+		var err error
+		if m.To == "" {
+			err = os.RemoveAll(m.From)
+		} else {
+			err = movePath(m.From, m.To)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", m, err)
+		}
+		if m.PruneDir {
+			prune[filepath.Dir(m.From)] = true
+		}
+	}
+	for dir := range prune {
+		os.Remove(dir) // only succeeds when empty
+	}
+	return nil
+}
+
+// offerLegacyMigration detects layouts of older installers and, with the
+// user's consent, moves their files to the current layout, pointing the
+// receipt at the current install path
+func offerLegacyMigration(installPath string, receipt *Receipt) error {
+	for _, layout := range LEGACY_LAYOUTS {
+		moves := layout.Detect(installPath, receipt)
+		if len(moves) == 0 {
+			continue
+		}
+
+		fmt.Printf("🗂️  Found an older install layout: %s\n", layout.Description)
+		for _, m := range moves {
+			fmt.Printf("   • %s\n", m)
+		}
+		if !opts.Yes && !confirm("Migrate to the current layout?") {
+			fmt.Printf("   Keeping the old layout; rerun with --yes to migrate\n")
+			continue
+		}
+
+		if err := applyLegacyMoves(moves); err != nil {
+			return fmt.Errorf("%s migration: %w", layout.Name, err)
+		}
+		for _, m := range moves {
+			if receipt.InstallPath != "" && within(receipt.InstallPath, m.From) {
+				receipt.InstallPath = installPath
+			}
+		}
+		if id := LEGACY_MIGRATION_PREFIX + layout.Name; !receipt.hasMigration(id) {
+			receipt.Migrations = append(receipt.Migrations, id)
+		}
+		if err := receipt.save(); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Migrated %s\n", layout.Description)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOfferLegacyMigrationMovesVersionedInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses symlinks")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VIBE_HOME", t.TempDir())
+	opts.Yes = true
+	defer func() { opts.Yes = false }()
+
+	installPath := filepath.Join(home, ".local", "bin")
+	versionDir := filepath.Join(home, ".local", "dotvibe", "v0.6.0")
+	_, _, filename := detectPlatform()
+	os.MkdirAll(adjacentDataDir(versionDir), 0755)
+	os.MkdirAll(installPath, 0755)
+	os.WriteFile(filepath.Join(versionDir, filename), []byte("old"), 0755)
+	os.WriteFile(filepath.Join(adjacentDataDir(versionDir), GRAMMARS[0].File), []byte("\x00asm"), 0644)
+	os.Symlink(filepath.Join(versionDir, filename), filepath.Join(installPath, filename))
+
+	receipt := &Receipt{InstallPath: versionDir}
+	if err := offerLegacyMigration(installPath, receipt); err != nil {
+		t.Fatalf("offerLegacyMigration failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(installPath, filename)); !os.IsNotExist(err) {
+		t.Errorf("Symlink into the versioned directory not removed")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".local", "dotvibe")); !os.IsNotExist(err) {
+		t.Errorf("Versioned directories not removed")
+	}
+	if _, err := os.Stat(filepath.Join(getDataDir(installPath), GRAMMARS[0].File)); err != nil {
+		t.Errorf("Grammar not moved to the data directory: %v", err)
+	}
+	if receipt.InstallPath != installPath || !receipt.hasMigration(LEGACY_MIGRATION_PREFIX+"versioned-dirs") {
+		t.Errorf("Receipt not updated: %+v", receipt)
+	}
+
+	// Nothing left to migrate
+	for _, layout := range LEGACY_LAYOUTS {
+		if moves := layout.Detect(installPath, receipt); len(moves) != 0 {
+			t.Errorf("%s still detected: %v", layout.Name, moves)
+		}
+	}
+}
+
+func TestDetectAdjacentDataDeletesDuplicates(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("grammars only move away from the binary with the XDG layout")
+	}
+	setupXDG(t)
+	installPath := t.TempDir()
+	for _, dir := range []string{adjacentDataDir(installPath), getDataDir(installPath)} {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, GRAMMARS[0].File), []byte("\x00asm"), 0644)
+	}
+
+	moves := detectAdjacentData(installPath, &Receipt{})
+	if len(moves) != 1 || moves[0].To != "" {
+		t.Fatalf("Expected the duplicate to be removed, got %v", moves)
+	}
+	if err := applyLegacyMoves(moves); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(adjacentDataDir(installPath)); !os.IsNotExist(err) {
+		t.Errorf("Empty data directory left next to the binary")
+	}
+}
+
+func TestDetectVersionedDirsNeedsAVersion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VIBE_HOME", t.TempDir())
+
+	// A dotvibe directory without any <version>/vibe is someone else's
+	root := filepath.Join(home, ".local", "dotvibe")
+	os.MkdirAll(filepath.Join(root, "notes"), 0755)
+	os.WriteFile(filepath.Join(root, "notes", "todo.txt"), []byte("keep"), 0644)
+	before, _ := os.Stat(root)
+
+	if moves := detectVersionedDirs(filepath.Join(home, ".local", "bin"), &Receipt{}); len(moves) != 0 {
+		t.Errorf("detectVersionedDirs() = %v, want nothing without a versioned binary", moves)
+	}
+	// Checking that the root can be modified writes nothing into it
+	if after, err := os.Stat(root); err != nil || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("%s was modified while detecting: %v", root, err)
+	}
+}
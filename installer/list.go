@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runList implements `install-dotvibe list`: what the receipt records as
+// installed, with the grammars present in the data directory
+func runList(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	if receipt.Version == "" {
+		fmt.Printf("📭 vibe is not installed (no receipt at %s)\n", getReceiptPath())
+		return nil
+	}
+
+	fmt.Printf("%-24s %-12s %s\n", "vibe", receipt.Version, receipt.InstallPath)
+	fmt.Printf("%-24s %-12s %s\n", INSTALLER_COMPONENT, version, "running installer")
+	for _, p := range receipt.Packages {
+		fmt.Printf("%-24s %-12s %s\n", p.Name, p.Version, p.Manager)
+	}
+	dataDir := getDataDir(receipt.InstallPath)
+	for _, g := range GRAMMARS {
+		if _, err := os.Stat(filepath.Join(dataDir, g.File)); err == nil {
+			fmt.Printf("%-24s %-12s %s\n", g.Package, receipt.grammar(g).Version, "grammar")
+		}
+	}
+	if len(receipt.FailedModules) > 0 {
+		fmt.Printf("\n⚠️  Failed in the last run: %v (rerun with --retry-failed)\n", receipt.FailedModules)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DECIMAL_COMMA_LANGUAGES write decimals with a comma (1,5 MiB)
+var DECIMAL_COMMA_LANGUAGES = map[string]bool{
+	"az": true, "be": true, "bg": true, "ca": true, "cs": true, "da": true, "de": true, "el": true,
+	"es": true, "et": true, "eu": true, "fi": true, "fr": true, "gl": true, "hr": true, "hu": true,
+	"id": true, "is": true, "it": true, "kk": true, "lt": true, "lv": true, "nb": true, "nl": true,
+	"nn": true, "no": true, "pl": true, "pt": true, "ro": true, "ru": true, "sk": true, "sl": true,
+	"sr": true, "sv": true, "tr": true, "uk": true, "vi": true,
+}
+
+// DECIMAL_SEPARATOR_TERRITORIES are regions whose decimal separator differs
+// from what their language uses elsewhere
+var DECIMAL_SEPARATOR_TERRITORIES = map[string]string{
+	"de_CH": ".", "it_CH": ".", "de_LI": ".", "es_MX": ".", "es_US": ".", "es_PR": ".",
+	"en_ZA": ",",
+}
+
+// decimalSeparator is the separator of the user's locale, read once
+var decimalSeparator = localeDecimalSeparator(os.Getenv)
+
+// localeDecimalSeparator returns the decimal separator of the locale in
+// LC_ALL, LC_NUMERIC or LANG, the first one set, as POSIX does. C, POSIX
+// and unknown locales use a period.
+func localeDecimalSeparator(getenv func(string) string) string {
+	locale := ""
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if locale = getenv(name); locale != "" {
+			break
+		}
+	}
+	// language[_TERRITORY][.codeset][@modifier]
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if sep, ok := DECIMAL_SEPARATOR_TERRITORIES[locale]; ok {
+		return sep
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if DECIMAL_COMMA_LANGUAGES[strings.ToLower(language)] {
+		return ","
+	}
+	return "."
+}
+
+// formatDecimal renders f with prec decimals and the locale's separator
+func formatDecimal(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	if decimalSeparator != "." {
+		s = strings.Replace(s, ".", decimalSeparator, 1)
+	}
+	return s
+}
+
+// formatPercent renders a fraction as a percentage with one decimal,
+// padded to a constant width for progress lines
+func formatPercent(fraction float64) string {
+	return fmt.Sprintf("%5s%%", formatDecimal(fraction*100, 1))
+}
+
+// formatDuration renders a measured duration for people: seconds below a
+// minute, then minutes and hours, in units that read the same everywhere
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return formatDecimal(d.Seconds(), 1) + " s"
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%d min %d s", int(d/time.Minute), int(d%time.Minute/time.Second))
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%d h %d min", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
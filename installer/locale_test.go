@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocaleDecimalSeparator(t *testing.T) {
+	for env, want := range map[string]string{
+		"":                 ".",
+		"C.UTF-8":          ".",
+		"en_US.UTF-8":      ".",
+		"de_DE.UTF-8":      ",",
+		"de_CH.UTF-8":      ".",
+		"fr_FR@euro":       ",",
+		"pt_BR":            ",",
+		"es_MX.UTF-8":      ".",
+		"ja_JP.eucJP":      ".",
+		"LC_NUMERIC=de_DE": ",",
+	} {
+		vars := map[string]string{"LANG": env}
+		if env == "LC_NUMERIC=de_DE" {
+			vars = map[string]string{"LANG": "en_US.UTF-8", "LC_NUMERIC": "de_DE"}
+		}
+		if got := localeDecimalSeparator(func(name string) string { return vars[name] }); got != want {
+			t.Errorf("separator for %q = %q, want %q", env, got, want)
+		}
+	}
+	// LC_ALL overrides everything
+	vars := map[string]string{"LC_ALL": "C", "LC_NUMERIC": "de_DE", "LANG": "fr_FR"}
+	if got := localeDecimalSeparator(func(name string) string { return vars[name] }); got != "." {
+		t.Errorf("separator with LC_ALL=C = %q", got)
+	}
+}
+
+func TestFormatLocalized(t *testing.T) {
+	defer func(saved string) { decimalSeparator = saved }(decimalSeparator)
+	decimalSeparator = ","
+	if got := mebibytes(3 << 19); got != "1,5 MiB" {
+		t.Errorf("mebibytes = %q, want 1,5 MiB", got)
+	}
+	if got := formatPercent(0.425); got != " 42,5%" {
+		t.Errorf("formatPercent = %q", got)
+	}
+
+	decimalSeparator = "."
+	for d, want := range map[time.Duration]string{
+		1500 * time.Millisecond:         "1.5 s",
+		90 * time.Second:                "1 min 30 s",
+		2*time.Hour + 5*time.Minute + 3: "2 h 5 min",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	defer func(saved string) { decimalSeparator = saved }(decimalSeparator)
+	decimalSeparator = "."
+	task := &progressTask{total: 100, done: 25, bytes: true, start: time.Now().Add(-10 * time.Second)}
+	if got := task.eta(); got != ", 30.0 s left" {
+		t.Errorf("eta after a quarter in 10s = %q, want 30 s left", got)
+	}
+	task.start = time.Now()
+	if got := task.eta(); got != "" {
+		t.Errorf("eta right after the start = %q, want none", got)
+	}
+}
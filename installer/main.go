@@ -6,24 +6,36 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/vhybzOS/.vibe/installer/platform"
 )
 
 var version = "dev" // Set by ldflags during build
 
 // detectPlatform returns the current platform information
 func detectPlatform() (goos, goarch, filename string) {
-	goos = runtime.GOOS
-	goarch = runtime.GOARCH
-	if goos == "windows" {
-		filename = "vibe.exe"
-	} else {
-		filename = "vibe"
-	}
-	return
+	target := platform.Detect()
+	return target.OS, target.Arch, "vibe" + target.Ext()
+}
+
+// Platform is a GOOS/GOARCH pair vibe is released for
+type Platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+// SUPPORTED_PLATFORMS is the release matrix published for every version
+var SUPPORTED_PLATFORMS = []Platform{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
 }
 
 // getInstallPath returns the install path for the current OS
@@ -41,6 +53,15 @@ func getInstallPathForOS(goos string) string {
 		}
 		return filepath.Join(userProfile, ".local", "bin")
 	default:
+		if prefix := termuxPrefix(); prefix != "" {
+			return filepath.Join(prefix, "bin")
+		}
+		if opts.AllowRoot && runningAsRoot() {
+			return ROOT_INSTALL_PATH
+		}
+		if dir := os.Getenv("XDG_BIN_HOME"); goos == "linux" && filepath.IsAbs(dir) {
+			return dir
+		}
 		// Use user directory for safer testing - can be changed to /usr/local/bin for production
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -50,38 +71,26 @@ func getInstallPathForOS(goos string) string {
 	}
 }
 
+// Release download locations on GitHub
+const (
+	GITHUB_RELEASES_URL = "https://github.com/vhybzOS/.vibe/releases/download"
+	GITHUB_LATEST_URL   = "https://api.github.com/repos/vhybzOS/.vibe/releases/latest"
+)
+
 // buildDownloadURL constructs the GitHub releases download URL
 func buildDownloadURL(goos, goarch, version string) string {
-	baseURL := "https://github.com/vhybzOS/.vibe/releases/download"
-
-	// Map Go arch names to release asset names
-	var archName string
-	switch goarch {
-	case "amd64":
-		archName = "x86_64"
-	case "arm64":
-		archName = "arm64"
-	default:
-		archName = goarch
-	}
-
-	// Map Go OS names to release asset names
-	var osName string
-	switch goos {
-	case "darwin":
-		osName = "macos"
-	default:
-		osName = goos
-	}
+	return fmt.Sprintf("%s/%s/%s", GITHUB_RELEASES_URL, version, releaseAssetName(goos, goarch, version))
+}
 
-	var filename string
-	if goos == "windows" {
-		filename = fmt.Sprintf("vibe-%s-%s-%s.exe", version, osName, archName)
-	} else {
-		filename = fmt.Sprintf("vibe-%s-%s-%s", version, osName, archName)
+// releaseAssetName returns the release asset filename for a platform,
+// named by the configured asset template
+func releaseAssetName(goos, goarch, version string) string {
+	name, err := renderAssetName(opts.AssetTemplate, goos, goarch, version)
+	if err != nil {
+		// Templates are validated when set; this only guards the default
+		name, _ = renderAssetName(DEFAULT_ASSET_TEMPLATE, goos, goarch, version)
 	}
-
-	return fmt.Sprintf("%s/%s/%s", baseURL, version, filename)
+	return name
 }
 
 // validateInstallPath checks if the install path is valid
@@ -99,18 +108,25 @@ func validateInstallPath(path string) error {
 
 // GitHubRelease represents a GitHub release response
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	Name    string `json:"name"`
+	TagName    string         `json:"tag_name"`
+	Name       string         `json:"name"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets,omitempty"`
+}
+
+// ReleaseAsset is a file uploaded to a release
+type ReleaseAsset struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest,omitempty"` // "sha256:<hex>", computed by the forge on upload
 }
 
 // getLatestVersion gets the latest release version from GitHub API
 func getLatestVersion() (string, error) {
-	url := "https://api.github.com/repos/vhybzOS/.vibe/releases/latest"
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := apiGet(GITHUB_LATEST_URL)
 	if err != nil {
-		// Fallback to hardcoded version if API fails  
+		// Fallback to hardcoded version if API fails
 		fmt.Printf("⚠️  GitHub API unavailable, using fallback version\n")
 		return "v0.7.27", nil
 	}
@@ -132,36 +148,40 @@ func getLatestVersion() (string, error) {
 	return release.TagName, nil
 }
 
-// ProgressWriter wraps an io.Writer to track download progress
+// ProgressWriter wraps an io.Writer to track download progress, showing
+// it on the progress board when it has a task
 type ProgressWriter struct {
 	io.Writer
 	total   int64
 	written int64
+	task    *progressTask
 }
 
 func (pw *ProgressWriter) Write(p []byte) (int, error) {
 	n, err := pw.Writer.Write(p)
-	if err != nil {
-		return n, err
-	}
-
 	pw.written += int64(n)
-
-	// Simple progress display
-	if pw.total > 0 {
-		percent := float64(pw.written) / float64(pw.total) * 100
-		fmt.Printf("\r📥 Downloading... %.1f%% (%d/%d bytes)", percent, pw.written, pw.total)
-	} else {
-		fmt.Printf("\r📥 Downloading... %d bytes", pw.written)
+	if pw.task != nil && n > 0 {
+		pw.task.Write(p[:n])
 	}
-
 	return n, err
 }
 
-// downloadBinary downloads the vibe binary from GitHub releases with progress
+// downloadBinary downloads the vibe binary from GitHub releases with
+// progress, starting over when the download stalls
 func downloadBinary(url, destPath string) error {
 	fmt.Printf("🔗 Downloading from: %s\n", url)
+	if opts.FlakyNetwork {
+		if err := downloadResumable(url, destPath, true); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Download complete!\n")
+		return nil
+	}
+	return retryStalled(path.Base(url), func() error { return downloadBinaryOnce(url, destPath) })
+}
 
+// downloadBinaryOnce makes one attempt at downloadBinary
+func downloadBinaryOnce(url, destPath string) error {
 	// Create the destination file
 	out, err := os.Create(destPath)
 	if err != nil {
@@ -170,69 +190,48 @@ func downloadBinary(url, destPath string) error {
 	defer out.Close()
 
 	// Make HTTP request
-	client := &http.Client{Timeout: 10 * time.Minute}
-	resp, err := client.Get(url)
+	resp, err := httpGet(url, opts.DownloadTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to download binary: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check if download was successful
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("download failed with status: %s: %w", resp.Status, errAssetNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("download failed with status: %d %s", resp.StatusCode, resp.Status)
 	}
+	if err := checkContentType(resp); err != nil {
+		return err
+	}
 
 	// Create progress writer
 	progressWriter := &ProgressWriter{
 		Writer: out,
 		total:  resp.ContentLength,
+		task:   progress.download(path.Base(url), resp.ContentLength),
 	}
 
 	// Copy with progress
 	_, err = io.Copy(progressWriter, resp.Body)
+	progressWriter.task.finish(err)
 	if err != nil {
 		return fmt.Errorf("failed to save binary: %w", err)
 	}
 
-	fmt.Printf("\n✅ Download complete!\n")
+	fmt.Printf("✅ Download complete!\n")
 	return nil
 }
 
-// installBinary places the downloaded binary in the install location
+// installBinary moves a verified download from quarantine to the install
+// location
 func installBinary(srcPath, destPath string) error {
 	fmt.Printf("📦 Installing binary to: %s\n", destPath)
-
-	// Open source file
-	src, err := os.Open(srcPath)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer src.Close()
-
-	// Create destination file
-	dst, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer dst.Close()
-
-	// Copy file
-	_, err = io.Copy(dst, src)
-	if err != nil {
-		return fmt.Errorf("failed to copy binary: %w", err)
+	if err := promote(srcPath, destPath, MODE_BINARY); err != nil {
+		return fmt.Errorf("failed to install binary: %w", err)
 	}
-
-	// Make executable (Unix only)
-	if runtime.GOOS != "windows" {
-		err = os.Chmod(destPath, 0755)
-		if err != nil {
-			return fmt.Errorf("failed to make binary executable: %w", err)
-		}
-	}
-
-	// Clean up temporary file
-	os.Remove(srcPath)
-
 	fmt.Printf("✅ Binary installed successfully!\n")
 	return nil
 }
@@ -240,6 +239,9 @@ func installBinary(srcPath, destPath string) error {
 // verifyInstallation checks that the installation was successful
 func verifyInstallation(binaryPath string) error {
 	fmt.Printf("🔍 Verifying installation...\n")
+	if err := simulateFailure(STEP_VERIFY); err != nil {
+		return err
+	}
 
 	// Check if file exists
 	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
@@ -264,85 +266,449 @@ func verifyInstallation(binaryPath string) error {
 }
 
 func main() {
+	if exe, err := runningInstaller(); err == nil && runtime.GOOS == "windows" {
+		removeReplacedInstaller(exe)
+	}
+	runCommand(os.Args[1:])
+}
+
+// migrateBeforeInstall moves an install from ~/.vibe to the XDG layout
+// before install or upgrade reads its receipt; --check leaves it in place
+func migrateBeforeInstall() {
+	if opts.Check {
+		return
+	}
+	if err := migrateToXDG(); err != nil {
+		warnf("Could not move to the XDG layout: %v", err)
+	}
+}
+
+// runInstall implements `install-dotvibe [install] [flags]`: it installs or
+// upgrades vibe and its dependencies, exiting through fatalf on failure
+func runInstall(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	migrateBeforeInstall()
+	var setup *Setup
+	if opts.FromSetup != "" {
+		var err error
+		if setup, err = loadSetup(opts.FromSetup); err != nil {
+			return err
+		}
+		if err := setup.apply(opts.FromSetup); err != nil {
+			return err
+		}
+	}
+	if opts.WaitFor != "" && setup != nil {
+		return fmt.Errorf("--wait-for and --from-setup both choose the version; use one")
+	}
+	if opts.Check && opts.Force {
+		return fmt.Errorf("--check and --force cannot be combined")
+	}
+	if opts.Poll <= 0 {
+		return fmt.Errorf("--poll must be positive")
+	}
+	if setting, ok := findSetting("build.compile_cache"); ok {
+		if _, err := setting.validate(opts.CompileCache); err != nil {
+			return fmt.Errorf("--compile-cache: %w", err)
+		}
+	}
+
+	if setting, ok := findSetting("install.container"); ok {
+		if _, err := setting.validate(opts.Container); err != nil {
+			return fmt.Errorf("--container: %w", err)
+		}
+	}
+	applyContainerDefaults(opts.Container, detectContainer("/", os.Getenv))
+
+	if err := checkRoot(runningAsRoot(), os.Getenv("SUDO_USER"), opts.AllowRoot); err != nil {
+		return err
+	}
+
+	defer startRunDeadline()()
+
 	fmt.Printf("🚀 Installing .vibe %s...\n", version)
+	if ci.Enabled {
+		fmt.Printf("🤖 CI environment detected, using log-friendly output\n")
+	}
+
+	if !opts.Check {
+		if err := recoverFromCrash(getInstallPath()); err != nil {
+			fatalf("%v", err)
+		}
+	}
 
 	// 1. Detect platform
+	beginGroup("Resolve release")
 	goos, goarch, filename := detectPlatform()
 	fmt.Printf("📱 Platform: %s/%s\n", goos, goarch)
+	if target := platform.Detect(); target.Static() && !opts.Static {
+		// Only the static build runs without glibc (Alpine, Android)
+		opts.Static = true
+		fmt.Printf("🧱 %s libc detected, installing the static Linux build\n", target.Libc)
+	}
+	if goos == "android" {
+		goos = "linux" // Android runs the Linux build
+	}
+	if isTermux() {
+		fmt.Printf("🤖 Termux detected, installing into %s\n", getInstallPath())
+		for name, reason := range TERMUX_UNSUPPORTED {
+			fmt.Printf("⏭️  Skipping %s: %s\n", name, reason)
+		}
+	}
+	if goos == "windows" {
+		var err error
+		if goarch, err = preflightWindows(goarch); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	runSummary.Platform = goos + "/" + goarch
 
-	// 2. Get latest version
-	latestVersion, err := getLatestVersion()
+	// 2. Get latest version from the selected source
+	source, err := newSources(opts.sourceSpec(), opts.Mirrors)
 	if err != nil {
-		fmt.Printf("❌ Failed to get latest version: %v\n", err)
-		os.Exit(1)
+		fatalf("Invalid source: %v", err)
+	}
+	origin := source // unwrapped, for the asset listing of the plan preview
+	source = withCache(withSharedCache(withP2P(source)))
+	fmt.Printf("🌐 Source: %s\n", source.Name())
+	if systemProxyNote != "" {
+		fmt.Println(systemProxyNote)
+	}
+	if opts.FlakyNetwork {
+		fmt.Printf("📶 Flaky network mode: resumable %s chunks, up to %d retries each, %s stall tolerance\n", mebibytes(FLAKY_CHUNK_SIZE), FLAKY_RETRIES, opts.StallTimeout)
+	}
+	runSummary.Source = source.Name()
+
+	if !opts.Check {
+		if err := runHooks(HookEvent{Event: HOOK_PRE_RESOLVE, Source: source.Name()}); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	latestVersion := opts.Version
+	if opts.WaitFor != "" {
+		if err := checkPolicyVersion(opts.WaitFor); err != nil {
+			fatalf("%v", err)
+		}
+		if err := waitForRelease(origin, opts.WaitFor, opts.Poll); err != nil {
+			fatalf("%v", err)
+		}
+		latestVersion = opts.WaitFor
+	} else if latestVersion != "" {
+		fmt.Printf("📌 Version: %s (from %s)\n", latestVersion, opts.FromSetup)
+	} else if latestVersion, err = source.LatestVersion(); err != nil {
+		fatalf("Failed to get latest version: %v", err)
+	} else {
+		fmt.Printf("📦 Latest version: %s\n", latestVersion)
+	}
+	if err := checkPolicyVersion(latestVersion); err != nil {
+		fatalf("%v", err)
+	}
+	if v, ok := parseVersion(latestVersion); ok && v.Prerelease != "" {
+		fmt.Printf("🧪 %s is a prerelease (--include-prereleases)\n", latestVersion)
+	}
+	runSummary.Version = latestVersion
+
+	// 3. Resolve release asset
+	assetSuffix := ""
+	if opts.Static && goos == "linux" {
+		assetSuffix = STATIC_ASSET_SUFFIX
 	}
-	fmt.Printf("📦 Latest version: %s\n", latestVersion)
+	assetNames := assetNameVariants(goos, goarch, latestVersion, assetSuffix)
+	fmt.Printf("🔗 Release asset: %s\n", assetNames[0])
 
-	// 3. Build download URL
-	downloadURL := buildDownloadURL(goos, goarch, latestVersion)
-	fmt.Printf("🔗 Download URL: %s\n", downloadURL)
+	if err := preflightGlibc(source, goos, goarch, latestVersion); err != nil {
+		fatalf("%v", err)
+	}
 
 	// 4. Get install path
 	installPath := getInstallPath()
 	err = validateInstallPath(installPath)
 	if err != nil {
-		fmt.Printf("❌ Invalid install path: %v\n", err)
-		os.Exit(1)
+		fatalf("Invalid install path: %v", err)
+	}
+	if installPath, err = writableInstallPath(installPath); err != nil {
+		fatalf("%v", err)
+	}
+
+	wslVersion, underWSL := 0, false
+	if goos == "linux" {
+		wslVersion, underWSL = detectWSL()
+	}
+	if underWSL {
+		fmt.Printf("🐧 WSL %d detected\n", wslVersion)
+		if err := checkWSLPaths(installPath); err != nil {
+			fatalf("%v", err)
+		}
 	}
 
 	// Ensure install directory exists
-	err = os.MkdirAll(installPath, 0755)
-	if err != nil {
-		fmt.Printf("❌ Failed to create install directory: %v\n", err)
-		os.Exit(1)
+	if !opts.Check {
+		if err := ensureDir(installPath, MODE_DIR); err != nil {
+			fatalf("Failed to create install directory: %v", err)
+		}
 	}
 
 	fmt.Printf("📁 Install directory: %s\n", installPath)
+	runSummary.InstallPath = installPath
 
-	// 5. Install all dependencies (Rust + cargo packages + WASM file)
-	fmt.Printf("🔧 Installing dependencies...\n")
-	err = installAllModules(installPath)
+	receipt, err := loadReceipt()
 	if err != nil {
-		fmt.Printf("❌ Dependency installation failed: %v\n", err)
-		os.Exit(1)
+		fatalf("%v", err)
 	}
-
-	// 6. Download main binary
-	tempPath := filepath.Join(os.TempDir(), filename)
-	err = downloadBinary(downloadURL, tempPath)
-	if err != nil {
-		fmt.Printf("❌ Download failed: %v\n", err)
-		os.Exit(1)
+	if setup != nil {
+		setup.seedReceipt(receipt)
 	}
 
-	// 7. Install main binary
 	finalPath := filepath.Join(installPath, filename)
-	err = installBinary(tempPath, finalPath)
+	if opts.Check {
+		return checkInstall(receipt, installPath, finalPath, latestVersion)
+	}
+
+	if err := offerLegacyMigration(installPath, receipt); err != nil {
+		warnf("Could not migrate the old install layout: %v", err)
+	}
+
+	if opts.RetryFailed {
+		beginGroup("Retry failed modules")
+		if err := retryFailedModules(installPath, source, receipt); err != nil {
+			fatalf("%v", err)
+		}
+		runSummary.Status = completedStatus()
+		finishRun()
+		return nil
+	}
+
+	if err := selectComponents(origin, latestVersion); err != nil {
+		warnf("Could not save the component selection: %v", err)
+	}
+	if !opts.Force {
+		beginGroup("Check installed state")
+		pending := pendingWork(receipt, installPath, finalPath, latestVersion)
+		if len(pending) == 0 {
+			fmt.Printf("✅ vibe %s and its dependencies are installed and unchanged, nothing to do (--force reinstalls)\n", latestVersion)
+			runSummary.Components = versionMap(getVersionInfo(latestVersion))
+			runSummary.Status = "unchanged"
+			finishRun()
+			return nil
+		}
+		if receipt.Version != "" {
+			fmt.Printf("🔎 Reinstalling because:\n")
+			for _, reason := range pending {
+				fmt.Printf("   • %s\n", reason)
+			}
+		}
+	}
+
+	// Show what changed when upgrading an existing installation
+	current, upgrading := installedVersion(finalPath)
+	upgrading = upgrading && current != latestVersion
+	if upgrading {
+		major := isMajorUpgrade(current, latestVersion)
+		fmt.Printf("⬆️  Upgrading from %s to %s\n", current, latestVersion)
+
+		summary, breaking := loadReleaseNotes(source, latestVersion)
+		switch opts.Changelog {
+		case CHANGELOG_SUMMARY:
+			printReleaseNotes(latestVersion, summary, breaking, major)
+		case CHANGELOG_BREAKING_ONLY:
+			printReleaseNotes(latestVersion, nil, breaking, major)
+		}
+
+		if err := gateBreakingUpgrade(installPath, current, latestVersion, major, breaking); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	plan := previewInstall(origin, latestVersion, assetNames)
+	if err := confirmMetered(plan.downloadSize(), isMetered()); err != nil {
+		fatalf("%v", err)
+	}
+
+	// 5. Install all dependencies (Rust + cargo packages + WASM file)
+	beginGroup("Install dependencies")
+	if opts.InPlace && receipt.Version != "" {
+		fmt.Printf("🔧 Updating dependencies...\n")
+		err = installModules(modulesToUpdate(allModules(), receipt), installPath, source, receipt)
+	} else {
+		fmt.Printf("🔧 Installing dependencies...\n")
+		err = installAllModules(installPath, source, receipt)
+	}
 	if err != nil {
-		fmt.Printf("❌ Installation failed: %v\n", err)
-		os.Exit(1)
+		// Keep the failed modules on record for --retry-failed
+		receipt.InstallPath = installPath
+		if saveErr := receipt.save(); saveErr != nil {
+			warnf("%v", saveErr)
+		}
+		fatalf("Dependency installation failed: %v", err)
+	}
+
+	// 6. Download main binary, unless an in-place update can keep it
+	beginGroup("Install vibe")
+	var asset, stagedDigest string
+	var rollback *rollbackPoint
+	if kept, ok := keptBinary(receipt, finalPath, current, latestVersion); opts.InPlace && ok {
+		fmt.Printf("✅ vibe %s is up to date, keeping %s\n", latestVersion, finalPath)
+		asset, stagedDigest = kept.Artifact, kept.SHA256
+	} else {
+		stagedPath, err := quarantinePath(filename)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		digests, err := releaseDigests(source, latestVersion)
+		if err != nil {
+			os.Remove(stagedPath)
+			fatalf("%v", err)
+		}
+		forgetFetches()
+		asset, err = fetchBinaryAsset(source, latestVersion, assetNames, filename, stagedPath, digests)
+		if err == nil {
+			err = simulateFailure(STEP_VIBE)
+		}
+		if err != nil {
+			os.Remove(stagedPath)
+			fatalf("Download failed: %v", err)
+		}
+		stagedDigest, _, _ = fileSHA256(stagedPath)
+		if err := runHooks(HookEvent{Event: HOOK_POST_DOWNLOAD, Source: source.Name(), Version: latestVersion,
+			InstallPath: installPath, Asset: asset, Path: stagedPath, SHA256: stagedDigest}); err != nil {
+			os.Remove(stagedPath)
+			fatalf("%v", err)
+		}
+
+		// 7. Install main binary, keeping the previous one for --rollback-on-error
+		if upgrading && opts.RollbackOnError {
+			if rollback, err = saveRollbackPoint(finalPath, current); err != nil {
+				fatalf("%v", err)
+			}
+		}
+		if err := installBinary(stagedPath, finalPath); err != nil {
+			fatalf("Installation failed: %v", err)
+		}
+		receipt.recordProvenance(newProvenance("vibe", asset, finalPath, source, stagedDigest, digests[asset]))
+		receipt.recordFile("vibe", latestVersion, finalPath)
+	}
+
+	if underWSL && opts.WSLWindows {
+		if err := installWindowsBinary(source, latestVersion, receipt); err != nil {
+			warnf("Could not install the Windows binary: %v", err)
+		}
+	}
+
+	// Run upgrade migrations once the new binary is in place
+	if upgrading && !opts.SkipMigrations {
+		if err := runMigrations(MIGRATIONS, receipt, installPath, current, latestVersion); err != nil {
+			rollback.failf("%v", err)
+		}
 	}
 
 	// 8. Verify all installations
+	beginGroup("Verify installation")
 	err = verifyInstallation(finalPath)
 	if err != nil {
-		fmt.Printf("❌ Binary verification failed: %v\n", err)
-		os.Exit(1)
+		rollback.failf("Binary verification failed: %v", err)
 	}
 
-	err = verifyAllModules()
+	if err := checkSharedLibraries(finalPath, receipt); err != nil {
+		rollback.failf("Binary verification failed: %v", err)
+	}
+
+	if opts.RollbackOnError {
+		if err := validateUpgrade(finalPath, opts.ValidateCommand); err != nil {
+			rollback.failf("Upgrade validation failed: %v", err)
+		}
+		rollback.discard()
+	}
+
+	moduleChecks, err := verifyAllModules()
+	runSummary.Modules = moduleChecks
 	if err != nil {
-		fmt.Printf("❌ Module verification failed: %v\n", err)
-		os.Exit(1)
+		fatalf("Module verification failed: %v", err)
+	}
+
+	if err := hardenPermissions(finalPath, installPath, receipt); err != nil {
+		warnf("%v", err)
+	}
+	if errs := removeMarkOfTheWeb(installedBinaries(finalPath, receipt)); len(errs) > 0 {
+		printSmartScreenGuidance(errs)
+	}
+	avSnapshot := snapshotFiles(installedBinaries(finalPath, receipt))
+	if selinuxMode() != "" {
+		if err := restoreContexts(selinuxPaths(finalPath, installPath, receipt)); err != nil {
+			warnf("%v", err)
+		}
+	}
+
+	if handshake, err := takeHandshake(finalPath, moduleChecks); err != nil {
+		warnf("Could not record version handshake: %v", err)
+	} else {
+		receipt.Handshake = handshake
+	}
+
+	// Make the install directory reachable from new shells
+	beginGroup("Configure PATH")
+	if err := ensureOnPath(installPath, receipt); err != nil {
+		warnf("%v", err)
+	}
+
+	checkPathConflicts(installPath, filename)
+	if err := verifyPathOrder(installPath, filename, receipt); err != nil {
+		warnf("Could not verify PATH order: %v", err)
 	}
 
+	receipt.Version = latestVersion
+	receipt.InstallPath = installPath
+	receipt.InstalledAt = time.Now().UTC()
+
+	if opts.EncryptData {
+		beginGroup("Encrypt data")
+		if err := setupDataEncryption(receipt); err != nil {
+			receipt.save()
+			fatalf("Failed to encrypt the data directory: %v", err)
+		}
+	}
+
+	beginGroup("Set up vibe")
+	bootstrap(finalPath, receipt)
+	checkDatabase()
+	if inst, err := recordInstance(receipt, latestVersion); err != nil {
+		warnf("%v", err)
+	} else {
+		runSummary.Instance = inst.ID
+	}
+
+	receipt.pruneFiles()
+	if err := receipt.save(); err != nil {
+		warnf("%v", err)
+	}
+
+	if changes := recheckForAntivirus(avSnapshot); len(changes) > 0 {
+		printAntivirusGuidance(changes, installPath)
+		fatalf("%d installed file(s) were removed or modified after installation", len(changes))
+	}
+
+	if err := runHooks(HookEvent{Event: HOOK_POST_INSTALL, Source: source.Name(), Version: latestVersion,
+		InstallPath: installPath, Asset: asset, Path: finalPath, SHA256: stagedDigest}); err != nil {
+		fatalf("%v (vibe %s is installed)", err, latestVersion)
+	}
+
+	runSummary.Components = versionMap(getVersionInfo(latestVersion))
+	runSummary.Checksums = installedChecksums(finalPath, installPath)
+	runSummary.Status = completedStatus()
+	finishRun()
+
 	// 9. Display success message with version info
-	fmt.Printf("✅ Installation complete!\n")
+	fmt.Printf("✅ Installation complete in %s!\n", formatDuration(time.Since(runStart)))
+	if len(runSummary.Degraded) > 0 {
+		fmt.Printf("⚠️  Degraded: installed without %s (rerun with --retry-failed once fixed)\n", strings.Join(runSummary.Degraded, ", "))
+	}
 	fmt.Printf("🎉 Try: %s --version\n", strings.TrimSuffix(filename, ".exe"))
-	
+
 	fmt.Printf("\n📦 Installed components:\n")
-	versions := getVersionInfo()
-	for component, version := range versions {
-		fmt.Printf("   • %s: v%s\n", component, version)
+	for _, c := range getVersionInfo(latestVersion) {
+		fmt.Printf("   • %s: %s\n", c.Name, displayVersion(c.Version))
 	}
+	return nil
 }
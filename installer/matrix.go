@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// AssetLister is implemented by sources that can list a release's files
+// without downloading them
+type AssetLister interface {
+	ReleaseAssets(version string) ([]ReleaseAsset, error)
+}
+
+func (githubSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	resp, err := apiGet(GITHUB_RELEASES_API + "/tags/" + version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", version, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("release %s not found", version)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error (%d)", resp.StatusCode)
+	}
+
+	var release GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release %s: %w", version, err)
+	}
+	return release.Assets, nil
+}
+
+// MatrixResult is the validation outcome of one expected asset
+type MatrixResult struct {
+	Asset    string
+	Platform string
+	Problems []string
+}
+
+// expectedAssets lists the binaries a release must publish, by platform,
+// under the names the installer downloads them by
+func expectedAssets(version string) map[string]string {
+	assets := map[string]string{}
+	for _, b := range releaseBinaries(version) {
+		assets[b.Asset] = b.Platform
+	}
+	return assets
+}
+
+// checkListedAssets compares the uploaded files with the expected ones.
+// Unexpected uploads that look like a misnamed expected asset are
+// reported against it, which catches typos in release scripts.
+func checkListedAssets(expected map[string]string, listed []ReleaseAsset) (missing map[string]string, sizes map[string]int64) {
+	missing = map[string]string{}
+	sizes = map[string]int64{}
+	var names []string
+	for _, a := range listed {
+		sizes[a.Name] = a.Size
+		names = append(names, a.Name)
+	}
+	for asset := range expected {
+		if _, ok := sizes[asset]; ok {
+			continue
+		}
+		msg := "missing from the release"
+		if near := closestMatch(asset, names); near != "" {
+			msg += fmt.Sprintf(" (found %q, misnamed?)", near)
+		}
+		missing[asset] = msg
+	}
+	return missing, sizes
+}
+
+// validateMatrix checks that every platform binary of version exists with
+// the size the listing reports, the manifest's size and digest, and the
+// digest in SHA256SUMS
+func validateMatrix(source Source, version string) ([]MatrixResult, []string, error) {
+	tempDir, err := os.MkdirTemp("", "vibe-matrix-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	var warnings []string
+	expected := expectedAssets(version)
+
+	missing := map[string]string{}
+	var listedSizes map[string]int64
+	if lister, ok := source.(AssetLister); ok {
+		listed, err := lister.ReleaseAssets(version)
+		if err != nil {
+			return nil, nil, err
+		}
+		missing, listedSizes = checkListedAssets(expected, listed)
+	}
+
+	sums := map[string]string{}
+	sumsPath := filepath.Join(tempDir, "SHA256SUMS")
+	if err := source.FetchAsset(version, "SHA256SUMS", sumsPath); err != nil {
+		warnings = append(warnings, "no SHA256SUMS published; digests cannot be checked")
+	} else if data, err := os.ReadFile(sumsPath); err == nil {
+		sums = parseChecksums(string(data))
+	}
+
+	manifestAssets := map[string]MirrorAsset{}
+	if manifest, err := fetchReleaseManifest(source, version); err == nil {
+		for _, a := range manifest.Assets {
+			manifestAssets[filepath.Base(a.Path)] = a
+		}
+	}
+
+	var results []MatrixResult
+	for asset, platform := range expected {
+		result := MatrixResult{Asset: asset, Platform: platform}
+		if msg, ok := missing[asset]; ok {
+			result.Problems = append(result.Problems, msg)
+			results = append(results, result)
+			continue
+		}
+
+		path := filepath.Join(tempDir, asset)
+		if err := source.FetchAsset(version, asset, path); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("download failed: %v", err))
+			results = append(results, result)
+			continue
+		}
+		digest, size, err := fileSHA256(path)
+		os.Remove(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if want, ok := listedSizes[asset]; ok && want != size {
+			result.Problems = append(result.Problems, fmt.Sprintf("downloaded %d bytes, release lists %d", size, want))
+		}
+		if size == 0 {
+			result.Problems = append(result.Problems, "empty file")
+		}
+		if m, ok := manifestAssets[asset]; ok {
+			if m.Size != size {
+				result.Problems = append(result.Problems, fmt.Sprintf("size %d, manifest says %d", size, m.Size))
+			}
+			if m.SHA256 != digest {
+				result.Problems = append(result.Problems, fmt.Sprintf("sha256 %s, manifest says %s", short(digest), short(m.SHA256)))
+			}
+		}
+		if want, ok := sums[asset]; ok && want != digest {
+			result.Problems = append(result.Problems, fmt.Sprintf("sha256 %s, SHA256SUMS says %s", short(digest), short(want)))
+		} else if !ok && len(sums) > 0 {
+			result.Problems = append(result.Problems, "not listed in SHA256SUMS")
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Platform < results[j].Platform })
+	return results, warnings, nil
+}
+
+// runMatrix implements `install-dotvibe matrix validate <tag>`
+func runMatrix(args []string) error {
+	if len(args) != 2 || args[0] != "validate" {
+		return errUsage
+	}
+	version := args[1]
+
+	defer startRunDeadline()()
+
+	source, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧮 Validating the release matrix of %s on %s...\n", version, source.Name())
+	results, warnings, err := validateMatrix(source, version)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if len(r.Problems) == 0 {
+			fmt.Printf("✅ %-17s %s\n", r.Platform, r.Asset)
+			continue
+		}
+		failed++
+		fmt.Printf("❌ %-17s %s\n", r.Platform, r.Asset)
+		for _, p := range r.Problems {
+			fmt.Printf("   • %s\n", p)
+		}
+	}
+	for _, w := range warnings {
+		warnf("%s", w)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d platform assets are broken", failed, len(results))
+	}
+	fmt.Printf("✅ All %d platform assets of %s are valid\n", len(results), version)
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// matrixSource serves a release from memory and lists its assets
+type matrixSource struct {
+	files map[string]string
+}
+
+func (m matrixSource) Name() string                         { return "matrix" }
+func (m matrixSource) LatestVersion() (string, error)       { return "v1.0.0", nil }
+func (m matrixSource) FetchGrammar(_, _, _, _ string) error { return fmt.Errorf("no grammars") }
+
+func (m matrixSource) FetchAsset(version, asset, destPath string) error {
+	data, ok := m.files[asset]
+	if !ok {
+		return fmt.Errorf("%s not found", asset)
+	}
+	return os.WriteFile(destPath, []byte(data), 0644)
+}
+
+func (m matrixSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	var assets []ReleaseAsset
+	for name, data := range m.files {
+		assets = append(assets, ReleaseAsset{Name: name, Size: int64(len(data))})
+	}
+	return assets, nil
+}
+
+func completeRelease(version string) map[string]string {
+	files := map[string]string{}
+	var sums strings.Builder
+	for asset := range expectedAssets(version) {
+		files[asset] = "binary " + asset
+		sum := sha256.Sum256([]byte(files[asset]))
+		fmt.Fprintf(&sums, "%s *%s\n", hex.EncodeToString(sum[:]), asset)
+	}
+	files["SHA256SUMS"] = sums.String()
+	return files
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := parseChecksums("ABC123  vibe-linux\ndef456 *vibe.exe\n\nmalformed\n")
+	if sums["vibe-linux"] != "abc123" || sums["vibe.exe"] != "def456" || len(sums) != 2 {
+		t.Errorf("parseChecksums = %v", sums)
+	}
+}
+
+func TestValidateMatrix(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	version := "v1.0.0"
+
+	results, warnings, err := validateMatrix(matrixSource{completeRelease(version)}, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(releaseBinaries(version)) || len(warnings) != 0 {
+		t.Fatalf("got %d results and warnings %v", len(results), warnings)
+	}
+	for _, r := range results {
+		if len(r.Problems) != 0 {
+			t.Errorf("%s: unexpected problems %v", r.Asset, r.Problems)
+		}
+	}
+
+	files := completeRelease(version)
+	linux := releaseAssetName("linux", "amd64", version)
+	darwin := releaseAssetName("darwin", "arm64", version)
+	files[linux+"-typo"] = files[linux]
+	delete(files, linux)
+	files[darwin] = "truncated"
+
+	results, _, err = validateMatrix(matrixSource{files}, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := map[string]string{}
+	for _, r := range results {
+		if len(r.Problems) > 0 {
+			broken[r.Asset] = strings.Join(r.Problems, "; ")
+		}
+	}
+	if len(broken) != 2 {
+		t.Fatalf("broken = %v, want the linux and darwin assets", broken)
+	}
+	if !strings.Contains(broken[linux], "misnamed") {
+		t.Errorf("missing asset problem = %q, want a misnamed hint", broken[linux])
+	}
+	if !strings.Contains(broken[darwin], "SHA256SUMS") {
+		t.Errorf("corrupt asset problem = %q, want a checksum mismatch", broken[darwin])
+	}
+
+	// The static Linux builds are part of the matrix too
+	files = completeRelease(version)
+	static := staticAssetName("linux", "arm64", version)
+	delete(files, static)
+	results, _, err = validateMatrix(matrixSource{files}, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if (len(r.Problems) > 0) != (r.Asset == static) {
+			t.Errorf("%s (%s): problems %v", r.Asset, r.Platform, r.Problems)
+		}
+	}
+}
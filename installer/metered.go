@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Whether the connection is metered: detected, or declared where the OS
+// does not tell (macOS only exposes it to native apps)
+const (
+	METERED_AUTO = "auto"
+	METERED_ON   = "on"
+	METERED_OFF  = "off"
+)
+
+// DEFAULT_METERED_THRESHOLD is the largest download made on a metered
+// connection without confirmation
+const DEFAULT_METERED_THRESHOLD = 100 << 20
+
+// WINDOWS_COST_SCRIPT prints the cost type of the internet connection:
+// Unrestricted, Fixed or Variable (metered), or Unknown
+const WINDOWS_COST_SCRIPT = `[void][Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime]
+$p = [Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile()
+if ($p) { $p.GetConnectionCost().NetworkCostType } else { 'Unknown' }`
+
+// parseNMCLIMetered reads `nmcli -t -f GENERAL.METERED device show`, which
+// prints one line per device, e.g. "GENERAL.METERED:yes (guessed)". Phone
+// hotspots are guessed metered from their DHCP vendor option.
+func parseNMCLIMetered(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		_, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && strings.HasPrefix(value, "yes") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWindowsCost reads the output of WINDOWS_COST_SCRIPT; fixed data
+// plans and per-byte charging both count as metered
+func parseWindowsCost(out string) bool {
+	switch strings.TrimSpace(out) {
+	case "Fixed", "Variable":
+		return true
+	}
+	return false
+}
+
+// detectMetered asks the OS whether the connection is metered. Failures
+// mean not metered: the check protects users, it must not block installs.
+func detectMetered() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch runtime.GOOS {
+	case "linux":
+		out, err := exec.CommandContext(ctx, "nmcli", "-t", "-f", "GENERAL.METERED", "device", "show").Output()
+		return err == nil && parseNMCLIMetered(string(out))
+	case "windows":
+		out, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", WINDOWS_COST_SCRIPT).Output()
+		return err == nil && parseWindowsCost(string(out))
+	}
+	return false
+}
+
+// isMetered applies the network.metered setting
+func isMetered() bool {
+	switch opts.Metered {
+	case METERED_ON:
+		return true
+	case METERED_OFF:
+		return false
+	}
+	return detectMetered()
+}
+
+// confirmMetered asks before downloading size bytes over a metered
+// connection when that is more than the threshold. Without a terminal the
+// install needs --allow-metered.
+func confirmMetered(size int64, metered bool) error {
+	if !metered || size <= opts.MeteredThreshold || opts.AllowMetered {
+		return nil
+	}
+	fmt.Printf("📶 This connection is metered and the install downloads %s\n", mebibytes(size))
+	if opts.Yes || confirm("Download it anyway?") {
+		return nil
+	}
+	return fmt.Errorf("not downloading %s over a metered connection (rerun with --allow-metered, or raise network.metered_threshold_mb)", mebibytes(size))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNMCLIMetered(t *testing.T) {
+	tests := []struct {
+		out  string
+		want bool
+	}{
+		{"GENERAL.METERED:no (guessed)\nGENERAL.METERED:unknown\n", false},
+		{"GENERAL.METERED:no\nGENERAL.METERED:yes (guessed)\n", true},
+		{"GENERAL.METERED:yes\n", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := parseNMCLIMetered(tt.out); got != tt.want {
+			t.Errorf("parseNMCLIMetered(%q) = %v, want %v", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestParseWindowsCost(t *testing.T) {
+	for out, want := range map[string]bool{"Unrestricted\r\n": false, "Fixed\r\n": true, "Variable": true, "Unknown": false} {
+		if got := parseWindowsCost(out); got != want {
+			t.Errorf("parseWindowsCost(%q) = %v, want %v", out, got, want)
+		}
+	}
+}
+
+func TestConfirmMetered(t *testing.T) {
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts = Options{MeteredThreshold: DEFAULT_METERED_THRESHOLD}
+
+	if err := confirmMetered(2*DEFAULT_METERED_THRESHOLD, false); err != nil {
+		t.Errorf("unmetered download refused: %v", err)
+	}
+	if err := confirmMetered(DEFAULT_METERED_THRESHOLD, true); err != nil {
+		t.Errorf("download at the threshold refused: %v", err)
+	}
+	// Tests run without a terminal, so nobody can confirm
+	if err := confirmMetered(2*DEFAULT_METERED_THRESHOLD, true); err == nil || !strings.Contains(err.Error(), "--allow-metered") {
+		t.Errorf("confirmMetered = %v, want a refusal pointing at --allow-metered", err)
+	}
+	opts.AllowMetered = true
+	if err := confirmMetered(2*DEFAULT_METERED_THRESHOLD, true); err != nil {
+		t.Errorf("--allow-metered download refused: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// METRICS_JOB is the Pushgateway job install metrics are grouped under
+const METRICS_JOB = "dotvibe_install"
+
+// promLabel escapes a label value for the Prometheus text format
+func promLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// formatMetrics renders a run summary in the Prometheus text exposition
+// format
+func formatMetrics(summary *RunSummary) string {
+	var b strings.Builder
+	metric := func(name, help, labels string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		fmt.Fprintf(&b, "%s%s %g\n", name, labels, value)
+	}
+
+	success := 0.0
+	if summary.Status == "success" || summary.Status == "unchanged" || summary.Status == "degraded" {
+		success = 1
+	}
+	metric("dotvibe_install_success", "Whether the last install succeeded.", "", success)
+	metric("dotvibe_install_duration_seconds", "Wall-clock duration of the last install.", "", summary.Duration)
+	metric("dotvibe_install_timestamp_seconds", "When the last install started.", "", float64(summary.StartedAt.Unix()))
+	metric("dotvibe_install_warnings", "Warnings raised by the last install.", "", float64(len(summary.Warnings)))
+	metric("dotvibe_install_degraded_components", "Optional modules the last install completed without.", "", float64(len(summary.Degraded)))
+	info := fmt.Sprintf(`status="%s",version="%s",installer_version="%s",platform="%s",source="%s"`,
+		promLabel(summary.Status), promLabel(summary.Version), promLabel(summary.Installer),
+		promLabel(summary.Platform), promLabel(summary.Source))
+	if summary.Instance != "" {
+		info += fmt.Sprintf(`,instance_id="%s"`, promLabel(summary.Instance)) // correlates the pushes of one installation
+	}
+	metric("dotvibe_install_info", "Outcome, versions and platform of the last install.", "{"+info+"}", 1)
+
+	if len(summary.Phases) > 0 {
+		fmt.Fprintf(&b, "# HELP dotvibe_install_phase_duration_seconds Duration of each phase of the last install.\n")
+		fmt.Fprintf(&b, "# TYPE dotvibe_install_phase_duration_seconds gauge\n")
+		for _, p := range summary.Phases {
+			fmt.Fprintf(&b, "dotvibe_install_phase_duration_seconds{phase=\"%s\"} %g\n", promLabel(p.Name), p.Seconds)
+		}
+	}
+
+	if len(summary.Components) > 0 {
+		names := make([]string, 0, len(summary.Components))
+		for name := range summary.Components {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&b, "# HELP dotvibe_install_component_info Installed component versions.\n")
+		fmt.Fprintf(&b, "# TYPE dotvibe_install_component_info gauge\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "dotvibe_install_component_info{component=\"%s\",version=\"%s\"} 1\n", promLabel(name), promLabel(summary.Components[name]))
+		}
+	}
+
+	if len(summary.Modules) > 0 {
+		fmt.Fprintf(&b, "# HELP dotvibe_install_module_ok Whether each module passed post-install verification.\n")
+		fmt.Fprintf(&b, "# TYPE dotvibe_install_module_ok gauge\n")
+		for _, m := range summary.Modules {
+			ok := 0
+			if m.Status == VERIFY_OK {
+				ok = 1
+			}
+			fmt.Fprintf(&b, "dotvibe_install_module_ok{module=\"%s\",status=\"%s\"} %d\n", promLabel(m.Name), promLabel(m.Status), ok)
+		}
+	}
+	return b.String()
+}
+
+// metricsGroupURL returns the Pushgateway URL of this machine's metric
+// group. A URL that already names a group (…/metrics/job/…) is used as is.
+func metricsGroupURL(pushURL, instance string) string {
+	if strings.Contains(pushURL, "/metrics/job/") {
+		return pushURL
+	}
+	return strings.TrimSuffix(pushURL, "/") + "/metrics/job/" + METRICS_JOB + "/instance/" + url.PathEscape(instance)
+}
+
+// pushMetrics replaces this machine's metric group on a Pushgateway with
+// the outcome of the run. It does not run under the run deadline, so a
+// timed-out install still reports.
+func pushMetrics(pushURL string, summary *RunSummary) error {
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		instance = "unknown"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.APITimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, metricsGroupURL(pushURL, instance), bytes.NewBufferString(formatMetrics(summary)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	tagRequest(req)
+	authorize(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Pushgateway returned %s", resp.Status)
+	}
+	return nil
+}
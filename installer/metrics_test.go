@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatMetrics(t *testing.T) {
+	summary := &RunSummary{
+		Status:     "failure",
+		Installer:  "1.2.0",
+		Version:    "v0.7.27",
+		Platform:   "linux/amd64",
+		StartedAt:  time.Unix(1700000000, 0),
+		Duration:   42.5,
+		Phases:     []PhaseTiming{{"Install vibe", 1.5}},
+		Components: map[string]string{"vibe": "v0.7.27"},
+		Modules:    []ModuleCheck{{Name: "surrealdb", Status: VERIFY_MISMATCH}},
+		Error:      `bad "quote"`,
+	}
+	text := formatMetrics(summary)
+	for _, want := range []string{
+		"dotvibe_install_success 0\n",
+		"dotvibe_install_duration_seconds 42.5\n",
+		"dotvibe_install_timestamp_seconds 1.7e+09\n",
+		`dotvibe_install_info{status="failure",version="v0.7.27",installer_version="1.2.0",platform="linux/amd64",source=""} 1`,
+		`dotvibe_install_phase_duration_seconds{phase="Install vibe"} 1.5`,
+		`dotvibe_install_component_info{component="vibe",version="v0.7.27"} 1`,
+		`dotvibe_install_module_ok{module="surrealdb",status="version_mismatch"} 0`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("metrics missing %q:\n%s", want, text)
+		}
+	}
+	summary.Instance = "0b9b7a4e-0000-4000-8000-000000000000"
+	if text := formatMetrics(summary); !strings.Contains(text, `source="",instance_id="0b9b7a4e-0000-4000-8000-000000000000"} 1`) {
+		t.Errorf("metrics lack the instance ID:\n%s", text)
+	}
+	if got := promLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("promLabel = %q", got)
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+	}))
+	defer server.Close()
+
+	if err := pushMetrics(server.URL+"/", &RunSummary{Status: "success"}); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || !strings.HasPrefix(path, "/metrics/job/"+METRICS_JOB+"/instance/") {
+		t.Errorf("pushed with %s %s", method, path)
+	}
+	if !strings.Contains(body, "dotvibe_install_success 1") {
+		t.Errorf("pushed body:\n%s", body)
+	}
+
+	if got := metricsGroupURL("http://gw/metrics/job/ci/runner/7", "host"); got != "http://gw/metrics/job/ci/runner/7" {
+		t.Errorf("metricsGroupURL with a group = %s", got)
+	}
+}
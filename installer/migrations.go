@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Migration is a one-off upgrade step that runs when an upgrade crosses the
+// version that introduced it, i.e. for upgrades from a version below
+// Introduced to Introduced or later.
+type Migration struct {
+	ID          string
+	Introduced  string
+	Description string
+	Run         func(installPath string) error
+}
+
+// MIGRATIONS is the registry of upgrade steps, in any order; the runner
+// sorts them by introducing version
+var MIGRATIONS = []Migration{}
+
+// applies reports whether an upgrade from one version to another crosses m
+func (m Migration) applies(from, to string) bool {
+	introduced, ok := parseVersion(m.Introduced)
+	if !ok {
+		return false
+	}
+	f, okFrom := parseVersion(from)
+	t, okTo := parseVersion(to)
+	if !okFrom || !okTo {
+		return false
+	}
+	return compareVersions(f, introduced) < 0 && compareVersions(t, introduced) >= 0
+}
+
+// pendingMigrations returns the migrations an upgrade must run, ordered by
+// introducing version, excluding those the receipt marks as completed
+func pendingMigrations(registry []Migration, receipt *Receipt, from, to string) []Migration {
+	var pending []Migration
+	for _, m := range registry {
+		if m.applies(from, to) && !receipt.hasMigration(m.ID) {
+			pending = append(pending, m)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, _ := parseVersion(pending[i].Introduced)
+		b, _ := parseVersion(pending[j].Introduced)
+		return compareVersions(a, b) < 0
+	})
+	return pending
+}
+
+// runMigrations executes pending migrations in order, recording each
+// completed step in the receipt so an interrupted upgrade resumes where it
+// stopped
+func runMigrations(registry []Migration, receipt *Receipt, installPath, from, to string) error {
+	if err := simulateFailure(STEP_MIGRATE); err != nil {
+		return err
+	}
+	pending := pendingMigrations(registry, receipt, from, to)
+	if len(pending) == 0 {
+		return nil
+	}
+
+	fmt.Printf("🔀 Running %d migration step(s)...\n", len(pending))
+	for _, m := range pending {
+		fmt.Printf("   • %s (%s): %s\n", m.ID, m.Introduced, m.Description)
+		if err := m.Run(installPath); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+
+		receipt.Migrations = append(receipt.Migrations, m.ID)
+		if err := receipt.save(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Migrations complete!\n")
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunMigrations(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+
+	var ran []string
+	step := func(id string) func(string) error {
+		return func(string) error {
+			ran = append(ran, id)
+			return nil
+		}
+	}
+	registry := []Migration{
+		{ID: "move-data", Introduced: "v0.9.0", Run: step("move-data")},
+		{ID: "rename-config", Introduced: "v0.8.0", Run: step("rename-config")},
+		{ID: "future", Introduced: "v2.0.0", Run: step("future")},
+		{ID: "ancient", Introduced: "v0.5.0", Run: step("ancient")},
+	}
+
+	receipt := &Receipt{}
+	if err := runMigrations(registry, receipt, "/opt/vibe", "v0.7.27", "v1.0.0"); err != nil {
+		t.Fatalf("runMigrations failed: %v", err)
+	}
+
+	if got, want := strings.Join(ran, ","), "rename-config,move-data"; got != want {
+		t.Errorf("migrations ran = %v, want %v", got, want)
+	}
+
+	saved, err := loadReceipt()
+	if err != nil {
+		t.Fatalf("loadReceipt failed: %v", err)
+	}
+	if !saved.hasMigration("move-data") || !saved.hasMigration("rename-config") {
+		t.Errorf("receipt migrations = %v, want both steps recorded", saved.Migrations)
+	}
+
+	// Completed steps are not repeated
+	ran = nil
+	if err := runMigrations(registry, saved, "/opt/vibe", "v0.7.27", "v1.0.0"); err != nil {
+		t.Fatalf("second runMigrations failed: %v", err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("completed migrations ran again: %v", ran)
+	}
+}
+
+func TestRunMigrationsStopsOnFailure(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+
+	registry := []Migration{
+		{ID: "first", Introduced: "v0.8.0", Run: func(string) error { return fmt.Errorf("disk full") }},
+		{ID: "second", Introduced: "v0.8.1", Run: func(string) error { return nil }},
+	}
+
+	receipt := &Receipt{}
+	err := runMigrations(registry, receipt, "/opt/vibe", "v0.7.0", "v0.8.1")
+	if err == nil || !strings.Contains(err.Error(), "migration first failed") {
+		t.Errorf("Expected first migration failure, got: %v", err)
+	}
+	if len(receipt.Migrations) != 0 {
+		t.Errorf("receipt migrations = %v, want none after failure", receipt.Migrations)
+	}
+}
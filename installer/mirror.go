@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MIRROR_MANIFEST is the per-version manifest written by the mirror command
+const MIRROR_MANIFEST = "manifest.json"
+
+// MirrorManifest describes every file mirrored for a release
+type MirrorManifest struct {
+	Version   string        `json:"version"`
+	Source    string        `json:"source"`
+	CreatedAt time.Time     `json:"created_at"`
+	MinGlibc  string        `json:"min_glibc,omitempty"` // oldest glibc the Linux binaries run on
+	Assets    []MirrorAsset `json:"assets"`
+}
+
+// MirrorAsset is a mirrored file, addressed relative to the mirror root
+type MirrorAsset struct {
+	Path     string `json:"path"`
+	Platform string `json:"platform,omitempty"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// mirrorFlags registers the flags of the mirror command
+func mirrorFlags(fs *flag.FlagSet) {
+	addSourceFlags(fs)
+	cacheFlags(fs)
+	fs.StringVar(&opts.Version, "version", opts.Version, "release to mirror (default: latest)")
+	fs.StringVar(&opts.SignKey, "sign-key", opts.SignKey, "PEM ed25519 private key to sign the mirrored manifest with")
+}
+
+// runMirror implements `install-dotvibe mirror [flags] <dir>`
+func runMirror(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	defer startRunDeadline()()
+
+	source, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+	source = withCache(withSharedCache(source))
+
+	version := opts.Version
+	if version == "" {
+		if version, err = source.LatestVersion(); err != nil {
+			return err
+		}
+	}
+	if err := checkPolicyVersion(version); err != nil {
+		return err
+	}
+
+	manifest, err := mirrorRelease(source, version, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Mirrored %s (%d files) to %s\n", version, len(manifest.Assets), args[0])
+	return nil
+}
+
+// mirrorRelease downloads all platform binaries and grammars of version
+// from source into dir, writes SHA256SUMS and the version manifest, signed
+// with --sign-key, and advances the mirror's latest pointer when version
+// is newer. Installs from the mirror verify them exactly as they verify a
+// release.
+func mirrorRelease(source Source, version, dir string) (*MirrorManifest, error) {
+	var signKey ed25519.PrivateKey
+	if opts.SignKey != "" {
+		var err error
+		if signKey, err = loadSigningKey(opts.SignKey); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("🪞 Mirroring %s from %s...\n", version, source.Name())
+
+	releaseDir := filepath.Join(dir, version)
+	if err := os.MkdirAll(releaseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	manifest := &MirrorManifest{
+		Version:   version,
+		Source:    source.Name(),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// Keep the upstream requirements so mirrors are checked the same way
+	if upstream, err := fetchReleaseManifest(source, version); err == nil {
+		manifest.MinGlibc = upstream.MinGlibc
+	}
+
+	// 1. Platform binaries, static Linux builds included
+	for _, b := range releaseBinaries(version) {
+		if err := source.FetchAsset(version, b.Asset, filepath.Join(releaseDir, b.Asset)); err != nil {
+			return nil, fmt.Errorf("failed to mirror %s: %w", b.Asset, err)
+		}
+		if err := manifest.add(dir, filepath.Join(version, b.Asset), b.Platform); err != nil {
+			return nil, err
+		}
+		if err := mirrorSignatures(source, version, b.Asset, releaseDir); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Grammars
+	for _, g := range GRAMMARS {
+		rel := filepath.Join("grammars", g.Package+"@"+g.Version, g.File)
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create grammar directory: %w", err)
+		}
+		if err := source.FetchGrammar(g.Package, g.Version, g.File, filepath.Join(dir, rel)); err != nil {
+			return nil, fmt.Errorf("failed to mirror grammar %s: %w", g.Package, err)
+		}
+		if err := manifest.add(dir, rel, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Checksums of every mirrored file
+	if err := writeMirrorChecksums(source, version, dir, manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.add(dir, filepath.Join(version, "SHA256SUMS"), ""); err != nil {
+		return nil, err
+	}
+
+	// 4. Manifest, its signature and latest pointer
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(filepath.Join(releaseDir, MIRROR_MANIFEST), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if signKey != nil {
+		if err := os.WriteFile(filepath.Join(releaseDir, MANIFEST_SIGNATURE), signManifest(data, signKey), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write manifest signature: %w", err)
+		}
+		fmt.Printf("🔏 Signed the manifest of %s\n", version)
+	}
+
+	if err := updateMirrorLatest(dir, version); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// writeMirrorChecksums writes SHA256SUMS for the mirrored files, named as
+// installs look them up. Files the release publishes a checksum for must
+// match it, so a mirror never vouches for a corrupted download.
+func writeMirrorChecksums(source Source, version, dir string, manifest *MirrorManifest) error {
+	upstream := map[string]string{}
+	if sumsPath, err := quarantinePath("SHA256SUMS"); err == nil {
+		if source.FetchAsset(version, "SHA256SUMS", sumsPath) == nil {
+			if data, err := os.ReadFile(sumsPath); err == nil {
+				upstream = parseChecksums(string(data))
+			}
+		} else {
+			fmt.Printf("⚠️  No SHA256SUMS published for %s, writing one from the mirrored files\n", version)
+		}
+		os.Remove(sumsPath)
+	}
+
+	var sums strings.Builder
+	for _, a := range manifest.Assets {
+		name := path.Base(a.Path)
+		if want, ok := upstream[name]; ok && want != a.SHA256 {
+			return fmt.Errorf("mirrored %s has sha256 %s, but the release publishes %s", name, short(a.SHA256), short(want))
+		}
+		fmt.Fprintf(&sums, "%s  %s\n", a.SHA256, name)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version, "SHA256SUMS"), []byte(sums.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write SHA256SUMS: %w", err)
+	}
+	return nil
+}
+
+// add hashes a mirrored file and records it in the manifest
+func (m *MirrorManifest) add(root, rel, platform string) error {
+	digest, size, err := fileSHA256(filepath.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	m.Assets = append(m.Assets, MirrorAsset{
+		Path:     filepath.ToSlash(rel),
+		Platform: platform,
+		Size:     size,
+		SHA256:   digest,
+	})
+	return nil
+}
+
+// updateMirrorLatest points <dir>/latest at version unless a newer stable
+// release is already mirrored
+func updateMirrorLatest(dir, version string) error {
+	v, ok := parseVersion(version)
+	if !ok || v.Prerelease != "" {
+		return nil
+	}
+
+	latestPath := filepath.Join(dir, "latest")
+	if current, err := os.ReadFile(latestPath); err == nil {
+		if cv, ok := parseVersion(strings.TrimSpace(string(current))); ok && compareVersions(cv, v) > 0 {
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(latestPath, []byte(version+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to update latest pointer: %w", err)
+	}
+	return nil
+}
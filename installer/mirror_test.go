@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSource serves deterministic content for every asset and grammar
+type fakeSource struct {
+	latest  string
+	missing map[string]bool
+}
+
+func (f fakeSource) Name() string { return "fake" }
+
+func (f fakeSource) LatestVersion() (string, error) { return f.latest, nil }
+
+func (f fakeSource) FetchAsset(version, asset, destPath string) error {
+	if f.missing[asset] {
+		return fmt.Errorf("%s not found", asset)
+	}
+	return os.WriteFile(destPath, []byte(version+"/"+asset), 0644)
+}
+
+func (f fakeSource) FetchGrammar(pkg, version, file, destPath string) error {
+	// Grammars are checked to be WebAssembly before they are installed
+	return os.WriteFile(destPath, append(wasmMagic, pkg+"@"+version+"/"+file...), 0644)
+}
+
+func TestMirrorRelease(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	dir := t.TempDir()
+	source := fakeSource{latest: "v1.2.0", missing: map[string]bool{"SHA256SUMS": true}}
+
+	manifest, err := mirrorRelease(source, "v1.2.0", dir)
+	if err != nil {
+		t.Fatalf("mirrorRelease failed: %v", err)
+	}
+
+	// SHA256SUMS is written even though the release publishes none
+	expectedFiles := len(releaseBinaries("v1.2.0")) + len(GRAMMARS) + 1
+	if len(manifest.Assets) != expectedFiles {
+		t.Errorf("manifest has %d assets, want %d", len(manifest.Assets), expectedFiles)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "v1.2.0", MIRROR_MANIFEST))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var onDisk MirrorManifest
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if onDisk.Version != "v1.2.0" {
+		t.Errorf("manifest version = %v, want v1.2.0", onDisk.Version)
+	}
+	// Static Linux builds are mirrored for machines without glibc
+	static := staticAssetName("linux", "arm64", "v1.2.0")
+	if _, err := os.Stat(filepath.Join(dir, "v1.2.0", static)); err != nil {
+		t.Errorf("static build not mirrored: %v", err)
+	}
+	found := false
+	for _, a := range onDisk.Assets {
+		found = found || a.Path == "v1.2.0/"+static && a.Platform == "linux/arm64"+STATIC_ASSET_SUFFIX
+	}
+	if !found {
+		t.Errorf("manifest does not list %s: %+v", static, onDisk.Assets)
+	}
+
+	// An older release must not move the latest pointer backwards
+	if _, err := mirrorRelease(source, "v1.1.0", dir); err != nil {
+		t.Fatalf("mirrorRelease(v1.1.0) failed: %v", err)
+	}
+	latest, _ := os.ReadFile(filepath.Join(dir, "latest"))
+	if string(latest) != "v1.2.0\n" {
+		t.Errorf("latest = %q, want v1.2.0", latest)
+	}
+
+	t.Run("served mirror works as --base-url", func(t *testing.T) {
+		server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+		defer server.Close()
+
+		mirror, err := newSource(server.URL + "/")
+		if err != nil {
+			t.Fatalf("newSource failed: %v", err)
+		}
+
+		version, err := mirror.LatestVersion()
+		if err != nil || version != "v1.2.0" {
+			t.Fatalf("LatestVersion() = %v, %v, want v1.2.0", version, err)
+		}
+
+		asset := releaseAssetName("linux", "amd64", version)
+		dest := filepath.Join(t.TempDir(), asset)
+		if err := mirror.FetchAsset(version, asset, dest); err != nil {
+			t.Fatalf("FetchAsset failed: %v", err)
+		}
+		got, _ := os.ReadFile(dest)
+		if string(got) != version+"/"+asset {
+			t.Errorf("mirrored asset content = %q", got)
+		}
+
+		g := GRAMMARS[0]
+		if err := mirror.FetchGrammar(g.Package, g.Version, g.File, filepath.Join(t.TempDir(), g.File)); err != nil {
+			t.Errorf("FetchGrammar failed: %v", err)
+		}
+	})
+}
@@ -1,13 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,169 +15,331 @@ const (
 	SURREALDB_VERSION      = "2.3.5"
 	TREE_SITTER_TS_VERSION = "0.23.2"
 
-	TREE_SITTER_WASM_URL = "https://unpkg.com/tree-sitter-typescript@" + TREE_SITTER_TS_VERSION + "/tree-sitter-typescript.wasm"
+	UNPKG_URL = "https://unpkg.com"
 )
 
-// checkRustInstallation verifies if Rust and Cargo are installed
-func checkRustInstallation() bool {
-	fmt.Printf("🔍 Checking Rust installation...\n")
-
-	cmd := exec.Command("cargo", "--version")
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("❌ Rust/Cargo not found\n")
-		return false
-	}
-
-	fmt.Printf("✅ Rust/Cargo is installed\n")
-	return true
+// SURREALDB_SHA256 pins the sha256 of the official SurrealDB
+// SURREALDB_VERSION binary per "goos/goarch", copied from the checksums
+// SurrealDB publishes next to each download. --prebuilt compiles surrealdb
+// on platforms without an entry unless --allow-unverified is given.
+var SURREALDB_SHA256 = map[string]string{}
+
+// Grammar identifies a pinned tree-sitter grammar file
+type Grammar struct {
+	Package string
+	Version string
+	File    string
 }
 
-// installRustToolchain installs Rust using rustup
-func installRustToolchain() error {
-	fmt.Printf("🦀 Installing Rust toolchain...\n")
+// GRAMMARS lists every grammar the installer places in the data directory
+var GRAMMARS = []Grammar{
+	{Package: "tree-sitter-typescript", Version: TREE_SITTER_TS_VERSION, File: "tree-sitter-typescript.wasm"},
+}
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		// Windows: Download and run rustup-init.exe
-		cmd = exec.Command("powershell", "-Command", 
-			"Invoke-WebRequest -Uri https://win.rustup.rs -OutFile rustup-init.exe; ./rustup-init.exe -y; Remove-Item rustup-init.exe")
-	} else {
-		// Unix-like: Use curl | sh pattern
-		cmd = exec.Command("sh", "-c", "curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y")
+// getDataDir returns the directory holding the grammars vibe loads: the
+// grammars directory of the vibe home on Linux, next to the binary elsewhere
+func getDataDir(installPath string) string {
+	if usesXDG() {
+		return filepath.Join(getVibeHome(), "grammars")
 	}
+	return adjacentDataDir(installPath)
+}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// downloadWasmFile downloads the tree-sitter WASM file to data directory,
+// keeping a newer version installed by `grammars update`. It is checked
+// against the checksums of the release being installed.
+func downloadWasmFile(installPath string, source Source, receipt *Receipt) error {
+	g := receipt.grammar(GRAMMARS[0])
+	fmt.Printf("📥 Downloading %s %s WASM file...\n", g.Package, g.Version)
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install Rust: %w", err)
+	release := runSummary.Version
+	if release == "" {
+		release = receipt.Version
 	}
-
-	// Add Cargo to PATH for current session
-	if runtime.GOOS != "windows" {
-		os.Setenv("PATH", os.Getenv("HOME")+"/.cargo/bin:"+os.Getenv("PATH"))
+	want, err := grammarDigest(source, release, g)
+	if err != nil {
+		return err
 	}
+	wasmPath, digest, err := installGrammar(getDataDir(installPath), source, g, want)
+	if err != nil {
+		return err
+	}
+	receipt.recordGrammar(InstalledGrammar{Package: g.Package, Version: g.Version, File: g.File, SHA256: digest})
+	receipt.recordProvenance(newProvenance(g.Package, g.File, wasmPath, source, digest, ""))
+	receipt.recordFile(g.Package, g.Version, wasmPath)
 
-	fmt.Printf("✅ Rust toolchain installed!\n")
+	fmt.Printf("✅ WASM file downloaded to: %s\n", wasmPath)
 	return nil
 }
 
-// installCargoPackage installs a specific cargo package with version
-func installCargoPackage(packageName, version string) error {
-	fmt.Printf("📦 Installing %s v%s...\n", packageName, version)
-
-	cmd := exec.Command("cargo", "install", packageName, "--version", version)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// Module is a dependency installed alongside vibe
+type Module struct {
+	Name    string
+	Cargo   bool // installed with cargo, so needs the Rust toolchain
+	Install func(installPath string, source Source, receipt *Receipt) error
+	Version string       // pinned version, if known
+	Verify  func() error // nil when there is nothing to run besides Command
+	Command string       // executable reporting its version with --version, if known
+
+	// Optional modules only degrade vibe when they fail: the install warns
+	// and completes without them instead of aborting. They can also be
+	// deselected in the component menu.
+	Optional    bool
+	Description string // what an optional module adds, for the menu
+
+	BuildTime time.Duration                                       // typical cargo build on a 4-core machine, for the plan preview
+	Explain   func(installPath string, source Source) Explanation // what Install would do, for explain
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to install %s: %w", packageName, err)
+// MODULES lists the dependencies in installation order
+var MODULES = append(append([]Module{}, CARGO_MODULES...), Module{
+	Name:        "tree-sitter-typescript",
+	Version:     TREE_SITTER_TS_VERSION,
+	Optional:    true, // vibe runs without it, only TypeScript goes unparsed
+	Description: "TypeScript and TSX parsing",
+	Install: func(installPath string, source Source, receipt *Receipt) error {
+		return downloadWasmFile(installPath, source, receipt)
+	},
+	Explain: func(installPath string, source Source) Explanation {
+		g := GRAMMARS[0]
+		return Explanation{
+			Summary: fmt.Sprintf("downloads the %s %s grammar", g.Package, g.Version),
+			URLs:    sourceURLs(source, func(u URLSource) string { return u.GrammarURL(g.Package, g.Version, g.File) }),
+			Files:   []string{filepath.Join(getDataDir(installPath), g.File)},
+		}
+	},
+})
+
+// findModules returns the built-in or user-defined modules with the given
+// names, in install order
+func findModules(names []string) []Module {
+	var found []Module
+	for _, m := range allModules() {
+		for _, name := range names {
+			if m.Name == name {
+				found = append(found, m)
+				break
+			}
+		}
 	}
-
-	fmt.Printf("✅ %s v%s installed!\n", packageName, version)
-	return nil
+	return found
 }
 
-// downloadWasmFile downloads the tree-sitter WASM file to data directory
-func downloadWasmFile(installPath string) error {
-	fmt.Printf("📥 Downloading tree-sitter-typescript WASM file...\n")
+// installAllModules installs all required dependencies
+func installAllModules(installPath string, source Source, receipt *Receipt) error {
+	fmt.Printf("🔧 Installing all dependencies...\n")
+	return installModules(allModules(), installPath, source, receipt)
+}
 
-	// Create data directory alongside the executable
-	dataDir := filepath.Join(installPath, "data")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
+// installModules installs each module, carrying on past failures so one
+// broken dependency does not hold back the others. The names of modules
+// that failed are recorded in the receipt for --retry-failed; only failed
+// required modules fail the install.
+func installModules(modules []Module, installPath string, source Source, receipt *Receipt) error {
+	// 1. Check/Install Rust and make sure the machine can compile, only
+	// when a cargo module needs it
+	var rustErr error
+	for _, m := range modules {
+		if m.Cargo {
+			if rustErr = simulateFailure(STEP_RUST); rustErr == nil {
+				rustErr = prepareCargo()
+			}
+			break
+		}
 	}
 
-	wasmPath := filepath.Join(dataDir, "tree-sitter-typescript.wasm")
-
-	// Download WASM file
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(TREE_SITTER_WASM_URL)
-	if err != nil {
-		return fmt.Errorf("failed to download WASM file: %w", err)
+	// 2. Install modules
+	var failed, required []string
+	for _, m := range modules {
+		err := rustErr
+		if !m.Cargo || rustErr == nil {
+			if err = simulateFailure(m.Name); err == nil {
+				err = m.Install(installPath, source, receipt)
+			}
+		}
+		if err == nil {
+			continue
+		}
+		failed = append(failed, m.Name)
+		if m.Optional {
+			degrade(m.Name, err)
+			continue
+		}
+		fmt.Printf("❌ %s: %v\n", m.Name, err)
+		required = append(required, m.Name)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %d %s", resp.StatusCode, resp.Status)
+	receipt.FailedModules = failed
+	if len(required) > 0 {
+		return fmt.Errorf("%d required module(s) failed: %s (fix the cause and rerun with --retry-failed)", len(required), strings.Join(required, ", "))
 	}
+	return nil
+}
 
-	// Create WASM file
-	out, err := os.Create(wasmPath)
-	if err != nil {
-		return fmt.Errorf("failed to create WASM file: %w", err)
+// degrade lets the run complete without an optional module that failed,
+// listing it in the summary
+func degrade(name string, err error) {
+	for _, d := range runSummary.Degraded {
+		if d == name {
+			return
+		}
 	}
-	defer out.Close()
+	warnf("%s is optional, continuing without it: %v", name, err)
+	runSummary.Degraded = append(runSummary.Degraded, name)
+}
 
-	// Copy with progress
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to save WASM file: %w", err)
-	}
+// Outcomes of verifying an installed module
+const (
+	VERIFY_OK       = "ok"
+	VERIFY_MISMATCH = "version_mismatch" // runs, but is not the pinned version
+	VERIFY_FAILED   = "failed"
+)
 
-	fmt.Printf("✅ WASM file downloaded to: %s\n", wasmPath)
-	return nil
+// ModuleCheck is the result of verifying one installed module
+type ModuleCheck struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Expected string `json:"expected,omitempty"` // pinned version
+	Reported string `json:"reported,omitempty"` // what the module reports, normalized to vX.Y.Z when possible
+	Error    string `json:"error,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
 }
 
-// installAllModules installs all required dependencies
-func installAllModules(installPath string) error {
-	fmt.Printf("🔧 Installing all dependencies...\n")
+// verifyModule runs a module's --version, comparing what it reports with
+// the pinned version, or its custom verification
+func verifyModule(m Module) ModuleCheck {
+	check := ModuleCheck{Name: m.Name, Status: VERIFY_OK, Expected: m.Version, Optional: m.Optional}
 
-	// 1. Check/Install Rust
-	if !checkRustInstallation() {
-		if err := installRustToolchain(); err != nil {
-			return err
+	if m.Command != "" {
+		out, err := versionOutput(m.Command)
+		if err != nil {
+			check.Status, check.Error = VERIFY_FAILED, fmt.Sprintf("%s --version failed: %v", m.Command, err)
+			return check
 		}
-		
-		// Verify installation worked
-		if !checkRustInstallation() {
-			return fmt.Errorf("Rust installation verification failed")
+		check.Reported = out
+		if v, ok := extractVersion(out); ok {
+			check.Reported = v
+			if m.Version != "" && strings.TrimPrefix(v, "v") != strings.TrimPrefix(m.Version, "v") {
+				check.Status = VERIFY_MISMATCH
+				check.Error = fmt.Sprintf("reports %s, expected v%s", v, strings.TrimPrefix(m.Version, "v"))
+				return check
+			}
 		}
 	}
 
-	// 2. Install cargo packages
-	packages := map[string]string{
-		"code2prompt": CODE2PROMPT_VERSION,
-		"surrealdb":   SURREALDB_VERSION,
+	if m.Verify != nil {
+		if err := m.Verify(); err != nil {
+			check.Status, check.Error = VERIFY_FAILED, err.Error()
+		}
 	}
+	return check
+}
 
-	for packageName, version := range packages {
-		if err := installCargoPackage(packageName, version); err != nil {
-			return err
+// checkModules verifies modules concurrently, returning the results in
+// module order. Modules with nothing to run are left out.
+func checkModules(modules []Module) []ModuleCheck {
+	var verifiable []Module
+	for _, m := range modules {
+		if m.Command != "" || m.Verify != nil {
+			verifiable = append(verifiable, m)
 		}
 	}
 
-	// 3. Download WASM file
-	if err := downloadWasmFile(installPath); err != nil {
-		return err
+	checks := make([]ModuleCheck, len(verifiable))
+	var wg sync.WaitGroup
+	for i, m := range verifiable {
+		wg.Add(1)
+		task := progress.step("Checking " + m.Name)
+		go func(i int, m Module) {
+			defer wg.Done()
+			checks[i] = verifyModule(m)
+			if checks[i].Status == VERIFY_OK {
+				task.finish(nil)
+			} else {
+				task.finish(errors.New(checks[i].Status))
+			}
+		}(i, m)
 	}
-
-	return nil
+	wg.Wait()
+	return checks
 }
 
 // verifyAllModules checks that all dependencies are working
-func verifyAllModules() error {
+func verifyAllModules() ([]ModuleCheck, error) {
 	fmt.Printf("🔍 Verifying all dependencies...\n")
+	checks, err := verifyModules(allModules())
+	if err != nil {
+		return checks, err
+	}
+	if len(runSummary.Degraded) > 0 {
+		fmt.Printf("✅ All required dependencies verified!\n")
+		return checks, nil
+	}
+	fmt.Printf("✅ All dependencies verified!\n")
+	return checks, nil
+}
 
-	// Test cargo packages
-	packages := []string{"code2prompt", "surreal"}
-	for _, pkg := range packages {
-		cmd := exec.Command(pkg, "--version")
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("verification failed for %s: %w", pkg, err)
+// verifyModules verifies modules and prints the outcome of each
+func verifyModules(modules []Module) ([]ModuleCheck, error) {
+	checks := checkModules(modules)
+	var failed []string
+	for _, c := range checks {
+		if c.Status != VERIFY_OK && c.Optional {
+			degrade(c.Name, errors.New(c.Error))
+			continue
+		}
+		if c.Status != VERIFY_OK {
+			fmt.Printf("❌ %s: %s\n", c.Name, c.Error)
+			failed = append(failed, c.Name)
+			continue
 		}
-		fmt.Printf("✅ %s is working\n", pkg)
+		if c.Reported != "" {
+			fmt.Printf("✅ %s is working (%s)\n", c.Name, c.Reported)
+		} else {
+			fmt.Printf("✅ %s is working\n", c.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return checks, fmt.Errorf("verification failed for %s", strings.Join(failed, ", "))
 	}
+	return checks, nil
+}
 
-	fmt.Printf("✅ All dependencies verified!\n")
-	return nil
+// ComponentVersion is one entry of the version listings
+type ComponentVersion struct {
+	Name    string
+	Version string
+}
+
+// INSTALLER_COMPONENT names the installer in version listings
+const INSTALLER_COMPONENT = "install-dotvibe"
+
+// getVersionInfo lists vibe at vibeVersion, the installer and every module
+// with a pinned version, always in install order so listings diff cleanly
+func getVersionInfo(vibeVersion string) []ComponentVersion {
+	versions := []ComponentVersion{{"vibe", vibeVersion}, {INSTALLER_COMPONENT, version}}
+	for _, m := range allModules() {
+		if m.Version != "" {
+			versions = append(versions, ComponentVersion{m.Name, m.Version})
+		}
+	}
+	return versions
 }
 
-// getVersionInfo returns version information for all dependencies
-func getVersionInfo() map[string]string {
-	return map[string]string{
-		"code2prompt":              CODE2PROMPT_VERSION,
-		"surrealdb":                SURREALDB_VERSION,
-		"tree-sitter-typescript":   TREE_SITTER_TS_VERSION,
+// versionMap indexes a version listing by component
+func versionMap(versions []ComponentVersion) map[string]string {
+	m := make(map[string]string, len(versions))
+	for _, v := range versions {
+		m[v.Name] = v.Version
 	}
-}
\ No newline at end of file
+	return m
+}
+
+// displayVersion shows versions the same way whether or not they were
+// pinned with a leading v
+func displayVersion(v string) string {
+	if v != "" && v[0] >= '0' && v[0] <= '9' {
+		return "v" + v
+	}
+	return v
+}
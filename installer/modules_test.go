@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCheckModules(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as binaries")
+	}
+	dir := t.TempDir()
+	script := func(name, output string) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte("#!/bin/sh\necho '"+output+"'\n"), 0755)
+		return path
+	}
+
+	modules := []Module{
+		{Name: "pinned", Version: "2.3.5", Command: script("pinned", "pinned 2.3.5 for linux")},
+		{Name: "stale", Version: "2.3.5", Command: script("stale", "stale 2.1.0")},
+		{Name: "broken", Command: filepath.Join(dir, "missing")},
+		{Name: "grammar"},
+		{Name: "custom", Verify: func() error { return fmt.Errorf("no output") }},
+	}
+	checks := checkModules(modules)
+
+	want := []ModuleCheck{
+		{Name: "pinned", Status: VERIFY_OK, Expected: "2.3.5", Reported: "v2.3.5"},
+		{Name: "stale", Status: VERIFY_MISMATCH, Expected: "2.3.5", Reported: "v2.1.0"},
+		{Name: "broken", Status: VERIFY_FAILED},
+		{Name: "custom", Status: VERIFY_FAILED, Error: "no output"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checkModules returned %d results, want %d: %+v", len(checks), len(want), checks)
+	}
+	for i, w := range want {
+		c := checks[i]
+		if c.Name != w.Name || c.Status != w.Status || c.Expected != w.Expected || c.Reported != w.Reported {
+			t.Errorf("check %d = %+v, want %+v", i, c, w)
+		}
+		if (c.Status == VERIFY_OK) != (c.Error == "") {
+			t.Errorf("%s: status %s with error %q", c.Name, c.Status, c.Error)
+		}
+	}
+
+	if _, err := verifyModules(modules[:1]); err != nil {
+		t.Errorf("verifyModules of a working module failed: %v", err)
+	}
+	if _, err := verifyModules(modules); err == nil {
+		t.Error("verifyModules succeeded with broken modules")
+	}
+}
+
+func TestGetVersionInfo(t *testing.T) {
+	first := getVersionInfo("v1.2.0")
+	if len(first) < 2 || first[0] != (ComponentVersion{"vibe", "v1.2.0"}) || first[1].Name != INSTALLER_COMPONENT {
+		t.Fatalf("getVersionInfo = %v, want vibe then the installer first", first)
+	}
+	for i := 0; i < 10; i++ {
+		if again := getVersionInfo("v1.2.0"); !reflect.DeepEqual(again, first) {
+			t.Fatalf("getVersionInfo changed order: %v, then %v", first, again)
+		}
+	}
+	if m := versionMap(first); m["vibe"] != "v1.2.0" || len(m) != len(first) {
+		t.Errorf("versionMap = %v", m)
+	}
+	for in, want := range map[string]string{"2.3.5": "v2.3.5", "v1.2.0": "v1.2.0", "dev": "dev", "": ""} {
+		if got := displayVersion(in); got != want {
+			t.Errorf("displayVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
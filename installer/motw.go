@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+)
+
+// ZONE_IDENTIFIER_STREAM is the NTFS alternate data stream in which
+// browsers and downloaders record where a file came from (Mark-of-the-Web)
+const ZONE_IDENTIFIER_STREAM = ":Zone.Identifier"
+
+// Security zones recorded in the Zone.Identifier stream
+const (
+	ZONE_LOCAL     = 0
+	ZONE_INTRANET  = 1
+	ZONE_TRUSTED   = 2
+	ZONE_INTERNET  = 3
+	ZONE_UNTRUSTED = 4
+)
+
+// zoneIDPattern matches the ZoneId entry of a Zone.Identifier stream
+var zoneIDPattern = regexp.MustCompile(`(?m)^ZoneId=(\d+)`)
+
+// parseZoneID returns the security zone recorded in a Zone.Identifier
+// stream such as "[ZoneTransfer]\r\nZoneId=3\r\n"
+func parseZoneID(stream string) (int, bool) {
+	m := zoneIDPattern.FindStringSubmatch(stream)
+	if m == nil {
+		return 0, false
+	}
+	zone, err := strconv.Atoi(m[1])
+	return zone, err == nil
+}
+
+// removeMarkOfTheWeb deletes the Zone.Identifier stream of each path, so
+// SmartScreen does not block the installed binaries. Paths without the
+// stream are skipped.
+func removeMarkOfTheWeb(paths []string) []error {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	var errs []error
+	for _, path := range paths {
+		stream := path + ZONE_IDENTIFIER_STREAM
+		data, err := os.ReadFile(stream)
+		if err != nil {
+			continue // no mark, or not on NTFS
+		}
+		if zone, ok := parseZoneID(string(data)); ok && zone < ZONE_INTERNET {
+			continue // local and intranet files are not blocked
+		}
+		if err := os.Remove(stream); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// printSmartScreenGuidance explains how to unblock binaries whose mark
+// could not be removed, typically because a policy enforces attachment
+// zone information
+func printSmartScreenGuidance(errs []error) {
+	warnf("Could not remove the downloaded-file mark from:")
+	for _, err := range errs {
+		fmt.Printf("   • %v\n", err)
+	}
+	fmt.Printf("   Windows SmartScreen may block these binaries. To unblock them:\n")
+	fmt.Printf("   • PowerShell: Get-ChildItem <install dir> | Unblock-File\n")
+	fmt.Printf("   • Explorer: right-click the file → Properties → tick Unblock\n")
+	fmt.Printf("   • When SmartScreen appears: More info → Run anyway\n")
+	fmt.Printf("   If your organisation manages SmartScreen policy, ask IT to allow vibe.\n")
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestParseZoneID(t *testing.T) {
+	tests := []struct {
+		stream string
+		zone   int
+		ok     bool
+	}{
+		{"[ZoneTransfer]\r\nZoneId=3\r\nHostUrl=https://github.com/\r\n", ZONE_INTERNET, true},
+		{"[ZoneTransfer]\nZoneId=1\n", ZONE_INTRANET, true},
+		{"[ZoneTransfer]\r\n", 0, false},
+	}
+	for _, tt := range tests {
+		zone, ok := parseZoneID(tt.stream)
+		if zone != tt.zone || ok != tt.ok {
+			t.Errorf("parseZoneID(%q) = %d, %v; want %d, %v", tt.stream, zone, ok, tt.zone, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PROBE_TIMEOUT bounds the request measuring how fast a source serves an
+// asset; slower sources are as good as down
+const PROBE_TIMEOUT = 5 * time.Second
+
+// URLSource is implemented by sources that download over HTTP, so they
+// can be probed before a download
+type URLSource interface {
+	AssetURL(version, asset string) string
+	GrammarURL(pkg, version, file string) string
+}
+
+func (githubSource) AssetURL(version, asset string) string {
+	return fmt.Sprintf("%s/%s/%s", GITHUB_RELEASES_URL, version, asset)
+}
+
+func (githubSource) GrammarURL(pkg, version, file string) string {
+	return fmt.Sprintf("%s/%s@%s/%s", UNPKG_URL, pkg, version, file)
+}
+
+func (m mirrorSource) AssetURL(version, asset string) string {
+	return fmt.Sprintf("%s/%s/%s", m.baseURL, version, asset)
+}
+
+func (m mirrorSource) GrammarURL(pkg, version, file string) string {
+	return fmt.Sprintf("%s/grammars/%s@%s/%s", m.baseURL, pkg, version, file)
+}
+
+// SourceChoice records which source served a download, for the report
+type SourceChoice struct {
+	File      string  `json:"file"`
+	Source    string  `json:"source"`
+	LatencyMS float64 `json:"latency_ms,omitempty"` // probe time, 0 when not probed
+}
+
+// probeResult is how one source answered a probe
+type probeResult struct {
+	source  Source
+	latency time.Duration
+	healthy bool
+	probed  bool
+}
+
+// probeURL times a one-byte range request. GitHub redirects release
+// downloads to its CDN, so HEAD would only measure the redirect.
+func probeURL(url string) (time.Duration, bool) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	start := time.Now()
+	resp, err := httpDo(req, PROBE_TIMEOUT)
+	if err != nil {
+		return 0, false
+	}
+	resp.Body.Close()
+	return time.Since(start), resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent
+}
+
+// multiSource spreads downloads over several sources: the primary and its
+// mirrors. Each file comes from the fastest healthy source, falling back to
+// the others in order of speed.
+type multiSource struct {
+	sources []Source // primary first
+}
+
+// newSources builds the primary source and wraps it with its mirrors,
+// when any are configured
+func newSources(primary string, mirrors []string) (Source, error) {
+	first, err := newSource(primary)
+	if err != nil {
+		return nil, err
+	}
+	if len(mirrors) == 0 {
+		return first, nil
+	}
+	multi := &multiSource{sources: []Source{first}}
+	for _, spec := range mirrors {
+		mirror, err := newSource(spec)
+		if err != nil {
+			return nil, fmt.Errorf("mirror %s: %w", spec, err)
+		}
+		multi.sources = append(multi.sources, mirror)
+	}
+	return multi, nil
+}
+
+func (m *multiSource) Name() string {
+	return fmt.Sprintf("%s (+%d mirrors)", m.sources[0].Name(), len(m.sources)-1)
+}
+
+// LatestVersion asks the primary, then each mirror until one answers
+func (m *multiSource) LatestVersion() (string, error) {
+	var errs []string
+	for _, s := range m.sources {
+		latest, err := s.LatestVersion()
+		if err == nil {
+			return latest, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", s.Name(), err))
+	}
+	return "", fmt.Errorf("no source could resolve the latest version:\n   %s", strings.Join(errs, "\n   "))
+}
+
+// ReleaseNotes passes release notes through from the first source that
+// has them
+func (m *multiSource) ReleaseNotes(version string) (string, error) {
+	for _, s := range m.sources {
+		if notes, ok := s.(ReleaseNotesSource); ok {
+			return notes.ReleaseNotes(version)
+		}
+	}
+	return "", nil
+}
+
+func (m *multiSource) FetchAsset(version, asset, destPath string) error {
+	ranked := m.rank(func(u URLSource) string { return u.AssetURL(version, asset) })
+	return m.fetch(asset, ranked, func(s Source) error { return s.FetchAsset(version, asset, destPath) })
+}
+
+func (m *multiSource) FetchGrammar(pkg, version, file, destPath string) error {
+	ranked := m.rank(func(u URLSource) string { return u.GrammarURL(pkg, version, file) })
+	return m.fetch(file, ranked, func(s Source) error { return s.FetchGrammar(pkg, version, file, destPath) })
+}
+
+// rank probes every source concurrently and orders them fastest healthy
+// first. Sources that cannot be probed keep their configured order after
+// the healthy ones; unhealthy ones come last as a final resort.
+func (m *multiSource) rank(urlOf func(URLSource) string) []probeResult {
+	results := make([]probeResult, len(m.sources))
+	var wg sync.WaitGroup
+	for i, s := range m.sources {
+		results[i] = probeResult{source: s, healthy: true}
+		u, ok := s.(URLSource)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			results[i].latency, results[i].healthy = probeURL(url)
+			results[i].probed = true
+		}(i, urlOf(u))
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if a.probed != b.probed {
+			return a.probed
+		}
+		return a.probed && a.latency < b.latency
+	})
+	return results
+}
+
+// fetch downloads from the ranked sources in turn, recording the one
+// that served the file. When no source has the file the error wraps
+// errAssetNotFound, so callers can try other names.
+func (m *multiSource) fetch(file string, ranked []probeResult, download func(Source) error) error {
+	var errs []string
+	missing := true
+	for _, r := range ranked {
+		if r.probed && r.healthy {
+			fmt.Printf("🛰️  %s from %s (%dms)\n", file, r.source.Name(), r.latency.Milliseconds())
+		}
+		if err := download(r.source); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", r.source.Name(), err))
+			missing = missing && errors.Is(err, errAssetNotFound)
+			continue
+		}
+		choice := SourceChoice{File: file, Source: r.source.Name()}
+		if r.probed {
+			choice.LatencyMS = float64(r.latency.Microseconds()) / 1000
+		}
+		runSummary.SourceChoices = append(runSummary.SourceChoices, choice)
+		return nil
+	}
+	if missing {
+		return fmt.Errorf("no source has %s: %w", file, errAssetNotFound)
+	}
+	return fmt.Errorf("every source failed for %s:\n   %s", file, strings.Join(errs, "\n   "))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// releaseServer serves v1.0.0 assets from a static mirror layout after
+// delay, or answers every request with status when it is not 200
+func releaseServer(t *testing.T, delay time.Duration, status int) *httptest.Server {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "latest"), []byte("v1.0.0\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "v1.0.0"), 0755)
+	os.WriteFile(filepath.Join(dir, "v1.0.0", "vibe-v1.0.0-linux-x86_64"), []byte("binary"), 0644)
+
+	files := http.FileServer(http.Dir(dir))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMultiSourcePicksFastestHealthy(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	slow := releaseServer(t, 300*time.Millisecond, http.StatusOK)
+	fast := releaseServer(t, 0, http.StatusOK)
+	broken := releaseServer(t, 0, http.StatusInternalServerError)
+
+	source, err := newSources(slow.URL, []string{broken.URL, fast.URL})
+	if err != nil {
+		t.Fatalf("newSources failed: %v", err)
+	}
+	before := len(runSummary.SourceChoices)
+
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := source.FetchAsset("v1.0.0", "vibe-v1.0.0-linux-x86_64", dest); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "binary" {
+		t.Errorf("downloaded %q, want binary", data)
+	}
+
+	choices := runSummary.SourceChoices[before:]
+	if len(choices) != 1 || choices[0].Source != fast.URL {
+		t.Fatalf("source choices = %+v, want the fast mirror %s", choices, fast.URL)
+	}
+	if choices[0].LatencyMS <= 0 {
+		t.Errorf("latency = %v, want the probe time", choices[0].LatencyMS)
+	}
+}
+
+func TestMultiSourceFallsBack(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	broken := releaseServer(t, 0, http.StatusServiceUnavailable)
+	healthy := releaseServer(t, 50*time.Millisecond, http.StatusOK)
+
+	source, err := newSources(broken.URL, []string{healthy.URL})
+	if err != nil {
+		t.Fatalf("newSources failed: %v", err)
+	}
+	if version, err := source.LatestVersion(); err != nil || version != "v1.0.0" {
+		t.Errorf("LatestVersion() = %v, %v; want v1.0.0 from the mirror", version, err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := source.FetchAsset("v1.0.0", "vibe-v1.0.0-linux-x86_64", dest); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+}
+
+func TestMultiSourceMissingAsset(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	source, err := newSources(releaseServer(t, 0, http.StatusOK).URL, []string{releaseServer(t, 0, http.StatusOK).URL})
+	if err != nil {
+		t.Fatalf("newSources failed: %v", err)
+	}
+
+	// Missing everywhere keeps errAssetNotFound, so other names are tried
+	err = source.FetchAsset("v1.0.0", "vibe-v1.0.0-plan9-mips", filepath.Join(t.TempDir(), "vibe"))
+	if !errors.Is(err, errAssetNotFound) {
+		t.Errorf("FetchAsset(missing) = %v, want errAssetNotFound", err)
+	}
+}
+
+func TestNewSourcesWithoutMirrors(t *testing.T) {
+	source, err := newSources("", nil)
+	if err != nil {
+		t.Fatalf("newSources failed: %v", err)
+	}
+	if _, ok := source.(*multiSource); ok {
+		t.Error("a single source should not be wrapped")
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OCI media types accepted when resolving artifact manifests
+const (
+	OCI_MANIFEST_MEDIA_TYPE    = "application/vnd.oci.image.manifest.v1+json"
+	DOCKER_MANIFEST_MEDIA_TYPE = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// OCI_TITLE_ANNOTATION names the file a layer was pushed from (set by oras push)
+	OCI_TITLE_ANNOTATION = "org.opencontainers.image.title"
+)
+
+// ociSource pulls release assets published as OCI artifacts (oras push).
+// Each release is a tag of the repository whose layers are the release
+// assets, named by their org.opencontainers.image.title annotation.
+// Grammars live in the <repository>/grammars/<pkg> repository, tagged by
+// grammar version.
+//
+// Registry credentials are read from VIBE_REGISTRY_USER/VIBE_REGISTRY_TOKEN
+// or `auth login <registry>`, falling back to the GitHub token for ghcr.io;
+// anonymous pulls are used otherwise.
+type ociSource struct {
+	registry   string            // host[:port]
+	repository string            // e.g. vhybzos/vibe
+	scheme     string            // https, or http for oci+http:// sources
+	tokens     map[string]string // bearer token per repository scope
+}
+
+// ociManifest is the subset of an OCI image manifest used by the installer
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor describes a content-addressed blob
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// newOCISource parses oci://registry/repository
+func newOCISource(u *url.URL) (*ociSource, error) {
+	repository := strings.Trim(u.Path, "/")
+	if u.Host == "" || repository == "" {
+		return nil, fmt.Errorf("oci source must be oci://<registry>/<repository>")
+	}
+
+	scheme := "https"
+	if u.Scheme == "oci+http" {
+		scheme = "http"
+	}
+
+	return &ociSource{
+		registry:   u.Host,
+		repository: strings.ToLower(repository),
+		scheme:     scheme,
+		tokens:     map[string]string{},
+	}, nil
+}
+
+func (o *ociSource) Name() string {
+	return fmt.Sprintf("oci://%s/%s", o.registry, o.repository)
+}
+
+func (o *ociSource) LatestVersion() (string, error) {
+	resp, err := o.get(o.repository, "/tags/list", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags of %s: %w", o.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	var tags struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return "", fmt.Errorf("failed to parse tag list: %w", err)
+	}
+
+	version, ok := pickVersion(tags.Tags, opts.IncludePrereleases)
+	if !ok {
+		return "", fmt.Errorf("no release tags found in %s", o.Name())
+	}
+	return version, nil
+}
+
+func (o *ociSource) FetchAsset(version, asset, destPath string) error {
+	return o.fetch(o.repository, version, asset, destPath)
+}
+
+func (o *ociSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return o.fetch(o.repository+"/grammars/"+pkg, version, file, destPath)
+}
+
+// fetch resolves the artifact manifest for tag and downloads the layer
+// titled file, verifying its digest
+func (o *ociSource) fetch(repository, tag, file, destPath string) error {
+	fmt.Printf("🔗 Pulling %s from %s/%s:%s\n", file, o.registry, repository, tag)
+
+	resp, err := o.get(repository, "/manifests/"+tag, OCI_MANIFEST_MEDIA_TYPE+", "+DOCKER_MANIFEST_MEDIA_TYPE)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s:%s: %w", repository, tag, err)
+	}
+	defer resp.Body.Close()
+
+	var manifest ociManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest of %s:%s: %w", repository, tag, err)
+	}
+
+	layer, ok := findOCILayer(manifest, file)
+	if !ok {
+		return fmt.Errorf("artifact %s:%s has no layer titled %s: %w", repository, tag, file, errAssetNotFound)
+	}
+
+	blob, err := o.get(repository, "/blobs/"+layer.Digest, "")
+	if err != nil {
+		return fmt.Errorf("failed to download blob %s: %w", layer.Digest, err)
+	}
+	defer blob.Body.Close()
+
+	return saveVerifiedBlob(blob.Body, layer, destPath)
+}
+
+// findOCILayer returns the manifest layer whose title annotation is file
+func findOCILayer(manifest ociManifest, file string) (ociDescriptor, bool) {
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[OCI_TITLE_ANNOTATION] == file {
+			return layer, true
+		}
+	}
+	return ociDescriptor{}, false
+}
+
+// saveVerifiedBlob writes r to destPath and checks it against the
+// descriptor's sha256 digest and size, removing the file on mismatch
+func saveVerifiedBlob(r io.Reader, desc ociDescriptor, destPath string) error {
+	algo, want, found := strings.Cut(desc.Digest, ":")
+	if !found || algo != "sha256" {
+		return fmt.Errorf("unsupported digest %q", desc.Digest)
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	progressWriter := &ProgressWriter{
+		Writer: io.MultiWriter(out, hasher),
+		total:  desc.Size,
+		task:   progress.download(filepath.Base(destPath), desc.Size),
+	}
+	written, err := io.Copy(progressWriter, r)
+	progressWriter.task.finish(err)
+	if err != nil {
+		return fmt.Errorf("failed to save blob: %w", err)
+	}
+
+	got := hex.EncodeToString(hasher.Sum(nil))
+	if got != want || (desc.Size > 0 && written != desc.Size) {
+		out.Close()
+		os.Remove(destPath)
+		return fmt.Errorf("digest mismatch: expected sha256:%s (%d bytes), got sha256:%s (%d bytes)", want, desc.Size, got, written)
+	}
+
+	fmt.Printf("✅ Digest verified: %s\n", desc.Digest)
+	return nil
+}
+
+// get performs an authenticated registry API request for repository,
+// obtaining a bearer token on the first 401 challenge
+func (o *ociSource) get(repository, apiPath, accept string) (*http.Response, error) {
+	endpoint := fmt.Sprintf("%s://%s/v2/%s%s", o.scheme, o.registry, repository, apiPath)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if token := o.tokens[repository]; token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := httpDo(req, opts.DownloadTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			token, err := o.fetchToken(challenge, repository)
+			if err != nil {
+				return nil, err
+			}
+			o.tokens[repository] = token
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("registry returned status: %d %s", resp.StatusCode, resp.Status)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("registry rejected credentials for %s", repository)
+}
+
+// fetchToken exchanges a Bearer WWW-Authenticate challenge for a pull token
+func (o *ociSource) fetchToken(challenge, repository string) (string, error) {
+	params := parseAuthChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry requires authentication but sent no bearer realm")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", realm, err)
+	}
+	q := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	q.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+	tokenURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if user, secret := o.credentials(); secret != "" {
+		req.SetBasicAuth(user, secret)
+	}
+
+	resp, err := httpDo(req, opts.DownloadTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token request failed with status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// credentials returns registry basic-auth credentials from the environment
+// or the keychain
+func (o *ociSource) credentials() (user, secret string) {
+	if secret := os.Getenv("VIBE_REGISTRY_TOKEN"); secret != "" {
+		return os.Getenv("VIBE_REGISTRY_USER"), secret
+	}
+	if stored := hostCredential(o.registry); stored != "" {
+		if user, secret, ok := strings.Cut(stored, ":"); ok {
+			return user, secret
+		}
+		return "token", stored
+	}
+	if o.registry == "ghcr.io" {
+		if secret := githubToken(); secret != "" {
+			return "token", secret
+		}
+	}
+	return "", ""
+}
+
+// parseAuthChallenge parses `Bearer realm="...",service="...",scope="..."`
+func parseAuthChallenge(challenge string) map[string]string {
+	params := map[string]string{}
+	scheme, rest, found := strings.Cut(strings.TrimSpace(challenge), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return params
+	}
+
+	for rest != "" {
+		var pair string
+		// Values are quoted and may contain commas (scope lists)
+		if eq := strings.Index(rest, "=\""); eq >= 0 {
+			end := strings.Index(rest[eq+2:], "\"")
+			if end < 0 {
+				break
+			}
+			pair = rest[:eq+2+end+1]
+			rest = strings.TrimLeft(rest[len(pair):], ", ")
+		} else {
+			pair, rest, _ = strings.Cut(rest, ",")
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		params[strings.ToLower(key)] = strings.Trim(value, "\"")
+	}
+	return params
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFakeRegistry serves a token-protected OCI registry with a single
+// artifact tag containing blob as a layer titled "vibe"
+func newFakeRegistry(t *testing.T, blob []byte) *httptest.Server {
+	t.Helper()
+	sum := sha256.Sum256(blob)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if got := r.URL.Query().Get("scope"); got != "repository:acme/vibe:pull" {
+				t.Errorf("token scope = %v, want repository:acme/vibe:pull", got)
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:acme/vibe:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/acme/vibe/tags/list":
+			json.NewEncoder(w).Encode(map[string]any{"tags": []string{"v0.7.9", "v0.7.27", "latest"}})
+		case "/v2/acme/vibe/manifests/v0.7.27":
+			json.NewEncoder(w).Encode(ociManifest{Layers: []ociDescriptor{{
+				Digest:      digest,
+				Size:        int64(len(blob)),
+				Annotations: map[string]string{OCI_TITLE_ANNOTATION: "vibe"},
+			}}})
+		case "/v2/acme/vibe/blobs/" + digest:
+			w.Write(blob)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOCISource(t *testing.T) {
+	blob := []byte("vibe binary contents")
+	server := newFakeRegistry(t, blob)
+
+	source, err := newSource("oci+http://" + strings.TrimPrefix(server.URL, "http://") + "/acme/vibe")
+	if err != nil {
+		t.Fatalf("newSource failed: %v", err)
+	}
+
+	t.Run("latest version from tags", func(t *testing.T) {
+		version, err := source.LatestVersion()
+		if err != nil {
+			t.Fatalf("LatestVersion failed: %v", err)
+		}
+		if version != "v0.7.27" {
+			t.Errorf("LatestVersion() = %v, want v0.7.27", version)
+		}
+	})
+
+	t.Run("fetch verifies digest", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "vibe")
+		if err := source.FetchAsset("v0.7.27", "vibe", dest); err != nil {
+			t.Fatalf("FetchAsset failed: %v", err)
+		}
+		got, _ := os.ReadFile(dest)
+		if string(got) != string(blob) {
+			t.Errorf("downloaded content = %q, want %q", got, blob)
+		}
+	})
+
+	t.Run("missing layer", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "other")
+		if err := source.FetchAsset("v0.7.27", "other", dest); err == nil {
+			t.Error("Expected error for missing layer")
+		}
+	})
+}
+
+func TestSaveVerifiedBlobMismatch(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "blob")
+	desc := ociDescriptor{Digest: "sha256:" + strings.Repeat("0", 64), Size: 4}
+
+	err := saveVerifiedBlob(strings.NewReader("evil"), desc, dest)
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("Expected digest mismatch error, got: %v", err)
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Error("Expected mismatched blob to be removed")
+	}
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	params := parseAuthChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:a/b:pull,push"`)
+
+	expected := map[string]string{
+		"realm":   "https://ghcr.io/token",
+		"service": "ghcr.io",
+		"scope":   "repository:a/b:pull,push",
+	}
+	for key, want := range expected {
+		if params[key] != want {
+			t.Errorf("parseAuthChallenge()[%s] = %v, want %v", key, params[key], want)
+		}
+	}
+}
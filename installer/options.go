@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+// Compile cache modes (--compile-cache, build.compile_cache setting)
+const (
+	COMPILE_CACHE_AUTO    = "auto"    // use sccache when it is already installed
+	COMPILE_CACHE_SCCACHE = "sccache" // install sccache first when missing
+	COMPILE_CACHE_OFF     = "off"
+)
+
+// Options holds the command-line configuration of an installer run
+type Options struct {
+	Source  string   // release source URL, empty for GitHub releases
+	BaseURL string   // static mirror URL, shorthand for an http(s) --source
+	Mirrors []string // extra sources downloads may come from, fastest first
+
+	ManifestKey     string // ed25519 public key release manifests must be signed with
+	SignKey         string // mirror: ed25519 private key signing the manifest
+	Paranoid        bool   // require two independent digest sources to agree
+	RetryMismatch   bool   // download an asset once more when it fails its checksum
+	NoVerify        bool   // install signed assets without checking their cosign signature
+	AllowUnsigned   bool   // install assets the release publishes no cosign signature for
+	AllowUnverified bool   // install release assets and grammars no checksum is published for
+	CosignIdentity  string // certificate identity regexp cosign signatures must carry
+
+	IncludePrereleases bool   // resolve rc/beta releases as well as stable ones
+	AssetTemplate      string // Go template naming release assets
+
+	ForgeAPI          string // release API base of a forge source, empty for the forge's default
+	ForgeReleasesPath string // forge API path templates, empty for the forge's defaults
+	ForgeLatestPath   string
+	ForgeReleasePath  string
+
+	Changelog       string // release notes display mode when upgrading
+	AllowBreaking   bool   // upgrade across breaking releases without asking
+	RollbackOnError bool   // restore the previous binary (only the binary) when an upgrade fails validation
+	ValidateCommand string // command validating an upgrade, run after the smoke test
+	SkipMigrations  bool   // do not run versioned migration steps
+	RetryFailed     bool   // only reinstall modules that failed last run
+	Static          bool   // install the static (musl) Linux build
+	Prebuilt        bool   // download prebuilt dependencies instead of compiling
+	AllowRoot       bool   // install as root, into ROOT_INSTALL_PATH
+	WSLWindows      bool   // under WSL, also install the Windows binary
+
+	CompileCache    string // sccache mode for cargo builds
+	CompileCacheDir string // shared sccache directory, empty for sccache's default
+	CargoRegistry   string // vendored crates directory or registry mirror URL
+	CargoOffline    bool   // never let cargo touch the network
+	CargoPrefetch   bool   // download crates with retries before compiling
+
+	Timeout         time.Duration // overall deadline of the run, 0 for none
+	APITimeout      time.Duration // per release metadata request
+	DownloadTimeout time.Duration // per file download
+	StallTimeout    time.Duration // longest wait for the next byte, 0 for no stall detection
+	FlakyNetwork    bool          // chunked, resumable downloads with many retries
+	UserAgentSuffix string        // appended to the User-Agent of every request
+	OrgTag          string        // sent in ORG_TAG_HEADER with every request, empty for none
+	P2P             bool          // fetch large assets over BitTorrent when the release has torrents
+
+	Metered          string // whether the connection is metered: auto, on or off
+	MeteredThreshold int64  // bytes downloaded on a metered connection without confirmation
+	AllowMetered     bool   // download over a metered connection without asking
+
+	NoCache      bool   // bypass the download cache
+	CacheMaxSize int64  // download cache size limit in bytes
+	SharedCache  string // shared cache URL or path, overridden by VIBE_CACHE_URL
+
+	EncryptData bool   // keep indexes and databases in an encrypted container
+	Container   string // container defaults: auto, on or off
+	DBEndpoint  string // SurrealDB server vibe uses, health checked after setup
+
+	Yes          bool // answer yes to consent prompts
+	NoModifyPath bool // never edit shell profiles or the registry PATH
+	NoInit       bool // skip vibe's first-run setup after install
+	Force        bool // reinstall even when the installed state matches
+	Insecure     bool // self-update: replace the installer without a digest or signature
+	InPlace      bool // update: only replace the binary and modules that changed
+
+	SimulateFailures []SimulatedFailure // steps to fail on purpose, for QA and support
+
+	Skip             []string // optional components deselected in the menu
+	ComponentsChosen bool     // the menu's choice is saved in the config
+	ChooseComponents bool     // show the menu again
+
+	Report         string         // install report location, empty for the default
+	MetricsPushURL string         // Pushgateway receiving install metrics, empty for none
+	Modules        []CustomModule // user-defined modules from the config file
+
+	Version   string        // release to mirror, or to install from a setup, instead of the latest
+	FromSetup string        // setup descriptor written by export-setup to reproduce
+	WaitFor   string        // release tag to wait for before installing it
+	Poll      time.Duration // how often --wait-for checks the release
+	Purge     bool          // uninstall: also remove dependencies and user data
+	WithToken bool          // auth: read the GitHub token from stdin instead of the device flow
+	AuthScope string        // auth: OAuth scopes requested from GitHub
+	Scan      bool          // cleanup: scan for leftovers
+	Check     bool          // latest, install: report via exit status whether anything would change
+	JSON      bool          // version: print machine-readable output
+}
+
+// opts is the configuration of the current run, seeded from the config
+// file before flags are parsed
+var opts = Options{
+	AssetTemplate:    DEFAULT_ASSET_TEMPLATE,
+	CosignIdentity:   DEFAULT_COSIGN_IDENTITY,
+	Changelog:        CHANGELOG_SUMMARY,
+	CompileCache:     COMPILE_CACHE_AUTO,
+	Container:        CONTAINER_AUTO,
+	APITimeout:       DEFAULT_API_TIMEOUT,
+	DownloadTimeout:  DEFAULT_DOWNLOAD_TIMEOUT,
+	StallTimeout:     DEFAULT_STALL_TIMEOUT,
+	CacheMaxSize:     DEFAULT_CACHE_MAX_SIZE,
+	Metered:          METERED_AUTO,
+	MeteredThreshold: DEFAULT_METERED_THRESHOLD,
+	Poll:             DEFAULT_POLL_INTERVAL,
+}
+
+// addSourceFlags registers the flags selecting where releases come from
+func addSourceFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.Source, "source", opts.Source, "release source URL (s3://, gs://, az://, oci://, https://); defaults to GitHub releases")
+	fs.StringVar(&opts.BaseURL, "base-url", opts.BaseURL, "base URL of a static release mirror created with the mirror command")
+	fs.Func("mirror", "extra release source to download from when it is faster (repeatable)", func(v string) error {
+		opts.Mirrors = append(opts.Mirrors, v)
+		return nil
+	})
+	fs.StringVar(&opts.ManifestKey, "manifest-key", opts.ManifestKey, "PEM ed25519 public key; only install releases whose manifest it signed")
+	fs.BoolVar(&opts.Paranoid, "paranoid", opts.Paranoid, "only install files whose digest two independent sources (SHA256SUMS, release manifest, release API) agree on")
+	fs.BoolVar(&opts.RetryMismatch, "retry-mismatch", opts.RetryMismatch, "download an asset once more, bypassing the cache, when it does not match its published checksum")
+	fs.BoolVar(&opts.NoVerify, "no-verify", opts.NoVerify, "install signed assets without verifying their cosign signature")
+	fs.BoolVar(&opts.AllowUnsigned, "allow-unsigned", opts.AllowUnsigned, "install assets the release publishes no cosign signature for, e.g. releases from before signing")
+	fs.BoolVar(&opts.AllowUnverified, "allow-unverified", opts.AllowUnverified, "install assets and grammars no checksum is published for, only checking that they are not empty")
+	fs.StringVar(&opts.CosignIdentity, "cosign-identity", opts.CosignIdentity, "regexp the certificate identity of cosign signatures must match")
+	fs.BoolVar(&opts.IncludePrereleases, "include-prereleases", opts.IncludePrereleases, "consider prerelease (rc, beta) versions when resolving the latest release")
+	fs.StringVar(&opts.ForgeAPI, "forge-api", opts.ForgeAPI, "release API base URL of a github+, gitea+, forgejo+ or gitlab+ source, when not the forge's default")
+	fs.Func("asset-template", "Go template naming release assets (default "+DEFAULT_ASSET_TEMPLATE+")", func(v string) error {
+		if err := validateAssetTemplate(v); err != nil {
+			return err
+		}
+		opts.AssetTemplate = v
+		return nil
+	})
+}
+
+// sourceSpec returns the release source selected on the command line
+func (o Options) sourceSpec() string {
+	if o.Source == "" {
+		return o.BaseURL
+	}
+	return o.Source
+}
+
+// installFlags registers the flags of the default install command
+func installFlags(fs *flag.FlagSet) {
+	addSourceFlags(fs)
+	cacheFlags(fs)
+	fs.BoolFunc("no-changelog", "do not show release notes when upgrading", func(string) error {
+		opts.Changelog = CHANGELOG_NONE
+		return nil
+	})
+	fs.BoolVar(&opts.AllowBreaking, "allow-breaking", opts.AllowBreaking, "upgrade across major or breaking releases without confirmation")
+	fs.BoolVar(&opts.RollbackOnError, "rollback-on-error", opts.RollbackOnError, "smoke test the upgraded binary and run --validate-cmd, restoring the previous binary if either fails; the data directory and receipt keep any migration that ran")
+	fs.StringVar(&opts.ValidateCommand, "validate-cmd", opts.ValidateCommand, "command validating an upgrade with --rollback-on-error, e.g. \"vibe index --dry-run\"")
+	fs.BoolVar(&opts.SkipMigrations, "skip-migrations", opts.SkipMigrations, "do not run upgrade migration steps (experts only)")
+	fs.StringVar(&opts.FromSetup, "from-setup", opts.FromSetup, "reproduce the versions, grammars and settings of a file written by export-setup (its settings win)")
+	fs.StringVar(&opts.WaitFor, "wait-for", opts.WaitFor, "wait until this release tag publishes every platform binary, then install it (bounded by --timeout)")
+	fs.DurationVar(&opts.Poll, "poll", opts.Poll, "how often --wait-for checks the release")
+	fs.BoolVar(&opts.Check, "check", opts.Check, "change nothing: exit 0 when the system already matches, 2 after listing what an install would change")
+	fs.BoolVar(&opts.ChooseComponents, "choose-components", opts.ChooseComponents, "show the menu of optional components again, saving the new selection")
+	fs.BoolVar(&opts.Force, "force", opts.Force, "reinstall even when vibe and its dependencies already match the target versions and checksums")
+	fs.BoolVar(&opts.RetryFailed, "retry-failed", opts.RetryFailed, "only reinstall the modules that failed in the previous run")
+	fs.BoolVar(&opts.AllowMetered, "allow-metered", opts.AllowMetered, "download over a metered connection without asking, whatever the size")
+	fs.BoolVar(&opts.P2P, "p2p", opts.P2P, "download large assets from peers with "+P2P_CLIENT+" when the release publishes torrents (checksummed, HTTPS fallback)")
+	fs.BoolVar(&opts.Static, "static", opts.Static, "install the fully static (musl) build on Linux, for old or non-glibc systems")
+	cargoFlags(fs)
+	fs.StringVar(&opts.Container, "container", opts.Container, "container defaults (no prompts or PATH edits, /usr/local/bin as root): auto, on or off")
+	fs.BoolVar(&opts.AllowRoot, "allow-root", opts.AllowRoot, "allow installing as root, into "+ROOT_INSTALL_PATH+" (for containers)")
+	fs.BoolVar(&opts.WSLWindows, "wsl-windows", opts.WSLWindows, "under WSL, also install the Windows binary for the Windows user")
+	fs.BoolVar(&opts.EncryptData, "encrypt-data", opts.EncryptData, "keep vibe's indexes and databases encrypted at rest, with the key in the OS keychain")
+	fs.StringVar(&opts.DBEndpoint, "db-endpoint", opts.DBEndpoint, "SurrealDB server vibe uses, e.g. http://127.0.0.1:8000, to health check after setup")
+	fs.BoolVar(&opts.NoInit, "no-init", opts.NoInit, "do not run vibe's first-run setup (vibe init --defaults) after install")
+	fs.BoolVar(&opts.NoModifyPath, "no-modify-path", opts.NoModifyPath, "do not add the install directory to PATH")
+	fs.StringVar(&opts.Report, "report", opts.Report, "where to write the JSON install report (default ~/.vibe/"+INSTALL_REPORT_FILE+")")
+	fs.StringVar(&opts.MetricsPushURL, "metrics-push-url", opts.MetricsPushURL, "Prometheus Pushgateway URL to report install duration, outcome and versions to")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Releases may publish a BitTorrent metainfo file next to each large asset,
+// <asset>.torrent, whose url-list web seeds point back at the HTTPS
+// download. With --p2p those assets are fetched with aria2c, so machines
+// installing at the same time share pieces instead of all hitting the
+// origin. Peers are untrusted: an asset is only fetched this way when the
+// release publishes its sha256, and anything that fails falls back to the
+// regular download.
+const (
+	P2P_CLIENT      = "aria2c"
+	P2P_TORRENT_EXT = ".torrent"
+)
+
+// p2pSource fetches large assets over BitTorrent when the release
+// publishes a torrent and a digest for them
+type p2pSource struct {
+	Source
+
+	mu      sync.Mutex
+	release string                       // version being installed, whose release carries the grammar torrents
+	digests map[string]map[string]string // release digests by version
+}
+
+// withP2P wraps a source in peer-to-peer downloads when --p2p is set and
+// aria2c is available
+func withP2P(source Source) Source {
+	if !opts.P2P {
+		return source
+	}
+	if _, err := exec.LookPath(P2P_CLIENT); err != nil {
+		warnf("--p2p needs %s in PATH; downloading over HTTPS", P2P_CLIENT)
+		return source
+	}
+	fmt.Printf("🧲 Peer-to-peer downloads enabled for large assets\n")
+	return &p2pSource{Source: source, digests: map[string]map[string]string{}}
+}
+
+// LatestVersion remembers the release being installed
+func (s *p2pSource) LatestVersion() (string, error) {
+	latest, err := s.Source.LatestVersion()
+	if err == nil {
+		s.mu.Lock()
+		s.release = latest
+		s.mu.Unlock()
+	}
+	return latest, err
+}
+
+// ReleaseNotes passes release notes through when the source has them
+func (s *p2pSource) ReleaseNotes(version string) (string, error) {
+	notes, ok := s.Source.(ReleaseNotesSource)
+	if !ok {
+		return "", nil
+	}
+	return notes.ReleaseNotes(version)
+}
+
+func (s *p2pSource) FetchAsset(version, asset, destPath string) error {
+	if s.fetchPeers(version, asset, destPath) {
+		return nil
+	}
+	return s.Source.FetchAsset(version, asset, destPath)
+}
+
+func (s *p2pSource) FetchGrammar(pkg, version, file, destPath string) error {
+	s.mu.Lock()
+	release := s.release
+	s.mu.Unlock()
+	if release != "" && s.fetchPeers(release, file, destPath) {
+		return nil
+	}
+	return s.Source.FetchGrammar(pkg, version, file, destPath)
+}
+
+// p2pEligible reports whether an asset is worth fetching from peers;
+// checksums, manifests and torrents are small and needed to trust the rest
+func p2pEligible(asset string) bool {
+	return asset != "SHA256SUMS" && asset != MIRROR_MANIFEST && asset != MANIFEST_SIGNATURE && !strings.HasSuffix(asset, P2P_TORRENT_EXT)
+}
+
+// digest returns the sha256 the release publishes for an asset
+func (s *p2pSource) digest(version, asset string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digests, ok := s.digests[version]
+	if !ok {
+		digests, _ = releaseDigests(s.Source, version) // the install fails on its own lookup
+		s.digests[version] = digests
+	}
+	return digests[asset]
+}
+
+// fetchPeers downloads an asset through its torrent and verifies it,
+// reporting whether destPath now holds the asset. Failures are warned
+// about and left to the HTTPS fallback.
+func (s *p2pSource) fetchPeers(version, asset, destPath string) bool {
+	if !p2pEligible(asset) {
+		return false
+	}
+	digest := s.digest(version, asset)
+	if digest == "" {
+		return false // nothing to check pieces from untrusted peers against
+	}
+
+	torrent, err := quarantinePath(asset + P2P_TORRENT_EXT)
+	if err != nil {
+		return false
+	}
+	defer os.Remove(torrent)
+	if err := s.Source.FetchAsset(version, asset+P2P_TORRENT_EXT, torrent); err != nil {
+		return false
+	}
+
+	fmt.Printf("🧲 Downloading %s from peers...\n", asset)
+	if err := downloadTorrent(torrent, asset, digest, destPath); err != nil {
+		warnf("Peer-to-peer download of %s failed, falling back to HTTPS: %v", asset, err)
+		return false
+	}
+	fmt.Printf("✅ Downloaded %s from peers\n", asset)
+	return true
+}
+
+// downloadTorrent runs aria2c on a torrent in a scratch directory and moves
+// the file to destPath once its digest matches
+func downloadTorrent(torrent, asset, digest, destPath string) error {
+	dir, err := os.MkdirTemp(getQuarantineDir(), "p2p-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(runCtx, opts.DownloadTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, P2P_CLIENT,
+		"--dir="+dir,
+		"--seed-time=0",
+		"--bt-enable-lpd=true", // find peers on the local network
+		"--enable-dht=true",
+		"--follow-torrent=true",
+		"--summary-interval=0",
+		"--console-log-level=warn",
+		torrent)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", P2P_CLIENT, err)
+	}
+
+	path := filepath.Join(dir, asset)
+	got, _, err := fileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("torrent did not contain %s: %w", asset, err)
+	}
+	if !strings.EqualFold(got, digest) {
+		return fmt.Errorf("checksum mismatch: got %s, release publishes %s", short(got), short(digest))
+	}
+	if err := os.Rename(path, destPath); err != nil {
+		return copyFile(path, destPath, MODE_DATA)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// assetSource serves fixed release assets
+type assetSource struct {
+	assets map[string]string
+}
+
+func (s assetSource) Name() string { return "assets" }
+
+func (s assetSource) LatestVersion() (string, error) { return "v1.0.0", nil }
+
+func (s assetSource) FetchAsset(version, asset, destPath string) error {
+	content, ok := s.assets[asset]
+	if !ok {
+		return fmt.Errorf("%s: %w", asset, errAssetNotFound)
+	}
+	return os.WriteFile(destPath, []byte(content), 0644)
+}
+
+func (s assetSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return os.WriteFile(destPath, wasmMagic, 0644)
+}
+
+// fakeAria2c puts a script on PATH that "downloads" content into --dir
+func fakeAria2c(t *testing.T, asset, content string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake aria2c is a shell script")
+	}
+	bin := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nfor a in \"$@\"; do case $a in --dir=*) dir=${a#--dir=};; esac; done\nprintf %%s '%s' > \"$dir/%s\"\n", content, asset)
+	if err := os.WriteFile(filepath.Join(bin, P2P_CLIENT), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestP2PSource(t *testing.T) {
+	const asset = "vibe-v1.0.0-linux-x86_64"
+	published := assetSource{assets: map[string]string{
+		asset:                   "from https",
+		asset + P2P_TORRENT_EXT: "d8:announce0:e",
+		"unchecked":             "from https",
+		"unchecked.torrent":     "d8:announce0:e",
+		"tampered":              "from https",
+		"tampered.torrent":      "d8:announce0:e",
+		"SHA256SUMS":            sha256Hex("from peers") + "  " + asset + "\n" + sha256Hex("from https") + "  tampered\n",
+	}}
+
+	tests := []struct {
+		name  string
+		asset string
+		peers string
+		want  string
+	}{
+		{"verified download from peers", asset, "from peers", "from peers"},
+		{"no published digest uses https", "unchecked", "from peers", "from https"},
+		{"checksum mismatch falls back to https", "tampered", "from peers", "from https"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VIBE_HOME", t.TempDir())
+			fakeAria2c(t, tt.asset, tt.peers)
+			opts.P2P = true
+			defer func() { opts.P2P = false }()
+
+			source := withP2P(published)
+			if _, ok := source.(*p2pSource); !ok {
+				t.Fatalf("withP2P did not wrap the source")
+			}
+			dest := filepath.Join(t.TempDir(), tt.asset)
+			if err := source.FetchAsset("v1.0.0", tt.asset, dest); err != nil {
+				t.Fatalf("FetchAsset failed: %v", err)
+			}
+			if got, _ := os.ReadFile(dest); string(got) != tt.want {
+				t.Errorf("downloaded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithP2PNeedsClient(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	opts.P2P = true
+	defer func() { opts.P2P = false }()
+
+	source := assetSource{}
+	if _, ok := withP2P(source).(*p2pSource); ok {
+		t.Error("withP2P wrapped the source without " + P2P_CLIENT + " in PATH")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PATH_PROBE_MARKER prefixes the PATH printed by the login-shell probe so
+// it can be told apart from anything rc files echo
+const PATH_PROBE_MARKER = "__VIBE_PATH__="
+
+// loginShellPath returns PATH as a fresh login shell of the user sees it,
+// which is what matters for "command not found" and can differ from the
+// installer's own environment. On Windows it is the machine PATH followed
+// by the user PATH, as new processes receive it.
+func loginShellPath() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if runtime.GOOS == "windows" {
+		out, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command",
+			"[Environment]::GetEnvironmentVariable('Path', 'Machine') + ';' + [Environment]::GetEnvironmentVariable('Path', 'User')").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read PATH from the registry: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	script := `printf '` + PATH_PROBE_MARKER + `%s\n' "$PATH"`
+	if filepath.Base(shell) == "fish" {
+		script = `printf '` + PATH_PROBE_MARKER + `%s\n' (string join : $PATH)`
+	}
+
+	cmd := exec.CommandContext(ctx, shell, "-l", "-i", "-c", script)
+	cmd.Stdin = nil
+	out, err := cmd.Output()
+	if path, ok := parsePathProbe(string(out)); ok {
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to start login shell %s: %w", shell, err)
+	}
+	return "", fmt.Errorf("login shell %s did not report PATH", shell)
+}
+
+// parsePathProbe extracts the probed PATH from login-shell output
+func parsePathProbe(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		if i := strings.Index(line, PATH_PROBE_MARKER); i >= 0 {
+			return strings.TrimSpace(line[i+len(PATH_PROBE_MARKER):]), true
+		}
+	}
+	return "", false
+}
+
+// verifyPathOrder checks that installPath is on the login shell's PATH
+// ahead of every directory holding another vibe, and offers to move the
+// installer's rc block last so its prepend wins
+func verifyPathOrder(installPath, filename string, receipt *Receipt) error {
+	pathList, err := loginShellPath()
+	if err != nil {
+		return err
+	}
+
+	if !pathContains(pathList, installPath) {
+		fmt.Printf("⚠️  New shells will not find vibe: %s is not on your login PATH\n", installPath)
+		return nil
+	}
+
+	var shadowing []PathConflict
+	for _, c := range findPathConflicts(pathList, installPath, filename) {
+		if c.Shadows {
+			shadowing = append(shadowing, c)
+		}
+	}
+	if len(shadowing) == 0 {
+		fmt.Printf("✅ PATH order verified: `vibe` resolves to %s\n", filepath.Join(installPath, filename))
+		return nil
+	}
+
+	fmt.Printf("⚠️  In new shells `vibe` resolves to %s, not %s\n", shadowing[0].Path, filepath.Join(installPath, filename))
+
+	if runtime.GOOS == "windows" {
+		fmt.Printf("💡 The machine PATH always precedes the user PATH; remove %s or reorder the system PATH as an administrator\n", shadowing[0].Path)
+		return nil
+	}
+	if opts.NoModifyPath {
+		fmt.Printf("💡 Move %s before %s in your PATH\n", installPath, filepath.Dir(shadowing[0].Path))
+		return nil
+	}
+	return movePathBlockLast(getShellProfile(), installPath, receipt)
+}
+
+// movePathBlockLast rewrites profile so the installer's PATH block is the
+// last thing it runs, making its prepend take precedence over earlier
+// PATH edits
+func movePathBlockLast(profile, installPath string, receipt *Receipt) error {
+	content, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", profile, err)
+	}
+
+	rest := string(content)
+	if start, end, found := findProfileBlock(rest); found {
+		rest = rest[:start] + rest[end:]
+	}
+	block := profileBlock(profile, installPath)
+	updated, _, _ := planProfileEdit(rest, block)
+	if updated == string(content) {
+		fmt.Printf("💡 %s already prepends %s last; another startup file (e.g. /etc/profile.d, ~/.zprofile) reorders PATH afterwards\n", profile, installPath)
+		return nil
+	}
+
+	fmt.Printf("\n🐚 Proposed change to %s (move the dotvibe PATH block to the end):\n\n", profile)
+	for _, line := range strings.Split(strings.TrimSuffix(block, "\n"), "\n") {
+		fmt.Printf("~ %s\n", line)
+	}
+	fmt.Printf("\n")
+	if !opts.Yes && !confirm(fmt.Sprintf("Apply this change to %s?", profile)) {
+		fmt.Printf("💡 Move %s before the conflicting directory in your PATH\n", installPath)
+		return nil
+	}
+
+	if err := os.WriteFile(profile, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", profile, err)
+	}
+	receipt.recordChange(EnvChange{Kind: CHANGE_RC_BLOCK, Path: profile, Value: installPath})
+	fmt.Printf("✅ Updated %s (restart your shell to pick it up)\n", profile)
+	return nil
+}
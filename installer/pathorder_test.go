@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsePathProbe(t *testing.T) {
+	out := "Welcome to zsh!\n" + PATH_PROBE_MARKER + "/usr/bin:/bin\n"
+	got, ok := parsePathProbe(out)
+	if !ok || got != "/usr/bin:/bin" {
+		t.Errorf("parsePathProbe() = %v, %v, want /usr/bin:/bin", got, ok)
+	}
+	if _, ok := parsePathProbe("no marker here"); ok {
+		t.Error("parsePathProbe() should fail without marker")
+	}
+}
+
+func TestMovePathBlockLast(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), ".bashrc")
+	block := profileBlock(profile, "/home/u/.local/bin")
+	os.WriteFile(profile, []byte("# top\n"+block+"export PATH=\"/opt/old:$PATH\"\n"), 0644)
+
+	saved := opts
+	defer func() { opts = saved }()
+	opts.Yes = true
+
+	receipt := &Receipt{}
+	if err := movePathBlockLast(profile, "/home/u/.local/bin", receipt); err != nil {
+		t.Fatalf("movePathBlockLast failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(profile)
+	if string(data) != "# top\nexport PATH=\"/opt/old:$PATH\"\n"+block {
+		t.Errorf("profile = %q", data)
+	}
+	if len(receipt.Changes) != 1 {
+		t.Errorf("receipt changes = %+v, want the rc block recorded", receipt.Changes)
+	}
+}
+
+func TestVerifyPathOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("login shell probing is Unix-only")
+	}
+
+	_, _, filename := detectPlatform()
+	old, install := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(old, filename), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(install, filename), []byte("#!/bin/sh\n"), 0755)
+
+	// A fake login shell reporting a PATH where the old copy wins
+	shell := filepath.Join(t.TempDir(), "fakesh")
+	script := "#!/bin/sh\necho '" + PATH_PROBE_MARKER + old + ":" + install + "'\n"
+	os.WriteFile(shell, []byte(script), 0755)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", shell)
+
+	saved := opts
+	defer func() { opts = saved }()
+	opts.Yes = true
+
+	receipt := &Receipt{}
+	if err := verifyPathOrder(install, filename, receipt); err != nil {
+		t.Fatalf("verifyPathOrder failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(home, ".profile"))
+	if !strings.HasSuffix(string(data), profileBlock(".profile", install)) {
+		t.Errorf(".profile = %q, want installer block last", data)
+	}
+}
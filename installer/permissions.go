@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Modes of everything the installer writes. They are applied with an
+// explicit chmod, so neither a restrictive nor a permissive umask changes
+// them.
+const (
+	MODE_BINARY      os.FileMode = 0755
+	MODE_DATA        os.FileMode = 0644
+	MODE_DIR         os.FileMode = 0755
+	MODE_PRIVATE_DIR os.FileMode = 0700 // vibe home: receipt, config, backups, cache
+)
+
+// posixModes reports whether file modes are meaningful on this platform
+func posixModes() bool {
+	return runtime.GOOS != "windows"
+}
+
+// ensureDir creates path with exactly mode, and any missing parents with
+// MODE_DIR. Directories that already exist are left as they are.
+func ensureDir(path string, mode os.FileMode) error {
+	var created []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		created = append(created, dir)
+	}
+
+	if err := os.MkdirAll(path, mode); err != nil {
+		return err
+	}
+	if !posixModes() {
+		return nil
+	}
+	for i, dir := range created {
+		dirMode := MODE_DIR
+		if i == 0 {
+			dirMode = mode
+		}
+		if err := os.Chmod(dir, dirMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileMode writes data to path with exactly mode
+func writeFileMode(path string, data []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	if !posixModes() {
+		return nil
+	}
+	return os.Chmod(path, mode)
+}
+
+// installedBinaries lists vibe and the standalone binaries recorded in the
+// receipt
+func installedBinaries(binaryPath string, receipt *Receipt) []string {
+	paths := []string{binaryPath}
+	for _, c := range receipt.Changes {
+		if c.Kind == CHANGE_FILE {
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
+// hardenPermissions resets the modes of an installation: binaries 0755,
+// data files 0644 in 0755 directories and a private vibe home. Downloads
+// are created under the user's umask, which may leave them writable by
+// everyone.
+func hardenPermissions(binaryPath, installPath string, receipt *Receipt) error {
+	if !posixModes() {
+		return nil
+	}
+
+	for _, path := range installedBinaries(binaryPath, receipt) {
+		if err := os.Chmod(path, MODE_BINARY); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to set permissions of %s: %w", path, err)
+		}
+	}
+
+	err := filepath.WalkDir(getDataDir(installPath), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			return nil
+		case d.IsDir():
+			return os.Chmod(path, MODE_DIR)
+		default:
+			return os.Chmod(path, MODE_DATA)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set permissions of the data directory: %w", err)
+	}
+
+	if err := os.Chmod(getVibeHome(), MODE_PRIVATE_DIR); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to set permissions of %s: %w", getVibeHome(), err)
+	}
+	return nil
+}
+
+// worldWritable lists files and directories under roots that any user on
+// the machine can modify. Symlinks are skipped; their mode is meaningless.
+func worldWritable(roots []string) ([]string, error) {
+	var found []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Mode().Perm()&0002 != 0 {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// checkPermissions flags world-writable installation files: anyone able to
+// write them can run code as the user
+func checkPermissions(receipt *Receipt) []string {
+	if !posixModes() {
+		return nil
+	}
+
+	_, _, filename := detectPlatform()
+	roots := installedBinaries(filepath.Join(receipt.InstallPath, filename), receipt)
+	roots = append(roots, getDataDir(receipt.InstallPath), getVibeHome())
+
+	found, err := worldWritable(roots)
+	if err != nil {
+		return []string{fmt.Sprintf("could not audit permissions: %v", err)}
+	}
+	var problems []string
+	for _, path := range found {
+		problems = append(problems, fmt.Sprintf("%s is world-writable (security issue; fix with chmod o-w)", path))
+	}
+	return problems
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureDirSetsModeOfCreatedDirectories(t *testing.T) {
+	if !posixModes() {
+		t.Skip("file modes are not meaningful on Windows")
+	}
+	parent := t.TempDir()
+	os.Chmod(parent, 0775)
+	path := filepath.Join(parent, "a", "b")
+
+	if err := ensureDir(path, MODE_PRIVATE_DIR); err != nil {
+		t.Fatalf("ensureDir failed: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != MODE_PRIVATE_DIR {
+		t.Errorf("%s has mode %v, want %v", path, info.Mode().Perm(), MODE_PRIVATE_DIR)
+	}
+	if info, _ := os.Stat(filepath.Join(parent, "a")); info.Mode().Perm() != MODE_DIR {
+		t.Errorf("Created parent has mode %v, want %v", info.Mode().Perm(), MODE_DIR)
+	}
+	if info, _ := os.Stat(parent); info.Mode().Perm() != 0775 {
+		t.Errorf("Existing parent changed to %v", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileModeOverridesExistingMode(t *testing.T) {
+	if !posixModes() {
+		t.Skip("file modes are not meaningful on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, nil, 0666)
+	os.Chmod(path, 0666)
+
+	if err := writeFileMode(path, []byte("x"), MODE_DATA); err != nil {
+		t.Fatalf("writeFileMode failed: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != MODE_DATA {
+		t.Errorf("Mode = %v, want %v", info.Mode().Perm(), MODE_DATA)
+	}
+}
+
+func TestHardenPermissionsClearsWorldWritable(t *testing.T) {
+	if !posixModes() {
+		t.Skip("file modes are not meaningful on Windows")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+	binary := filepath.Join(installPath, "vibe")
+	wasm := filepath.Join(getDataDir(installPath), "tree-sitter-typescript.wasm")
+	os.MkdirAll(filepath.Dir(wasm), 0755)
+	os.WriteFile(binary, nil, 0755)
+	os.WriteFile(wasm, nil, 0644)
+	os.Chmod(binary, 0777)
+	os.Chmod(wasm, 0666)
+
+	receipt := &Receipt{InstallPath: installPath}
+	if problems := checkPermissions(receipt); len(problems) != 2 {
+		t.Fatalf("Expected 2 world-writable files, got %v", problems)
+	}
+
+	if err := hardenPermissions(binary, installPath, receipt); err != nil {
+		t.Fatalf("hardenPermissions failed: %v", err)
+	}
+	if info, _ := os.Stat(binary); info.Mode().Perm() != MODE_BINARY {
+		t.Errorf("Binary mode = %v, want %v", info.Mode().Perm(), MODE_BINARY)
+	}
+	if info, _ := os.Stat(wasm); info.Mode().Perm() != MODE_DATA {
+		t.Errorf("Data mode = %v, want %v", info.Mode().Perm(), MODE_DATA)
+	}
+	if problems := checkPermissions(receipt); len(problems) != 0 {
+		t.Errorf("Still world-writable after hardening: %v", problems)
+	}
+}
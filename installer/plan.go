@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Assumptions behind the plan estimate. They only need to tell a
+// 30-second install from a 30-minute one.
+const (
+	PLAN_BANDWIDTH          = 5 << 20 // bytes per second assumed for downloads
+	PLAN_REFERENCE_CPUS     = 4       // machine the module build times are measured on
+	PLAN_DEFAULT_BUILD_TIME = 5 * time.Minute
+	PLAN_RUST_INSTALL_TIME  = 2 * time.Minute
+	PLAN_STEP_TIME          = 2 * time.Second // fixed cost of a download without a known size
+)
+
+// planStep is one step of an install, as previewed before it starts
+type planStep struct {
+	Action  string // what the step does, e.g. "Compile surrealdb v2.3.5"
+	Size    int64  // bytes downloaded, 0 when unknown or none
+	Compile bool
+	Time    time.Duration
+}
+
+// installPlan is the resolved list of steps of an install
+type installPlan struct {
+	Steps    []planStep
+	Prebuilt []string // compiled modules that --prebuilt would download instead
+}
+
+// buildTime scales a module's reference build time to this machine's cores
+func buildTime(m Module, cpus int) time.Duration {
+	base := m.BuildTime
+	if base == 0 {
+		base = PLAN_DEFAULT_BUILD_TIME
+	}
+	if cpus < 1 {
+		cpus = 1
+	}
+	if cpus > 4*PLAN_REFERENCE_CPUS {
+		cpus = 4 * PLAN_REFERENCE_CPUS // linking does not parallelise
+	}
+	return base * PLAN_REFERENCE_CPUS / time.Duration(cpus)
+}
+
+// downloadTime estimates a download of size bytes, 0 when unknown
+func downloadTime(size int64) time.Duration {
+	return PLAN_STEP_TIME + time.Duration(size)*time.Second/PLAN_BANDWIDTH
+}
+
+// hasPrebuilt reports whether --prebuilt replaces a compiled module with
+// a download
+func hasPrebuilt(m Module) bool {
+	return m.Cargo && !withPrebuilt([]Module{m})[0].Cargo
+}
+
+// planInstall lists the steps installing modules and the vibe binary
+// takes. haveCargo tells whether the Rust toolchain is already installed;
+// binarySize is the release asset's size, 0 when the source does not say.
+func planInstall(modules []Module, haveCargo bool, cpus int, binary string, binarySize int64) installPlan {
+	var plan installPlan
+	for _, m := range modules {
+		if m.Cargo && !haveCargo {
+			plan.Steps = append(plan.Steps, planStep{Action: "Install the Rust toolchain", Time: PLAN_RUST_INSTALL_TIME})
+			haveCargo = true
+		}
+		name := m.Name
+		if m.Version != "" {
+			name += " v" + strings.TrimPrefix(m.Version, "v")
+		}
+		if m.Cargo {
+			plan.Steps = append(plan.Steps, planStep{Action: "Compile " + name + " with cargo", Compile: true, Time: buildTime(m, cpus)})
+			if hasPrebuilt(m) {
+				plan.Prebuilt = append(plan.Prebuilt, m.Name)
+			}
+			continue
+		}
+		plan.Steps = append(plan.Steps, planStep{Action: "Download " + name, Time: downloadTime(0)})
+	}
+	plan.Steps = append(plan.Steps, planStep{Action: "Download " + binary, Size: binarySize, Time: downloadTime(binarySize)})
+	return plan
+}
+
+// downloadSize sums the known sizes of the plan's downloads
+func (p installPlan) downloadSize() int64 {
+	var size int64
+	for _, s := range p.Steps {
+		size += s.Size
+	}
+	return size
+}
+
+// total sums the estimated time of every step
+func (p installPlan) total() (total, compile time.Duration) {
+	for _, s := range p.Steps {
+		total += s.Time
+		if s.Compile {
+			compile += s.Time
+		}
+	}
+	return total, compile
+}
+
+// formatEstimate rounds a duration to what a rough estimate can promise
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "under a minute"
+	case d < time.Hour:
+		return fmt.Sprintf("~%d min", int(d.Round(time.Minute)/time.Minute))
+	}
+	d = d.Round(5 * time.Minute)
+	return fmt.Sprintf("~%d h %d min", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// print shows the plan with its estimate, pointing at --prebuilt when
+// compiling dominates
+func (p installPlan) print() {
+	fmt.Printf("📋 Plan:\n")
+	for i, s := range p.Steps {
+		detail := formatEstimate(s.Time)
+		if s.Size > 0 {
+			detail = mebibytes(s.Size) + ", " + detail
+		}
+		fmt.Printf("   %d. %-44s %s\n", i+1, s.Action, detail)
+	}
+
+	total, compile := p.total()
+	if compile == 0 {
+		fmt.Printf("⏱️  Estimated time: %s\n", formatEstimate(total))
+		return
+	}
+	fmt.Printf("⏱️  Estimated time: %s, %s of it compiling\n", formatEstimate(total), formatEstimate(compile))
+	if len(p.Prebuilt) > 0 {
+		fmt.Printf("💡 Rerun with --prebuilt to download %s instead of compiling it\n", strings.Join(p.Prebuilt, ", "))
+	}
+}
+
+// releaseAssetSize looks up the size of the first of names the source
+// lists for version, or 0 when it does not list assets
+func releaseAssetSize(source Source, version string, names []string) int64 {
+	lister, ok := source.(AssetLister)
+	if !ok {
+		return 0
+	}
+	assets, err := lister.ReleaseAssets(version)
+	if err != nil {
+		return 0
+	}
+	for _, name := range names {
+		for _, a := range assets {
+			if a.Name == name {
+				return a.Size
+			}
+		}
+	}
+	return 0
+}
+
+// previewInstall prints the plan of the install about to start, returning
+// it for the checks that depend on it
+func previewInstall(source Source, version string, assetNames []string) installPlan {
+	_, err := exec.LookPath("cargo")
+	size := releaseAssetSize(source, version, assetNames)
+	plan := planInstall(allModules(), err == nil, runtime.NumCPU(), "vibe "+version, size)
+	plan.print()
+	return plan
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPlanInstall(t *testing.T) {
+	modules := []Module{
+		{Name: "code2prompt", Cargo: true, Version: "3.0.2", BuildTime: 2 * time.Minute},
+		{Name: "surrealdb", Cargo: true, Version: "2.3.5", BuildTime: 20 * time.Minute},
+		{Name: "tree-sitter-typescript", Version: "0.23.2"},
+	}
+
+	plan := planInstall(modules, false, 8, "vibe v1.2.0", 50<<20)
+	want := []string{
+		"Install the Rust toolchain",
+		"Compile code2prompt v3.0.2 with cargo",
+		"Compile surrealdb v2.3.5 with cargo",
+		"Download tree-sitter-typescript v0.23.2",
+		"Download vibe v1.2.0",
+	}
+	if len(plan.Steps) != len(want) {
+		t.Fatalf("plan has %d steps, want %d: %+v", len(plan.Steps), len(want), plan.Steps)
+	}
+	for i, action := range want {
+		if plan.Steps[i].Action != action {
+			t.Errorf("step %d = %q, want %q", i+1, plan.Steps[i].Action, action)
+		}
+	}
+	if got := plan.Steps[2].Time; got != 10*time.Minute {
+		t.Errorf("surrealdb on 8 cores takes %v, want 10m", got)
+	}
+	if got := plan.Steps[4].Time; got != 12*time.Second {
+		t.Errorf("a 50 MB download takes %v, want 12s", got)
+	}
+	if _, compile := plan.total(); compile != 11*time.Minute {
+		t.Errorf("compile time = %v, want 11m", compile)
+	}
+	// Only the full installer has a prebuilt surrealdb, and only where its
+	// digest is pinned
+	wantPrebuilt := len(CARGO_MODULES) > 0 && SURREALDB_SHA256[runtime.GOOS+"/"+runtime.GOARCH] != ""
+	if wantPrebuilt != (len(plan.Prebuilt) == 1 && plan.Prebuilt[0] == "surrealdb") {
+		t.Errorf("prebuilt alternatives = %v", plan.Prebuilt)
+	}
+
+	// With cargo present and nothing to compile, only downloads remain
+	plan = planInstall(modules[2:], true, 8, "vibe v1.2.0", 0)
+	if total, compile := plan.total(); len(plan.Steps) != 2 || compile != 0 || total > time.Minute {
+		t.Errorf("download-only plan = %+v", plan)
+	}
+}
+
+func TestFormatEstimate(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		20 * time.Second:                "under a minute",
+		90 * time.Second:                "~2 min",
+		25 * time.Minute:                "~25 min",
+		time.Hour + 22*time.Minute:      "~1 h 20 min",
+		2*time.Hour + 3*time.Minute + 1: "~2 h 5 min",
+	} {
+		if got := formatEstimate(d); got != want {
+			t.Errorf("formatEstimate(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package platform describes the machine the installer runs on in the
+// terms vibe's release assets use, and normalises the many spellings of
+// operating systems and architectures (Go, uname, Rust target triples).
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// C libraries a Linux binary can be linked against
+const (
+	LibcGNU    = "gnu"
+	LibcMusl   = "musl"
+	LibcBionic = "bionic" // Android
+)
+
+// VariantStatic is the fully static (musl) build, which runs on any Linux
+// including Alpine and Android
+const VariantStatic = "static"
+
+// Target is a normalised platform descriptor. OS and Arch use Go's names.
+type Target struct {
+	OS      string // linux, darwin, windows, android
+	Arch    string // amd64, arm64
+	Libc    string // Linux and Android only
+	Variant string // VariantStatic, or "" for the default build
+}
+
+// Detect describes the current machine
+func Detect() Target {
+	return detect(runtime.GOOS, runtime.GOARCH, os.Getenv, exists)
+}
+
+// exists reports whether any file matches a glob pattern
+func exists(pattern string) bool {
+	matches, _ := filepath.Glob(pattern)
+	return len(matches) > 0
+}
+
+// detect builds the descriptor from probes, so detection is testable
+func detect(goos, goarch string, getenv func(string) string, exists func(string) bool) Target {
+	t := Target{OS: goos, Arch: goarch}
+
+	// Binaries built for linux/arm64 also run in Termux, reporting GOOS linux
+	termux := getenv("TERMUX_VERSION") != "" || strings.Contains(getenv("PREFIX"), "com.termux")
+	switch {
+	case goos == "android" || (goos == "linux" && termux):
+		t.Libc = LibcBionic
+	case goos == "linux" && exists("/lib/ld-musl-*.so.1"):
+		t.Libc = LibcMusl
+	case goos == "linux":
+		t.Libc = LibcGNU
+	}
+
+	// Only the static build runs without glibc
+	if t.Libc == LibcMusl || t.Libc == LibcBionic {
+		t.Variant = VariantStatic
+	}
+	return t
+}
+
+// AssetOS returns the OS name used in release asset names. Android runs
+// the Linux build.
+func (t Target) AssetOS() string {
+	switch t.OS {
+	case "darwin":
+		return "macos"
+	case "android":
+		return "linux"
+	}
+	return t.OS
+}
+
+// AssetArch returns the architecture name used in release asset names
+func (t Target) AssetArch() string {
+	if t.Arch == "amd64" {
+		return "x86_64"
+	}
+	return t.Arch
+}
+
+// Ext returns the executable file extension
+func (t Target) Ext() string {
+	if t.OS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// Static reports whether the target needs the static build
+func (t Target) Static() bool {
+	return t.Variant == VariantStatic
+}
+
+// String returns the platform part of release asset names, e.g.
+// linux-x86_64, macos-arm64 or linux-arm64-musl for the static build
+func (t Target) String() string {
+	s := t.AssetOS() + "-" + t.AssetArch()
+	if t.Static() {
+		s += "-musl"
+	}
+	return s
+}
+
+// Triple returns the Rust target triple, e.g. x86_64-unknown-linux-gnu
+func (t Target) Triple() string {
+	arch := t.AssetArch()
+	if t.Arch == "arm64" {
+		arch = "aarch64"
+	}
+	switch t.OS {
+	case "darwin":
+		return arch + "-apple-darwin"
+	case "windows":
+		return arch + "-pc-windows-msvc"
+	case "android":
+		return arch + "-linux-android"
+	case "linux":
+		libc := t.Libc
+		if libc == "" || libc == LibcBionic {
+			libc = LibcGNU
+		}
+		if t.Static() {
+			libc = LibcMusl
+		}
+		return arch + "-unknown-linux-" + libc
+	}
+	return arch + "-unknown-" + t.OS
+}
+
+// osAliases maps the spellings of operating systems to Go's names
+var osAliases = map[string]string{
+	"linux": "linux", "darwin": "darwin", "macos": "darwin", "osx": "darwin", "apple": "darwin",
+	"windows": "windows", "win": "windows", "win32": "windows", "android": "android",
+}
+
+// archAliases maps the spellings of architectures to Go's names
+var archAliases = map[string]string{
+	"amd64": "amd64", "x86_64": "amd64", "x64": "amd64",
+	"arm64": "arm64", "aarch64": "arm64",
+}
+
+// Parse normalises a platform written as a Go pair (linux/amd64), a
+// release asset suffix (macos-arm64, linux-x86_64-musl) or a Rust target
+// triple (aarch64-unknown-linux-musl)
+func Parse(s string) (Target, error) {
+	// x86_64 is the one name containing a separator
+	normalised := strings.ReplaceAll(strings.ToLower(s), "x86_64", "amd64")
+	fields := strings.FieldsFunc(normalised, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_'
+	})
+
+	var t Target
+	for _, f := range fields {
+		switch {
+		case f == "android":
+			t.OS = "android" // triples spell it after linux: aarch64-linux-android
+		case osAliases[f] != "" && t.OS == "":
+			t.OS = osAliases[f]
+		case archAliases[f] != "" && t.Arch == "":
+			t.Arch = archAliases[f]
+		case f == LibcGNU:
+			t.Libc = LibcGNU
+		case f == LibcMusl || f == VariantStatic:
+			t.Libc = LibcMusl
+			t.Variant = VariantStatic
+		case f == "unknown" || f == "pc" || f == "msvc":
+			// vendor and ABI parts of target triples
+		default:
+			return Target{}, fmt.Errorf("unrecognised platform %q: unknown part %q", s, f)
+		}
+	}
+	if t.OS == "" || t.Arch == "" {
+		return Target{}, fmt.Errorf("unrecognised platform %q: need an OS and an architecture", s)
+	}
+	if t.OS == "android" {
+		t.Libc = LibcBionic
+		t.Variant = VariantStatic
+	} else if t.OS == "linux" && t.Libc == "" {
+		t.Libc = LibcGNU
+	}
+	return t, nil
+}
@@ -0,0 +1,82 @@
+package platform
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	noMusl := func(string) bool { return false }
+	musl := func(pattern string) bool { return pattern == "/lib/ld-musl-*.so.1" }
+
+	tests := []struct {
+		name   string
+		goos   string
+		goarch string
+		env    map[string]string
+		exists func(string) bool
+		want   Target
+	}{
+		{"glibc linux", "linux", "amd64", nil, noMusl, Target{OS: "linux", Arch: "amd64", Libc: LibcGNU}},
+		{"alpine", "linux", "arm64", nil, musl, Target{OS: "linux", Arch: "arm64", Libc: LibcMusl, Variant: VariantStatic}},
+		{"termux", "linux", "arm64", map[string]string{"PREFIX": "/data/data/com.termux/files/usr"}, noMusl,
+			Target{OS: "linux", Arch: "arm64", Libc: LibcBionic, Variant: VariantStatic}},
+		{"macos", "darwin", "arm64", nil, noMusl, Target{OS: "darwin", Arch: "arm64"}},
+	}
+	for _, tt := range tests {
+		if got := detect(tt.goos, tt.goarch, env(tt.env), tt.exists); got != tt.want {
+			t.Errorf("%s: detect() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTargetNames(t *testing.T) {
+	tests := []struct {
+		target Target
+		asset  string
+		triple string
+	}{
+		{Target{OS: "linux", Arch: "amd64", Libc: LibcGNU}, "linux-x86_64", "x86_64-unknown-linux-gnu"},
+		{Target{OS: "linux", Arch: "arm64", Libc: LibcMusl, Variant: VariantStatic}, "linux-arm64-musl", "aarch64-unknown-linux-musl"},
+		{Target{OS: "darwin", Arch: "arm64"}, "macos-arm64", "aarch64-apple-darwin"},
+		{Target{OS: "windows", Arch: "amd64"}, "windows-x86_64", "x86_64-pc-windows-msvc"},
+		{Target{OS: "android", Arch: "arm64", Libc: LibcBionic, Variant: VariantStatic}, "linux-arm64-musl", "aarch64-linux-android"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.String(); got != tt.asset {
+			t.Errorf("%+v.String() = %q, want %q", tt.target, got, tt.asset)
+		}
+		if got := tt.target.Triple(); got != tt.triple {
+			t.Errorf("%+v.Triple() = %q, want %q", tt.target, got, tt.triple)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]Target{
+		"linux/amd64":               {OS: "linux", Arch: "amd64", Libc: LibcGNU},
+		"macos-arm64":               {OS: "darwin", Arch: "arm64"},
+		"linux-x86_64-musl":         {OS: "linux", Arch: "amd64", Libc: LibcMusl, Variant: VariantStatic},
+		"aarch64-unknown-linux-gnu": {OS: "linux", Arch: "arm64", Libc: LibcGNU},
+		"x86_64-pc-windows-msvc":    {OS: "windows", Arch: "amd64"},
+		"Darwin_x86_64":             {OS: "darwin", Arch: "amd64"},
+		"aarch64-linux-android":     {OS: "android", Arch: "arm64", Libc: LibcBionic, Variant: VariantStatic},
+	}
+	for input, want := range tests {
+		got, err := Parse(input)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v", input, got, err, want)
+		}
+		if err == nil {
+			if round, err := Parse(got.String()); err != nil || round.String() != got.String() {
+				t.Errorf("Parse(%q) does not round-trip: %+v, %v", got.String(), round, err)
+			}
+		}
+	}
+
+	for _, bad := range []string{"linux", "amd64", "freebsd-amd64", "linux-sparc"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) accepted", bad)
+		}
+	}
+}
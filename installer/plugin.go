@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PLUGIN_SCRIPTS are the asdf plugin callbacks the plugin command writes to
+// <dir>/bin; mise runs the same scripts
+var PLUGIN_SCRIPTS = []string{"list-all", "latest-stable", "download", "install"}
+
+// pluginFlags registers the flags of the plugin command
+func pluginFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.BaseURL, "base-url", opts.BaseURL, "base URL of a static release mirror to download from instead of GitHub")
+}
+
+// runPlugin implements `install-dotvibe plugin [--base-url URL] <dir>`
+func runPlugin(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	if err := writePlugin(args[0], strings.TrimSuffix(opts.BaseURL, "/")); err != nil {
+		return err
+	}
+	fmt.Printf("✅ asdf plugin written to %s\n", args[0])
+	fmt.Printf("🎉 Try: asdf plugin add vibe %s && asdf install vibe latest\n", args[0])
+	return nil
+}
+
+// writePlugin generates the plugin scripts in dir/bin. An empty baseURL
+// downloads from GitHub releases, otherwise from a static mirror.
+func writePlugin(dir, baseURL string) error {
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	for _, name := range PLUGIN_SCRIPTS {
+		script, err := pluginScript(name, baseURL)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pluginScript generates one plugin callback. Release tags carry a "v"
+// prefix that asdf versions do not.
+func pluginScript(name, baseURL string) (string, error) {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("# Generated by install-dotvibe plugin, do not edit\n")
+	b.WriteString("set -euo pipefail\n\n")
+
+	switch name {
+	case "list-all":
+		if baseURL != "" {
+			// Static mirrors only advertise their newest release
+			fmt.Fprintf(&b, "curl -fsSL %q | sed 's/^v//' | tr '\\n' ' '\n", baseURL+"/latest")
+			break
+		}
+		b.WriteString("auth=()\n")
+		b.WriteString("if [ -n \"${GITHUB_TOKEN:-}\" ]; then auth=(-H \"Authorization: Bearer $GITHUB_TOKEN\"); fi\n")
+		fmt.Fprintf(&b, "curl -fsSL ${auth[@]+\"${auth[@]}\"} %q |\n", strings.TrimSuffix(GITHUB_LATEST_URL, "/latest")+"?per_page=100")
+		b.WriteString("    grep -o '\"tag_name\": *\"[^\"]*\"' | sed 's/.*\"v\\{0,1\\}\\([^\"]*\\)\"$/\\1/' |\n")
+		b.WriteString("    sort -t. -k1,1n -k2,2n -k3,3n | tr '\\n' ' '\n")
+
+	case "latest-stable":
+		b.WriteString("\"$(dirname \"$0\")/list-all\" | tr ' ' '\\n' | grep -v -- - | grep . | tail -n 1\n")
+
+	case "download":
+		b.WriteString("tag=\"v${ASDF_INSTALL_VERSION}\"\n")
+		b.WriteString("case \"$(uname -s)/$(uname -m)\" in\n")
+		for _, p := range SUPPORTED_PLATFORMS {
+			patterns := unamePatterns(p)
+			if patterns == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "    %s) asset=\"%s\" ;;\n", patterns, releaseAssetName(p.GOOS, p.GOARCH, "${tag}"))
+		}
+		b.WriteString("    *) echo \"vibe is not released for $(uname -s)/$(uname -m)\" >&2; exit 1 ;;\n")
+		b.WriteString("esac\n\n")
+
+		releases := GITHUB_RELEASES_URL
+		if baseURL != "" {
+			releases = baseURL
+		}
+		fmt.Fprintf(&b, "url=\"%s/${tag}\"\n", releases)
+		b.WriteString("mkdir -p \"$ASDF_DOWNLOAD_PATH\"\n")
+		b.WriteString("curl -fsSL -o \"$ASDF_DOWNLOAD_PATH/vibe\" \"$url/$asset\"\n\n")
+
+		// Same policy as the installer: verify when SHA256SUMS is published
+		b.WriteString("if curl -fsSL -o \"$ASDF_DOWNLOAD_PATH/SHA256SUMS\" \"$url/SHA256SUMS\" 2>/dev/null; then\n")
+		b.WriteString("    expected=$(awk -v a=\"$asset\" '$2 == a || $2 == \"*\" a { print $1 }' \"$ASDF_DOWNLOAD_PATH/SHA256SUMS\")\n")
+		b.WriteString("    if command -v sha256sum >/dev/null; then\n")
+		b.WriteString("        actual=$(sha256sum \"$ASDF_DOWNLOAD_PATH/vibe\" | cut -d' ' -f1)\n")
+		b.WriteString("    else\n")
+		b.WriteString("        actual=$(shasum -a 256 \"$ASDF_DOWNLOAD_PATH/vibe\" | cut -d' ' -f1)\n")
+		b.WriteString("    fi\n")
+		b.WriteString("    if [ -z \"$expected\" ] || [ \"$expected\" != \"$actual\" ]; then\n")
+		b.WriteString("        echo \"checksum mismatch for $asset (expected ${expected:-none}, got $actual)\" >&2\n")
+		b.WriteString("        rm -f \"$ASDF_DOWNLOAD_PATH/vibe\"\n")
+		b.WriteString("        exit 1\n")
+		b.WriteString("    fi\n")
+		b.WriteString("else\n")
+		b.WriteString("    echo \"warning: no SHA256SUMS published for $tag, skipping verification\" >&2\n")
+		b.WriteString("fi\n")
+
+	case "install":
+		b.WriteString("mkdir -p \"$ASDF_INSTALL_PATH/bin\"\n")
+		b.WriteString("cp \"$ASDF_DOWNLOAD_PATH/vibe\" \"$ASDF_INSTALL_PATH/bin/vibe\"\n")
+		b.WriteString("chmod 0755 \"$ASDF_INSTALL_PATH/bin/vibe\"\n")
+
+	default:
+		return "", fmt.Errorf("unknown plugin script %q", name)
+	}
+
+	return b.String(), nil
+}
+
+// unamePatterns returns the `uname -s`/`uname -m` case patterns matching a
+// platform, or "" for platforms asdf does not run on
+func unamePatterns(p Platform) string {
+	var system string
+	switch p.GOOS {
+	case "linux":
+		system = "Linux"
+	case "darwin":
+		system = "Darwin"
+	default:
+		return ""
+	}
+
+	machines := []string{p.GOARCH}
+	switch p.GOARCH {
+	case "amd64":
+		machines = []string{"x86_64", "amd64"}
+	case "arm64":
+		machines = []string{"arm64", "aarch64"}
+	}
+
+	patterns := make([]string, len(machines))
+	for i, m := range machines {
+		patterns[i] = system + "/" + m
+	}
+	return strings.Join(patterns, "|")
+}
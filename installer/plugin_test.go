@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPluginScriptsSyntax(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	dir := t.TempDir()
+	if err := writePlugin(dir, ""); err != nil {
+		t.Fatalf("writePlugin failed: %v", err)
+	}
+	for _, name := range PLUGIN_SCRIPTS {
+		path := filepath.Join(dir, "bin", name)
+		if out, err := exec.Command(bash, "-n", path).CombinedOutput(); err != nil {
+			t.Errorf("%s is not valid bash: %v\n%s", name, err, out)
+		}
+	}
+
+	download, _ := pluginScript("download", "")
+	for _, want := range []string{"Linux/x86_64|Linux/amd64", "vibe-${tag}-macos-arm64", GITHUB_RELEASES_URL} {
+		if !strings.Contains(download, want) {
+			t.Errorf("download script missing %q", want)
+		}
+	}
+	if strings.Contains(download, "windows") {
+		t.Error("download script should not offer windows assets")
+	}
+}
+
+func TestPluginInstallFromMirror(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("asdf plugins only run on Unix")
+	}
+	for _, tool := range []string{"bash", "curl"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	asset := releaseAssetName(runtime.GOOS, runtime.GOARCH, "v1.2.0")
+	binary := []byte("#!/bin/sh\necho vibe 1.2.0\n")
+	sum := sha256.Sum256(binary)
+	// The test tampers with the checksums while the server runs
+	var sums atomic.Value
+	sums.Store(hex.EncodeToString(sum[:]) + "  " + asset + "\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			w.Write([]byte("v1.2.0\n"))
+		case "/v1.2.0/" + asset:
+			w.Write(binary)
+		case "/v1.2.0/SHA256SUMS":
+			w.Write([]byte(sums.Load().(string)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := writePlugin(dir, server.URL); err != nil {
+		t.Fatalf("writePlugin failed: %v", err)
+	}
+
+	out, err := exec.Command(filepath.Join(dir, "bin", "latest-stable")).Output()
+	if err != nil {
+		t.Fatalf("latest-stable failed: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "1.2.0" {
+		t.Errorf("latest-stable = %q, want 1.2.0", out)
+	}
+
+	env := append(os.Environ(),
+		"ASDF_INSTALL_VERSION=1.2.0",
+		"ASDF_DOWNLOAD_PATH="+filepath.Join(t.TempDir(), "download"),
+		"ASDF_INSTALL_PATH="+filepath.Join(t.TempDir(), "install"),
+	)
+	for _, name := range []string{"download", "install"} {
+		cmd := exec.Command(filepath.Join(dir, "bin", name))
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s failed: %v\n%s", name, err, out)
+		}
+	}
+	installed := filepath.Join(env[len(env)-1][len("ASDF_INSTALL_PATH="):], "bin", "vibe")
+	if data, err := os.ReadFile(installed); err != nil || string(data) != string(binary) {
+		t.Errorf("installed binary = %q, %v", data, err)
+	}
+
+	// A tampered checksum must fail the download
+	sums.Store(strings.Repeat("0", 64) + "  " + asset + "\n")
+	cmd := exec.Command(filepath.Join(dir, "bin", "download"))
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "checksum mismatch") {
+		t.Errorf("Expected checksum mismatch, got %v\n%s", err, out)
+	}
+}
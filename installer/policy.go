@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Release channels a policy can allow
+const (
+	CHANNEL_STABLE     = "stable"
+	CHANNEL_PRERELEASE = "prerelease"
+
+	POLICY_SOURCE_GITHUB = "github" // the default GitHub releases source, in a policy's sources
+)
+
+// Policy is an organisation's constraint on what the installer may do,
+// provisioned by an administrator at policyPath. Users cannot override it
+// with flags or the config file; every command refuses to run against a
+// policy it cannot read. Empty fields allow anything.
+type Policy struct {
+	Channels        []string `json:"channels,omitempty"`          // stable, prerelease
+	Sources         []string `json:"sources,omitempty"`           // allowed source, mirror, shared cache, forge API, module and prerequisite URL prefixes, or github; also rules out --p2p
+	MinVersion      string   `json:"min_version,omitempty"`       // oldest release that may be installed
+	MaxVersion      string   `json:"max_version,omitempty"`       // newest release that may be installed
+	AllowTelemetry  *bool    `json:"allow_telemetry,omitempty"`   // whether install metrics may be pushed
+	AllowAutoUpdate *bool    `json:"allow_auto_update,omitempty"` // whether anything may update without a user running it
+}
+
+// policyPath is where administrators provision the policy
+var policyPath = defaultPolicyPath()
+
+// policy is the policy in force for this run, empty without a policy file
+var policy Policy
+
+func defaultPolicyPath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "vibe", "policy.json")
+	}
+	return "/etc/vibe/policy.json"
+}
+
+// loadPolicy reads and validates the policy file. A missing file is no
+// policy; anything else that cannot be understood is an error, so a typo
+// never silently lifts a constraint.
+func loadPolicy(path string) (Policy, error) {
+	var p Policy
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	for _, channel := range p.Channels {
+		if channel != CHANNEL_STABLE && channel != CHANNEL_PRERELEASE {
+			return p, fmt.Errorf("invalid policy %s: unknown channel %q (use %s or %s)", path, channel, CHANNEL_STABLE, CHANNEL_PRERELEASE)
+		}
+	}
+	for _, bound := range []string{p.MinVersion, p.MaxVersion} {
+		if _, ok := parseVersion(bound); bound != "" && !ok {
+			return p, fmt.Errorf("invalid policy %s: %q is not a version", path, bound)
+		}
+	}
+	return p, nil
+}
+
+func (p Policy) allowsChannel(channel string) bool {
+	if len(p.Channels) == 0 {
+		return true
+	}
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsSource reports whether a source spec ("" for GitHub releases) is
+// one of the allowed sources
+func (p Policy) allowsSource(spec string) bool {
+	if len(p.Sources) == 0 {
+		return true
+	}
+	for _, allowed := range p.Sources {
+		if allowed == POLICY_SOURCE_GITHUB {
+			if spec == "" {
+				return true
+			}
+			continue
+		}
+		allowed = strings.TrimSuffix(allowed, "/")
+		if spec == allowed || strings.HasPrefix(spec, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// check validates the options of a run against the policy
+func (p Policy) check(o Options) error {
+	if o.IncludePrereleases && !p.allowsChannel(CHANNEL_PRERELEASE) {
+		return fmt.Errorf("prerelease versions are not allowed")
+	}
+	for _, spec := range append([]string{o.sourceSpec()}, o.Mirrors...) {
+		if !p.allowsSource(spec) {
+			if spec == "" {
+				spec = "GitHub releases"
+			}
+			return fmt.Errorf("source %s is not allowed (allowed: %s)", spec, strings.Join(p.Sources, ", "))
+		}
+	}
+	if o.MetricsPushURL != "" && p.AllowTelemetry != nil && !*p.AllowTelemetry {
+		return fmt.Errorf("pushing install metrics is not allowed")
+	}
+	if len(p.Sources) == 0 {
+		return nil
+	}
+	// Anything else the installer downloads from has to be allowed too
+	if shared := sharedCacheLocation(o); shared != "" && !o.NoCache && !p.allowsSource(shared) {
+		return fmt.Errorf("shared cache %s is not allowed (allowed: %s)", shared, strings.Join(p.Sources, ", "))
+	}
+	if o.ForgeAPI != "" && !p.allowsSource(o.ForgeAPI) {
+		return fmt.Errorf("forge API %s is not allowed (allowed: %s)", o.ForgeAPI, strings.Join(p.Sources, ", "))
+	}
+	if o.P2P {
+		return fmt.Errorf("peer-to-peer downloads are not allowed while the policy restricts sources")
+	}
+	return nil
+}
+
+// checkVersion validates a resolved release against the policy
+func (p Policy) checkVersion(version string) error {
+	v, ok := parseVersion(version)
+	if !ok {
+		if p.MinVersion != "" || p.MaxVersion != "" || len(p.Channels) > 0 {
+			return fmt.Errorf("cannot check %q against the version policy", version)
+		}
+		return nil
+	}
+	if v.Prerelease != "" && !p.allowsChannel(CHANNEL_PRERELEASE) {
+		return fmt.Errorf("%s is a prerelease, which is not allowed", version)
+	}
+	if v.Prerelease == "" && !p.allowsChannel(CHANNEL_STABLE) {
+		return fmt.Errorf("%s is a stable release, but only prereleases are allowed", version)
+	}
+	if min, ok := parseVersion(p.MinVersion); ok && compareVersions(v, min) < 0 {
+		return fmt.Errorf("%s is older than the minimum version %s", version, p.MinVersion)
+	}
+	if max, ok := parseVersion(p.MaxVersion); ok && compareVersions(v, max) > 0 {
+		return fmt.Errorf("%s is newer than the maximum version %s", version, p.MaxVersion)
+	}
+	return nil
+}
+
+// enforcePolicy loads the policy and checks the options of the command
+// about to run
+func enforcePolicy() error {
+	p, err := loadPolicy(policyPath)
+	if err != nil {
+		return err
+	}
+	policy = p
+	if err := p.check(opts); err != nil {
+		return fmt.Errorf("blocked by the policy in %s: %w", policyPath, err)
+	}
+	return nil
+}
+
+// checkPolicyURL validates a download from outside the release source,
+// such as a module or a prerequisite, against the sources the policy in
+// force allows. what names the download in the error.
+func checkPolicyURL(what, url string) error {
+	if !policy.allowsSource(url) {
+		return fmt.Errorf("blocked by the policy in %s: %s %s is not allowed (allowed: %s)", policyPath, what, url, strings.Join(policy.Sources, ", "))
+	}
+	return nil
+}
+
+// checkPolicyVersion validates a resolved release against the policy in force
+func checkPolicyVersion(version string) error {
+	if err := policy.checkVersion(version); err != nil {
+		return fmt.Errorf("blocked by the policy in %s: %w", policyPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	if p, err := loadPolicy(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(p.Sources) != 0 {
+		t.Errorf("missing policy = %+v, %v; want no constraints", p, err)
+	}
+
+	for name, content := range map[string]string{
+		"unknown field":   `{"source": ["github"]}`,
+		"unknown channel": `{"channels": ["nightly"]}`,
+		"bad version":     `{"max_version": "latest"}`,
+		"malformed":       `{"channels": [`,
+	} {
+		if _, err := loadPolicy(writePolicy(t, content)); err == nil {
+			t.Errorf("%s: loadPolicy succeeded", name)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	t.Setenv("VIBE_CACHE_URL", "")
+	deny := false
+	p := Policy{
+		Channels:       []string{CHANNEL_STABLE},
+		Sources:        []string{POLICY_SOURCE_GITHUB, "https://mirror.corp/vibe/"},
+		MinVersion:     "v1.2.0",
+		MaxVersion:     "v1.4.0",
+		AllowTelemetry: &deny,
+	}
+
+	for _, tc := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, ""},
+		{Options{BaseURL: "https://mirror.corp/vibe"}, ""},
+		{Options{Source: "https://mirror.corp/vibe/releases"}, ""},
+		{Options{Source: "https://mirror.corp/vibe-evil"}, "not allowed"},
+		{Options{Mirrors: []string{"https://elsewhere.example"}}, "not allowed"},
+		{Options{IncludePrereleases: true}, "prerelease"},
+		{Options{MetricsPushURL: "https://push.example"}, "metrics"},
+		{Options{SharedCache: "https://mirror.corp/vibe/cache"}, ""},
+		{Options{SharedCache: "https://cache.example"}, "shared cache"},
+		{Options{SharedCache: "https://cache.example", NoCache: true}, ""},
+		{Options{ForgeAPI: "https://mirror.corp/vibe/api"}, ""},
+		{Options{ForgeAPI: "https://api.example"}, "forge API"},
+		{Options{P2P: true}, "peer-to-peer"},
+	} {
+		err := p.check(tc.opts)
+		if tc.want == "" && err != nil || tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("check(%+v) = %v, want %q", tc.opts, err, tc.want)
+		}
+	}
+
+	t.Setenv("VIBE_CACHE_URL", "/mnt/cache")
+	if err := p.check(Options{}); err == nil || !strings.Contains(err.Error(), "/mnt/cache") {
+		t.Errorf("check with VIBE_CACHE_URL=/mnt/cache = %v, want a shared cache error", err)
+	}
+
+	for version, allowed := range map[string]bool{
+		"v1.2.0":     true,
+		"v1.4.0":     true,
+		"v1.1.9":     false,
+		"v1.5.0":     false,
+		"v1.3.0-rc1": false,
+	} {
+		if err := p.checkVersion(version); (err == nil) != allowed {
+			t.Errorf("checkVersion(%s) = %v, want allowed=%v", version, err, allowed)
+		}
+	}
+}
+
+func TestPolicyBlocksCommands(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	saved := policyPath
+	defer func() { policyPath, policy = saved, Policy{} }()
+
+	policyPath = writePolicy(t, `{"sources": ["https://mirror.corp/vibe"]}`)
+	err := execute([]string{"list-remote", "--base-url", "https://elsewhere.example"})
+	if err == nil || !strings.Contains(err.Error(), "blocked by the policy") {
+		t.Errorf("list-remote from a forbidden source = %v, want a policy error", err)
+	}
+
+	policyPath = writePolicy(t, `{"sourcez": []}`)
+	if err := execute([]string{"list"}); err == nil || !strings.Contains(err.Error(), "invalid policy") {
+		t.Errorf("list with an invalid policy = %v, want an error", err)
+	}
+
+	// A blocked command changes nothing on disk
+	policyPath = writePolicy(t, `{"sources": ["https://mirror.corp/vibe"]}`)
+	home := setupXDG(t)
+	legacy := filepath.Join(home, ".vibe")
+	os.MkdirAll(legacy, 0755)
+	if err := execute([]string{"list-remote", "--base-url", "https://elsewhere.example"}); err == nil {
+		t.Error("list-remote from a forbidden source succeeded")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("a blocked command moved %s: %v", legacy, err)
+	}
+}
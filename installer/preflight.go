@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+)
+
+// STATIC_ASSET_SUFFIX marks the fully static (musl) release asset of a
+// platform, published for Linux systems whose glibc is too old or absent
+const STATIC_ASSET_SUFFIX = "-musl"
+
+// staticAssetName returns the fully static (musl) release asset for a
+// platform
+func staticAssetName(goos, goarch, version string) string {
+	return releaseAssetName(goos, goarch, version) + STATIC_ASSET_SUFFIX
+}
+
+// fetchReleaseManifest downloads the manifest published with a release.
+// Not every release or source has one. With --manifest-key the manifest
+// must carry a valid signature. It is staged in the quarantine like every
+// download: its digests can override SHA256SUMS, so it must never be read
+// from a path another user could have written first.
+func fetchReleaseManifest(source Source, version string) (*MirrorManifest, error) {
+	tempPath, err := quarantinePath(MIRROR_MANIFEST)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempPath)
+	if err := source.FetchAsset(version, MIRROR_MANIFEST, tempPath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release manifest: %w", err)
+	}
+	if opts.ManifestKey != "" {
+		if err := checkManifestSignature(source, version, data); err != nil {
+			return nil, err
+		}
+	}
+	var manifest MirrorManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse release manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// glibcPattern extracts a glibc version from getconf or ldd output
+var glibcPattern = regexp.MustCompile(`(?i)(?:glibc|gnu libc[^0-9]*)\s*(\d+\.\d+)`)
+
+// parseGlibcVersion finds the glibc version in `getconf GNU_LIBC_VERSION`
+// or `ldd --version` output
+func parseGlibcVersion(output string) (string, bool) {
+	m := glibcPattern.FindStringSubmatch(output)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// detectGlibc returns the system glibc version, or false on systems
+// without glibc such as Alpine (musl)
+func detectGlibc() (string, bool) {
+	for _, probe := range [][]string{{"getconf", "GNU_LIBC_VERSION"}, {"ldd", "--version"}} {
+		out, _ := exec.Command(probe[0], probe[1:]...).CombinedOutput()
+		if v, ok := parseGlibcVersion(string(out)); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// compareGlibc compares two MAJOR.MINOR glibc versions like compareVersions
+func compareGlibc(a, b string) int {
+	va, _ := parseVersion(a + ".0")
+	vb, _ := parseVersion(b + ".0")
+	return compareVersions(va, vb)
+}
+
+// checkGlibc fails when the system glibc is older than required, or missing
+// altogether, pointing at the static asset that runs without it
+func checkGlibc(system string, found bool, required, staticAsset string) error {
+	if required == "" {
+		return nil
+	}
+	hint := fmt.Sprintf("install the static build %s instead with --static", staticAsset)
+	if !found {
+		return fmt.Errorf("vibe needs glibc %s or newer but none was found (musl-based system?); %s", required, hint)
+	}
+	if compareGlibc(system, required) < 0 {
+		return fmt.Errorf("vibe needs glibc %s or newer but this system has %s; %s", required, system, hint)
+	}
+	fmt.Printf("✅ glibc %s (vibe needs %s or newer)\n", system, required)
+	return nil
+}
+
+// preflightGlibc runs the glibc check for the release about to be installed
+func preflightGlibc(source Source, goos, goarch, version string) error {
+	if goos != "linux" || opts.Static {
+		return nil
+	}
+	manifest, err := fetchReleaseManifest(source, version)
+	if err != nil || manifest.MinGlibc == "" {
+		return nil // nothing declared, nothing to check
+	}
+	system, found := detectGlibc()
+	return checkGlibc(system, found, manifest.MinGlibc, staticAssetName(goos, goarch, version))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGlibcVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+		ok     bool
+	}{
+		{"glibc 2.35\n", "2.35", true},
+		{"ldd (Ubuntu GLIBC 2.35-0ubuntu3.8) 2.35\nCopyright (C) 2022", "2.35", true},
+		{"ldd (GNU libc) 2.38\n", "2.38", true},
+		{"musl libc (x86_64)\nVersion 1.2.4\n", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseGlibcVersion(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseGlibcVersion(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckGlibc(t *testing.T) {
+	static := staticAssetName("linux", "amd64", "v1.0.0")
+	if static != "vibe-v1.0.0-linux-x86_64-musl" {
+		t.Errorf("staticAssetName = %v", static)
+	}
+
+	if err := checkGlibc("2.35", true, "2.31", static); err != nil {
+		t.Errorf("Newer glibc rejected: %v", err)
+	}
+	if err := checkGlibc("2.31", true, "2.31", static); err != nil {
+		t.Errorf("Equal glibc rejected: %v", err)
+	}
+	if err := checkGlibc("", false, "", static); err != nil {
+		t.Errorf("Undeclared requirement should pass: %v", err)
+	}
+
+	// 2.9 is older than 2.31 despite sorting after it as a string
+	err := checkGlibc("2.9", true, "2.31", static)
+	if err == nil || !strings.Contains(err.Error(), static) || !strings.Contains(err.Error(), "--static") {
+		t.Errorf("Expected error pointing at %s, got %v", static, err)
+	}
+	if err := checkGlibc("", false, "2.31", static); err == nil {
+		t.Error("Expected error on a system without glibc")
+	}
+}
+
+func TestFetchReleaseManifestIgnoresPlantedFile(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts.ManifestKey = ""
+
+	// The manifest used to be staged at this predictable shared path,
+	// where another user could plant their own digests first
+	planted := filepath.Join(os.TempDir(), "vibe-v1.0.0-"+MIRROR_MANIFEST)
+	if _, err := os.Lstat(planted); err == nil {
+		t.Skipf("%s already exists", planted)
+	}
+	forged := []byte(`{"version":"v1.0.0","min_glibc":"9.99"}`)
+	if err := os.WriteFile(planted, forged, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(planted)
+
+	source := &variantSource{files: map[string][]byte{
+		MIRROR_MANIFEST: []byte(`{"version":"v1.0.0","min_glibc":"2.17"}`),
+	}}
+	manifest, err := fetchReleaseManifest(source, "v1.0.0")
+	if err != nil {
+		t.Fatalf("fetchReleaseManifest: %v", err)
+	}
+	if manifest.MinGlibc != "2.17" {
+		t.Errorf("MinGlibc = %q, want the served 2.17", manifest.MinGlibc)
+	}
+	data, err := os.ReadFile(planted)
+	if err != nil || string(data) != string(forged) {
+		t.Errorf("planted file was touched: %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(getQuarantineDir())
+	if len(entries) != 0 {
+		t.Errorf("staged manifest was not removed: %v", entries)
+	}
+}
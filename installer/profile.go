@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Markers delimiting the block the installer owns in shell rc files
+const (
+	PROFILE_BLOCK_START = "# >>> dotvibe PATH >>>"
+	PROFILE_BLOCK_END   = "# <<< dotvibe PATH <<<"
+)
+
+// Kinds of environment changes recorded in the receipt
+const (
+	CHANGE_RC_BLOCK      = "rc-block"
+	CHANGE_REGISTRY_PATH = "registry-path"
+)
+
+// EnvChange is a modification of the user's environment recorded in the
+// receipt so it can be reverted
+type EnvChange struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path,omitempty"`  // rc file for rc-block changes
+	Value string `json:"value,omitempty"` // directory added to PATH
+}
+
+// getShellProfile returns the rc file of the user's login shell
+func getShellProfile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		return filepath.Join(home, ".zshrc")
+	case "bash":
+		if runtime.GOOS == "darwin" {
+			return filepath.Join(home, ".bash_profile")
+		}
+		return filepath.Join(home, ".bashrc")
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "config.fish")
+	default:
+		return filepath.Join(home, ".profile")
+	}
+}
+
+// profileBlock returns the marker-delimited PATH block for an rc file
+func profileBlock(profile, dir string) string {
+	line := fmt.Sprintf("export PATH=\"%s:$PATH\"", dir)
+	if strings.HasSuffix(profile, ".fish") {
+		line = fmt.Sprintf("fish_add_path --prepend \"%s\"", dir)
+	}
+	return PROFILE_BLOCK_START + "\n" + line + "\n" + PROFILE_BLOCK_END + "\n"
+}
+
+// findProfileBlock locates an existing installer block in content,
+// returning its byte range including the trailing newline
+func findProfileBlock(content string) (start, end int, found bool) {
+	start = strings.Index(content, PROFILE_BLOCK_START)
+	if start < 0 {
+		return 0, 0, false
+	}
+	rel := strings.Index(content[start:], PROFILE_BLOCK_END)
+	if rel < 0 {
+		return 0, 0, false
+	}
+	end = start + rel + len(PROFILE_BLOCK_END)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// planProfileEdit computes the rc file content with block installed,
+// replacing any previous installer block. It returns the new content and
+// a diff preview, or changed=false when the file already matches.
+func planProfileEdit(content, block string) (updated, diff string, changed bool) {
+	var removed string
+	if start, end, found := findProfileBlock(content); found {
+		removed = content[start:end]
+		if removed == block {
+			return content, "", false
+		}
+		updated = content[:start] + block + content[end:]
+	} else {
+		updated = content
+		if updated != "" && !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		updated += block
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(removed, "\n"), "\n") {
+		if line != "" {
+			b.WriteString("- " + line + "\n")
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(block, "\n"), "\n") {
+		b.WriteString("+ " + line + "\n")
+	}
+	return updated, b.String(), true
+}
+
+// pathContains reports whether dir is an entry of a PATH-style list
+func pathContains(pathList, dir string) bool {
+	for _, entry := range filepath.SplitList(pathList) {
+		if filepath.Clean(entry) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureOnPath makes installPath part of the user's PATH, previewing the
+// exact change and asking for consent first. Applied changes are appended
+// to the receipt.
+func ensureOnPath(installPath string, receipt *Receipt) error {
+	if err := simulateFailure(STEP_PATH); err != nil {
+		return err
+	}
+	if opts.NoModifyPath || pathContains(os.Getenv("PATH"), installPath) {
+		return nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return ensureOnWindowsPath(installPath, receipt)
+	}
+
+	profile := getShellProfile()
+	if profile == "" {
+		return fmt.Errorf("could not determine shell profile; add %s to PATH manually", installPath)
+	}
+
+	existing, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", profile, err)
+	}
+
+	updated, diff, changed := planProfileEdit(string(existing), profileBlock(profile, installPath))
+	if !changed {
+		return nil
+	}
+
+	fmt.Printf("\n🐚 %s is not on your PATH. Proposed change to %s:\n\n%s\n", installPath, profile, diff)
+	if !opts.Yes && !confirm(fmt.Sprintf("Apply this change to %s?", profile)) {
+		fmt.Printf("⏭️  Left %s untouched; add %s to PATH yourself\n", profile, installPath)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(profile), err)
+	}
+	if err := os.WriteFile(profile, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", profile, err)
+	}
+
+	receipt.recordChange(EnvChange{Kind: CHANGE_RC_BLOCK, Path: profile, Value: installPath})
+	fmt.Printf("✅ Updated %s (restart your shell to pick it up)\n", profile)
+	return nil
+}
+
+// ensureOnWindowsPath prepends installPath to the user PATH in the registry
+func ensureOnWindowsPath(installPath string, receipt *Receipt) error {
+	out, err := exec.Command("powershell", "-NoProfile", "-Command",
+		"[Environment]::GetEnvironmentVariable('Path', 'User')").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read user PATH: %w", err)
+	}
+
+	current := strings.TrimSpace(string(out))
+	if pathContains(current, installPath) {
+		return nil
+	}
+
+	updated := installPath
+	if current != "" {
+		updated += ";" + current
+	}
+
+	fmt.Printf("\n🪟 Proposed change to HKCU\\Environment\\Path:\n\n+ %s\n\n", installPath)
+	if !opts.Yes && !confirm("Add this directory to your user PATH?") {
+		fmt.Printf("⏭️  Left PATH untouched; add %s to PATH yourself\n", installPath)
+		return nil
+	}
+
+	cmd := exec.Command("powershell", "-NoProfile", "-Command",
+		"[Environment]::SetEnvironmentVariable('Path', $env:VIBE_NEW_PATH, 'User')")
+	cmd.Env = append(os.Environ(), "VIBE_NEW_PATH="+updated)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update user PATH: %w", err)
+	}
+
+	receipt.recordChange(EnvChange{Kind: CHANGE_REGISTRY_PATH, Value: installPath})
+	fmt.Printf("✅ Updated user PATH (open a new terminal to pick it up)\n")
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPlanProfileEdit(t *testing.T) {
+	block := profileBlock("/home/u/.bashrc", "/home/u/.local/bin")
+
+	t.Run("append to file without trailing newline", func(t *testing.T) {
+		updated, diff, changed := planProfileEdit("alias ll='ls -l'", block)
+		if !changed {
+			t.Fatal("Expected change")
+		}
+		if updated != "alias ll='ls -l'\n"+block {
+			t.Errorf("updated = %q", updated)
+		}
+		if !strings.Contains(diff, "+ export PATH=\"/home/u/.local/bin:$PATH\"") || strings.Contains(diff, "- ") {
+			t.Errorf("diff = %q", diff)
+		}
+	})
+
+	t.Run("identical block is a no-op", func(t *testing.T) {
+		content := "# mine\n" + block + "# after\n"
+		if _, _, changed := planProfileEdit(content, block); changed {
+			t.Error("Expected no change for existing identical block")
+		}
+	})
+
+	t.Run("stale block is replaced in place", func(t *testing.T) {
+		old := profileBlock("/home/u/.bashrc", "/old/bin")
+		updated, diff, changed := planProfileEdit("# mine\n"+old+"# after\n", block)
+		if !changed {
+			t.Fatal("Expected change")
+		}
+		if updated != "# mine\n"+block+"# after\n" {
+			t.Errorf("updated = %q", updated)
+		}
+		if !strings.Contains(diff, "- export PATH=\"/old/bin:$PATH\"") {
+			t.Errorf("diff = %q", diff)
+		}
+	})
+
+	t.Run("fish syntax", func(t *testing.T) {
+		fish := profileBlock("/home/u/.config/fish/config.fish", "/opt/bin")
+		if !strings.Contains(fish, "fish_add_path --prepend \"/opt/bin\"") {
+			t.Errorf("fish block = %q", fish)
+		}
+	})
+}
+
+func TestEnsureOnPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell profiles are not used on Windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/zsh")
+	t.Setenv("PATH", "/usr/bin")
+
+	saved := opts
+	defer func() { opts = saved }()
+
+	receipt := &Receipt{}
+	installPath := filepath.Join(home, ".local", "bin")
+
+	t.Run("declined without --yes", func(t *testing.T) {
+		opts.Yes = false
+		if err := ensureOnPath(installPath, receipt); err != nil {
+			t.Fatalf("ensureOnPath failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(home, ".zshrc")); !os.IsNotExist(err) {
+			t.Error("profile must not be written without consent")
+		}
+	})
+
+	t.Run("applied with --yes", func(t *testing.T) {
+		opts.Yes = true
+		if err := ensureOnPath(installPath, receipt); err != nil {
+			t.Fatalf("ensureOnPath failed: %v", err)
+		}
+		data, _ := os.ReadFile(filepath.Join(home, ".zshrc"))
+		if !strings.Contains(string(data), PROFILE_BLOCK_START) {
+			t.Errorf(".zshrc = %q, want installer block", data)
+		}
+		if len(receipt.Changes) != 1 || receipt.Changes[0].Kind != CHANGE_RC_BLOCK {
+			t.Errorf("receipt changes = %+v", receipt.Changes)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PROGRESS_REDRAW_INTERVAL limits how often a terminal board is redrawn
+const PROGRESS_REDRAW_INTERVAL = 100 * time.Millisecond
+
+// progressBoard shows the tasks in flight: downloads with a bar each, other
+// work with its state, and a total line while several run at once. On a
+// terminal the lines are redrawn in place; elsewhere downloads log every
+// 10%, so CI and piped output stay sequential.
+type progressBoard struct {
+	mu    sync.Mutex
+	out   io.Writer
+	live  bool
+	tasks []*progressTask // tasks of the current batch, until all finish
+	drawn int             // lines drawn by the last redraw
+	last  time.Time
+}
+
+// progressTask is one download or step on a progress board
+type progressTask struct {
+	board  *progressBoard
+	name   string
+	total  int64 // bytes expected, 0 when unknown or not a download
+	done   int64 // bytes received
+	logged int64 // last 10% step logged off-terminal
+	bytes  bool  // whether the task transfers data
+	state  string
+	start  time.Time // on the monotonic clock, for the ETA
+}
+
+// PROGRESS_ETA_AFTER is how long a download runs before its ETA is shown,
+// so the first bursts do not produce wild estimates
+const PROGRESS_ETA_AFTER = 3 * time.Second
+
+// Task states
+const (
+	TASK_RUNNING = ""
+	TASK_DONE    = "done"
+	TASK_FAILED  = "failed"
+)
+
+// progress is the board of the current run
+var progress = &progressBoard{out: os.Stdout, live: stdoutIsTerminal() && !ci.Enabled}
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// download adds a download of total bytes (0 when unknown) to the board
+func (b *progressBoard) download(name string, total int64) *progressTask {
+	return b.add(&progressTask{name: name, total: total, bytes: true})
+}
+
+// step adds work without a byte count, such as a module check
+func (b *progressBoard) step(name string) *progressTask {
+	return b.add(&progressTask{name: name})
+}
+
+func (b *progressBoard) add(t *progressTask) *progressTask {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t.board = b
+	t.start = time.Now()
+	b.tasks = append(b.tasks, t)
+	b.redraw(true)
+	return t
+}
+
+// setTotal sets the size of a download once a response reveals it
+func (t *progressTask) setTotal(total int64) {
+	t.board.mu.Lock()
+	defer t.board.mu.Unlock()
+	t.total = total
+}
+
+// Write counts downloaded bytes, so a task can sit in an io.MultiWriter
+func (t *progressTask) Write(p []byte) (int, error) {
+	b := t.board
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t.done += int64(len(p))
+
+	if !b.live {
+		if containerMode {
+			return len(p), nil // keep image build logs short
+		}
+		// Logs do not render carriage returns: print a line every 10%
+		// (or every 10 MiB when the size is unknown) instead
+		step := t.done / (10 << 20)
+		if t.total > 0 {
+			step = t.done * 10 / t.total
+		}
+		if step > t.logged {
+			t.logged = step
+			if t.total > 0 {
+				fmt.Fprintf(b.out, "📥 Downloading %s... %d%% (%d/%d bytes)\n", t.name, step*10, t.done, t.total)
+			} else {
+				fmt.Fprintf(b.out, "📥 Downloading %s... %d bytes\n", t.name, t.done)
+			}
+		}
+		return len(p), nil
+	}
+	b.redraw(false)
+	return len(p), nil
+}
+
+// finish marks a task done or failed. The board is released once every
+// task of the batch has finished, leaving its last lines on screen.
+func (t *progressTask) finish(err error) {
+	b := t.board
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t.state = TASK_DONE
+	if err != nil {
+		t.state = TASK_FAILED
+	}
+	b.redraw(true)
+
+	for _, task := range b.tasks {
+		if task.state == TASK_RUNNING {
+			return
+		}
+	}
+	b.tasks, b.drawn = nil, 0
+}
+
+// redraw repaints the board on a terminal, at most every
+// PROGRESS_REDRAW_INTERVAL unless a task started or finished
+func (b *progressBoard) redraw(force bool) {
+	if !b.live || (!force && time.Since(b.last) < PROGRESS_REDRAW_INTERVAL) {
+		return
+	}
+	b.last = time.Now()
+
+	var s strings.Builder
+	if b.drawn > 0 {
+		fmt.Fprintf(&s, "\x1b[%dF", b.drawn) // back to the first line of the board
+	}
+	lines := 0
+	for _, t := range b.tasks {
+		fmt.Fprintf(&s, "\x1b[2K%s\n", t.line())
+		lines++
+	}
+	if len(b.tasks) > 1 {
+		fmt.Fprintf(&s, "\x1b[2K%s\n", b.totalLine())
+		lines++
+	}
+	b.drawn = lines
+	io.WriteString(b.out, s.String())
+}
+
+// progressBar draws a bar of width cells filled to fraction
+func progressBar(fraction float64, width int) string {
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// mebibytes shows a byte count with the precision a progress line needs.
+// Sizes are binary throughout the installer, labelled MiB and GiB.
+func mebibytes(n int64) string {
+	return formatDecimal(float64(n)/(1<<20), 1) + " MiB"
+}
+
+// eta estimates the time left of a download from its average rate so far,
+// "" while there is too little to go on
+func (t *progressTask) eta() string {
+	elapsed := time.Since(t.start)
+	if t.state != TASK_RUNNING || t.total <= 0 || t.done <= 0 || elapsed < PROGRESS_ETA_AFTER {
+		return ""
+	}
+	left := time.Duration(float64(elapsed) * float64(t.total-t.done) / float64(t.done))
+	return ", " + formatDuration(left) + " left"
+}
+
+func (t *progressTask) line() string {
+	icon := "📥"
+	switch {
+	case t.state == TASK_DONE:
+		icon = "✅"
+	case t.state == TASK_FAILED:
+		icon = "❌"
+	case !t.bytes:
+		icon = "⏳"
+	}
+	switch {
+	case !t.bytes:
+		return fmt.Sprintf("%s %s", icon, t.name)
+	case t.total > 0:
+		fraction := float64(t.done) / float64(t.total)
+		return fmt.Sprintf("%s %-28s %s %s %s/%s%s", icon, t.name, progressBar(fraction, 20), formatPercent(fraction), mebibytes(t.done), mebibytes(t.total), t.eta())
+	}
+	return fmt.Sprintf("%s %-28s %s", icon, t.name, mebibytes(t.done))
+}
+
+// totalLine sums the batch: bytes of the downloads with a known size and
+// the number of finished tasks
+func (b *progressBoard) totalLine() string {
+	var done, total int64
+	finished := 0
+	for _, t := range b.tasks {
+		if t.state != TASK_RUNNING {
+			finished++
+		}
+		if t.total > 0 {
+			done += t.done
+			total += t.total
+		}
+	}
+	if total == 0 {
+		return fmt.Sprintf("   %-28s %d/%d", "total", finished, len(b.tasks))
+	}
+	fraction := float64(done) / float64(total)
+	return fmt.Sprintf("   %-28s %s %s %d/%d", "total", progressBar(fraction, 20), formatPercent(fraction), finished, len(b.tasks))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgressBoardLive(t *testing.T) {
+	var out strings.Builder
+	board := &progressBoard{out: &out, live: true}
+
+	binary := board.download("vibe-linux-x86_64", 100)
+	wasm := board.download("tree-sitter-typescript.wasm", 50)
+	check := board.step("Checking surrealdb")
+	binary.Write(make([]byte, 100))
+	binary.finish(nil)
+	wasm.Write(make([]byte, 10))
+	check.finish(nil)
+	wasm.finish(nil)
+
+	got := out.String()
+	for _, want := range []string{"vibe-linux-x86_64", "tree-sitter-typescript.wasm", "Checking surrealdb", "total", "100.0%", "\x1b[4F"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("board output lacks %q:\n%s", want, got)
+		}
+	}
+	if board.tasks != nil || board.drawn != 0 {
+		t.Errorf("board was not released after every task finished")
+	}
+
+	// The next batch starts below the last one instead of redrawing over it
+	out.Reset()
+	board.download("next", 1).finish(nil)
+	if strings.Contains(out.String(), "\x1b[4F") {
+		t.Errorf("a new batch moved the cursor into the previous one: %q", out.String())
+	}
+}
+
+func TestProgressBoardLogs(t *testing.T) {
+	var out strings.Builder
+	board := &progressBoard{out: &out}
+
+	task := board.download("vibe-linux-x86_64", 100)
+	for i := 0; i < 10; i++ {
+		task.Write(make([]byte, 10))
+	}
+	task.finish(nil)
+
+	got := out.String()
+	if strings.Contains(got, "\x1b") || strings.Contains(got, "\r") {
+		t.Errorf("non-terminal output contains control sequences: %q", got)
+	}
+	if n := strings.Count(got, "\n"); n != 10 {
+		t.Errorf("logged %d progress lines, want one per 10%%:\n%s", n, got)
+	}
+}
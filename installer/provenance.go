@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// How the digest of a downloaded artifact was checked
+const (
+	VERIFIED_SIGNED   = "signed-manifest" // digest from a manifest signed by --manifest-key
+	VERIFIED_CHECKSUM = "checksum"        // digest published with the release, unsigned
+	VERIFIED_NONE     = "none"            // no published digest
+)
+
+// Provenance records where an installed artifact came from, so audits
+// can tell official installs from mirror installs and check the chain
+// from the source to the file on disk
+type Provenance struct {
+	Component    string    `json:"component"` // vibe, or the grammar package
+	Artifact     string    `json:"artifact"`  // file downloaded from the source
+	Path         string    `json:"path"`      // where it was installed
+	Source       string    `json:"source"`    // source that served it
+	Official     bool      `json:"official"`  // served by the project's GitHub releases
+	URL          string    `json:"url,omitempty"`
+	Cached       bool      `json:"cached,omitempty"` // served from the download cache, downloaded by an earlier run
+	SHA256       string    `json:"sha256"`           // of the installed file
+	Published    string    `json:"published_sha256,omitempty"`
+	Verification string    `json:"verification"`
+	TLS          *TLSPeer  `json:"tls,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// TLSPeer identifies the server an artifact was downloaded from
+type TLSPeer struct {
+	Version string `json:"version"`
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	SHA256  string `json:"sha256"` // of the leaf certificate
+}
+
+// fetchRecord is what the HTTP layer saw of the last download of a file
+type fetchRecord struct {
+	URL    string
+	TLS    *TLSPeer
+	Cached bool
+}
+
+var (
+	fetchMu  sync.Mutex
+	fetchLog = map[string]fetchRecord{} // by requested file name
+)
+
+// logFetch remembers the final URL and TLS peer of a successful GET, by
+// the name of the file requested, since redirects rename it
+func logFetch(req *http.Request, resp *http.Response) {
+	if req.Method != http.MethodGet || resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return
+	}
+	record := fetchRecord{URL: resp.Request.URL.Redacted()}
+	if state := resp.TLS; state != nil && len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		record.TLS = &TLSPeer{
+			Version: tls.VersionName(state.Version),
+			Subject: leaf.Subject.String(),
+			Issuer:  leaf.Issuer.String(),
+			SHA256:  fmt.Sprintf("%x", sha256.Sum256(leaf.Raw)),
+		}
+	}
+	fetchMu.Lock()
+	fetchLog[path.Base(req.URL.Path)] = record
+	fetchMu.Unlock()
+}
+
+// logCacheHit remembers that a file came from the download cache
+func logCacheHit(key string) {
+	fetchMu.Lock()
+	fetchLog[path.Base(key)] = fetchRecord{Cached: true}
+	fetchMu.Unlock()
+}
+
+// forgetFetches drops what earlier downloads recorded, before the download
+// of an artifact whose provenance is kept
+func forgetFetches() {
+	fetchMu.Lock()
+	fetchLog = map[string]fetchRecord{}
+	fetchMu.Unlock()
+}
+
+// servedBy returns the source a file came from: the mirror a multi-source
+// picked, or the only source
+func servedBy(source Source, file string) string {
+	for i := len(runSummary.SourceChoices) - 1; i >= 0; i-- {
+		if runSummary.SourceChoices[i].File == file {
+			return runSummary.SourceChoices[i].Source
+		}
+	}
+	return source.Name()
+}
+
+// newProvenance describes an artifact just downloaded from source and
+// installed at path. published is the digest it was checked against.
+func newProvenance(component, artifact, path string, source Source, digest, published string) Provenance {
+	p := Provenance{
+		Component:    component,
+		Artifact:     artifact,
+		Path:         path,
+		Source:       servedBy(source, artifact),
+		SHA256:       digest,
+		Published:    published,
+		Verification: VERIFIED_NONE,
+		FetchedAt:    time.Now().UTC(),
+	}
+	p.Official = p.Source == githubSource{}.Name()
+	switch {
+	case published != "" && opts.ManifestKey != "":
+		p.Verification = VERIFIED_SIGNED
+	case published != "":
+		p.Verification = VERIFIED_CHECKSUM
+	}
+	fetchMu.Lock()
+	record := fetchLog[artifact]
+	fetchMu.Unlock()
+	p.URL, p.TLS, p.Cached = record.URL, record.TLS, record.Cached
+	return p
+}
+
+// recordProvenance adds or replaces the provenance of a component
+func (r *Receipt) recordProvenance(p Provenance) {
+	for i, existing := range r.Provenance {
+		if existing.Component == p.Component {
+			r.Provenance[i] = p
+			return
+		}
+	}
+	r.Provenance = append(r.Provenance, p)
+}
+
+// checkProvenance detects installed files that no longer match the digest
+// recorded when they were downloaded
+func checkProvenance(receipt *Receipt) []string {
+	var found []string
+	for _, p := range receipt.Provenance {
+		digest, _, err := fileSHA256(p.Path)
+		switch {
+		case err != nil:
+			found = append(found, fmt.Sprintf("%s: %v", filepath.Base(p.Path), err))
+		case digest != p.SHA256:
+			found = append(found, fmt.Sprintf("%s changed since it was installed from %s", p.Path, p.Source))
+		}
+	}
+	return found
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProvenanceRecordsTLSPeer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cdn/vibe-linux-x86_64" {
+			http.Redirect(w, r, "/cdn/vibe-linux-x86_64", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("binary"))
+	}))
+	defer server.Close()
+	savedClient := http.DefaultClient
+	http.DefaultClient = server.Client()
+	defer func() { http.DefaultClient = savedClient }()
+
+	forgetFetches()
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := downloadFileOnce(server.URL+"/releases/v1.0.0/vibe-linux-x86_64", dest, 0); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	digest, _, _ := fileSHA256(dest)
+
+	p := newProvenance("vibe", "vibe-linux-x86_64", dest, mirrorSource{baseURL: server.URL}, digest, digest)
+	if p.URL != server.URL+"/cdn/vibe-linux-x86_64" {
+		t.Errorf("URL = %q, want the redirect target", p.URL)
+	}
+	if p.TLS == nil || !strings.HasPrefix(p.TLS.Version, "TLS") || len(p.TLS.SHA256) != 64 {
+		t.Errorf("TLS = %+v, want the peer certificate", p.TLS)
+	}
+	if p.Official || p.Cached || p.Verification != VERIFIED_CHECKSUM {
+		t.Errorf("provenance = %+v, want an uncached mirror download checked against a checksum", p)
+	}
+}
+
+func TestProvenanceVerification(t *testing.T) {
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	forgetFetches()
+	logCacheHit("GitHub releases/v1.0.0/vibe-linux-x86_64")
+
+	opts.ManifestKey = "key.pem"
+	p := newProvenance("vibe", "vibe-linux-x86_64", "/bin/vibe", githubSource{}, "ab", "cd")
+	if !p.Official || !p.Cached || p.Verification != VERIFIED_SIGNED {
+		t.Errorf("provenance = %+v, want an official cached download checked against a signed manifest", p)
+	}
+	opts.ManifestKey = ""
+	if p := newProvenance("grammar", "g.wasm", "/g.wasm", githubSource{}, "ab", ""); p.Verification != VERIFIED_NONE {
+		t.Errorf("Verification = %q without a published digest, want %q", p.Verification, VERIFIED_NONE)
+	}
+}
+
+func TestCheckProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vibe")
+	os.WriteFile(path, []byte("binary"), 0755)
+	digest, _, _ := fileSHA256(path)
+	receipt := &Receipt{}
+	receipt.recordProvenance(Provenance{Component: "vibe", Path: path, SHA256: "stale", Source: "fake"})
+	receipt.recordProvenance(Provenance{Component: "vibe", Path: path, SHA256: digest, Source: "fake"})
+	if len(receipt.Provenance) != 1 {
+		t.Fatalf("recorded %d entries for one component", len(receipt.Provenance))
+	}
+	if found := checkProvenance(receipt); len(found) != 0 {
+		t.Errorf("checkProvenance = %v, want nothing", found)
+	}
+	os.WriteFile(path, []byte("tampered"), 0755)
+	if found := checkProvenance(receipt); len(found) != 1 || !strings.Contains(found[0], "changed since it was installed from fake") {
+		t.Errorf("checkProvenance = %v, want the modified file", found)
+	}
+}
+
+func TestCheckInstalledFiles(t *testing.T) {
+	dir := t.TempDir()
+	tool, crate := filepath.Join(dir, "tool"), filepath.Join(dir, "surreal")
+	os.WriteFile(tool, []byte("tool"), 0755)
+	os.WriteFile(crate, []byte("surreal"), 0755)
+	receipt := &Receipt{}
+	receipt.recordFile("tool", "v1.0.0", tool)
+	receipt.recordFile("tool", "v1.0.1", tool)
+	receipt.recordFile("surrealdb", "2.0.0", crate)
+	if len(receipt.Files) != 2 || receipt.Files[0].Version != "v1.0.1" {
+		t.Fatalf("Files = %+v, want one entry per path", receipt.Files)
+	}
+	if found := checkInstalledFiles(receipt); len(found) != 0 {
+		t.Errorf("checkInstalledFiles = %v, want nothing", found)
+	}
+
+	os.WriteFile(tool, []byte("tampered"), 0755)
+	os.Remove(crate)
+	found := checkInstalledFiles(receipt)
+	if len(found) != 2 || !strings.Contains(found[0], "changed since it was installed") || !strings.Contains(found[1], "of surrealdb is missing") {
+		t.Errorf("checkInstalledFiles = %v, want the changed and the missing file", found)
+	}
+
+	receipt.pruneFiles()
+	if len(receipt.Files) != 1 || receipt.Files[0].Path != tool {
+		t.Errorf("pruneFiles kept %+v, want only the existing file", receipt.Files)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PROXY_ENV_VARS are the variables that configure a proxy explicitly; when
+// any is set the system settings are left alone
+var PROXY_ENV_VARS = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy"}
+
+// systemProxy is the proxy configured in the OS settings
+type systemProxy struct {
+	HTTP   string   // host:port for http:// URLs
+	HTTPS  string   // host:port for https:// URLs
+	PAC    string   // proxy auto-config script URL
+	Bypass []string // hosts reached directly, in NO_PROXY form
+}
+
+// bypassEntry converts an OS proxy exception ("<local>", "*.corp.example")
+// to NO_PROXY form, or "" when NO_PROXY cannot express it
+func bypassEntry(entry string) string {
+	entry = strings.TrimSpace(entry)
+	switch {
+	case entry == "":
+		return ""
+	case entry == "<local>":
+		return "localhost,127.0.0.1,::1"
+	case strings.HasPrefix(entry, "*."):
+		entry = entry[1:]
+	}
+	if strings.Contains(entry, "*") {
+		return "" // wildcards other than a leading *. (10.*) have no NO_PROXY form
+	}
+	return entry
+}
+
+// scutilPattern matches a "Key : value" line of `scutil --proxy`
+var scutilPattern = regexp.MustCompile(`^\s*(\S+)\s*:\s*(.*?)\s*$`)
+
+// parseScutilProxy reads the macOS proxy settings printed by `scutil --proxy`
+func parseScutilProxy(out string) systemProxy {
+	values := map[string]string{}
+	var p systemProxy
+	inExceptions := false
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "ExceptionsList") {
+			inExceptions = true
+			continue
+		}
+		m := scutilPattern.FindStringSubmatch(line)
+		if inExceptions {
+			if strings.TrimSpace(line) == "}" {
+				inExceptions = false
+			} else if m != nil {
+				if entry := bypassEntry(m[2]); entry != "" {
+					p.Bypass = append(p.Bypass, entry)
+				}
+			}
+			continue
+		}
+		if m != nil {
+			values[m[1]] = m[2]
+		}
+	}
+
+	if values["HTTPEnable"] == "1" && values["HTTPProxy"] != "" {
+		p.HTTP = values["HTTPProxy"] + ":" + values["HTTPPort"]
+	}
+	if values["HTTPSEnable"] == "1" && values["HTTPSProxy"] != "" {
+		p.HTTPS = values["HTTPSProxy"] + ":" + values["HTTPSPort"]
+	}
+	if values["ProxyAutoConfigEnable"] == "1" {
+		p.PAC = values["ProxyAutoConfigURLString"]
+	}
+	return p
+}
+
+// parseWindowsProxy reads the Internet Settings values printed by
+// `reg query`: ProxyServer is "host:port" for every protocol or
+// "http=host:port;https=host:port"
+func parseWindowsProxy(out string) systemProxy {
+	values := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.HasPrefix(fields[1], "REG_") {
+			values[fields[0]] = strings.Join(fields[2:], " ")
+		}
+	}
+
+	var p systemProxy
+	if values["ProxyEnable"] == "0x1" {
+		p.HTTP, p.HTTPS = splitProxyServer(values["ProxyServer"])
+		p.Bypass = splitBypass(values["ProxyOverride"])
+	}
+	p.PAC = values["AutoConfigURL"]
+	return p
+}
+
+// parseWinHTTPProxy reads the machine-wide proxy printed by
+// `netsh winhttp show proxy`, which services and tools without user
+// settings use
+func parseWinHTTPProxy(out string) systemProxy {
+	var p systemProxy
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Proxy Server(s)":
+			p.HTTP, p.HTTPS = splitProxyServer(strings.TrimSpace(value))
+		case "Bypass List":
+			p.Bypass = splitBypass(strings.TrimSpace(value))
+		}
+	}
+	return p
+}
+
+// splitProxyServer splits a Windows proxy server list into the HTTP and
+// HTTPS proxies
+func splitProxyServer(server string) (httpProxy, httpsProxy string) {
+	if server == "" {
+		return "", ""
+	}
+	if !strings.Contains(server, "=") {
+		return server, server
+	}
+	for _, part := range strings.Split(server, ";") {
+		scheme, addr, _ := strings.Cut(part, "=")
+		switch strings.ToLower(strings.TrimSpace(scheme)) {
+		case "http":
+			httpProxy = addr
+		case "https":
+			httpsProxy = addr
+		}
+	}
+	return httpProxy, httpsProxy
+}
+
+// splitBypass converts a ;-separated Windows bypass list
+func splitBypass(list string) []string {
+	var bypass []string
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ';' || r == ',' }) {
+		if entry = bypassEntry(entry); entry != "" {
+			bypass = append(bypass, entry)
+		}
+	}
+	return bypass
+}
+
+// pacProxyPattern matches a "PROXY host:port" result in a PAC script
+var pacProxyPattern = regexp.MustCompile(`PROXY\s+([A-Za-z0-9.\-\[\]:]+:\d+)`)
+
+// pacProxy finds the proxy of a PAC script. Without a JavaScript engine
+// only the common corporate script is understood: one proxy for
+// everything outside a few DIRECT exceptions.
+func pacProxy(script string) (string, error) {
+	proxies := map[string]bool{}
+	var first string
+	for _, m := range pacProxyPattern.FindAllStringSubmatch(script, -1) {
+		if !proxies[m[1]] {
+			proxies[m[1]] = true
+			if first == "" {
+				first = m[1]
+			}
+		}
+	}
+	switch len(proxies) {
+	case 0:
+		return "", nil
+	case 1:
+		return first, nil
+	}
+	return "", fmt.Errorf("the proxy auto-config script chooses between %d proxies", len(proxies))
+}
+
+// fetchPAC downloads a proxy auto-config script
+func fetchPAC(url string) (string, error) {
+	if path, ok := strings.CutPrefix(url, "file://"); ok {
+		data, err := os.ReadFile(path)
+		return string(data), err
+	}
+	resp, err := httpGet(url, opts.APITimeout)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	return string(data), err
+}
+
+// readSystemProxy queries the OS proxy settings
+func readSystemProxy() systemProxy {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.CommandContext(ctx, "scutil", "--proxy").Output()
+		if err != nil {
+			return systemProxy{}
+		}
+		return parseScutilProxy(string(out))
+	case "windows":
+		out, err := exec.CommandContext(ctx, "reg", "query", `HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`).Output()
+		if err == nil {
+			if p := parseWindowsProxy(string(out)); p.HTTP != "" || p.HTTPS != "" || p.PAC != "" {
+				return p
+			}
+		}
+		out, err = exec.CommandContext(ctx, "netsh", "winhttp", "show", "proxy").Output()
+		if err != nil {
+			return systemProxy{}
+		}
+		return parseWinHTTPProxy(string(out))
+	}
+	return systemProxy{}
+}
+
+// proxyURL makes a host:port proxy address a URL
+func proxyURL(addr string) string {
+	if strings.Contains(addr, "://") {
+		return addr
+	}
+	return "http://" + addr
+}
+
+// systemProxyNote tells the install what configureSystemProxy did. It is
+// not printed right away, to keep the output of commands like latest clean.
+var systemProxyNote string
+
+// configureSystemProxy applies the OS proxy settings on Windows and macOS
+// when no proxy variable is set. They are exported so cargo, curl and the
+// other tools the installer runs use them as well.
+func configureSystemProxy() {
+	for _, name := range PROXY_ENV_VARS {
+		if os.Getenv(name) != "" {
+			return
+		}
+	}
+	p := readSystemProxy()
+
+	if p.HTTP == "" && p.HTTPS == "" && p.PAC != "" {
+		script, err := fetchPAC(p.PAC)
+		if err != nil {
+			systemProxyNote = fmt.Sprintf("⚠️  Could not read the proxy auto-config script %s: %v", p.PAC, err)
+			return
+		}
+		proxy, err := pacProxy(script)
+		if err != nil {
+			systemProxyNote = fmt.Sprintf("⚠️  %v; set HTTPS_PROXY to the proxy to use", err)
+			return
+		}
+		p.HTTP, p.HTTPS = proxy, proxy
+	}
+	if p.HTTP == "" && p.HTTPS == "" {
+		return
+	}
+
+	if p.HTTPS != "" {
+		os.Setenv("HTTPS_PROXY", proxyURL(p.HTTPS))
+	}
+	if p.HTTP != "" {
+		os.Setenv("HTTP_PROXY", proxyURL(p.HTTP))
+	}
+	if len(p.Bypass) > 0 && os.Getenv("NO_PROXY") == "" && os.Getenv("no_proxy") == "" {
+		os.Setenv("NO_PROXY", strings.Join(p.Bypass, ","))
+	}
+	shown := p.HTTPS
+	if shown == "" {
+		shown = p.HTTP
+	}
+	systemProxyNote = "🌐 Using the system proxy " + shown
+}
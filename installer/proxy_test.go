@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScutilProxy(t *testing.T) {
+	out := `<dictionary> {
+  ExceptionsList : <array> {
+    0 : *.local
+    1 : 169.254/16
+    2 : intranet.corp.example
+  }
+  FTPPassive : 1
+  HTTPEnable : 1
+  HTTPPort : 3128
+  HTTPProxy : proxy.corp.example
+  HTTPSEnable : 1
+  HTTPSPort : 3129
+  HTTPSProxy : proxy.corp.example
+  ProxyAutoConfigEnable : 0
+}
+`
+	p := parseScutilProxy(out)
+	want := systemProxy{
+		HTTP:   "proxy.corp.example:3128",
+		HTTPS:  "proxy.corp.example:3129",
+		Bypass: []string{".local", "169.254/16", "intranet.corp.example"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("parseScutilProxy = %+v, want %+v", p, want)
+	}
+
+	pac := parseScutilProxy("<dictionary> {\n  ProxyAutoConfigEnable : 1\n  ProxyAutoConfigURLString : http://wpad.corp.example/proxy.pac\n}\n")
+	if pac.PAC != "http://wpad.corp.example/proxy.pac" || pac.HTTPS != "" {
+		t.Errorf("PAC settings parsed as %+v", pac)
+	}
+}
+
+func TestParseWindowsProxy(t *testing.T) {
+	out := `
+HKEY_CURRENT_USER\Software\Microsoft\Windows\CurrentVersion\Internet Settings
+    ProxyEnable    REG_DWORD    0x1
+    ProxyServer    REG_SZ    http=proxy.corp.example:8080;https=proxy.corp.example:8443;ftp=ftp.corp.example:21
+    ProxyOverride    REG_SZ    <local>;*.corp.example;10.*
+`
+	p := parseWindowsProxy(out)
+	want := systemProxy{
+		HTTP:   "proxy.corp.example:8080",
+		HTTPS:  "proxy.corp.example:8443",
+		Bypass: []string{"localhost,127.0.0.1,::1", ".corp.example"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("parseWindowsProxy = %+v, want %+v", p, want)
+	}
+
+	disabled := parseWindowsProxy("    ProxyEnable    REG_DWORD    0x0\n    ProxyServer    REG_SZ    proxy:8080\n")
+	if disabled.HTTP != "" {
+		t.Errorf("a disabled proxy was used: %+v", disabled)
+	}
+
+	winhttp := parseWinHTTPProxy("Current WinHTTP proxy settings:\n\n    Proxy Server(s) :  proxy.corp.example:8080\n    Bypass List     :  <local>\n")
+	if winhttp.HTTPS != "proxy.corp.example:8080" || len(winhttp.Bypass) != 1 {
+		t.Errorf("parseWinHTTPProxy = %+v", winhttp)
+	}
+}
+
+func TestPacProxy(t *testing.T) {
+	script := `function FindProxyForURL(url, host) {
+	if (isPlainHostName(host) || dnsDomainIs(host, ".corp.example")) return "DIRECT";
+	return "PROXY proxy.corp.example:8080; DIRECT";
+}`
+	if proxy, err := pacProxy(script); err != nil || proxy != "proxy.corp.example:8080" {
+		t.Errorf("pacProxy = %q, %v", proxy, err)
+	}
+
+	if _, err := pacProxy(`return host == "a" ? "PROXY a.example:80" : "PROXY b.example:80";`); err == nil {
+		t.Errorf("a script choosing between proxies was accepted")
+	}
+	if proxy, err := pacProxy(`return "DIRECT";`); err != nil || proxy != "" {
+		t.Errorf("direct-only script = %q, %v", proxy, err)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QUARANTINE_DIR holds downloads under the cache directory until they are
+// verified. Nothing is written to an install location before that, so an
+// interrupted or tampered download never replaces a working file.
+const QUARANTINE_DIR = "quarantine"
+
+// errChecksumMismatch reports a download that differs from its published
+// digest: corrupted in transit or in a cache, or tampered with
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// wasmMagic starts every WebAssembly module: the magic number \0asm and
+// binary format version 1. Anything shorter cannot be a grammar.
+var wasmMagic = []byte("\x00asm\x01\x00\x00\x00")
+
+// getQuarantineDir returns the staging directory for downloads
+func getQuarantineDir() string {
+	return filepath.Join(getCacheDir(), QUARANTINE_DIR)
+}
+
+// quarantinePath reserves a unique staging path for a download of name
+func quarantinePath(name string) (string, error) {
+	dir := getQuarantineDir()
+	if err := ensureDir(dir, MODE_PRIVATE_DIR); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", name, err)
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
+// releaseDigests collects the sha256 digests a release publishes, from its
+// SHA256SUMS file and its manifest. Either may be missing, unless
+// --manifest-key requires a signed manifest: SHA256SUMS is then only
+// trusted when the manifest lists its digest.
+//
+// With --paranoid, only digests that two independent origins agree on are
+// returned; see crossCheckDigests.
+func releaseDigests(source Source, version string) (map[string]string, error) {
+	sums := map[string]string{}
+	sumsDigest := ""
+	if path, err := quarantinePath("SHA256SUMS"); err == nil {
+		if source.FetchAsset(version, "SHA256SUMS", path) == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				sums = parseChecksums(string(data))
+				sumsDigest, _, _ = fileSHA256(path)
+			}
+		}
+		os.Remove(path)
+	}
+
+	listedDigests := map[string]string{}
+	manifest, err := fetchReleaseManifest(source, version)
+	if err != nil && opts.ManifestKey != "" {
+		return nil, fmt.Errorf("cannot verify release %s: %w", version, err)
+	}
+	if err == nil {
+		if opts.ManifestKey != "" {
+			listed := ""
+			for _, a := range manifest.Assets {
+				if filepath.Base(a.Path) == "SHA256SUMS" {
+					listed = strings.ToLower(a.SHA256)
+				}
+			}
+			if sumsDigest != "" && listed != "" && sumsDigest != listed {
+				return nil, fmt.Errorf("SHA256SUMS of %s does not match its signed manifest", version)
+			}
+			if listed == "" {
+				sums = map[string]string{}
+			}
+		}
+		for _, a := range manifest.Assets {
+			listedDigests[filepath.Base(a.Path)] = strings.ToLower(a.SHA256)
+		}
+	}
+
+	if opts.Paranoid {
+		return crossCheckDigests(version, []digestOrigin{
+			{Name: "SHA256SUMS", Digests: sums},
+			{Name: "the release manifest", Digests: listedDigests},
+			{Name: "the release API", Digests: apiDigests(source, version)},
+		})
+	}
+	digests := sums
+	for name, digest := range listedDigests {
+		digests[name] = digest
+	}
+	return digests, nil
+}
+
+// requireDigest refuses a release asset or grammar nothing publishes a
+// checksum for, unless --allow-unverified accepts checking only that it is
+// not empty
+func requireDigest(version, name, want string) error {
+	if want != "" {
+		return nil
+	}
+	if !opts.AllowUnverified {
+		return fmt.Errorf("no checksum is published for %s of %s; pass --allow-unverified to install it anyway", name, version)
+	}
+	warnf("No checksum is published for %s of %s; installing it without one", name, version)
+	return nil
+}
+
+// verifyDigest checks a staged file against its published digest, deleting
+// it on a mismatch. Files without a published digest only need to be
+// non-empty; release assets, grammars and downloaded modules go through
+// requireDigest first.
+func verifyDigest(path, name, want string) error {
+	digest, size, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		os.Remove(path)
+		return fmt.Errorf("%s downloaded empty", name)
+	}
+	if want != "" && !strings.EqualFold(digest, want) {
+		os.Remove(path)
+		return fmt.Errorf("%w for %s: got %s, release publishes %s", errChecksumMismatch, name, short(digest), short(want))
+	}
+	if want != "" {
+		fmt.Printf("🔐 Verified %s (sha256 %s)\n", name, short(digest))
+	}
+	return nil
+}
+
+// verifyWasm checks that a staged grammar is a WebAssembly module rather
+// than an error page or a truncated download
+func verifyWasm(path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 64)
+	n, _ := io.ReadFull(f, header)
+	f.Close()
+	header = header[:n]
+	if bytes.HasPrefix(header, wasmMagic) {
+		return nil
+	}
+	os.Remove(path)
+	switch {
+	case n < len(wasmMagic) && bytes.HasPrefix(wasmMagic, header):
+		return fmt.Errorf("received non-WASM content for %s: %d bytes, shorter than a WebAssembly header", name, n)
+	case bytes.HasPrefix(header, wasmMagic[:4]):
+		return fmt.Errorf("received non-WASM content for %s: unsupported WebAssembly version % x", name, header[4:min(n, 8)])
+	}
+	return fmt.Errorf("received non-WASM content for %s, starting %q", name, printable(header))
+}
+
+// printable shortens data to its first line of text, for error messages
+// quoting what a server sent instead of a file
+func printable(data []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	return strings.ToValidUTF8(line, "?")
+}
+
+// promote moves a verified file from quarantine to dest. The file is
+// written next to dest under a temporary name and renamed over it, so dest
+// is always either the old file or the complete new one.
+func promote(staged, dest string, mode os.FileMode) error {
+	tmp := fmt.Sprintf("%s.%d.tmp", dest, os.Getpid())
+	if err := os.Rename(staged, tmp); err != nil {
+		// Quarantine and install location are on different filesystems
+		if err := copyFile(staged, tmp, mode); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		os.Remove(staged)
+	}
+	if posixModes() {
+		if err := os.Chmod(tmp, mode); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
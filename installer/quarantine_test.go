@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuarantinePipeline(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	dest := filepath.Join(t.TempDir(), "vibe")
+	os.WriteFile(dest, []byte("old binary"), 0755)
+
+	staged, err := quarantinePath("vibe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(staged, getQuarantineDir()) {
+		t.Errorf("staged at %s, outside %s", staged, getQuarantineDir())
+	}
+
+	// A download that does not match its digest never reaches dest
+	os.WriteFile(staged, []byte("tampered"), 0644)
+	if err := verifyDigest(staged, "vibe", sha256Hex("new binary")); err == nil {
+		t.Fatal("verifyDigest accepted a tampered download")
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Error("tampered download left in quarantine")
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "old binary" {
+		t.Errorf("dest = %q after a rejected download", data)
+	}
+
+	os.WriteFile(staged, []byte("new binary"), 0644)
+	if err := verifyDigest(staged, "vibe", strings.ToUpper(sha256Hex("new binary"))); err != nil {
+		t.Fatalf("verifyDigest rejected a good download: %v", err)
+	}
+	if err := promote(staged, dest, MODE_BINARY); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "new binary" {
+		t.Errorf("dest = %q after promotion", data)
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Error("promoted file left in quarantine")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 1 {
+		t.Errorf("install directory has %d entries, want only the binary", len(entries))
+	}
+}
+
+func TestVerifyWasm(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.wasm")
+	bad := filepath.Join(dir, "bad.wasm")
+	os.WriteFile(good, append(wasmMagic, 1, 0, 0, 0), 0644)
+	os.WriteFile(bad, []byte("<html>Not Found</html>"), 0644)
+
+	if err := verifyWasm(good, "good.wasm"); err != nil {
+		t.Errorf("verifyWasm rejected a module: %v", err)
+	}
+	if err := verifyWasm(bad, "bad.wasm"); err == nil || !strings.Contains(err.Error(), "received non-WASM content") || !strings.Contains(err.Error(), "<html>") {
+		t.Errorf("verifyWasm of an HTML page = %v", err)
+	}
+	for name, data := range map[string][]byte{"truncated": wasmMagic[:6], "version 2": []byte("\x00asm\x02\x00\x00\x00")} {
+		os.WriteFile(bad, data, 0644)
+		if err := verifyWasm(bad, "bad.wasm"); err == nil {
+			t.Errorf("verifyWasm accepted a %s module", name)
+		}
+	}
+	if err := verifyDigest(good, "empty", ""); err != nil {
+		t.Errorf("verifyDigest without a digest = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// immutableSystem describes a distribution whose system directories are
+// read-only by design, with the way it expects user software installed
+type immutableSystem struct {
+	Name string
+	Hint string
+}
+
+// detectImmutableSystem recognises immutable distributions and read-only
+// containers from their marker files, rooted at root for tests
+func detectImmutableSystem(root string) (immutableSystem, bool) {
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(root, path))
+		return err == nil
+	}
+	osRelease, _ := os.ReadFile(filepath.Join(root, "etc", "os-release"))
+
+	switch {
+	case exists("etc/NIXOS"):
+		return immutableSystem{"NixOS", "the Nix store and /usr are immutable; install into your home and add it to PATH with home-manager's home.sessionPath, or package vibe in a Nix derivation"}, true
+	case exists("run/ostree-booted"):
+		return immutableSystem{"an rpm-ostree system (Fedora Silverblue, Kinoite, Bazzite)", "/usr is read-only; install into your home, or inside a toolbox or distrobox container"}, true
+	case strings.Contains(string(osRelease), "ID=steamos"):
+		return immutableSystem{"SteamOS", "the root filesystem is read-only and reset by updates; only your home persists"}, true
+	case detectContainer(root, os.Getenv) != "":
+		return immutableSystem{"a container", "mount a writable volume and point XDG_BIN_HOME at it, or install at image build time"}, true
+	}
+	return immutableSystem{}, false
+}
+
+// probeWritable checks that dir can be created and written, by writing a
+// scratch file into it or into its nearest existing parent
+func probeWritable(dir string) error {
+	probe := dir
+	for {
+		if info, err := os.Stat(probe); err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", probe)
+			}
+			break
+		}
+		parent := filepath.Dir(probe)
+		if parent == probe {
+			return fmt.Errorf("no existing parent of %s", dir)
+		}
+		probe = parent
+	}
+	f, err := os.CreateTemp(probe, ".vibe-write-probe-")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// installPathFallbacks lists durable writable alternatives to the default
+// install path, most durable first
+func installPathFallbacks() []string {
+	var candidates []string
+	if dir := os.Getenv("XDG_BIN_HOME"); filepath.IsAbs(dir) {
+		candidates = append(candidates, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".local", "bin"))
+	}
+	return candidates
+}
+
+// tempInstallPath creates the last resort, which does not survive a reboot:
+// a private directory under the temp directory. Its name is predictable, so
+// another user may have created it first or left a symlink there; unless it
+// is the user's own directory closed to everyone else, a fresh randomly
+// named one is used instead. --check only computes the path.
+func tempInstallPath() (string, error) {
+	dir := filepath.Join(os.TempDir(), "vibe-"+strconv.Itoa(os.Getuid()))
+	if opts.Check {
+		return filepath.Join(dir, "bin"), nil
+	}
+	err := os.Mkdir(dir, MODE_PRIVATE_DIR)
+	if err == nil && posixModes() {
+		err = os.Chmod(dir, MODE_PRIVATE_DIR)
+	}
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		err = checkPrivateDir(dir)
+	}
+	if err != nil {
+		warnf("Not using %s: %v", dir, err)
+		if dir, err = os.MkdirTemp("", "vibe-"); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "bin"), nil
+}
+
+// checkPrivateDir makes sure dir is a real directory that only the current
+// user can reach
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return fmt.Errorf("it is a symbolic link")
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("it is not a directory")
+	}
+	if !posixModes() {
+		return nil
+	}
+	if uid, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return fmt.Errorf("it belongs to user %d", uid)
+	}
+	if info.Mode().Perm() != MODE_PRIVATE_DIR {
+		return fmt.Errorf("it has mode %o, not %o", info.Mode().Perm(), MODE_PRIVATE_DIR)
+	}
+	return nil
+}
+
+// writableInstallPath returns installPath when it can be written, and
+// otherwise explains why not and returns the first writable fallback
+func writableInstallPath(installPath string) (string, error) {
+	err := probeWritable(installPath)
+	if err == nil {
+		return installPath, nil
+	}
+
+	reason := err.Error()
+	if errors.Is(err, syscall.EROFS) {
+		reason = "it is on a read-only filesystem"
+	} else if errors.Is(err, os.ErrPermission) {
+		reason = "you cannot write to it"
+	}
+	warnf("Cannot install into %s: %s", installPath, reason)
+	system, immutable := detectImmutableSystem("/")
+	if immutable {
+		fmt.Printf("🧊 This is %s: %s\n", system.Name, system.Hint)
+	}
+
+	for _, candidate := range installPathFallbacks() {
+		if filepath.Clean(candidate) == filepath.Clean(installPath) || probeWritable(candidate) != nil {
+			continue
+		}
+		fmt.Printf("↪️  Installing into %s instead\n", candidate)
+		return candidate, nil
+	}
+	candidate, err := tempInstallPath()
+	if err != nil {
+		return "", fmt.Errorf("no writable install directory (%v); set XDG_BIN_HOME to a writable directory", err)
+	}
+	warnf("Falling back to %s, which is lost on reboot; set XDG_BIN_HOME to a persistent writable directory", candidate)
+	return candidate, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestDetectImmutableSystem(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("container", "")
+	for marker, want := range map[string]string{
+		"etc/NIXOS":         "NixOS",
+		"run/ostree-booted": "an rpm-ostree system (Fedora Silverblue, Kinoite, Bazzite)",
+		".dockerenv":        "a container",
+	} {
+		root := t.TempDir()
+		os.MkdirAll(filepath.Join(root, filepath.Dir(marker)), 0755)
+		os.WriteFile(filepath.Join(root, marker), nil, 0644)
+		if system, ok := detectImmutableSystem(root); !ok || system.Name != want {
+			t.Errorf("%s: detected %q, %v; want %q", marker, system.Name, ok, want)
+		}
+	}
+
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "etc"), 0755)
+	os.WriteFile(filepath.Join(root, "etc", "os-release"), []byte("NAME=\"SteamOS\"\nID=steamos\n"), 0644)
+	if system, ok := detectImmutableSystem(root); !ok || system.Name != "SteamOS" {
+		t.Errorf("SteamOS detected as %q, %v", system.Name, ok)
+	}
+
+	if _, ok := detectImmutableSystem(t.TempDir()); ok {
+		t.Errorf("an empty root was detected as immutable")
+	}
+}
+
+func TestWritableInstallPath(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := writableInstallPath(filepath.Join(dir, "new", "bin")); err != nil || got != filepath.Join(dir, "new", "bin") {
+		t.Errorf("writable path = %q, %v; want it unchanged", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Errorf("probing created the install directory")
+	}
+
+	// A path that cannot be created falls back to XDG_BIN_HOME
+	blocked := filepath.Join(dir, "file")
+	os.WriteFile(blocked, nil, 0644)
+	fallback := filepath.Join(t.TempDir(), "bin")
+	t.Setenv("XDG_BIN_HOME", fallback)
+	if got, err := writableInstallPath(filepath.Join(blocked, "bin")); err != nil || got != fallback {
+		t.Errorf("fallback = %q, %v; want %s", got, err, fallback)
+	}
+}
+
+func TestTempInstallPath(t *testing.T) {
+	if !posixModes() {
+		t.Skip("file modes and symlinks are not checked on Windows")
+	}
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	private := filepath.Join(tmp, "vibe-"+strconv.Itoa(os.Getuid()))
+
+	// --check reports the path without creating it
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts.Check = true
+	if got, err := tempInstallPath(); err != nil || got != filepath.Join(private, "bin") {
+		t.Fatalf("tempInstallPath() with --check = %q, %v; want %s", got, err, filepath.Join(private, "bin"))
+	}
+	if _, err := os.Lstat(private); !os.IsNotExist(err) {
+		t.Errorf("tempInstallPath() with --check created %s: %v", private, err)
+	}
+	opts.Check = false
+
+	got, err := tempInstallPath()
+	if err != nil || got != filepath.Join(private, "bin") {
+		t.Fatalf("tempInstallPath() = %q, %v; want %s", got, err, filepath.Join(private, "bin"))
+	}
+	if info, err := os.Lstat(private); err != nil || info.Mode().Perm() != MODE_PRIVATE_DIR {
+		t.Errorf("%s created as %v, %v; want mode %o", private, info.Mode(), err, MODE_PRIVATE_DIR)
+	}
+
+	// A directory others can reach, or a link planted in its place, is
+	// passed over for a fresh one
+	os.Chmod(private, 0755)
+	if got, err := tempInstallPath(); err != nil || filepath.Dir(got) == private {
+		t.Errorf("tempInstallPath() with an open directory = %q, %v", got, err)
+	}
+	os.Remove(private)
+	os.Symlink(t.TempDir(), private)
+	if got, err := tempInstallPath(); err != nil || filepath.Dir(got) == private {
+		t.Errorf("tempInstallPath() with a symlink = %q, %v", got, err)
+	}
+}
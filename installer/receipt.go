@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RECEIPT_FILE is the install state manifest kept in the vibe home directory
+const RECEIPT_FILE = "install-state.json"
+
+// Receipt records what the installer has done to this machine
+type Receipt struct {
+	Version     string      `json:"version"`
+	InstallPath string      `json:"install_path"`
+	InstalledAt time.Time   `json:"installed_at"`
+	Migrations  []string    `json:"migrations,omitempty"` // completed migration IDs
+	Changes     []EnvChange `json:"changes,omitempty"`    // environment modifications to revert
+
+	Packages       []InstalledPackage `json:"packages,omitempty"`        // dependencies installed via package managers
+	FailedModules  []string           `json:"failed_modules,omitempty"`  // modules that failed in the last run
+	Handshake      *Handshake         `json:"handshake,omitempty"`       // what the binaries reported after install
+	Grammars       []InstalledGrammar `json:"grammars,omitempty"`        // grammar files in the data directory
+	DataDir        string             `json:"data_dir,omitempty"`        // indexes and databases, when moved with data move
+	DataEncryption string             `json:"data_encryption,omitempty"` // how the data directory is encrypted, with --encrypt-data
+	InitializedAt  *time.Time         `json:"initialized_at,omitempty"`  // when vibe init --defaults first succeeded
+	Provenance     []Provenance       `json:"provenance,omitempty"`      // where each downloaded artifact came from
+	Files          []InstalledFile    `json:"files,omitempty"`           // every file the install wrote
+}
+
+// InstalledFile is a file the installer wrote. The list is what uninstall,
+// repair and verify work from, rather than guessing paths.
+type InstalledFile struct {
+	Path      string `json:"path"`
+	Component string `json:"component"` // vibe, a module or a grammar package
+	Version   string `json:"version,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+}
+
+// InstalledGrammar is a grammar file the installer placed in the data
+// directory
+type InstalledGrammar struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	File    string `json:"file"`
+	SHA256  string `json:"sha256"`
+}
+
+// InstalledPackage is a dependency the installer installed through a
+// package manager such as cargo
+type InstalledPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Manager string `json:"manager"`
+}
+
+// getVibeHome returns the directory holding installer state and user
+// data: $XDG_DATA_HOME/vibe on Linux and ~/.vibe elsewhere, overridable
+// with VIBE_HOME
+func getVibeHome() string {
+	if home := os.Getenv("VIBE_HOME"); home != "" {
+		return home
+	}
+	if usesXDG() {
+		return filepath.Join(xdgDataHome(), XDG_APP_DIR)
+	}
+	return legacyVibeHome()
+}
+
+// getReceiptPath returns the location of the install receipt
+func getReceiptPath() string {
+	return filepath.Join(getVibeHome(), RECEIPT_FILE)
+}
+
+// loadReceipt reads the install receipt, returning an empty receipt when
+// nothing has been installed yet
+func loadReceipt() (*Receipt, error) {
+	data, err := os.ReadFile(getReceiptPath())
+	if os.IsNotExist(err) {
+		return &Receipt{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install receipt: %w", err)
+	}
+
+	var receipt Receipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse install receipt %s: %w", getReceiptPath(), err)
+	}
+	return &receipt, nil
+}
+
+// save writes the receipt atomically
+func (r *Receipt) save() error {
+	path := getReceiptPath()
+	if err := ensureDir(filepath.Dir(path), MODE_PRIVATE_DIR); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install receipt: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := writeFileMode(tmp, append(data, '\n'), MODE_DATA); err != nil {
+		return fmt.Errorf("failed to write install receipt: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write install receipt: %w", err)
+	}
+	return nil
+}
+
+// hasMigration reports whether a migration already completed
+func (r *Receipt) hasMigration(id string) bool {
+	for _, done := range r.Migrations {
+		if done == id {
+			return true
+		}
+	}
+	return false
+}
+
+// recordChange adds an environment change, replacing an earlier record of
+// the same change
+func (r *Receipt) recordChange(change EnvChange) {
+	for i, c := range r.Changes {
+		if c.Kind == change.Kind && c.Path == change.Path {
+			r.Changes[i] = change
+			return
+		}
+	}
+	r.Changes = append(r.Changes, change)
+}
+
+// recordGrammar adds or updates an installed grammar
+func (r *Receipt) recordGrammar(g InstalledGrammar) {
+	for i, existing := range r.Grammars {
+		if existing.Package == g.Package {
+			r.Grammars[i] = g
+			return
+		}
+	}
+	r.Grammars = append(r.Grammars, g)
+}
+
+// grammar returns a pinned grammar at the version to install: the pinned
+// one, or a newer one installed by `grammars update`
+func (r *Receipt) grammar(pinned Grammar) Grammar {
+	for _, g := range r.Grammars {
+		if g.Package != pinned.Package {
+			continue
+		}
+		installed, ok := parseVersion(g.Version)
+		want, okPinned := parseVersion(pinned.Version)
+		if ok && okPinned && compareVersions(installed, want) > 0 {
+			pinned.Version = g.Version
+		}
+	}
+	return pinned
+}
+
+// recordFile adds or replaces the entry of a file just written, hashing
+// its content
+func (r *Receipt) recordFile(component, version, path string) {
+	digest, _, err := fileSHA256(path)
+	if err != nil {
+		warnf("Could not checksum %s: %v", path, err)
+	}
+	f := InstalledFile{Path: path, Component: component, Version: version, SHA256: digest}
+	for i, existing := range r.Files {
+		if existing.Path == path {
+			r.Files[i] = f
+			return
+		}
+	}
+	r.Files = append(r.Files, f)
+}
+
+// pruneFiles forgets recorded files that no longer exist, such as the
+// binary of an earlier install directory deleted by hand, so they are not
+// reported as missing on every run
+func (r *Receipt) pruneFiles() {
+	var kept []InstalledFile
+	for _, f := range r.Files {
+		if _, err := os.Stat(f.Path); err == nil {
+			kept = append(kept, f)
+		}
+	}
+	r.Files = kept
+}
+
+// checkInstalledFiles detects recorded files that were deleted or changed
+// since the installer wrote them. Downloads are left to checkProvenance,
+// which can also say where they came from.
+func checkInstalledFiles(receipt *Receipt) []string {
+	downloaded := map[string]bool{}
+	for _, p := range receipt.Provenance {
+		downloaded[p.Path] = true
+	}
+	var found []string
+	for _, f := range receipt.Files {
+		if downloaded[f.Path] {
+			continue
+		}
+		digest, _, err := fileSHA256(f.Path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			found = append(found, fmt.Sprintf("%s of %s is missing", f.Path, f.Component))
+		case err != nil:
+			found = append(found, err.Error())
+		case f.SHA256 != "" && digest != f.SHA256:
+			found = append(found, fmt.Sprintf("%s of %s changed since it was installed", f.Path, f.Component))
+		}
+	}
+	return found
+}
+
+// recordPackage adds or updates an installed package
+func (r *Receipt) recordPackage(pkg InstalledPackage) {
+	for i, p := range r.Packages {
+		if p.Name == pkg.Name && p.Manager == pkg.Manager {
+			r.Packages[i] = pkg
+			return
+		}
+	}
+	r.Packages = append(r.Packages, pkg)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// INSTALL_LOCK_FILE marks an install in progress. A lock whose process is
+// gone is evidence of a crashed run.
+const INSTALL_LOCK_FILE = "install.lock"
+
+// RunLock is the content of the install lock
+type RunLock struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// heldLock is the lock this run holds, released by finishRun
+var heldLock string
+
+// getLockPath returns where the install lock lives
+func getLockPath() string {
+	return filepath.Join(getVibeHome(), INSTALL_LOCK_FILE)
+}
+
+// readRunLock reads an install lock; ok is false without one
+func readRunLock(path string) (lock RunLock, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RunLock{}, false
+	}
+	// An unreadable lock was cut short by the crash it records
+	json.Unmarshal(data, &lock)
+	return lock, true
+}
+
+// processAlive reports whether a process is still running
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true // FindProcess opens the process, failing once it has exited
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || os.IsPermission(err)
+}
+
+// acquireRunLock records this run in the install lock
+func acquireRunLock() error {
+	path := getLockPath()
+	if err := ensureDir(filepath.Dir(path), MODE_DIR); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, _ := json.Marshal(RunLock{PID: os.Getpid(), StartedAt: time.Now().UTC()})
+	if err := writeFileMode(path, data, MODE_DATA); err != nil {
+		return fmt.Errorf("failed to write install lock: %w", err)
+	}
+	heldLock = path
+	return nil
+}
+
+// releaseRunLock removes the install lock if this run holds it
+func releaseRunLock() {
+	if heldLock != "" {
+		os.Remove(heldLock)
+		heldLock = ""
+	}
+}
+
+// promoteTempPattern matches the temporary names promote and the shared
+// cache write files under before renaming them into place
+var promoteTempPattern = regexp.MustCompile(`\.\d+\.tmp$`)
+
+// findCrashLeftovers collects what a crashed run leaves behind: its lock,
+// downloads still in quarantine, files half moved into place and state
+// files half written
+func findCrashLeftovers(installPath string) []Leftover {
+	var leftovers []Leftover
+
+	lockPath := getLockPath()
+	if lock, ok := readRunLock(lockPath); ok && !processAlive(lock.PID) {
+		reason := "lock of an install that did not finish"
+		if lock.PID > 0 {
+			reason = fmt.Sprintf("lock of install pid %d started %s that did not finish", lock.PID, lock.StartedAt.Local().Format("2006-01-02 15:04"))
+		}
+		leftovers = append(leftovers, Leftover{lockPath, reason})
+	}
+
+	if entries, err := os.ReadDir(getQuarantineDir()); err == nil {
+		for _, entry := range entries {
+			reason := "download never verified and installed"
+			if strings.HasPrefix(entry.Name(), "rollback-") {
+				reason = "previous binary kept for an upgrade that did not finish"
+			}
+			leftovers = append(leftovers, Leftover{filepath.Join(getQuarantineDir(), entry.Name()), reason})
+		}
+	}
+
+	for _, dir := range []string{installPath, getDataDir(installPath)} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+		for _, path := range matches {
+			if promoteTempPattern.MatchString(path) {
+				leftovers = append(leftovers, Leftover{path, "file half moved into place"})
+			}
+		}
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(getVibeHome(), "*.tmp"))
+	for _, path := range matches {
+		leftovers = append(leftovers, Leftover{path, "state file half written"})
+	}
+	return leftovers
+}
+
+// recoverFromCrash refuses to run alongside another install, reports what
+// a crashed earlier run left behind and offers to clean it up, then takes
+// the install lock
+func recoverFromCrash(installPath string) error {
+	if lock, ok := readRunLock(getLockPath()); ok && lock.PID != os.Getpid() && processAlive(lock.PID) {
+		return fmt.Errorf("another install (pid %d) has been running since %s; wait for it to finish or remove %s if it is stuck",
+			lock.PID, lock.StartedAt.Local().Format("15:04:05"), getLockPath())
+	}
+
+	leftovers := findCrashLeftovers(installPath)
+	if len(leftovers) > 0 {
+		fmt.Printf("🩹 A previous install did not finish. It left:\n")
+		for _, l := range leftovers {
+			fmt.Printf("   • %s (%s)\n", l.Path, l.Reason)
+		}
+		if opts.Yes || confirm("Clean these up before installing?") {
+			for _, l := range leftovers {
+				if err := os.RemoveAll(l.Path); err != nil {
+					warnf("Could not remove %s: %v", l.Path, err)
+				}
+			}
+			fmt.Printf("✅ Cleaned up after the previous install\n")
+		} else {
+			fmt.Printf("⏭️  Leaving them in place; this install replaces what it needs (install-dotvibe cleanup --scan removes the rest)\n")
+		}
+	}
+
+	if receipt, err := loadReceipt(); err == nil && len(receipt.FailedModules) > 0 && !opts.RetryFailed {
+		fmt.Printf("💡 The previous install failed to install %s; rerun with --retry-failed to resume with just those\n",
+			strings.Join(receipt.FailedModules, ", "))
+	}
+
+	return acquireRunLock()
+}
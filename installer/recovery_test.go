@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindCrashLeftovers(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+
+	if leftovers := findCrashLeftovers(installPath); len(leftovers) != 0 {
+		t.Fatalf("clean state reported leftovers: %v", leftovers)
+	}
+
+	// A lock held by a process that has exited, staging and half-moved files
+	data, _ := json.Marshal(RunLock{PID: 1 << 30, StartedAt: time.Now()})
+	os.WriteFile(getLockPath(), data, 0644)
+	staged, _ := quarantinePath("vibe")
+	os.WriteFile(filepath.Join(installPath, "vibe.4242.tmp"), []byte("partial"), 0755)
+	os.WriteFile(filepath.Join(getVibeHome(), RECEIPT_FILE+".tmp"), []byte("{"), 0644)
+	os.WriteFile(filepath.Join(installPath, "notes.tmp"), []byte("user file"), 0644)
+
+	var found []string
+	for _, l := range findCrashLeftovers(installPath) {
+		found = append(found, l.Path)
+	}
+	want := []string{getLockPath(), staged, filepath.Join(installPath, "vibe.4242.tmp"), filepath.Join(getVibeHome(), RECEIPT_FILE+".tmp")}
+	if strings.Join(found, "\n") != strings.Join(want, "\n") {
+		t.Errorf("leftovers:\n%s\nwant:\n%s", strings.Join(found, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRecoverFromCrash(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	defer func(saved bool) { opts.Yes = saved }(opts.Yes)
+	opts.Yes = true
+	installPath := t.TempDir()
+
+	// A live lock from another process blocks the run
+	data, _ := json.Marshal(RunLock{PID: os.Getppid(), StartedAt: time.Now()})
+	os.MkdirAll(getVibeHome(), 0755)
+	os.WriteFile(getLockPath(), data, 0644)
+	if err := recoverFromCrash(installPath); err == nil {
+		t.Fatal("recoverFromCrash ran alongside a live install")
+	}
+
+	// A stale lock is cleaned up and replaced by ours
+	data, _ = json.Marshal(RunLock{PID: 1 << 30})
+	os.WriteFile(getLockPath(), data, 0644)
+	staged, _ := quarantinePath("vibe")
+	if err := recoverFromCrash(installPath); err != nil {
+		t.Fatal(err)
+	}
+	defer releaseRunLock()
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Error("staged download was not cleaned up")
+	}
+	if lock, ok := readRunLock(getLockPath()); !ok || lock.PID != os.Getpid() {
+		t.Errorf("lock = %+v, %v; want this process", lock, ok)
+	}
+
+	releaseRunLock()
+	if _, err := os.Stat(getLockPath()); !os.IsNotExist(err) {
+		t.Error("releaseRunLock left the lock behind")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// INSTALL_REPORT_FILE is the default name of the JSON install report
+const INSTALL_REPORT_FILE = "install-report.json"
+
+// getReportPath returns where the install report is written
+func getReportPath() string {
+	if opts.Report != "" {
+		return opts.Report
+	}
+	return filepath.Join(getVibeHome(), INSTALL_REPORT_FILE)
+}
+
+// installedChecksums hashes the installed binary and grammar files so the
+// report records exactly what landed on disk
+func installedChecksums(binaryPath, installPath string) map[string]string {
+	paths := []string{binaryPath}
+	for _, g := range GRAMMARS {
+		paths = append(paths, filepath.Join(getDataDir(installPath), g.File))
+	}
+
+	checksums := map[string]string{}
+	for _, path := range paths {
+		sum, _, err := fileSHA256(path)
+		if err != nil {
+			warnf("Could not checksum %s: %v", path, err)
+			continue
+		}
+		checksums[path] = sum
+	}
+	return checksums
+}
+
+// writeInstallReport writes the run summary as indented JSON, replacing any
+// report from a previous run
+func writeInstallReport(path string, summary *RunSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp := path + ".tmp"
+	if err := writeFileMode(tmp, append(data, '\n'), MODE_DATA); err != nil {
+		return fmt.Errorf("failed to write install report: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetReportPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("VIBE_HOME", home)
+	saved := opts
+	defer func() { opts = saved }()
+
+	opts.Report = ""
+	if got, want := getReportPath(), filepath.Join(home, INSTALL_REPORT_FILE); got != want {
+		t.Errorf("getReportPath() = %v, want %v", got, want)
+	}
+
+	opts.Report = "/artifacts/report.json"
+	if got := getReportPath(); got != "/artifacts/report.json" {
+		t.Errorf("getReportPath() with --report = %v", got)
+	}
+}
+
+func TestWriteInstallReport(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "vibe")
+	os.WriteFile(binary, []byte("binary"), 0755)
+
+	summary := &RunSummary{
+		Status:     "success",
+		Version:    "v1.0.0",
+		Phases:     []PhaseTiming{{"Install vibe", 1.5}},
+		Components: map[string]string{"vibe": "v1.0.0"},
+		Checksums:  installedChecksums(binary, dir),
+		Warnings:   []string{"not on PATH"},
+	}
+
+	path := filepath.Join(dir, "artifacts", "report.json")
+	if err := writeInstallReport(path, summary); err != nil {
+		t.Fatalf("writeInstallReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	var decoded RunSummary
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+
+	want, _, _ := fileSHA256(binary)
+	if decoded.Checksums[binary] != want {
+		t.Errorf("checksum of %s = %v, want %v", binary, decoded.Checksums[binary], want)
+	}
+	if len(decoded.Phases) != 1 || decoded.Phases[0].Seconds != 1.5 {
+		t.Errorf("phases = %+v", decoded.Phases)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary report file left behind")
+	}
+}
@@ -0,0 +1,209 @@
+//go:build !slim
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Memory needed to compile the cargo modules; surrealdb dominates
+const (
+	CARGO_MIN_MEMORY         = 2 << 30 // below this the build is likely OOM-killed
+	CARGO_RECOMMENDED_MEMORY = 4 << 30 // below this the build is slow and risky
+	CARGO_MEMORY_PER_JOB     = 1 << 30 // rustc peak per parallel job
+)
+
+// SURREALDB_DOWNLOAD_URL serves official prebuilt SurrealDB binaries
+const SURREALDB_DOWNLOAD_URL = "https://download.surrealdb.com"
+
+// cargoJobs caps cargo's parallel build jobs; 0 leaves cargo's default
+var cargoJobs int
+
+// parseMemAvailable returns MemAvailable from /proc/meminfo content, in bytes
+func parseMemAvailable(meminfo string) (uint64, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024, err == nil
+		}
+	}
+	return 0, false
+}
+
+// availableMemory returns the memory available for a build, in bytes. On
+// macOS this is physical memory, since the OS pages aggressively.
+func availableMemory() (uint64, bool) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile("/proc/meminfo")
+		if err != nil {
+			return 0, false
+		}
+		return parseMemAvailable(string(data))
+	case "darwin":
+		out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// planCargoJobs decides how many parallel jobs cargo may run with the
+// given memory and cores, and whether compiling should go ahead at all
+func planCargoJobs(memory uint64, known bool, cpus int) (jobs int, err error) {
+	if !known {
+		return 0, nil
+	}
+	if memory < CARGO_MIN_MEMORY {
+		return 1, fmt.Errorf("only %s of memory available, compiling the cargo modules needs at least %s; rerun with --prebuilt to download prebuilt binaries instead",
+			formatBytes(memory), formatBytes(CARGO_MIN_MEMORY))
+	}
+
+	jobs = int(memory / CARGO_MEMORY_PER_JOB)
+	if jobs > cpus {
+		jobs = cpus
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	return jobs, nil
+}
+
+// checkBuildResources runs before cargo compiles anything: it refuses
+// builds that would be OOM-killed unless the user insists, warns on tight
+// memory and caps cargo's jobs to what memory allows
+func checkBuildResources() error {
+	memory, known := availableMemory()
+	cpus := runtime.NumCPU()
+	if known {
+		fmt.Printf("🧮 Build resources: %s memory available, %d CPU cores\n", formatBytes(memory), cpus)
+	}
+
+	jobs, err := planCargoJobs(memory, known, cpus)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		if !confirm("Compile anyway?") {
+			return err
+		}
+	} else if known && memory < CARGO_RECOMMENDED_MEMORY {
+		warnf("Only %s of memory available, compiling may be slow; consider --prebuilt", formatBytes(memory))
+	}
+
+	if jobs > 0 && jobs < cpus {
+		fmt.Printf("🔧 Limiting cargo to %d parallel jobs\n", jobs)
+		cargoJobs = jobs
+	}
+	return nil
+}
+
+// surrealPrebuiltURL returns the official SurrealDB download for a platform
+func surrealPrebuiltURL(goos, goarch, version string) string {
+	tag := "v" + version
+	if goos == "windows" {
+		return fmt.Sprintf("%s/%s/surreal-%s.windows-%s.exe", SURREALDB_DOWNLOAD_URL, tag, tag, goarch)
+	}
+	return fmt.Sprintf("%s/%s/surreal-%s.%s-%s.tgz", SURREALDB_DOWNLOAD_URL, tag, tag, goos, goarch)
+}
+
+// prebuiltSurrealModule installs surrealdb without compiling it
+func prebuiltSurrealModule() Module {
+	return Module{
+		Name:    "surrealdb",
+		Version: SURREALDB_VERSION,
+		Install: installPrebuiltSurreal,
+		// The install directory may not be on PATH yet
+		Command: filepath.Join(getInstallPath(), surrealBinName()),
+		Explain: func(installPath string, source Source) Explanation {
+			return Explanation{
+				Summary: fmt.Sprintf("downloads the official surrealdb v%s binary (--prebuilt)", SURREALDB_VERSION),
+				URLs:    []string{surrealPrebuiltURL(runtime.GOOS, runtime.GOARCH, SURREALDB_VERSION)},
+				Files:   []string{filepath.Join(installPath, surrealBinName())},
+			}
+		},
+	}
+}
+
+// withPrebuilt swaps compiled modules for their prebuilt downloads. A
+// download without a pinned digest is only used with --allow-unverified;
+// otherwise the module is still compiled.
+func withPrebuilt(modules []Module) []Module {
+	for i, m := range modules {
+		if m.Name == "surrealdb" && (surrealPrebuiltDigest() != "" || opts.AllowUnverified) {
+			modules[i] = prebuiltSurrealModule()
+		}
+	}
+	return modules
+}
+
+// surrealPrebuiltDigest returns the pinned sha256 of the prebuilt
+// SurrealDB binary for this platform, "" when none is pinned
+func surrealPrebuiltDigest() string {
+	return SURREALDB_SHA256[runtime.GOOS+"/"+runtime.GOARCH]
+}
+
+// surrealBinName returns the file name of the SurrealDB executable
+func surrealBinName() string {
+	if runtime.GOOS == "windows" {
+		return "surreal.exe"
+	}
+	return "surreal"
+}
+
+// installPrebuiltSurreal downloads the official SurrealDB binary next to
+// vibe instead of compiling it with cargo
+func installPrebuiltSurreal(installPath string, source Source, receipt *Receipt) error {
+	url := surrealPrebuiltURL(runtime.GOOS, runtime.GOARCH, SURREALDB_VERSION)
+	if err := checkPolicyURL("prebuilt surrealdb", url); err != nil {
+		return err
+	}
+	fmt.Printf("📥 Downloading prebuilt surrealdb v%s...\n", SURREALDB_VERSION)
+
+	bin := surrealBinName()
+	dest := filepath.Join(installPath, bin)
+
+	tempPath, err := quarantinePath("module-surrealdb")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempPath)
+	if err := downloadFile(url, tempPath, opts.DownloadTimeout); err != nil {
+		return err
+	}
+	want := surrealPrebuiltDigest()
+	if err := requireDigest("v"+SURREALDB_VERSION, "surrealdb", want); err != nil {
+		return err
+	}
+	if err := verifyDigest(tempPath, "surrealdb", want); err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(url, ".tgz") {
+		staged := tempPath + ".bin"
+		defer os.Remove(staged)
+		if err := extractFile(tempPath, url, bin, staged); err != nil {
+			return err
+		}
+		tempPath = staged
+	}
+	if err := installBinary(tempPath, dest); err != nil {
+		return err
+	}
+
+	receipt.recordChange(EnvChange{Kind: CHANGE_FILE, Path: dest})
+	receipt.recordFile("surrealdb", SURREALDB_VERSION, dest)
+	fmt.Printf("✅ surrealdb v%s installed to %s\n", SURREALDB_VERSION, dest)
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build !slim
+
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseMemAvailable(t *testing.T) {
+	meminfo := "MemTotal:        8048576 kB\nMemFree:          123456 kB\nMemAvailable:    3145728 kB\n"
+	got, ok := parseMemAvailable(meminfo)
+	if !ok || got != 3<<30 {
+		t.Errorf("parseMemAvailable = %v, %v, want %v", got, ok, uint64(3<<30))
+	}
+
+	if _, ok := parseMemAvailable("MemTotal: 1 kB\n"); ok {
+		t.Error("Expected no MemAvailable")
+	}
+}
+
+func TestPlanCargoJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		memory  uint64
+		known   bool
+		cpus    int
+		want    int
+		wantErr bool
+	}{
+		{"unknown memory keeps cargo default", 0, false, 8, 0, false},
+		{"plenty of memory uses every core", 32 << 30, true, 8, 8, false},
+		{"memory caps jobs", 3 << 30, true, 8, 3, false},
+		{"too little memory refuses", 1 << 30, true, 4, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planCargoJobs(tt.memory, tt.known, tt.cpus)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("planCargoJobs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "--prebuilt") {
+				t.Errorf("Refusal should suggest --prebuilt: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("planCargoJobs = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrebuiltModules(t *testing.T) {
+	want := "https://download.surrealdb.com/v2.3.5/surreal-v2.3.5.linux-amd64.tgz"
+	if got := surrealPrebuiltURL("linux", "amd64", "2.3.5"); got != want {
+		t.Errorf("surrealPrebuiltURL = %v, want %v", got, want)
+	}
+
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	savedDigest, pinned := SURREALDB_SHA256[platform]
+	defer func() {
+		if pinned {
+			SURREALDB_SHA256[platform] = savedDigest
+		} else {
+			delete(SURREALDB_SHA256, platform)
+		}
+	}()
+	opts.Prebuilt = true
+	surrealCompiled := func() bool {
+		for _, m := range allModules() {
+			if m.Name == "surrealdb" {
+				return m.Cargo
+			}
+		}
+		return false
+	}
+
+	SURREALDB_SHA256[platform] = strings.Repeat("a", 64)
+	if surrealCompiled() {
+		t.Error("--prebuilt should not compile surrealdb")
+	}
+	delete(SURREALDB_SHA256, platform)
+	if !surrealCompiled() {
+		t.Error("--prebuilt should compile surrealdb when no digest is pinned")
+	}
+	opts.AllowUnverified = true
+	if surrealCompiled() {
+		t.Error("--allow-unverified should download surrealdb without a pinned digest")
+	}
+}
+
+func TestPrebuiltSurrealChecksPolicy(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedPolicy := policy
+	defer func() { policy = savedPolicy }()
+	policy = Policy{Sources: []string{"https://mirror.corp/vibe"}}
+
+	err := installPrebuiltSurreal(t.TempDir(), fakeSource{}, &Receipt{})
+	if err == nil || !strings.Contains(err.Error(), "blocked by the policy") || !strings.Contains(err.Error(), SURREALDB_DOWNLOAD_URL) {
+		t.Errorf("prebuilt surrealdb from a forbidden URL = %v, want a policy error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// retryFailedModules reinstalls and verifies only the modules the receipt
+// records as failed, skipping the release download and the other modules
+func retryFailedModules(installPath string, source Source, receipt *Receipt) error {
+	if len(receipt.FailedModules) == 0 {
+		fmt.Printf("✅ No failed modules recorded in %s, nothing to retry\n", getReceiptPath())
+		return nil
+	}
+
+	modules := findModules(receipt.FailedModules)
+	if len(modules) != len(receipt.FailedModules) {
+		fmt.Printf("⚠️  Skipping modules no longer installed by this version: %s\n",
+			strings.Join(unknownModules(receipt.FailedModules), ", "))
+	}
+	fmt.Printf("🔁 Retrying failed modules: %s\n", strings.Join(receipt.FailedModules, ", "))
+
+	installErr := installModules(modules, installPath, source, receipt)
+	if err := receipt.save(); err != nil {
+		return err
+	}
+	if installErr != nil {
+		return installErr
+	}
+
+	if _, err := verifyModules(modules); err != nil {
+		return err
+	}
+	if len(runSummary.Degraded) > 0 {
+		fmt.Printf("⚠️  Still degraded: %s\n", strings.Join(runSummary.Degraded, ", "))
+		return nil
+	}
+	fmt.Printf("✅ All previously failed modules are installed!\n")
+	return nil
+}
+
+// unknownModules returns the names that match no entry in MODULES
+func unknownModules(names []string) []string {
+	var unknown []string
+	for _, name := range names {
+		if len(findModules([]string{name})) == 0 {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
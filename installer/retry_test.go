@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstallModulesRecordsFailures(t *testing.T) {
+	var installed []string
+	module := func(name string, fail bool) Module {
+		return Module{Name: name, Install: func(string, Source, *Receipt) error {
+			installed = append(installed, name)
+			if fail {
+				return fmt.Errorf("compile error")
+			}
+			return nil
+		}}
+	}
+	modules := []Module{module("a", false), module("b", true), module("c", false)}
+
+	receipt := &Receipt{}
+	if err := installModules(modules, t.TempDir(), fakeSource{}, receipt); err == nil {
+		t.Fatalf("installModules() succeeded with a failing module")
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(installed, want) {
+		t.Errorf("installed = %v, want %v (a failure must not stop later modules)", installed, want)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(receipt.FailedModules, want) {
+		t.Errorf("FailedModules = %v, want %v", receipt.FailedModules, want)
+	}
+
+	// A successful run clears the record
+	if err := installModules(modules[:1], t.TempDir(), fakeSource{}, receipt); err != nil {
+		t.Fatalf("installModules() failed: %v", err)
+	}
+	if len(receipt.FailedModules) != 0 {
+		t.Errorf("FailedModules = %v after success, want none", receipt.FailedModules)
+	}
+}
+
+func TestOptionalModuleFailureDegrades(t *testing.T) {
+	savedSummary := runSummary
+	defer func() { runSummary = savedSummary }()
+	runSummary = &RunSummary{}
+
+	failing := func(string, Source, *Receipt) error { return fmt.Errorf("404") }
+	modules := []Module{
+		{Name: "vibe-lsp", Install: func(string, Source, *Receipt) error { return nil }},
+		{Name: "grammar", Optional: true, Install: failing},
+	}
+	receipt := &Receipt{}
+	if err := installModules(modules, t.TempDir(), fakeSource{}, receipt); err != nil {
+		t.Fatalf("installModules() failed on an optional module: %v", err)
+	}
+	if want := []string{"grammar"}; !reflect.DeepEqual(receipt.FailedModules, want) || !reflect.DeepEqual(runSummary.Degraded, want) {
+		t.Errorf("FailedModules = %v, Degraded = %v, want %v in both", receipt.FailedModules, runSummary.Degraded, want)
+	}
+	if status := completedStatus(); status != "degraded" {
+		t.Errorf("completedStatus() = %q, want degraded", status)
+	}
+
+	// A failed optional module also passes verification, listed once
+	checks, err := verifyModules([]Module{{Name: "grammar", Optional: true, Verify: func() error { return fmt.Errorf("missing") }}})
+	if err != nil || !checks[0].Optional {
+		t.Errorf("verifyModules() = %+v, %v, want an optional failure", checks, err)
+	}
+	if len(runSummary.Degraded) != 1 {
+		t.Errorf("Degraded = %v, want grammar once", runSummary.Degraded)
+	}
+
+	modules[0].Install = failing
+	if err := installModules(modules, t.TempDir(), fakeSource{}, receipt); err == nil || !strings.Contains(err.Error(), "1 required module(s) failed: vibe-lsp") {
+		t.Errorf("installModules() = %v, want the required failure only", err)
+	}
+}
+
+func TestFindModules(t *testing.T) {
+	if len(CARGO_MODULES) == 0 {
+		t.Skip("slim builds have no cargo modules")
+	}
+	got := findModules([]string{"tree-sitter-typescript", "code2prompt", "missing"})
+	if len(got) != 2 || got[0].Name != "code2prompt" || got[1].Name != "tree-sitter-typescript" {
+		t.Errorf("findModules() = %v, want code2prompt and tree-sitter-typescript in install order", got)
+	}
+	if unknown := unknownModules([]string{"surrealdb", "missing"}); !reflect.DeepEqual(unknown, []string{"missing"}) {
+		t.Errorf("unknownModules() = %v, want [missing]", unknown)
+	}
+}
+
+func TestRetryFailedModules(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+
+	// Nothing recorded: nothing to do
+	if err := retryFailedModules(installPath, fakeSource{}, &Receipt{}); err != nil {
+		t.Errorf("retryFailedModules() with no failures = %v", err)
+	}
+
+	// The release publishes the digest of the grammar being retried
+	g := GRAMMARS[0]
+	wasm := string(wasmMagic) + g.Package + "@" + g.Version + "/" + g.File
+	source := &variantSource{files: map[string][]byte{"SHA256SUMS": []byte(sha256Hex(wasm) + "  " + g.File + "\n")}}
+	receipt := &Receipt{Version: "v1.0.0", FailedModules: []string{"tree-sitter-typescript"}}
+	if err := retryFailedModules(installPath, source, receipt); err != nil {
+		t.Fatalf("retryFailedModules() failed: %v", err)
+	}
+	if len(receipt.FailedModules) != 0 {
+		t.Errorf("FailedModules = %v after a successful retry", receipt.FailedModules)
+	}
+
+	saved, err := loadReceipt()
+	if err != nil || len(saved.FailedModules) != 0 {
+		t.Errorf("saved receipt = %+v, %v; want no failed modules", saved, err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// rollbackPoint keeps the previous vibe binary while an upgrade is
+// validated, so --rollback-on-error can put it back. Only the binary is
+// kept: the data directory and the receipt keep whatever the upgrade's
+// migrations changed.
+type rollbackPoint struct {
+	saved      string // copy of the previous binary, in quarantine
+	binaryPath string
+	version    string
+}
+
+// saveRollbackPoint copies the installed binary aside before it is
+// replaced. There is nothing to roll back to without one, and nil is
+// returned.
+func saveRollbackPoint(binaryPath, version string) (*rollbackPoint, error) {
+	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	saved, err := quarantinePath("rollback-" + filepath.Base(binaryPath))
+	if err != nil {
+		return nil, err
+	}
+	if err := copyFile(binaryPath, saved, MODE_BINARY); err != nil {
+		os.Remove(saved)
+		return nil, fmt.Errorf("failed to keep %s for rollback: %w", version, err)
+	}
+	return &rollbackPoint{saved: saved, binaryPath: binaryPath, version: version}, nil
+}
+
+// restore puts the previous binary back in place
+func (r *rollbackPoint) restore() error {
+	return promote(r.saved, r.binaryPath, MODE_BINARY)
+}
+
+// discard drops the saved binary once the upgrade is validated
+func (r *rollbackPoint) discard() {
+	if r != nil {
+		os.Remove(r.saved)
+	}
+}
+
+// failf rolls back to the previous binary, when one was kept, and then
+// fails the run like fatalf
+func (r *rollbackPoint) failf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if r == nil {
+		fatalf("%s", msg)
+	}
+	fmt.Printf("⏪ Rolling back to %s...\n", r.version)
+	if err := r.restore(); err != nil {
+		fatalf("%s; rolling back to %s also failed: %v (the previous binary is at %s)", msg, r.version, err, r.saved)
+	}
+	fatalf("%s; rolled back the binary to %s (the data directory and receipt were not rolled back)", msg, r.version)
+}
+
+// validateUpgrade smoke tests the new binary with --version, then runs
+// the user's validation command, if any, with the install directory first
+// on PATH so it exercises the new binary
+func validateUpgrade(binaryPath, command string) error {
+	fmt.Printf("🧪 Smoke testing %s...\n", binaryPath)
+	output, err := versionOutput(binaryPath)
+	if err != nil {
+		return fmt.Errorf("smoke test failed: %s --version: %w", binaryPath, err)
+	}
+	fmt.Printf("✅ %s\n", output)
+
+	if command == "" {
+		return nil
+	}
+	fmt.Printf("🧪 Running validation: %s\n", command)
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(runCtx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(runCtx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(binaryPath)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"VIBE_BIN="+binaryPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("validation command %q failed: %w", command, err)
+	}
+	fmt.Printf("✅ Validation passed\n")
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRollbackPoint(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	binary := filepath.Join(t.TempDir(), "vibe")
+
+	if r, err := saveRollbackPoint(binary, "v1.0.0"); r != nil || err != nil {
+		t.Fatalf("saveRollbackPoint without a binary = %v, %v", r, err)
+	}
+
+	os.WriteFile(binary, []byte("old"), 0755)
+	r, err := saveRollbackPoint(binary, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(binary, []byte("new"), 0755)
+	if err := r.restore(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != "old" {
+		t.Errorf("binary = %q after rollback", data)
+	}
+}
+
+func TestValidateUpgrade(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as binaries")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "vibe")
+	os.WriteFile(binary, []byte("#!/bin/sh\n[ \"$1\" = --version ] && echo 'vibe 1.1.0' && exit 0\nexit 3\n"), 0755)
+
+	if err := validateUpgrade(binary, ""); err != nil {
+		t.Errorf("smoke test failed: %v", err)
+	}
+	// The validation command finds the new binary on PATH
+	if err := validateUpgrade(binary, "vibe --version && test \"$VIBE_BIN\" = "+binary); err != nil {
+		t.Errorf("passing validation failed: %v", err)
+	}
+	if err := validateUpgrade(binary, "vibe index --dry-run"); err == nil {
+		t.Error("failing validation command passed")
+	}
+
+	os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0755)
+	if err := validateUpgrade(binary, ""); err == nil {
+		t.Error("smoke test passed a broken binary")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ROOT_INSTALL_PATH is where vibe is installed when running as root with
+// --allow-root; root's ~/.local/bin is rarely on PATH, even in containers.
+// End-to-end tests running as root point it into their fake home.
+var ROOT_INSTALL_PATH = "/usr/local/bin"
+
+// runningAsRoot reports whether the installer runs with euid 0. Geteuid
+// returns -1 on Windows.
+func runningAsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// checkRoot refuses to install as root unless allowed. Under sudo the
+// install lands in root's home and the invoking user never sees it.
+func checkRoot(root bool, sudoUser string, allowRoot bool) error {
+	if !root || allowRoot {
+		return nil
+	}
+	if sudoUser != "" {
+		return fmt.Errorf("refusing to run under sudo: vibe would be installed for root instead of %s.\n"+
+			"   Run the installer again without sudo; it installs into your home directory and needs no privileges.\n"+
+			"   To install for root on purpose (e.g. in a container), pass --allow-root", sudoUser)
+	}
+	return fmt.Errorf("refusing to run as root: vibe is installed per user into the home directory.\n" +
+		"   Run the installer as the user who will use vibe, or pass --allow-root to install into " + ROOT_INSTALL_PATH +
+		" (e.g. in a container that only has root)")
+}
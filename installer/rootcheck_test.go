@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRoot(t *testing.T) {
+	if err := checkRoot(false, "", false); err != nil {
+		t.Errorf("Regular user refused: %v", err)
+	}
+	if err := checkRoot(true, "", true); err != nil {
+		t.Errorf("--allow-root refused: %v", err)
+	}
+
+	err := checkRoot(true, "alice", false)
+	if err == nil || !strings.Contains(err.Error(), "without sudo") || !strings.Contains(err.Error(), "alice") {
+		t.Errorf("sudo error = %v", err)
+	}
+
+	err = checkRoot(true, "", false)
+	if err == nil || !strings.Contains(err.Error(), "--allow-root") || !strings.Contains(err.Error(), ROOT_INSTALL_PATH) {
+		t.Errorf("root error = %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// INSTALLER_ASSET_TEMPLATE names the installer builds uploaded next to
+// vibe, as the build:all task in Taskfile.yml names them
+const INSTALLER_ASSET_TEMPLATE = "install-dotvibe-{{.GOOS}}-{{.GOARCH}}{{.Ext}}"
+
+// REPLACED_SUFFIX marks the installer a Windows self-update moved aside: a
+// running executable can be renamed there but not overwritten or deleted
+const REPLACED_SUFFIX = ".old"
+
+// selfUpdateFlags registers the flags of the self-update command
+func selfUpdateFlags(fs *flag.FlagSet) {
+	addSourceFlags(fs)
+	fs.BoolVar(&opts.Check, "check", opts.Check, "only report the newest installer; exits 10 when it is newer than this one")
+	fs.BoolVar(&opts.Force, "force", opts.Force, "replace the installer even when it is a development build or not older")
+	fs.BoolVar(&opts.Insecure, "insecure", opts.Insecure, "replace the installer even when the release publishes neither a digest nor a signature for it")
+}
+
+// installerAssetName returns the installer asset of a platform
+func installerAssetName(goos, goarch string) (string, error) {
+	return renderAssetName(INSTALLER_ASSET_TEMPLATE, goos, goarch, "")
+}
+
+// newestInstaller returns the newest release the policy allows that
+// publishes asset. Sources that cannot list releases are asked for their
+// latest one, which then has to have it.
+func newestInstaller(source Source, asset string) (string, error) {
+	lister, ok := source.(ReleaseLister)
+	if !ok {
+		latest, err := source.LatestVersion()
+		if err != nil {
+			return "", err
+		}
+		return latest, checkPolicyVersion(latest)
+	}
+	releases, err := lister.ListReleases()
+	if err != nil {
+		return "", err
+	}
+	published := map[string]bool{}
+	for _, r := range releases {
+		for _, a := range r.Assets {
+			if a.Name == asset {
+				published[r.TagName] = true
+			}
+		}
+	}
+	for _, tag := range releaseVersions(releases, opts.IncludePrereleases) {
+		if published[tag] && policy.checkVersion(tag) == nil {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("no release the policy allows publishes %s", asset)
+}
+
+// runSelfUpdate implements `install-dotvibe self-update [--check]`: it
+// replaces the running installer with the newest release's build for this
+// platform, verified like vibe itself
+func runSelfUpdate(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	if policy.AllowAutoUpdate != nil && !*policy.AllowAutoUpdate && !isInteractive() {
+		return fmt.Errorf("blocked by the policy in %s: the installer may only be updated by a user at a terminal", policyPath)
+	}
+
+	asset, err := installerAssetName(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	source, err := newSource(opts.sourceSpec())
+	if err != nil {
+		return err
+	}
+	latest, err := newestInstaller(source, asset)
+	if err != nil {
+		return err
+	}
+
+	available := updateAvailable(version, true, latest)
+	if _, ok := parseVersion(version); !ok && !opts.Force {
+		fmt.Printf("install-dotvibe %s is a development build; the newest release is %s\n", version, latest)
+		if opts.Check {
+			return nil
+		}
+		return fmt.Errorf("refusing to replace a development build without --force")
+	}
+	if opts.Check {
+		fmt.Printf("install-dotvibe %s, newest release %s\n", version, latest)
+		if available {
+			return exitStatus(EXIT_UPDATE_AVAILABLE)
+		}
+		return nil
+	}
+	if !available && !opts.Force {
+		fmt.Printf("✅ install-dotvibe %s is up to date\n", version)
+		return nil
+	}
+
+	exe, err := runningInstaller()
+	if err != nil {
+		return fmt.Errorf("cannot locate the running installer: %w", err)
+	}
+	if err := updateInstaller(source, latest, asset, exe); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated install-dotvibe %s → %s at %s\n", version, latest, exe)
+	if runtime.GOOS == "windows" {
+		fmt.Printf("🔁 Restart install-dotvibe to use %s; the replaced %s is removed on its next run\n", latest, version)
+	}
+	return nil
+}
+
+// updateInstaller downloads asset of release latest and moves it over exe
+// once verified. Without a published digest the asset's signature is all
+// that vouches for it, so it has to have one unless --insecure is passed.
+func updateInstaller(source Source, latest, asset, exe string) error {
+	fmt.Printf("⬇️  Downloading %s from %s\n", asset, latest)
+	digests, err := releaseDigests(source, latest)
+	if err != nil {
+		return err
+	}
+	verified := true
+	if digests[asset] == "" {
+		switch {
+		case opts.Insecure:
+			warnf("%s of %s has no published digest; replacing the installer unverified (--insecure)", asset, latest)
+			opts.AllowUnsigned = true
+			verified = false
+		case opts.NoVerify:
+			return fmt.Errorf("%s of %s has no published digest and --no-verify skips its signature; pass --insecure to replace the installer anyway", asset, latest)
+		default:
+			opts.AllowUnsigned = false
+		}
+		// The signature stands in for the digest
+		opts.AllowUnverified = true
+	}
+	staged, err := quarantinePath(asset)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(staged)
+	if _, err := fetchBinaryAsset(source, latest, []string{asset}, "", staged, digests); err != nil {
+		return err
+	}
+	// Nothing downloaded is run before it is verified
+	if verified {
+		if err := checkInstallerBuild(staged, latest); err != nil {
+			return err
+		}
+	}
+
+	if err := replaceExecutable(staged, exe, runtime.GOOS); err != nil {
+		return fmt.Errorf("cannot replace %s: %w; rerun as the user that installed it", exe, err)
+	}
+	return nil
+}
+
+// runningInstaller returns the path of the running installer, with
+// symlinks resolved so an update replaces the file rather than the link
+func runningInstaller() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
+
+// checkInstallerBuild runs a staged installer to make sure it starts on
+// this machine and reports the release it was downloaded from
+func checkInstallerBuild(path, want string) error {
+	if posixModes() {
+		if err := os.Chmod(path, MODE_BINARY); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := requestContext(DEFAULT_API_TIMEOUT)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "version", "--json").Output()
+	if err != nil {
+		return fmt.Errorf("the downloaded installer does not run: %w", err)
+	}
+	var info BuildInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return fmt.Errorf("the downloaded installer does not report its build: %w", err)
+	}
+	got, okGot := parseVersion(info.Version)
+	expected, okWant := parseVersion(want)
+	if !okGot || !okWant || compareVersions(got, expected) != 0 {
+		return fmt.Errorf("the downloaded installer reports version %q, not %s", info.Version, want)
+	}
+	return nil
+}
+
+// replaceExecutable moves a verified installer over exe. Elsewhere a
+// rename replaces it atomically, even while it runs; Windows refuses that,
+// so the running installer is renamed aside first and its leftover removed
+// by the next run (see removeReplacedInstaller).
+func replaceExecutable(staged, exe, goos string) error {
+	if goos != "windows" {
+		return promote(staged, exe, MODE_BINARY)
+	}
+	old := exe + REPLACED_SUFFIX
+	if err := os.Remove(old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := promote(staged, exe, MODE_BINARY); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	return nil
+}
+
+// removeReplacedInstaller deletes the installer a Windows self-update left
+// behind, now that it no longer runs
+func removeReplacedInstaller(exe string) {
+	if err := os.Remove(exe + REPLACED_SUFFIX); err != nil && !errors.Is(err, os.ErrNotExist) {
+		warnf("Could not remove the replaced installer: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestInstallerAssetName(t *testing.T) {
+	for _, tc := range []struct{ goos, goarch, want string }{
+		{"linux", "amd64", "install-dotvibe-linux-amd64"},
+		{"darwin", "arm64", "install-dotvibe-darwin-arm64"},
+		{"windows", "amd64", "install-dotvibe-windows-amd64.exe"},
+	} {
+		if got, err := installerAssetName(tc.goos, tc.goarch); err != nil || got != tc.want {
+			t.Errorf("installerAssetName(%s, %s) = %q, %v; want %q", tc.goos, tc.goarch, got, err, tc.want)
+		}
+	}
+}
+
+func TestNewestInstaller(t *testing.T) {
+	savedOpts, savedPolicy := opts, policy
+	defer func() { opts, policy = savedOpts, savedPolicy }()
+	opts = Options{}
+	policy = Policy{MaxVersion: "v1.3.0"}
+
+	asset := "install-dotvibe-linux-amd64"
+	with := []ReleaseAsset{{Name: asset}}
+	source := listingSource{releases: []GitHubRelease{
+		{TagName: "v1.1.0", Assets: with},
+		{TagName: "v1.2.0", Assets: with},
+		{TagName: "v1.3.0"}, // vibe only
+		{TagName: "v1.4.0", Assets: with},
+		{TagName: "v1.5.0-rc.1", Prerelease: true, Assets: with},
+	}}
+	if got, err := newestInstaller(source, asset); err != nil || got != "v1.2.0" {
+		t.Errorf("newestInstaller = %q, %v; want v1.2.0", got, err)
+	}
+
+	policy = Policy{MaxVersion: "v1.0.0"}
+	if _, err := newestInstaller(source, asset); err == nil {
+		t.Error("newestInstaller found a release the policy blocks")
+	}
+}
+
+func TestSelfUpdateRefusedUnattendedByPolicy(t *testing.T) {
+	savedOpts, savedPolicy := opts, policy
+	defer func() { opts, policy = savedOpts, savedPolicy }()
+	forbidden := false
+	policy = Policy{AllowAutoUpdate: &forbidden}
+
+	if isInteractive() {
+		t.Skip("stdin is a terminal")
+	}
+	if err := runSelfUpdate(nil); err == nil {
+		t.Error("an unattended self-update ran although the policy forbids it")
+	}
+}
+
+func TestCheckInstallerBuild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "install-dotvibe")
+	script := "#!/bin/sh\necho '{\"version\": \"v1.2.0\"}'\n"
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkInstallerBuild(path, "v1.2.0"); err != nil {
+		t.Errorf("a matching build was rejected: %v", err)
+	}
+	if err := checkInstallerBuild(path, "v1.3.0"); err == nil {
+		t.Error("a build reporting another version was accepted")
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	for _, goos := range []string{"linux", "windows"} {
+		dir := t.TempDir()
+		exe := filepath.Join(dir, "install-dotvibe")
+		staged := filepath.Join(dir, "staged")
+		os.WriteFile(exe, []byte("old"), 0755)
+		os.WriteFile(staged, []byte("new"), 0600)
+
+		if err := replaceExecutable(staged, exe, goos); err != nil {
+			t.Fatalf("%s: replaceExecutable failed: %v", goos, err)
+		}
+		if data, _ := os.ReadFile(exe); string(data) != "new" {
+			t.Errorf("%s: installer holds %q after the update", goos, data)
+		}
+		_, err := os.Stat(exe + REPLACED_SUFFIX)
+		if kept := err == nil; kept != (goos == "windows") {
+			t.Errorf("%s: replaced installer kept aside = %v", goos, kept)
+		}
+
+		removeReplacedInstaller(exe)
+		if _, err := os.Stat(exe + REPLACED_SUFFIX); err == nil {
+			t.Errorf("%s: replaced installer left behind", goos)
+		}
+	}
+}
+
+func TestUpdateInstallerNeedsVerification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+
+	asset := "install-dotvibe-linux-amd64"
+	marker := filepath.Join(t.TempDir(), "ran")
+	build := []byte("#!/bin/sh\ntouch " + marker + "\necho '{\"version\": \"v1.2.0\"}'\n")
+	exe := filepath.Join(t.TempDir(), "install-dotvibe")
+
+	// Neither a digest nor a signature: refused without running anything
+	opts = Options{}
+	os.WriteFile(exe, []byte("old"), 0755)
+	source := &variantSource{files: map[string][]byte{asset: build}}
+	if err := updateInstaller(source, "v1.2.0", asset, exe); err == nil {
+		t.Error("an installer with neither a digest nor a signature replaced the running one")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the unverified download was run")
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Errorf("installer holds %q after a refused update", data)
+	}
+
+	// --allow-unsigned does not lift the requirement for the installer
+	opts = Options{AllowUnsigned: true}
+	if err := updateInstaller(source, "v1.2.0", asset, exe); err == nil {
+		t.Error("--allow-unsigned replaced the installer without a digest")
+	}
+
+	// --insecure replaces it, still without running it
+	opts = Options{Insecure: true}
+	if err := updateInstaller(source, "v1.2.0", asset, exe); err != nil {
+		t.Fatalf("updateInstaller with --insecure failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the unverified download was run")
+	}
+
+	// A published digest verifies it, and the verified build is run
+	opts = Options{AllowUnsigned: true}
+	source.files["SHA256SUMS"] = []byte(sha256Hex(string(build)) + "  " + asset + "\n")
+	if err := updateInstaller(source, "v1.2.0", asset, exe); err != nil {
+		t.Fatalf("updateInstaller with a digest failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("the verified build was not checked")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SELINUX_ENFORCE exists when SELinux is enabled and reads "1" in
+// enforcing mode
+const SELINUX_ENFORCE = "/sys/fs/selinux/enforce"
+
+// selinuxMode returns "enforcing", "permissive", or "" when SELinux is off
+func selinuxMode() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile(SELINUX_ENFORCE)
+	if err != nil {
+		return ""
+	}
+	if strings.TrimSpace(string(data)) == "1" {
+		return "enforcing"
+	}
+	return "permissive"
+}
+
+// selinuxPaths lists what the installer labels: the binaries and the data
+// directory
+func selinuxPaths(binaryPath, installPath string, receipt *Receipt) []string {
+	paths := installedBinaries(binaryPath, receipt)
+	if _, err := os.Stat(getDataDir(installPath)); err == nil {
+		paths = append(paths, getDataDir(installPath))
+	}
+	return paths
+}
+
+// restoreContexts relabels installed files with the policy's default
+// context. Files moved out of /tmp keep user_tmp_t, which confined
+// domains such as systemd services are not allowed to execute.
+func restoreContexts(paths []string) error {
+	if _, err := exec.LookPath("restorecon"); err != nil {
+		return fmt.Errorf("SELinux is enabled but restorecon was not found (install policycoreutils)")
+	}
+	args := append([]string{"-F", "-R"}, paths...)
+	if out, err := exec.CommandContext(runCtx, "restorecon", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("restorecon failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("🛡️  Restored SELinux contexts of installed files\n")
+	return nil
+}
+
+// parseRelabels extracts the files `restorecon -n -v` would relabel. Both
+// the current ("Would relabel X from A to B") and older ("restorecon reset
+// X context A->B") output formats are understood.
+func parseRelabels(output string) []string {
+	var relabels []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Would relabel "):
+			relabels = append(relabels, strings.TrimPrefix(line, "Would relabel "))
+		case strings.HasPrefix(line, "restorecon reset "):
+			relabels = append(relabels, strings.TrimPrefix(line, "restorecon reset "))
+		}
+	}
+	return relabels
+}
+
+// parseDenials returns the AVC denial records of ausearch output that
+// concern one of the named executables
+func parseDenials(output string, names []string) []string {
+	var denials []string
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, "avc:") || !strings.Contains(line, "denied") {
+			continue
+		}
+		for _, name := range names {
+			if strings.Contains(line, `comm="`+name+`"`) || strings.Contains(line, "/"+name+`"`) {
+				denials = append(denials, strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+	return denials
+}
+
+// checkSELinux reports installed files with the wrong SELinux context and
+// recent denials involving them
+func checkSELinux(receipt *Receipt) []string {
+	if selinuxMode() == "" {
+		return nil
+	}
+
+	_, _, filename := detectPlatform()
+	paths := selinuxPaths(filepath.Join(receipt.InstallPath, filename), receipt.InstallPath, receipt)
+
+	var problems []string
+	if _, err := exec.LookPath("restorecon"); err == nil {
+		args := append([]string{"-n", "-v", "-R"}, paths...)
+		out, _ := exec.Command("restorecon", args...).CombinedOutput()
+		for _, r := range parseRelabels(string(out)) {
+			problems = append(problems, fmt.Sprintf("wrong SELinux context: %s (fix with restorecon -R)", r))
+		}
+	}
+
+	// The audit log is usually only readable by root; skip it quietly otherwise
+	if _, err := exec.LookPath("ausearch"); err == nil {
+		out, _ := exec.Command("ausearch", "-m", "AVC,USER_AVC", "-ts", "recent", "-i").CombinedOutput()
+		var names []string
+		for _, p := range paths {
+			names = append(names, filepath.Base(p))
+		}
+		for _, d := range parseDenials(string(out), names) {
+			problems = append(problems, "SELinux denial: "+d)
+		}
+	}
+	return problems
+}
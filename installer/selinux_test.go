@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRelabels(t *testing.T) {
+	output := `Would relabel /home/u/.local/bin/vibe from unconfined_u:object_r:user_tmp_t:s0 to unconfined_u:object_r:bin_t:s0
+restorecon reset /home/u/.local/bin/data context unconfined_u:object_r:user_tmp_t:s0->unconfined_u:object_r:usr_t:s0
+`
+	want := []string{
+		"/home/u/.local/bin/vibe from unconfined_u:object_r:user_tmp_t:s0 to unconfined_u:object_r:bin_t:s0",
+		"/home/u/.local/bin/data context unconfined_u:object_r:user_tmp_t:s0->unconfined_u:object_r:usr_t:s0",
+	}
+	if got := parseRelabels(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRelabels = %q, want %q", got, want)
+	}
+	if got := parseRelabels(""); got != nil {
+		t.Errorf("parseRelabels(\"\") = %q", got)
+	}
+}
+
+func TestParseDenials(t *testing.T) {
+	output := `----
+type=AVC msg=audit(10/16/2026 10:00:00.000:1) : avc:  denied  { execute } for  pid=42 comm=systemd path="/home/u/.local/bin/vibe" scontext=system_u:system_r:init_t:s0 tcontext=unconfined_u:object_r:user_tmp_t:s0 tclass=file
+type=AVC msg=audit(10/16/2026 10:00:01.000:2) : avc:  denied  { read } for  pid=43 comm="nginx" path="/var/www" tclass=dir
+type=AVC msg=audit(10/16/2026 10:00:02.000:3) : avc:  granted  { execute } for  pid=44 comm="vibe" tclass=file
+`
+	got := parseDenials(output, []string{"vibe", "surreal"})
+	if len(got) != 1 || !strings.Contains(got[0], "/.local/bin/vibe") {
+		t.Fatalf("parseDenials = %q, want only the vibe denial", got)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SETUP_SCHEMA is the version of the setup descriptor format
+const SETUP_SCHEMA = 1
+
+// Setup is a portable description of an installation: what export-setup
+// writes and install --from-setup reproduces on another machine. Paths
+// and anything else tied to the exporting machine are left out.
+type Setup struct {
+	Schema      int                `json:"schema"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	Platform    string             `json:"platform"` // where it was exported, for reference
+	Vibe        string             `json:"vibe"`     // release tag
+	Grammars    []InstalledGrammar `json:"grammars,omitempty"`
+	Packages    []InstalledPackage `json:"packages,omitempty"`
+	EncryptData bool               `json:"encrypt_data,omitempty"`
+	Config      Config             `json:"config,omitempty"` // installer settings, including [module.*] tables
+}
+
+// exportSetup describes the installation of receipt configured by cfg.
+// Settings holding absolute paths only make sense on this machine and are
+// returned separately.
+func exportSetup(receipt *Receipt, cfg Config) (Setup, []string) {
+	setup := Setup{
+		Schema:      SETUP_SCHEMA,
+		ExportedAt:  time.Now().UTC(),
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		Vibe:        receipt.Version,
+		Grammars:    receipt.Grammars,
+		Packages:    receipt.Packages,
+		EncryptData: receipt.DataEncryption != "",
+		Config:      Config{},
+	}
+	var local []string
+	for key, value := range cfg {
+		if filepath.IsAbs(value) || filepath.VolumeName(value) != "" {
+			local = append(local, key)
+			continue
+		}
+		setup.Config[key] = value
+	}
+	sort.Strings(local)
+	return setup, local
+}
+
+// loadSetup reads a setup descriptor
+func loadSetup(path string) (*Setup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read setup: %w", err)
+	}
+	var setup Setup
+	if err := json.Unmarshal(data, &setup); err != nil {
+		return nil, fmt.Errorf("failed to parse setup %s: %w", path, err)
+	}
+	switch {
+	case setup.Schema > SETUP_SCHEMA:
+		return nil, fmt.Errorf("setup %s has schema %d; this installer reads up to %d, update it first", path, setup.Schema, SETUP_SCHEMA)
+	case setup.Vibe == "":
+		return nil, fmt.Errorf("setup %s does not name a vibe version", path)
+	}
+	if _, ok := parseVersion(setup.Vibe); !ok {
+		return nil, fmt.Errorf("setup %s names an invalid vibe version %q", path, setup.Vibe)
+	}
+	return &setup, nil
+}
+
+// apply makes the run install what the setup describes. Its settings
+// replace those of the local config file and the command line.
+func (s *Setup) apply(origin string) error {
+	if err := s.Config.apply(origin); err != nil {
+		return err
+	}
+	opts.Version = s.Vibe
+	opts.EncryptData = opts.EncryptData || s.EncryptData
+	fmt.Printf("📋 Reproducing the setup exported from %s on %s\n", s.Platform, s.ExportedAt.Format("2006-01-02"))
+	return nil
+}
+
+// seedReceipt records the setup's grammar versions, so the install
+// fetches those rather than the versions this installer pins, and warns
+// about what it cannot reproduce: grammars older than the pinned ones and
+// packages whose pinned version differs from the setup's
+func (s *Setup) seedReceipt(receipt *Receipt) {
+	for _, g := range s.Grammars {
+		receipt.recordGrammar(g)
+	}
+	for _, pinned := range GRAMMARS {
+		want := receipt.grammar(pinned)
+		for _, g := range s.Grammars {
+			if g.Package == pinned.Package && g.Version != want.Version {
+				warnf("The setup has grammar %s %s, this installer installs at least %s", g.Package, g.Version, want.Version)
+			}
+		}
+	}
+	pinned := versionMap(getVersionInfo(s.Vibe))
+	for _, p := range s.Packages {
+		if version, ok := pinned[p.Name]; ok && strings.TrimPrefix(version, "v") != strings.TrimPrefix(p.Version, "v") {
+			warnf("The setup has %s %s, this installer installs %s", p.Name, p.Version, version)
+		}
+	}
+}
+
+// runExportSetup implements `install-dotvibe export-setup [file]`
+func runExportSetup(args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	if receipt.Version == "" {
+		return fmt.Errorf("vibe is not installed (no receipt at %s)", getReceiptPath())
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	setup, local := exportSetup(receipt, cfg)
+	data, err := json.MarshalIndent(setup, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode setup: %w", err)
+	}
+	data = append(data, '\n')
+
+	if len(args) == 0 || args[0] == "-" {
+		os.Stdout.Write(data)
+	} else {
+		if err := writeFileMode(args[0], data, MODE_DATA); err != nil {
+			return fmt.Errorf("failed to write setup: %w", err)
+		}
+		fmt.Printf("✅ Setup of vibe %s written to %s\n", setup.Vibe, args[0])
+		fmt.Printf("   Reproduce it elsewhere with: install-dotvibe install --from-setup %s\n", filepath.Base(args[0]))
+	}
+	if len(local) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Left out settings holding paths of this machine: %s\n", strings.Join(local, ", "))
+	}
+	return nil
+}
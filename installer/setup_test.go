@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupRoundTrip(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	receipt := &Receipt{
+		Version:        "v1.4.0",
+		Grammars:       []InstalledGrammar{{Package: GRAMMARS[0].Package, Version: "99.0.0", File: GRAMMARS[0].File}},
+		Packages:       []InstalledPackage{{Name: "code2prompt", Version: "0.0.1", Manager: "cargo"}},
+		DataEncryption: "keychain",
+	}
+	cfg := Config{
+		"network.flaky":           "true",
+		"build.compile_cache_dir": filepath.Join(t.TempDir(), "sccache"),
+	}
+
+	setup, local := exportSetup(receipt, cfg)
+	if len(local) != 1 || local[0] != "build.compile_cache_dir" {
+		t.Errorf("left out %v, want the absolute path setting only", local)
+	}
+	if _, ok := setup.Config["build.compile_cache_dir"]; ok {
+		t.Error("the setup carries a path of the exporting machine")
+	}
+	if err := receipt.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(getConfigPath(), []byte("[network]\nflaky = true\n"), MODE_DATA); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "setup.json")
+	if err := runExportSetup([]string{path}); err != nil {
+		t.Fatalf("export-setup failed: %v", err)
+	}
+
+	loaded, err := loadSetup(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := opts
+	defer func() { opts = saved }()
+	if err := loaded.apply(path); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Version != "v1.4.0" || !opts.EncryptData || !opts.FlakyNetwork {
+		t.Errorf("applying the setup gave version %q, encrypt %t, flaky %t", opts.Version, opts.EncryptData, opts.FlakyNetwork)
+	}
+
+	fresh := &Receipt{}
+	loaded.seedReceipt(fresh)
+	if g := fresh.grammar(GRAMMARS[0]); g.Version != "99.0.0" {
+		t.Errorf("grammar to install = %s, want the setup's 99.0.0", g.Version)
+	}
+}
+
+func TestLoadSetupRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "setup.json")
+	os.WriteFile(path, []byte(`{"schema": 99, "vibe": "v1.0.0"}`), MODE_DATA)
+	if _, err := loadSetup(path); err == nil {
+		t.Error("a setup of a newer schema was accepted")
+	}
+	os.WriteFile(path, []byte(`{"schema": 1}`), MODE_DATA)
+	if _, err := loadSetup(path); err == nil {
+		t.Error("a setup without a vibe version was accepted")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sharedCacheSource consults a cache shared by many machines before the
+// upstream source. Each upstream source gets its own directory in the
+// cache, named by sharedCacheKey, so a fork and upstream never serve each
+// other's files; inside it the cache uses the mirror layout
+// (<version>/<asset> and grammars/<pkg>@<version>/<file>). A cache on a
+// writable filesystem path, such as a network mount, is filled with the
+// release assets fetched from upstream once they match the digest upstream
+// publishes, so only the first machine downloads from the internet.
+// Checksums, manifests and signatures always come from upstream: anyone
+// who can write to the cache could otherwise vouch for their own files.
+type sharedCacheSource struct {
+	Source
+	location string // http(s) URL or filesystem path, without trailing slash
+}
+
+// sharedCacheLocation returns VIBE_CACHE_URL, or the cache.shared_url setting
+func sharedCacheLocation(o Options) string {
+	if loc := os.Getenv("VIBE_CACHE_URL"); loc != "" {
+		return loc
+	}
+	return o.SharedCache
+}
+
+// withSharedCache wraps a source in the shared cache when one is configured
+func withSharedCache(source Source) Source {
+	location := sharedCacheLocation(opts)
+	if location == "" || opts.NoCache {
+		return source
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme == "file" {
+		location = u.Path
+	}
+	fmt.Printf("🗄️  Shared cache: %s\n", location)
+	return sharedCacheSource{Source: source, location: strings.TrimSuffix(location, "/")}
+}
+
+// remote reports whether the cache is served over HTTP
+func (s sharedCacheSource) remote() bool {
+	return strings.HasPrefix(s.location, "http://") || strings.HasPrefix(s.location, "https://")
+}
+
+// sharedCacheKey names the cache directory of a source after a hash of
+// its name, which tells forks and mirrors apart
+func sharedCacheKey(source Source) string {
+	sum := sha256.Sum256([]byte(originSource(source).Name()))
+	return hex.EncodeToString(sum[:6])
+}
+
+// isVerificationMaterial reports whether a release asset vouches for the
+// others rather than being installed
+func isVerificationMaterial(asset string) bool {
+	switch asset {
+	case "SHA256SUMS", MIRROR_MANIFEST, MANIFEST_SIGNATURE:
+		return true
+	}
+	return strings.HasSuffix(asset, COSIGN_SIGNATURE_EXT) || strings.HasSuffix(asset, COSIGN_CERTIFICATE_EXT)
+}
+
+func (s sharedCacheSource) FetchAsset(version, asset, destPath string) error {
+	if isVerificationMaterial(asset) {
+		return s.Source.FetchAsset(version, asset, destPath)
+	}
+	published := func() string {
+		digests, err := releaseDigests(s.Source, version)
+		if err != nil {
+			return ""
+		}
+		return digests[asset]
+	}
+	return s.fetch(version+"/"+asset, destPath, published, func() error {
+		return s.Source.FetchAsset(version, asset, destPath)
+	})
+}
+
+// FetchGrammar serves grammars the cache has, which installGrammar checks
+// against their published digest, but never fills it with one: the cache
+// does not know which release's checksums cover a grammar
+func (s sharedCacheSource) FetchGrammar(pkg, version, file, destPath string) error {
+	published := func() string { return "" }
+	return s.fetch("grammars/"+pkg+"@"+version+"/"+file, destPath, published, func() error {
+		return s.Source.FetchGrammar(pkg, version, file, destPath)
+	})
+}
+
+// ReleaseNotes passes release notes through when the source has them
+func (s sharedCacheSource) ReleaseNotes(version string) (string, error) {
+	notes, ok := s.Source.(ReleaseNotesSource)
+	if !ok {
+		return "", nil
+	}
+	return notes.ReleaseNotes(version)
+}
+
+// fetch tries the shared cache, then upstream, filling a writable cache
+// when the download matches the digest published returns
+func (s sharedCacheSource) fetch(rel, destPath string, published func() string, upstream func() error) error {
+	rel = sharedCacheKey(s.Source) + "/" + rel
+	if s.remote() {
+		if err := downloadFile(s.location+"/"+rel, destPath, opts.DownloadTimeout); err == nil {
+			fmt.Printf("♻️  Using %s from the shared cache\n", filepath.Base(rel))
+			return nil
+		}
+	} else if err := copyFile(filepath.Join(s.location, filepath.FromSlash(rel)), destPath, 0644); err == nil {
+		fmt.Printf("♻️  Using %s from the shared cache\n", filepath.Base(rel))
+		return nil
+	}
+
+	if err := upstream(); err != nil {
+		return err
+	}
+
+	if s.remote() {
+		return nil
+	}
+	want := published()
+	if digest, _, err := fileSHA256(destPath); err != nil || want == "" || !strings.EqualFold(digest, want) {
+		return nil
+	}
+	if err := s.fill(rel, destPath); err != nil {
+		warnf("Could not add %s to the shared cache: %v", filepath.Base(rel), err)
+	}
+	return nil
+}
+
+// fill copies a download into the shared cache directory. The file is
+// written under a temporary name and renamed so other machines never read
+// a partial copy.
+func (s sharedCacheSource) fill(rel, path string) error {
+	dest := filepath.Join(s.location, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", dest, os.Getpid())
+	if err := copyFile(path, tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSharedCacheDirectory(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	shared := t.TempDir()
+	t.Setenv("VIBE_CACHE_URL", shared)
+	release := func() *variantSource {
+		return &variantSource{files: map[string][]byte{
+			"vibe-linux": []byte("binary"),
+			"SHA256SUMS": []byte(sha256Hex("binary") + "  vibe-linux\n"),
+		}}
+	}
+
+	// The first machine downloads from upstream and fills the shared cache
+	upstream := release()
+	first := withSharedCache(upstream)
+	if err := first.FetchAsset("v1.0.0", "vibe-linux", filepath.Join(t.TempDir(), "vibe")); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	cached := filepath.Join(shared, sharedCacheKey(upstream), "v1.0.0", "vibe-linux")
+	if data, err := os.ReadFile(cached); err != nil || string(data) != "binary" {
+		t.Fatalf("shared cache not filled: %q, %v", data, err)
+	}
+
+	// The next machine is served from the shared cache, but always reads
+	// the checksums from upstream
+	upstream = release()
+	second := withSharedCache(upstream)
+	dest := filepath.Join(t.TempDir(), "vibe")
+	if err := second.FetchAsset("v1.0.0", "vibe-linux", dest); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	if err := second.FetchAsset("v1.0.0", "SHA256SUMS", filepath.Join(t.TempDir(), "SHA256SUMS")); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	if len(upstream.fetched) != 1 || upstream.fetched[0] != "SHA256SUMS" {
+		t.Errorf("upstream fetches = %v, want only SHA256SUMS", upstream.fetched)
+	}
+	if _, err := os.Stat(filepath.Join(shared, sharedCacheKey(upstream), "v1.0.0", "SHA256SUMS")); err == nil {
+		t.Error("SHA256SUMS was added to the shared cache")
+	}
+
+	// A fork sharing the cache has its own entries
+	fork, err := newSource("github+https://github.com/someone/fork")
+	if err != nil {
+		t.Fatalf("newSource failed: %v", err)
+	}
+	if sharedCacheKey(fork) == sharedCacheKey(githubSource{}) {
+		t.Errorf("fork and upstream share the cache key %s", sharedCacheKey(fork))
+	}
+}
+
+func TestSharedCacheFillsOnlyVerifiedAssets(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	shared := t.TempDir()
+	t.Setenv("VIBE_CACHE_URL", shared)
+
+	upstream := &variantSource{files: map[string][]byte{
+		"vibe-linux":  []byte("binary"),
+		"vibe-darwin": []byte("tampered"),
+		"SHA256SUMS":  []byte(sha256Hex("other") + "  vibe-darwin\n"),
+	}}
+	source := withSharedCache(upstream)
+	for _, asset := range []string{"vibe-linux", "vibe-darwin"} {
+		if err := source.FetchAsset("v1.0.0", asset, filepath.Join(t.TempDir(), asset)); err != nil {
+			t.Fatalf("FetchAsset(%s) failed: %v", asset, err)
+		}
+		if _, err := os.Stat(filepath.Join(shared, sharedCacheKey(upstream), "v1.0.0", asset)); err == nil {
+			t.Errorf("unverified %s was added to the shared cache", asset)
+		}
+	}
+}
+
+func TestSharedCacheHTTP(t *testing.T) {
+	upstream := countingSource{fakeSource: fakeSource{latest: "v1.0.0"}, fetches: new(int)}
+	key := sharedCacheKey(upstream)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+key+"/grammars/tree-sitter-typescript@0.23.2/tree-sitter-typescript.wasm" {
+			w.Header().Set("Content-Type", "application/wasm")
+			w.Write([]byte("cached wasm"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	t.Setenv("VIBE_CACHE_URL", server.URL+"/")
+
+	source := withSharedCache(upstream)
+
+	dest := filepath.Join(t.TempDir(), "grammar.wasm")
+	if err := source.FetchGrammar("tree-sitter-typescript", "0.23.2", "tree-sitter-typescript.wasm", dest); err != nil {
+		t.Fatalf("FetchGrammar failed: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "cached wasm" {
+		t.Errorf("grammar = %q, want the cached copy", data)
+	}
+
+	// Misses fall through to upstream
+	if err := source.FetchAsset("v1.0.0", "vibe-linux", filepath.Join(t.TempDir(), "vibe")); err != nil {
+		t.Fatalf("FetchAsset failed: %v", err)
+	}
+	if *upstream.fetches != 1 {
+		t.Errorf("upstream was hit %d times, want 1", *upstream.fetches)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// LIBRARY_PACKAGES suggests the distribution package providing commonly
+// missing shared libraries, as "Debian/Ubuntu name / Fedora/Arch name"
+var LIBRARY_PACKAGES = map[string]string{
+	"libssl.so.3":      "libssl3 / openssl-libs",
+	"libcrypto.so.3":   "libssl3 / openssl-libs",
+	"libssl.so.1.1":    "libssl1.1 / openssl1.1",
+	"libcrypto.so.1.1": "libssl1.1 / openssl1.1",
+	"libstdc++.so.6":   "libstdc++6 / libstdc++",
+	"libgcc_s.so.1":    "libgcc-s1 / libgcc",
+	"libz.so.1":        "zlib1g / zlib",
+	"libbz2.so.1.0":    "libbz2-1.0 / bzip2-libs",
+	"liblzma.so.5":     "liblzma5 / xz-libs",
+	"libsqlite3.so.0":  "libsqlite3-0 / sqlite-libs",
+}
+
+// MissingLibrary is a shared library an installed binary cannot load
+type MissingLibrary struct {
+	Binary  string
+	Library string
+}
+
+// String describes the missing library with a package suggestion
+func (m MissingLibrary) String() string {
+	msg := fmt.Sprintf("%s needs %s, which was not found", filepath.Base(m.Binary), m.Library)
+	if pkg, ok := LIBRARY_PACKAGES[filepath.Base(m.Library)]; ok {
+		msg += fmt.Sprintf(" (install package %s)", pkg)
+	}
+	return msg
+}
+
+// librarySearchPath returns the directories the dynamic loader searches,
+// excluding per-binary RUNPATH entries
+func librarySearchPath() []string {
+	dirs := filepath.SplitList(os.Getenv("LD_LIBRARY_PATH"))
+
+	// The loader cache lists everything configured in /etc/ld.so.conf
+	if out, err := exec.Command("ldconfig", "-p").Output(); err == nil {
+		seen := map[string]bool{}
+		for _, line := range strings.Split(string(out), "\n") {
+			if _, path, found := strings.Cut(line, "=> "); found {
+				dir := filepath.Dir(strings.TrimSpace(path))
+				if !seen[dir] {
+					seen[dir] = true
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+	}
+
+	triple := map[string]string{"amd64": "x86_64-linux-gnu", "arm64": "aarch64-linux-gnu"}[runtime.GOARCH]
+	for _, root := range []string{"/lib", "/usr/lib"} {
+		dirs = append(dirs, root, root+"64")
+		if triple != "" {
+			dirs = append(dirs, filepath.Join(root, triple))
+		}
+	}
+	return dirs
+}
+
+// missingLibraries parses the dynamic section of an ELF binary and returns
+// the program interpreter and DT_NEEDED libraries that do not resolve.
+// Statically linked binaries have neither and always pass.
+func missingLibraries(binary string, searchPath []string) ([]MissingLibrary, error) {
+	f, err := elf.Open(binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", binary, err)
+	}
+	defer f.Close()
+
+	var missing []MissingLibrary
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return nil, fmt.Errorf("failed to read interpreter of %s: %w", binary, err)
+		}
+		interp := strings.TrimRight(string(data), "\x00")
+		if _, err := os.Stat(interp); err != nil {
+			missing = append(missing, MissingLibrary{Binary: binary, Library: interp})
+		}
+	}
+
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dynamic section of %s: %w", binary, err)
+	}
+
+	// RUNPATH/RPATH entries are searched first, with $ORIGIN expanded
+	var dirs []string
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		entries, _ := f.DynString(tag)
+		for _, entry := range entries {
+			for _, dir := range filepath.SplitList(entry) {
+				dir = strings.NewReplacer("$ORIGIN", filepath.Dir(binary), "${ORIGIN}", filepath.Dir(binary)).Replace(dir)
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	dirs = append(dirs, searchPath...)
+
+	for _, lib := range needed {
+		if !resolveLibrary(lib, dirs) {
+			missing = append(missing, MissingLibrary{Binary: binary, Library: lib})
+		}
+	}
+	return missing, nil
+}
+
+// resolveLibrary reports whether lib exists in one of dirs
+func resolveLibrary(lib string, dirs []string) bool {
+	if strings.Contains(lib, "/") {
+		_, err := os.Stat(lib)
+		return err == nil
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, lib)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSharedLibraries confirms that vibe and the prebuilt dependencies the
+// installer downloaded can load all their shared libraries. Only Linux ELF
+// binaries are checked; macOS system libraries live in the dyld cache.
+func checkSharedLibraries(binaryPath string, receipt *Receipt) error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	fmt.Printf("🔍 Checking shared library dependencies...\n")
+
+	binaries := []string{binaryPath}
+	for _, c := range receipt.Changes {
+		if c.Kind == CHANGE_FILE {
+			binaries = append(binaries, c.Path)
+		}
+	}
+
+	searchPath := librarySearchPath()
+	var problems []string
+	for _, binary := range binaries {
+		if _, err := os.Stat(binary); err != nil {
+			continue // removed since it was recorded
+		}
+		missing, err := missingLibraries(binary, searchPath)
+		if err != nil {
+			// Scripts and other non-ELF files have no libraries to check
+			var formatErr *elf.FormatError
+			if errors.As(err, &formatErr) {
+				continue
+			}
+			return err
+		}
+		for _, m := range missing {
+			problems = append(problems, m.String())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("missing shared libraries:\n   • %s", strings.Join(problems, "\n   • "))
+	}
+	fmt.Printf("✅ All shared libraries resolve\n")
+	return nil
+}
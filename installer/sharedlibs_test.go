@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMissingLibraries(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("shared library checks only run on Linux")
+	}
+	binary := "/bin/ls"
+	if _, err := os.Stat(binary); err != nil {
+		t.Skip("/bin/ls not available")
+	}
+
+	missing, err := missingLibraries(binary, librarySearchPath())
+	if err != nil {
+		t.Fatalf("missingLibraries failed: %v", err)
+	}
+	if len(missing) > 0 {
+		t.Errorf("Expected all libraries of %s to resolve, missing %v", binary, missing)
+	}
+
+	// With nowhere to search, every DT_NEEDED entry is missing
+	missing, err = missingLibraries(binary, []string{t.TempDir()})
+	if err != nil {
+		t.Fatalf("missingLibraries failed: %v", err)
+	}
+	for _, m := range missing {
+		if m.Binary != binary || m.Library == "" {
+			t.Errorf("Unexpected missing entry %+v", m)
+		}
+	}
+}
+
+func TestCheckSharedLibrariesSkipsScripts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("shared library checks only run on Linux")
+	}
+	script := filepath.Join(t.TempDir(), "tool")
+	os.WriteFile(script, []byte("#!/bin/sh\necho ok\n"), 0755)
+
+	receipt := &Receipt{Changes: []EnvChange{
+		{Kind: CHANGE_FILE, Path: script},
+		{Kind: CHANGE_FILE, Path: filepath.Join(t.TempDir(), "removed")},
+	}}
+	if err := checkSharedLibraries(script, receipt); err != nil {
+		t.Errorf("checkSharedLibraries failed on a script: %v", err)
+	}
+}
+
+func TestMissingLibraryString(t *testing.T) {
+	m := MissingLibrary{Binary: "/home/u/.local/bin/vibe", Library: "libssl.so.3"}
+	if got := m.String(); !strings.Contains(got, "vibe needs libssl.so.3") || !strings.Contains(got, "libssl3") {
+		t.Errorf("String() = %q", got)
+	}
+
+	m.Library = "libunknown.so.9"
+	if got := m.String(); strings.Contains(got, "install package") {
+		t.Errorf("String() suggested a package for an unknown library: %q", got)
+	}
+}
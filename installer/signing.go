@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// MANIFEST_SIGNATURE is published next to a release manifest: the base64
+// ed25519 signature of the manifest bytes. Mirrors are signed with
+// `mirror --sign-key key.pem` and installs check them with
+// --manifest-key pub.pem; keys are PEM files as written by
+// `openssl genpkey -algorithm ed25519` and `openssl pkey -pubout`.
+const MANIFEST_SIGNATURE = MIRROR_MANIFEST + ".sig"
+
+// readPEMBlock reads the first PEM block of a key file
+func readPEMBlock(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM key file", path)
+	}
+	return block, nil
+}
+
+// loadSigningKey reads an ed25519 private key in PKCS#8 PEM form
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", path)
+	}
+	return private, nil
+}
+
+// loadTrustedKey reads an ed25519 public key in PKIX PEM form
+func loadTrustedKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 key", path)
+	}
+	return public, nil
+}
+
+// signManifest returns the signature file content for manifest bytes
+func signManifest(data []byte, key ed25519.PrivateKey) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)) + "\n")
+}
+
+// verifyManifestSignature checks a signature file against manifest bytes
+func verifyManifestSignature(data, sig []byte, key ed25519.PublicKey) error {
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil {
+		return fmt.Errorf("malformed manifest signature: %w", err)
+	}
+	if !ed25519.Verify(key, data, raw) {
+		return fmt.Errorf("release manifest signature does not match the trusted key")
+	}
+	return nil
+}
+
+// checkManifestSignature fetches the signature published with a release
+// manifest and checks it against the --manifest-key
+func checkManifestSignature(source Source, version string, data []byte) error {
+	key, err := loadTrustedKey(opts.ManifestKey)
+	if err != nil {
+		return err
+	}
+	sigPath, err := quarantinePath(MANIFEST_SIGNATURE)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sigPath)
+	if err := source.FetchAsset(version, MANIFEST_SIGNATURE, sigPath); err != nil {
+		return fmt.Errorf("release manifest of %s is not signed: %w", version, err)
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+	return verifyManifestSignature(data, sig, key)
+}
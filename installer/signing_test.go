@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeyPair writes an ed25519 key pair as PEM files
+func writeKeyPair(t *testing.T) (private, public string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	private = filepath.Join(dir, "key.pem")
+	os.WriteFile(private, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	der, _ = x509.MarshalPKIXPublicKey(pub)
+	public = filepath.Join(dir, "pub.pem")
+	os.WriteFile(public, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+	return private, public
+}
+
+func TestSignedMirror(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	private, public := writeKeyPair(t)
+	_, otherPublic := writeKeyPair(t)
+	defer func() { opts.SignKey, opts.ManifestKey = "", "" }()
+
+	dir := t.TempDir()
+	opts.SignKey = private
+	if _, err := mirrorRelease(fakeSource{latest: "v1.2.0", missing: map[string]bool{"SHA256SUMS": true}}, "v1.2.0", dir); err != nil {
+		t.Fatalf("mirrorRelease failed: %v", err)
+	}
+	opts.SignKey = ""
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	mirror, err := newSource(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The digests an install checks against come from the signed manifest
+	opts.ManifestKey = public
+	digests, err := releaseDigests(mirror, "v1.2.0")
+	if err != nil {
+		t.Fatalf("releaseDigests failed: %v", err)
+	}
+	asset := releaseAssetName("linux", "amd64", "v1.2.0")
+	if digests[asset] != sha256Hex("v1.2.0/"+asset) {
+		t.Errorf("digest of %s = %q", asset, digests[asset])
+	}
+
+	sums, _ := os.ReadFile(filepath.Join(dir, "v1.2.0", "SHA256SUMS"))
+	if !strings.Contains(string(sums), digests[asset]+"  "+asset) {
+		t.Errorf("SHA256SUMS does not list %s:\n%s", asset, sums)
+	}
+
+	opts.ManifestKey = otherPublic
+	if _, err := releaseDigests(mirror, "v1.2.0"); err == nil {
+		t.Error("a manifest signed with another key was accepted")
+	}
+
+	opts.ManifestKey = public
+	manifestPath := filepath.Join(dir, "v1.2.0", MIRROR_MANIFEST)
+	data, _ := os.ReadFile(manifestPath)
+	os.WriteFile(manifestPath, []byte(strings.Replace(string(data), digests[asset], sha256Hex("evil"), 1)), 0644)
+	if _, err := releaseDigests(mirror, "v1.2.0"); err == nil {
+		t.Error("a tampered manifest was accepted")
+	}
+
+	os.Remove(filepath.Join(dir, "v1.2.0", MANIFEST_SIGNATURE))
+	if _, err := releaseDigests(mirror, "v1.2.0"); err == nil {
+		t.Error("an unsigned manifest was accepted")
+	}
+}
+
+func TestMirrorRejectsChecksumMismatch(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	asset := releaseAssetName("linux", "amd64", "v1.2.0")
+	source := assetSource{assets: map[string]string{"SHA256SUMS": sha256Hex("other") + "  " + asset + "\n"}}
+	manifest := &MirrorManifest{Assets: []MirrorAsset{{Path: "v1.2.0/" + asset, SHA256: sha256Hex("v1.2.0/" + asset)}}}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "v1.2.0"), 0755)
+	if err := writeMirrorChecksums(source, "v1.2.0", dir, manifest); err == nil {
+		t.Error("a mirrored file not matching the published SHA256SUMS was accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Steps failures can be injected into besides those explain knows
+const (
+	STEP_MIGRATE = "migrate" // upgrade migrations; --rollback-on-error restores only the binary after them
+	STEP_VERIFY  = "verify"  // verification of the installed binary
+)
+
+// EXIT_SIMULATED_CRASH is the exit status of a simulated crash, the one a
+// shell reports for a process killed with SIGKILL
+const EXIT_SIMULATED_CRASH = 137
+
+// SimulatedFailure is a failure injected with --simulate-failure, so QA
+// and support can reproduce failure handling, rollback and resume without
+// breaking a network or a disk
+type SimulatedFailure struct {
+	Step  string
+	Crash bool // exit at once, leaving the lock and staged files behind
+}
+
+// simulationSteps lists the steps failures can be injected into
+func simulationSteps() []string {
+	return append(explainSteps(), STEP_MIGRATE, STEP_VERIFY)
+}
+
+// hiddenFlags registers flags that are accepted but left out of help and
+// completion, being meant for QA and support rather than users
+func hiddenFlags(fs *flag.FlagSet) {
+	fs.Func("simulate-failure", "fail a step on purpose: step=<name>[,crash] (repeatable)", func(v string) error {
+		f, err := parseSimulatedFailure(v)
+		if err != nil {
+			return err
+		}
+		opts.SimulateFailures = append(opts.SimulateFailures, f)
+		return nil
+	})
+}
+
+// parseSimulatedFailure parses "step=<name>" with an optional ",crash"
+func parseSimulatedFailure(v string) (SimulatedFailure, error) {
+	var f SimulatedFailure
+	for _, field := range strings.Split(v, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "step":
+			f.Step = value
+		case "crash":
+			f.Crash = true
+		default:
+			return f, fmt.Errorf("unknown field %q; use step=<name>[,crash]", field)
+		}
+	}
+	steps := simulationSteps()
+	for _, s := range steps {
+		if s == f.Step {
+			return f, nil
+		}
+	}
+	if suggestion := closestMatch(f.Step, steps); suggestion != "" {
+		return f, fmt.Errorf("unknown step %q; did you mean %q?", f.Step, suggestion)
+	}
+	return f, fmt.Errorf("unknown step %q; steps: %s", f.Step, strings.Join(steps, ", "))
+}
+
+// simulateFailure returns the injected failure of step, or nil. A
+// simulated crash exits on the spot, skipping every cleanup as a killed
+// process would, for the next run to recover from.
+func simulateFailure(step string) error {
+	for _, f := range opts.SimulateFailures {
+		if f.Step != step {
+			continue
+		}
+		if f.Crash {
+			fmt.Printf("🧪 Simulating a crash in %s\n", step)
+			os.Exit(EXIT_SIMULATED_CRASH)
+		}
+		fmt.Printf("🧪 Simulating a failure of %s\n", step)
+		return fmt.Errorf("simulated failure of %s (--simulate-failure)", step)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSimulatedFailure(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SimulatedFailure
+		wantErr string
+	}{
+		{"step=vibe", SimulatedFailure{Step: STEP_VIBE}, ""},
+		{"step=migrate,crash", SimulatedFailure{Step: STEP_MIGRATE, Crash: true}, ""},
+		{"step=vibr", SimulatedFailure{}, `did you mean "vibe"`},
+		{"step=vibe,mode=slow", SimulatedFailure{}, `unknown field "mode=slow"`},
+	}
+	for _, tt := range tests {
+		got, err := parseSimulatedFailure(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseSimulatedFailure(%q) = %v, want an error containing %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSimulatedFailure(%q) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestSimulatedModuleFailure(t *testing.T) {
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+	opts.SimulateFailures = []SimulatedFailure{{Step: "a"}}
+
+	var installed []string
+	module := func(name string) Module {
+		return Module{Name: name, Install: func(string, Source, *Receipt) error {
+			installed = append(installed, name)
+			return nil
+		}}
+	}
+	modules := []Module{module("a"), module("b")}
+	receipt := &Receipt{}
+	if err := installModules(modules, t.TempDir(), fakeSource{}, receipt); err == nil {
+		t.Fatal("installModules() succeeded with a simulated failure")
+	}
+	if !reflect.DeepEqual(installed, []string{"b"}) || !reflect.DeepEqual(receipt.FailedModules, []string{"a"}) {
+		t.Errorf("installed = %v, failed = %v, want only a failed", installed, receipt.FailedModules)
+	}
+
+	// The failure is recorded for --retry-failed to resume from
+	opts.SimulateFailures = nil
+	if err := installModules(modules[:1], t.TempDir(), fakeSource{}, receipt); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if len(receipt.FailedModules) != 0 {
+		t.Errorf("FailedModules = %v after the retry", receipt.FailedModules)
+	}
+}
+
+func TestSimulateFailureFlagIsHidden(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts := opts
+	defer func() { opts = savedOpts }()
+
+	if err := execute([]string{"cache", "--simulate-failure", "step=path", "ls"}); err != nil {
+		t.Fatalf("execute with --simulate-failure failed: %v", err)
+	}
+	if want := []SimulatedFailure{{Step: STEP_PATH}}; !reflect.DeepEqual(opts.SimulateFailures, want) {
+		t.Errorf("SimulateFailures = %+v, want %+v", opts.SimulateFailures, want)
+	}
+
+	install, _ := findCommand("install")
+	var help bytes.Buffer
+	printCommandHelp(&help, install)
+	for _, name := range append(flagNames(install.Flags), help.String()) {
+		if strings.Contains(name, "simulate-failure") {
+			t.Errorf("--simulate-failure is not hidden: %q", name)
+		}
+	}
+}
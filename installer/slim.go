@@ -0,0 +1,49 @@
+//go:build slim
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Slim builds install only vibe and its grammars, for container images and
+// CI where dependencies come from elsewhere. Everything that compiles with
+// cargo is left out.
+
+// CARGO_MODULES is empty: slim builds have no cargo modules
+var CARGO_MODULES []Module
+
+// cargoFlags registers nothing: slim builds do not run cargo
+func cargoFlags(fs *flag.FlagSet) {}
+
+// prepareCargo fails: slim builds cannot install cargo modules, including
+// user-defined ones
+func prepareCargo() error {
+	return fmt.Errorf("this is a slim installer without cargo support; use the full installer for cargo modules")
+}
+
+// withPrebuilt has nothing to swap without cargo modules
+func withPrebuilt(modules []Module) []Module {
+	return modules
+}
+
+// cargoModule only reports that cargo modules need the full installer
+func cargoModule(name, version string) func(string, Source, *Receipt) error {
+	return func(string, Source, *Receipt) error {
+		return prepareCargo()
+	}
+}
+
+// explainCargo reports that cargo modules need the full installer
+func explainCargo(name, version, bin string) Explanation {
+	return Explanation{
+		Summary: fmt.Sprintf("would compile %s v%s with cargo", name, version),
+		Notes:   []string{prepareCargo().Error()},
+	}
+}
+
+// explainRust reports that slim builds never install Rust
+func explainRust() Explanation {
+	return Explanation{Summary: "installs the Rust toolchain", Notes: []string{prepareCargo().Error()}}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// errAssetNotFound reports that a source does not have a requested asset,
+// as opposed to failing to download it
+var errAssetNotFound = errors.New("asset not found")
+
+// Source resolves release versions and downloads release assets from a
+// distribution channel (GitHub releases, object storage mirrors, ...)
+type Source interface {
+	// Name returns a human-readable description of the source
+	Name() string
+	// LatestVersion returns the newest stable release tag
+	LatestVersion() (string, error)
+	// FetchAsset downloads a release asset of the given version to destPath
+	FetchAsset(version, asset, destPath string) error
+	// FetchGrammar downloads a file from a pinned grammar package to destPath
+	FetchGrammar(pkg, version, file, destPath string) error
+}
+
+// newSource selects a Source implementation from the --source URL scheme
+func newSource(spec string) (Source, error) {
+	if spec == "" {
+		return githubSource{}, nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL %q: %w", spec, err)
+	}
+
+	switch u.Scheme {
+	case "s3", "gs", "az":
+		return newBlobSource(u)
+	case "oci", "oci+http":
+		return newOCISource(u)
+	case "http", "https":
+		return newHTTPSource(u)
+	}
+	if kind, _, ok := strings.Cut(u.Scheme, "+"); ok {
+		switch kind {
+		case FORGE_GITHUB, FORGE_GITEA, FORGE_FORGEJO, FORGE_GITLAB:
+			return newForgeSource(kind, u)
+		}
+	}
+	return nil, fmt.Errorf("unsupported source scheme %q (supported: s3://, gs://, az://, oci://, https://, github+https://, gitea+https://, forgejo+https://, gitlab+https://)", u.Scheme)
+}
+
+// githubSource is the default source backed by GitHub releases and unpkg
+type githubSource struct{}
+
+func (githubSource) Name() string {
+	return "GitHub releases"
+}
+
+func (g githubSource) LatestVersion() (string, error) {
+	if opts.IncludePrereleases {
+		return latestPrerelease(g)
+	}
+	return getLatestVersion()
+}
+
+func (githubSource) FetchAsset(version, asset, destPath string) error {
+	return downloadBinary(fmt.Sprintf("%s/%s/%s", GITHUB_RELEASES_URL, version, asset), destPath)
+}
+
+func (githubSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return downloadFile(fmt.Sprintf("%s/%s@%s/%s", UNPKG_URL, pkg, version, file), destPath, opts.DownloadTimeout)
+}
+
+// mirrorSource reads a static mirror laid out by the mirror command:
+// latest, <version>/<asset> and grammars/<pkg>@<version>/<file>
+type mirrorSource struct {
+	baseURL string
+}
+
+func newMirrorSource(u *url.URL) mirrorSource {
+	return mirrorSource{baseURL: strings.TrimSuffix(u.String(), "/")}
+}
+
+func (m mirrorSource) Name() string {
+	return m.baseURL
+}
+
+func (m mirrorSource) LatestVersion() (string, error) {
+	resp, err := httpGet(m.baseURL+"/latest", opts.APITimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to query mirror: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("mirror returned status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", fmt.Errorf("failed to read mirror version: %w", err)
+	}
+	latest := strings.TrimSpace(string(body))
+	if _, ok := parseVersion(latest); !ok {
+		return "", fmt.Errorf("mirror advertises invalid version %q", latest)
+	}
+	return latest, nil
+}
+
+func (m mirrorSource) FetchAsset(version, asset, destPath string) error {
+	return downloadBinary(fmt.Sprintf("%s/%s/%s", m.baseURL, version, asset), destPath)
+}
+
+func (m mirrorSource) FetchGrammar(pkg, version, file, destPath string) error {
+	return downloadFile(fmt.Sprintf("%s/grammars/%s@%s/%s", m.baseURL, pkg, version, file), destPath, opts.DownloadTimeout)
+}
+
+// downloadFile fetches url into destPath without progress output
+func downloadFile(url, destPath string, timeout time.Duration) error {
+	if opts.FlakyNetwork {
+		return downloadResumable(url, destPath, false)
+	}
+	return retryStalled(path.Base(url), func() error { return downloadFileOnce(url, destPath, timeout) })
+}
+
+// downloadFileOnce makes one attempt at downloadFile
+func downloadFileOnce(url, destPath string, timeout time.Duration) error {
+	resp, err := httpGet(url, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed with status: %d %s", resp.StatusCode, resp.Status)
+	}
+	if err := checkContentType(resp); err != nil {
+		return err
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return fmt.Errorf("failed to save %s: %w", destPath, err)
+	}
+	return nil
+}
+
+// checkContentType rejects a response that cannot be the requested file,
+// before any of it is written: an HTML page, such as an error or login
+// page served with status 200, and for a WebAssembly file also text or JSON
+func checkContentType(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	name := path.Base(resp.Request.URL.Path)
+	wasm := strings.HasSuffix(name, ".wasm")
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+	case wasm && (strings.HasPrefix(mediaType, "text/") || mediaType == "application/json"):
+	default:
+		return nil
+	}
+	if wasm {
+		return fmt.Errorf("received non-WASM content for %s from %s (Content-Type %s)", name, resp.Request.URL.Host, contentType)
+	}
+	return fmt.Errorf("received a web page instead of %s from %s (Content-Type %s)", name, resp.Request.URL.Host, contentType)
+}
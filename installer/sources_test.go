@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "default github", spec: "", wantName: "GitHub releases"},
+		{name: "s3 bucket with prefix", spec: "s3://releases/vibe/", wantName: "s3://releases/vibe"},
+		{name: "gcs bucket", spec: "gs://releases", wantName: "gs://releases"},
+		{name: "azure container", spec: "az://acct/container/vibe", wantName: "az://acct/container/vibe"},
+		{name: "azure without container", spec: "az://acct", wantErr: true},
+		{name: "missing bucket", spec: "s3:///vibe", wantErr: true},
+		{name: "unsupported scheme", spec: "ftp://example.com/vibe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := newSource(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newSource(%s) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && source.Name() != tt.wantName {
+				t.Errorf("newSource(%s).Name() = %v, want %v", tt.spec, source.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestBlobSourceKeys(t *testing.T) {
+	source, err := newSource("az://acct/container/mirror/vibe")
+	if err != nil {
+		t.Fatalf("newSource failed: %v", err)
+	}
+	b := source.(*blobSource)
+
+	if got, want := b.key("v1.0.0/vibe"), "mirror/vibe/v1.0.0/vibe"; got != want {
+		t.Errorf("key() = %v, want %v", got, want)
+	}
+	if got, want := b.objectURL("x/y"), "az://acct/container/x/y"; got != want {
+		t.Errorf("objectURL() = %v, want %v", got, want)
+	}
+}
+
+func TestParseBlobListing(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  string
+		expected string
+	}{
+		{
+			name:     "aws s3 ls",
+			listing:  "                           PRE grammars/\n                           PRE v0.7.27/\n                           PRE v0.8.0/\n",
+			expected: "v0.8.0",
+		},
+		{
+			name:     "gcloud storage ls",
+			listing:  "gs://releases/vibe/v0.7.9/\ngs://releases/vibe/v0.7.27/\ngs://releases/vibe/v0.8.0-rc.1/\n",
+			expected: "v0.7.27",
+		},
+		{
+			name:     "az storage blob list",
+			listing:  "vibe/v0.6.0/\nvibe/v0.7.0/\nvibe/index.json\n",
+			expected: "v0.7.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pickLatestVersion(parseBlobListing(tt.listing))
+			if !ok || got != tt.expected {
+				t.Errorf("latest of %q = %v, want %v", tt.listing, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v0.7.27", "v0.7.9", 1},
+		{"v0.8.0", "v1.0.0", -1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"1.0.0-beta", "v1.0.0-alpha", 1},
+	}
+
+	for _, tt := range tests {
+		a, okA := parseVersion(tt.a)
+		b, okB := parseVersion(tt.b)
+		if !okA || !okB {
+			t.Fatalf("parseVersion failed for %s or %s", tt.a, tt.b)
+		}
+		if got := compareVersions(a, b); got != tt.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+
+	if _, ok := parseVersion("latest"); ok {
+		t.Error("parseVersion(latest) should fail")
+	}
+}
+
+func TestDownloadRejectsWebPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/grammar.wasm":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, "Package not found")
+		case "/vibe-linux":
+			fmt.Fprint(w, "<!DOCTYPE html><html><body>Sign in</body></html>")
+		case "/ok.wasm":
+			w.Header().Set("Content-Type", "application/wasm")
+			w.Write(wasmMagic)
+		}
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := downloadFile(server.URL+"/grammar.wasm", dest, time.Minute); err == nil || !strings.Contains(err.Error(), "received non-WASM content") {
+		t.Errorf("text served as a grammar = %v", err)
+	}
+	if err := downloadFile(server.URL+"/vibe-linux", dest, time.Minute); err == nil || !strings.Contains(err.Error(), "web page") {
+		t.Errorf("HTML served as a binary = %v", err)
+	}
+	if err := downloadFile(server.URL+"/ok.wasm", dest, time.Minute); err != nil {
+		t.Errorf("a grammar served as application/wasm = %v", err)
+	}
+}
+
+func TestBlobSourceMissingObject(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake aws CLI is a shell script")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho 'fatal error: An error occurred (404) when calling the HeadObject operation: Key \"v1.0.0/vibe.sig\" does not exist' >&2\nexit 1\n"
+	os.WriteFile(filepath.Join(bin, "aws"), []byte(script), 0755)
+	t.Setenv("PATH", bin)
+
+	b := &blobSource{scheme: "s3", bucket: "releases"}
+	err := b.FetchAsset("v1.0.0", "vibe.sig", filepath.Join(t.TempDir(), "vibe.sig"))
+	if !errors.Is(err, errAssetNotFound) {
+		t.Errorf("FetchAsset of a missing object = %v, want errAssetNotFound", err)
+	}
+
+	script = "#!/bin/sh\necho 'fatal error: Unable to locate credentials' >&2\nexit 1\n"
+	os.WriteFile(filepath.Join(bin, "aws"), []byte(script), 0755)
+	err = b.FetchAsset("v1.0.0", "vibe", filepath.Join(t.TempDir(), "vibe"))
+	if err == nil || errors.Is(err, errAssetNotFound) {
+		t.Errorf("FetchAsset without credentials = %v, want a failure other than not found", err)
+	}
+}
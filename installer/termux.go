@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/vhybzOS/.vibe/installer/platform"
+)
+
+// TERMUX_DEFAULT_PREFIX is where Termux, the Android terminal emulator,
+// keeps its whole userland
+const TERMUX_DEFAULT_PREFIX = "/data/data/com.termux/files/usr"
+
+// TERMUX_UNSUPPORTED lists built-in modules that cannot be installed on
+// Android, with the reason shown to the user
+var TERMUX_UNSUPPORTED = map[string]string{
+	"surrealdb": "its RocksDB storage engine does not build for Android; point vibe at a SurrealDB server on another machine",
+}
+
+// termuxPrefix returns Termux's $PREFIX, or "" when not running in Termux
+func termuxPrefix() string {
+	if platform.Detect().Libc != platform.LibcBionic {
+		return ""
+	}
+	prefix := os.Getenv("PREFIX")
+	if prefix == "" {
+		return TERMUX_DEFAULT_PREFIX
+	}
+	return prefix
+}
+
+// isTermux reports whether the installer runs inside Termux
+func isTermux() bool {
+	return termuxPrefix() != ""
+}
+
+// termuxSupported drops the modules Android cannot run
+func termuxSupported(modules []Module) []Module {
+	var supported []Module
+	for _, m := range modules {
+		if _, ok := TERMUX_UNSUPPORTED[m.Name]; !ok {
+			supported = append(supported, m)
+		}
+	}
+	return supported
+}
+
+// installTermuxRust installs Rust from the Termux repository; rustup has
+// no Android host toolchain
+func installTermuxRust() *exec.Cmd {
+	return exec.Command(filepath.Join(termuxPrefix(), "bin", "pkg"), "install", "-y", "rust")
+}
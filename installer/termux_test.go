@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTermuxPaths(t *testing.T) {
+	if runtime.GOOS == "android" {
+		t.Skip("always Termux on Android")
+	}
+	t.Setenv("TERMUX_VERSION", "")
+	t.Setenv("PREFIX", "/usr")
+	if isTermux() {
+		t.Fatalf("PREFIX=/usr detected as Termux")
+	}
+
+	prefix := "/data/data/com.termux/files/usr"
+	t.Setenv("PREFIX", prefix)
+	if got := termuxPrefix(); got != prefix {
+		t.Errorf("termuxPrefix() = %q, want %q", got, prefix)
+	}
+	if got := getInstallPathForOS("linux"); got != filepath.Join(prefix, "bin") {
+		t.Errorf("Install path = %s, want %s/bin", got, prefix)
+	}
+
+	for _, m := range allModules() {
+		if _, unsupported := TERMUX_UNSUPPORTED[m.Name]; unsupported {
+			t.Errorf("%s offered on Termux", m.Name)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Default per-request timeouts
+const (
+	DEFAULT_API_TIMEOUT      = 30 * time.Second // release metadata and registry API calls
+	DEFAULT_DOWNLOAD_TIMEOUT = 10 * time.Minute // each binary, grammar or module download
+	DEFAULT_STALL_TIMEOUT    = 30 * time.Second // longest wait for the next byte of a response
+
+	STALL_RETRIES = 3 // further attempts at a download that stalled
+)
+
+// errStalled reports a response that stopped sending bytes
+var errStalled = errors.New("no data received")
+
+// runCtx carries the overall --timeout deadline of the run; every request
+// and long-running command derives its context from it
+var runCtx = context.Background()
+
+// startRunDeadline applies opts.Timeout to runCtx. The returned function
+// releases the deadline's resources.
+func startRunDeadline() context.CancelFunc {
+	if opts.Timeout <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	runCtx = ctx
+	return cancel
+}
+
+// requestContext derives a context bounded by both the per-request timeout
+// and the overall run deadline; a zero timeout means no per-request limit
+func requestContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(runCtx)
+	}
+	return context.WithTimeout(runCtx, timeout)
+}
+
+// cancelOnClose releases a request context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// stallWatchdog cancels a request when no bytes arrive for opts.StallTimeout,
+// so a connection a proxy left hanging fails in seconds rather than at the
+// end of the download timeout
+type stallWatchdog struct {
+	timer   *time.Timer
+	stalled atomic.Bool
+}
+
+// watchStall starts a watchdog cancelling a request, or returns nil when
+// stall detection is off
+func watchStall(cancel context.CancelFunc) *stallWatchdog {
+	if opts.StallTimeout <= 0 {
+		return nil
+	}
+	w := &stallWatchdog{}
+	w.timer = time.AfterFunc(opts.StallTimeout, func() {
+		w.stalled.Store(true)
+		cancel()
+	})
+	return w
+}
+
+// err turns the error of a cancelled request into errStalled
+func (w *stallWatchdog) err(err error) error {
+	if w != nil && w.stalled.Load() {
+		return fmt.Errorf("%w for %s (--stall-timeout): %v", errStalled, opts.StallTimeout, err)
+	}
+	return err
+}
+
+func (w *stallWatchdog) stop() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// watchedBody resets the watchdog on every read
+type watchedBody struct {
+	io.ReadCloser
+	watchdog *stallWatchdog
+}
+
+func (b watchedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.watchdog.stalled.Load() {
+		b.watchdog.timer.Reset(opts.StallTimeout)
+	}
+	if err == io.EOF {
+		b.watchdog.stop()
+	}
+	if err != nil && err != io.EOF {
+		err = b.watchdog.err(err)
+	}
+	return n, err
+}
+
+func (b watchedBody) Close() error {
+	b.watchdog.stop()
+	return b.ReadCloser.Close()
+}
+
+// httpDo sends req under a deadline of timeout. The deadline stays in force
+// while the body is read and is released when the body is closed; a
+// response that stops sending bytes is cancelled early with errStalled.
+// Every request is tagged, and hosts with a stored credential are
+// authenticated.
+func httpDo(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	tagRequest(req)
+	authorize(req)
+	ctx, cancel := requestContext(timeout)
+	watchdog := watchStall(cancel)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		watchdog.stop()
+		cancel()
+		return nil, watchdog.err(timeoutError(err, timeout))
+	}
+	logFetch(req, resp)
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	if watchdog != nil {
+		resp.Body = watchedBody{ReadCloser: resp.Body, watchdog: watchdog}
+	}
+	return resp, nil
+}
+
+// retryStalled runs a download again when it stalled, up to STALL_RETRIES
+// more times; other failures are returned at once
+func retryStalled(what string, download func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := download()
+		if err == nil || !errors.Is(err, errStalled) || attempt > STALL_RETRIES {
+			return err
+		}
+		warnf("Download of %s stalled, retrying (%d/%d)", what, attempt, STALL_RETRIES)
+	}
+}
+
+// httpGet issues a GET request under a deadline of timeout
+func httpGet(url string, timeout time.Duration) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpDo(req, timeout)
+}
+
+// timeoutError explains which deadline expired
+func timeoutError(err error, timeout time.Duration) error {
+	switch {
+	case runCtx.Err() != nil:
+		return fmt.Errorf("installer timed out after %s (--timeout): %w", opts.Timeout, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("request timed out after %s: %w", timeout, err)
+	}
+	return err
+}
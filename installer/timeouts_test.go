@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPGetTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	resp, err := httpGet(server.URL+"/fast", time.Second)
+	if err != nil {
+		t.Fatalf("httpGet failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+
+	_, err = httpGet(server.URL+"/slow", 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "request timed out after 50ms") {
+		t.Errorf("Expected per-request timeout, got %v", err)
+	}
+
+	// The overall deadline applies even without a per-request limit
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	runCtx = ctx
+	opts.Timeout = 50 * time.Millisecond
+	defer func() { runCtx = context.Background(); opts.Timeout = 0 }()
+
+	_, err = httpGet(server.URL+"/slow", 0)
+	if err == nil || !strings.Contains(err.Error(), "--timeout") {
+		t.Errorf("Expected overall timeout, got %v", err)
+	}
+}
+
+func TestDurationSetting(t *testing.T) {
+	setting, _ := findSetting("network.download_timeout")
+	if got, err := setting.validate("90s"); err != nil || got != "1m30s" {
+		t.Errorf("validate(90s) = %v, %v", got, err)
+	}
+	if _, err := setting.validate("soon"); err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+}
+
+func TestDownloadStallRetry(t *testing.T) {
+	opts.StallTimeout = 100 * time.Millisecond
+	defer func() { opts.StallTimeout = DEFAULT_STALL_TIMEOUT }()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Length", "8")
+		w.Write([]byte("half"))
+		if attempts == 1 {
+			// The first attempt hangs after half the body, like a stuck proxy
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("full"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "asset")
+	start := time.Now()
+	if err := downloadFile(server.URL+"/asset", dest, time.Minute); err != nil {
+		t.Fatalf("downloadFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "halffull" || attempts != 2 {
+		t.Errorf("got %q after %d attempts, want halffull after 2", data, attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("stalled download took %s to be retried", elapsed)
+	}
+
+	// Data arriving steadily is never cut off, however long it takes
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer slow.Close()
+	if err := downloadFile(slow.URL, dest, time.Minute); err != nil {
+		t.Errorf("slow but steady download failed: %v", err)
+	}
+}
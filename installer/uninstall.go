@@ -0,0 +1,369 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Additional kinds of environment changes recorded in the receipt
+const (
+	CHANGE_SERVICE = "service" // Path is the unit/plist/task definition, Value its name
+	CHANGE_SHIM    = "shim"    // Path is the shim file
+)
+
+// uninstallStep is one reversible action planned by the uninstaller
+type uninstallStep struct {
+	Description string
+	Run         func() error
+}
+
+// uninstallFlags registers the flags of the uninstall command
+func uninstallFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.Purge, "purge", opts.Purge, "also remove cargo-installed dependencies, backups and ~/.vibe/data")
+}
+
+// runUninstall implements `install-dotvibe uninstall [--yes] [--purge]`
+func runUninstall(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+	if receipt.InstallPath == "" {
+		fmt.Printf("⚠️  No install receipt at %s, falling back to default locations\n", getReceiptPath())
+		receipt.InstallPath = getInstallPath()
+	}
+
+	steps := planUninstall(receipt)
+	if opts.Purge {
+		steps = append(steps, planPurge(receipt)...)
+	}
+	if len(steps) == 0 {
+		fmt.Printf("✅ Nothing to uninstall\n")
+		return nil
+	}
+
+	fmt.Printf("🗑️  The following will be removed:\n")
+	for _, step := range steps {
+		fmt.Printf("   • %s\n", step.Description)
+	}
+	if !opts.Yes && !confirm("Proceed with uninstall?") {
+		return fmt.Errorf("uninstall cancelled (use --yes to skip this prompt)")
+	}
+
+	var removed, failed []string
+	for _, step := range steps {
+		if err := step.Run(); err != nil {
+			fmt.Printf("❌ %s: %v\n", step.Description, err)
+			failed = append(failed, step.Description)
+			continue
+		}
+		fmt.Printf("✅ %s\n", step.Description)
+		removed = append(removed, step.Description)
+	}
+
+	if opts.Purge {
+		fmt.Printf("\n📋 Purge report:\n")
+		for _, r := range removed {
+			fmt.Printf("   removed  %s\n", r)
+		}
+		for _, f := range failed {
+			fmt.Printf("   FAILED   %s\n", f)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d step(s) failed; the receipt was kept so uninstall can be re-run", len(failed))
+	}
+
+	if err := os.Remove(getReceiptPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove install receipt: %w", err)
+	}
+	fmt.Printf("✅ Uninstall complete!\n")
+	return nil
+}
+
+// planUninstall derives the removal steps from the receipt. Environment
+// changes are reverted in reverse order of application, before files go.
+func planUninstall(receipt *Receipt) []uninstallStep {
+	var steps []uninstallStep
+
+	for i := len(receipt.Changes) - 1; i >= 0; i-- {
+		change := receipt.Changes[i]
+		switch change.Kind {
+		case CHANGE_SERVICE:
+			steps = append(steps, uninstallStep{
+				Description: fmt.Sprintf("service %s (%s)", change.Value, change.Path),
+				Run:         func() error { return removeService(change) },
+			})
+		case CHANGE_RC_BLOCK:
+			steps = append(steps, uninstallStep{
+				Description: fmt.Sprintf("PATH block in %s", change.Path),
+				Run:         func() error { return removeProfileBlock(change.Path) },
+			})
+		case CHANGE_REGISTRY_PATH:
+			steps = append(steps, uninstallStep{
+				Description: fmt.Sprintf("%s from the user PATH", change.Value),
+				Run:         func() error { return removeFromWindowsPath(change.Value) },
+			})
+		case CHANGE_SHIM:
+			steps = append(steps, uninstallStep{
+				Description: fmt.Sprintf("shim %s", change.Path),
+				Run:         func() error { return removeIfExists(change.Path) },
+			})
+		case CHANGE_FILE:
+			steps = append(steps, uninstallStep{
+				Description: change.Path,
+				Run:         func() error { return removeIfExists(change.Path) },
+			})
+		}
+	}
+
+	_, _, filename := detectPlatform()
+	binary, dataDir := filepath.Join(receipt.InstallPath, filename), getDataDir(receipt.InstallPath)
+	for _, path := range []string{binary, dataDir} {
+		if _, err := os.Stat(path); err == nil {
+			path := path
+			steps = append(steps, uninstallStep{
+				Description: path,
+				Run:         func() error { return os.RemoveAll(path) },
+			})
+		}
+	}
+
+	// Recorded files elsewhere, such as a binary left in an earlier install
+	// directory. Files of cargo packages are left to cargo uninstall
+	// (--purge), and receipts from before files were recorded still list
+	// their downloads' provenance.
+	covered := map[string]bool{binary: true}
+	for _, change := range receipt.Changes {
+		if change.Kind == CHANGE_FILE {
+			covered[change.Path] = true
+		}
+	}
+	packages := map[string]bool{}
+	for _, pkg := range receipt.Packages {
+		packages[pkg.Name] = true
+	}
+	var recorded []string
+	for _, f := range receipt.Files {
+		if !packages[f.Component] {
+			recorded = append(recorded, f.Path)
+		}
+	}
+	for _, p := range receipt.Provenance {
+		recorded = append(recorded, p.Path)
+	}
+	for _, path := range recorded {
+		if covered[path] || strings.HasPrefix(path, dataDir+string(filepath.Separator)) {
+			continue
+		}
+		covered[path] = true
+		if _, err := os.Stat(path); err == nil {
+			path := path
+			steps = append(steps, uninstallStep{
+				Description: path,
+				Run:         func() error { return removeIfExists(path) },
+			})
+		}
+	}
+
+	return steps
+}
+
+// planPurge adds the steps of `uninstall --purge`: uninstalling the cargo
+// packages the receipt records, data backups, and the user data in
+// ~/.vibe/data, which is only removed after a separate confirmation
+func planPurge(receipt *Receipt) []uninstallStep {
+	var steps []uninstallStep
+
+	for _, pkg := range receipt.Packages {
+		if pkg.Manager != "cargo" {
+			continue
+		}
+		pkg := pkg
+		steps = append(steps, uninstallStep{
+			Description: fmt.Sprintf("cargo package %s v%s", pkg.Name, pkg.Version),
+			Run:         func() error { return uninstallCargoPackage(pkg.Name) },
+		})
+	}
+	if receipt.Version == "" {
+		// Without a receipt nothing tells whether vibe or the user installed
+		// the pinned packages, so each one is asked about, even with --yes
+		for _, name := range []string{"code2prompt", "surrealdb"} {
+			name := name
+			steps = append(steps, uninstallStep{
+				Description: fmt.Sprintf("cargo package %s (possibly installed by vibe)", name),
+				Run: func() error {
+					if !confirm(fmt.Sprintf("No install receipt records %s; uninstall it with cargo anyway?", name)) {
+						return fmt.Errorf("kept at user request")
+					}
+					return uninstallCargoPackage(name)
+				},
+			})
+		}
+	}
+
+	backups := getBackupDir(receipt.InstallPath)
+	if _, err := os.Stat(backups); err == nil {
+		steps = append(steps, uninstallStep{
+			Description: backups,
+			Run:         func() error { return os.RemoveAll(backups) },
+		})
+	}
+
+	cache := getCacheDir()
+	if _, err := os.Stat(cache); err == nil {
+		steps = append(steps, uninstallStep{
+			Description: cache + " (download cache)",
+			Run:         func() error { return os.RemoveAll(cache) },
+		})
+	}
+
+	if credentials := loadCredentialIndex(); len(credentials.Accounts) > 0 {
+		steps = append(steps, uninstallStep{
+			Description: fmt.Sprintf("%d stored credential(s) in the OS keychain", len(credentials.Accounts)),
+			Run: func() error {
+				for _, account := range credentials.Accounts {
+					if err := keychainDelete(account); err != nil {
+						warnf("%v", err)
+					}
+				}
+				return os.Remove(getCredentialIndexPath())
+			},
+		})
+	}
+
+	userData := getUserDataDir(receipt)
+	if _, err := os.Stat(userData); err == nil {
+		steps = append(steps, uninstallStep{
+			Description: userData + " (indexes and databases)",
+			Run: func() error {
+				if !opts.Yes && !confirm(fmt.Sprintf("Permanently delete %s?", userData)) {
+					return fmt.Errorf("kept at user request")
+				}
+				if receipt.DataEncryption != "" {
+					if err := removeDataEncryption(receipt); err != nil {
+						return err
+					}
+				}
+				os.Remove(filepath.Join(getVibeHome(), USER_DATA_DIR)) // link left by data move
+				return os.RemoveAll(userData)
+			},
+		})
+	}
+
+	return steps
+}
+
+// uninstallCargoPackage removes a cargo-installed package, treating a
+// package that is not installed as already removed
+func uninstallCargoPackage(name string) error {
+	out, err := exec.Command("cargo", "uninstall", name).CombinedOutput()
+	if err != nil && !strings.Contains(string(out), "did not match any packages") {
+		return fmt.Errorf("cargo uninstall %s: %s", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// removeIfExists deletes a file, ignoring files that are already gone
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeProfileBlock deletes the installer's marker-delimited block from an
+// rc file, leaving the rest of the file untouched
+func removeProfileBlock(profile string) error {
+	content, err := os.ReadFile(profile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	start, end, found := findProfileBlock(string(content))
+	if !found {
+		return nil
+	}
+
+	info, err := os.Stat(profile)
+	if err != nil {
+		return err
+	}
+	updated := string(content[:start]) + string(content[end:])
+	return os.WriteFile(profile, []byte(updated), info.Mode().Perm())
+}
+
+// removePathEntry drops dir from a PATH-style list
+func removePathEntry(pathList, dir string, separator string) string {
+	var kept []string
+	for _, entry := range strings.Split(pathList, separator) {
+		if entry != "" && filepath.Clean(entry) != filepath.Clean(dir) {
+			kept = append(kept, entry)
+		}
+	}
+	return strings.Join(kept, separator)
+}
+
+// removeFromWindowsPath deletes dir from the user PATH in the registry
+func removeFromWindowsPath(dir string) error {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+
+	out, err := exec.Command("powershell", "-NoProfile", "-Command",
+		"[Environment]::GetEnvironmentVariable('Path', 'User')").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read user PATH: %w", err)
+	}
+
+	cmd := exec.Command("powershell", "-NoProfile", "-Command",
+		"[Environment]::SetEnvironmentVariable('Path', $env:VIBE_NEW_PATH, 'User')")
+	cmd.Env = append(os.Environ(), "VIBE_NEW_PATH="+removePathEntry(strings.TrimSpace(string(out)), dir, ";"))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update user PATH: %w", err)
+	}
+	return nil
+}
+
+// removeService stops and unregisters a service created by the installer
+func removeService(change EnvChange) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("systemctl", "--user", "disable", "--now", change.Value)
+	case "darwin":
+		cmd = exec.Command("launchctl", "unload", "-w", change.Path)
+	case "windows":
+		cmd = exec.Command("schtasks", "/Delete", "/TN", change.Value, "/F")
+	}
+
+	// A service that is already stopped or unregistered is fine
+	if cmd != nil {
+		if out, err := cmd.CombinedOutput(); err != nil {
+			fmt.Printf("⚠️  %s: %s\n", strings.Join(cmd.Args, " "), strings.TrimSpace(string(out)))
+		}
+	}
+
+	if change.Path != "" {
+		if err := removeIfExists(change.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.GOOS == "linux" {
+		exec.Command("systemctl", "--user", "daemon-reload").Run()
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunUninstall(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	saved := opts
+	defer func() { opts = saved }()
+
+	installPath := t.TempDir()
+	_, _, filename := detectPlatform()
+	binary := filepath.Join(installPath, filename)
+	os.WriteFile(binary, []byte("vibe"), 0755)
+	os.MkdirAll(getDataDir(installPath), 0755)
+	os.WriteFile(filepath.Join(getDataDir(installPath), "tree-sitter-typescript.wasm"), []byte("\x00asm"), 0644)
+
+	profile := filepath.Join(t.TempDir(), ".bashrc")
+	os.WriteFile(profile, []byte("# mine\n"+profileBlock(profile, installPath)+"# after\n"), 0600)
+	shim := filepath.Join(t.TempDir(), "vibe-shim")
+	os.WriteFile(shim, []byte("#!/bin/sh\n"), 0755)
+
+	moved := filepath.Join(t.TempDir(), filename) // installed into another directory before
+	os.WriteFile(moved, []byte("vibe"), 0755)
+
+	tool := filepath.Join(t.TempDir(), "tool") // written by a module
+	os.WriteFile(tool, []byte("tool"), 0755)
+	crate := filepath.Join(t.TempDir(), "surreal") // left to cargo uninstall
+	os.WriteFile(crate, []byte("surreal"), 0755)
+
+	receipt := &Receipt{Version: "v1.0.0", InstallPath: installPath}
+	receipt.recordProvenance(Provenance{Component: "vibe", Path: moved})
+	receipt.recordFile("tool", "v1.0.0", tool)
+	receipt.recordPackage(InstalledPackage{Name: "surrealdb", Version: "2.0.0", Manager: "cargo"})
+	receipt.recordFile("surrealdb", "2.0.0", crate)
+	receipt.recordChange(EnvChange{Kind: CHANGE_RC_BLOCK, Path: profile, Value: installPath})
+	receipt.recordChange(EnvChange{Kind: CHANGE_SHIM, Path: shim})
+	if err := receipt.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := execute([]string{"uninstall", "--yes"}); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+
+	for _, path := range []string{binary, getDataDir(installPath), shim, moved, tool, getReceiptPath()} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after uninstall", path)
+		}
+	}
+	if _, err := os.Stat(crate); err != nil {
+		t.Errorf("uninstall without --purge removed a cargo package's file: %v", err)
+	}
+
+	data, _ := os.ReadFile(profile)
+	if string(data) != "# mine\n# after\n" {
+		t.Errorf("profile after uninstall = %q, want only user content", data)
+	}
+	if info, _ := os.Stat(profile); info.Mode().Perm() != 0600 {
+		t.Errorf("profile mode = %v, want 0600 preserved", info.Mode().Perm())
+	}
+}
+
+func TestRemovePathEntry(t *testing.T) {
+	got := removePathEntry(`C:\Users\u\.local\bin;C:\Windows;;C:\Tools`, `C:\Users\u\.local\bin`, ";")
+	if filepath.Separator == '\\' {
+		if got != `C:\Windows;C:\Tools` {
+			t.Errorf("removePathEntry() = %v", got)
+		}
+		return
+	}
+	got = removePathEntry("/home/u/.local/bin:/usr/bin:/bin", "/home/u/.local/bin/", ":")
+	if got != "/usr/bin:/bin" {
+		t.Errorf("removePathEntry() = %v, want /usr/bin:/bin", got)
+	}
+}
+
+func TestPlanPurge(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	os.MkdirAll(filepath.Join(getVibeHome(), "data"), 0755)
+
+	installPath := t.TempDir()
+	os.MkdirAll(filepath.Join(installPath, "data-backups"), 0755)
+
+	receipt := &Receipt{Version: "v1.0.0", InstallPath: installPath}
+	receipt.recordPackage(InstalledPackage{Name: "surrealdb", Version: "2.3.5", Manager: "cargo"})
+	receipt.recordPackage(InstalledPackage{Name: "surrealdb", Version: "2.3.6", Manager: "cargo"})
+
+	steps := planPurge(receipt)
+	var descriptions []string
+	for _, step := range steps {
+		descriptions = append(descriptions, step.Description)
+	}
+
+	expected := []string{
+		"cargo package surrealdb v2.3.6",
+		filepath.Join(installPath, "data-backups"),
+		filepath.Join(getVibeHome(), "data") + " (indexes and databases)",
+	}
+	if strings.Join(descriptions, "|") != strings.Join(expected, "|") {
+		t.Errorf("planPurge() = %q, want %q", descriptions, expected)
+	}
+
+	// Without --yes and without a terminal the user data is kept
+	saved := opts
+	defer func() { opts = saved }()
+	opts.Yes = false
+	if err := steps[2].Run(); err == nil {
+		t.Error("Expected user data deletion to require confirmation")
+	}
+
+	// Only recorded packages are purged
+	for _, step := range planPurge(&Receipt{Version: "v1.0.0", InstallPath: installPath}) {
+		if strings.HasPrefix(step.Description, "cargo package") {
+			t.Errorf("purged %q, which the receipt does not record", step.Description)
+		}
+	}
+
+	// Without a receipt the pinned packages are only offered, each needing
+	// its own confirmation even with --yes
+	opts.Yes = true
+	var offered []uninstallStep
+	for _, step := range planPurge(&Receipt{InstallPath: installPath}) {
+		if strings.HasPrefix(step.Description, "cargo package") {
+			offered = append(offered, step)
+		}
+	}
+	if len(offered) != 2 || !strings.Contains(offered[0].Description, "possibly installed by vibe") {
+		t.Fatalf("packages without a receipt = %+v", offered)
+	}
+	for _, step := range offered {
+		if err := step.Run(); err == nil || !strings.Contains(err.Error(), "kept") {
+			t.Errorf("%s ran without confirmation: %v", step.Description, err)
+		}
+	}
+}
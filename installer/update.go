@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// runUpdate implements `install-dotvibe update [flags]`: an upgrade in
+// place that only replaces what changed. Modules that verify at their
+// pinned version are left alone, so Rust is not checked and nothing is
+// recompiled unless a cargo module needs it, and vibe is only downloaded
+// when the installed binary is another version or was modified.
+func runUpdate(args []string) error {
+	opts.InPlace = true
+	return runUpgrade(args)
+}
+
+// keptBinary returns the provenance of the installed vibe binary when an
+// in-place update can keep it: it reports the target version and is still
+// the file that was downloaded
+func keptBinary(receipt *Receipt, binaryPath, current, version string) (Provenance, bool) {
+	if current != version {
+		return Provenance{}, false
+	}
+	for _, p := range receipt.Provenance {
+		if p.Component != "vibe" || p.Path != binaryPath {
+			continue
+		}
+		digest, _, err := fileSHA256(binaryPath)
+		return p, err == nil && digest == p.SHA256
+	}
+	return Provenance{}, false
+}
+
+// modulesToUpdate returns the modules an in-place update has to install:
+// those that failed before, fail verification or report another version
+// than pinned, and those with nothing to run whose recorded files are
+// missing, changed or older than pinned
+func modulesToUpdate(modules []Module, receipt *Receipt) []Module {
+	failed := map[string]bool{}
+	for _, name := range receipt.FailedModules {
+		failed[name] = true
+	}
+	checks := map[string]ModuleCheck{}
+	for _, c := range checkModules(modules) {
+		checks[c.Name] = c
+	}
+
+	var outdated []Module
+	for _, m := range modules {
+		reason := ""
+		if c, ok := checks[m.Name]; ok {
+			if c.Status != VERIFY_OK {
+				reason = c.Status
+			}
+		} else {
+			reason = staleFiles(m, receipt)
+		}
+		if failed[m.Name] {
+			reason = "failed earlier"
+		}
+		if reason == "" {
+			fmt.Printf("✅ %s is up to date\n", m.Name)
+			continue
+		}
+		fmt.Printf("🔄 %s needs updating: %s\n", m.Name, strings.ReplaceAll(reason, "_", " "))
+		outdated = append(outdated, m)
+	}
+	return outdated
+}
+
+// staleFiles describes why the recorded files of a module need replacing,
+// or returns "" when they are intact and not older than pinned
+func staleFiles(m Module, receipt *Receipt) string {
+	found := false
+	for _, f := range receipt.Files {
+		if f.Component != m.Name {
+			continue
+		}
+		found = true
+		if digest, _, err := fileSHA256(f.Path); err != nil || (f.SHA256 != "" && digest != f.SHA256) {
+			return f.Path + " is missing or changed"
+		}
+		recorded, okRecorded := parseVersion(f.Version)
+		pinned, okPinned := parseVersion(m.Version)
+		if okRecorded && okPinned && compareVersions(recorded, pinned) < 0 {
+			return fmt.Sprintf("%s is older than %s", f.Version, m.Version)
+		}
+	}
+	if !found {
+		return "no installed files recorded"
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModulesToUpdate(t *testing.T) {
+	dir := t.TempDir()
+	grammar := filepath.Join(dir, "grammar.wasm")
+	os.WriteFile(grammar, wasmMagic, 0644)
+
+	receipt := &Receipt{FailedModules: []string{"flaky"}}
+	receipt.recordFile("grammar", "0.23.2", grammar)
+	ok := func() error { return nil }
+	modules := []Module{
+		{Name: "current", Verify: ok},
+		{Name: "broken", Verify: func() error { return fmt.Errorf("missing") }},
+		{Name: "flaky", Verify: ok},
+		{Name: "grammar", Version: "0.23.2"},
+		{Name: "newer-grammar", Version: "0.24.0"},
+	}
+	older := filepath.Join(dir, "older.wasm")
+	os.WriteFile(older, wasmMagic, 0644)
+	receipt.recordFile("newer-grammar", "0.23.2", older)
+
+	names := func() []string {
+		var names []string
+		for _, m := range modulesToUpdate(modules, receipt) {
+			names = append(names, m.Name)
+		}
+		return names
+	}
+	if got, want := names(), []string{"broken", "flaky", "newer-grammar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("modulesToUpdate = %v, want %v", got, want)
+	}
+
+	os.WriteFile(grammar, []byte("changed"), 0644)
+	if got, want := names(), []string{"broken", "flaky", "grammar", "newer-grammar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("modulesToUpdate after a change = %v, want %v", got, want)
+	}
+}
+
+func TestKeptBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "vibe")
+	os.WriteFile(binary, []byte("vibe"), 0755)
+	digest, _, _ := fileSHA256(binary)
+	receipt := &Receipt{Provenance: []Provenance{{Component: "vibe", Artifact: "vibe.tar.gz", Path: binary, SHA256: digest}}}
+
+	if p, ok := keptBinary(receipt, binary, "v1.0.0", "v1.0.0"); !ok || p.Artifact != "vibe.tar.gz" {
+		t.Errorf("keptBinary = %+v, %v, want the installed binary kept", p, ok)
+	}
+	if _, ok := keptBinary(receipt, binary, "v1.0.0", "v1.1.0"); ok {
+		t.Error("kept a binary of another version")
+	}
+	os.WriteFile(binary, []byte("modified"), 0755)
+	if _, ok := keptBinary(receipt, binary, "v1.0.0", "v1.0.0"); ok {
+		t.Error("kept a modified binary")
+	}
+}
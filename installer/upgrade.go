@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+// versionPattern matches the first version tag in `vibe --version` output
+var versionPattern = regexp.MustCompile(`v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?`)
+
+// installedVersion returns the version reported by an installed vibe binary
+func installedVersion(binaryPath string) (string, bool) {
+	out, err := exec.Command(binaryPath, "--version").Output()
+	if err != nil {
+		return "", false
+	}
+	return extractVersion(string(out))
+}
+
+// extractVersion finds a version tag in free-form output and normalizes
+// it to the "vX.Y.Z" form used by release tags
+func extractVersion(output string) (string, bool) {
+	match := versionPattern.FindString(output)
+	if match == "" {
+		return "", false
+	}
+	if match[0] != 'v' {
+		match = "v" + match
+	}
+	return match, true
+}
+
+// isMajorUpgrade reports whether moving from one version to another crosses
+// a major version. Below 1.0.0 a minor bump is treated as major, following
+// semver's rule that anything may change in 0.x releases.
+func isMajorUpgrade(from, to string) bool {
+	f, okFrom := parseVersion(from)
+	t, okTo := parseVersion(to)
+	if !okFrom || !okTo {
+		return false
+	}
+	if t.Major != f.Major {
+		return t.Major > f.Major
+	}
+	return f.Major == 0 && t.Minor > f.Minor
+}
+
+// gateBreakingUpgrade stops upgrades across a major version or to a release
+// flagged as breaking unless the user confirms or passed --allow-breaking.
+// Once allowed, the data directory is backed up before anything changes.
+func gateBreakingUpgrade(installPath, current, target string, major bool, breaking []string) error {
+	if !major && len(breaking) == 0 {
+		return nil
+	}
+
+	if !opts.AllowBreaking {
+		reason := "a major version upgrade"
+		if !major {
+			reason = "flagged as breaking"
+		}
+		fmt.Printf("🚧 %s → %s is %s\n", current, target, reason)
+		if !confirm(fmt.Sprintf("Upgrade to %s anyway?", target)) {
+			return fmt.Errorf("upgrade to %s requires confirmation; re-run with --allow-breaking to proceed", target)
+		}
+	}
+
+	_, err := backupDataDir(installPath, current)
+	return err
+}
+
+// runUpgrade implements `install-dotvibe upgrade [flags]`: an install that
+// refuses to run without an existing installation
+func runUpgrade(args []string) error {
+	migrateBeforeInstall()
+	_, _, filename := detectPlatform()
+	installPath := getInstallPath()
+	if receipt, err := loadReceipt(); err == nil && receipt.InstallPath != "" {
+		installPath = receipt.InstallPath // a fallback chosen because the default was read-only
+	}
+	if _, found := installedVersion(filepath.Join(installPath, filename)); !found {
+		return fmt.Errorf("vibe is not installed in %s; run install-dotvibe install", installPath)
+	}
+	return runInstall(args)
+}
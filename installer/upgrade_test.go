@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+		ok       bool
+	}{
+		{"vibe 0.7.27\n", "v0.7.27", true},
+		{"vibe v0.8.0-rc.1 (abc123)", "v0.8.0-rc.1", true},
+		{"command not found", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := extractVersion(tt.output)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("extractVersion(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestIsMajorUpgrade(t *testing.T) {
+	tests := []struct {
+		from, to string
+		expected bool
+	}{
+		{"v0.7.27", "v0.7.28", false},
+		{"v0.7.27", "v0.8.0", true},
+		{"v1.4.0", "v1.5.0", false},
+		{"v1.4.0", "v2.0.0", true},
+		{"v2.0.0", "v1.9.0", false},
+		{"garbage", "v2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMajorUpgrade(tt.from, tt.to); got != tt.expected {
+			t.Errorf("isMajorUpgrade(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.expected)
+		}
+	}
+}
+
+func TestGateBreakingUpgrade(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	installPath := t.TempDir()
+	wasm := filepath.Join(getDataDir(installPath), "tree-sitter-typescript.wasm")
+	if err := os.MkdirAll(filepath.Dir(wasm), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(wasm, []byte("\x00asm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("patch upgrade passes", func(t *testing.T) {
+		if err := gateBreakingUpgrade(installPath, "v0.7.1", "v0.7.2", false, nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("breaking upgrade refused without confirmation", func(t *testing.T) {
+		err := gateBreakingUpgrade(installPath, "v0.7.1", "v0.8.0", true, nil)
+		if err == nil || !strings.Contains(err.Error(), "--allow-breaking") {
+			t.Errorf("Expected --allow-breaking error, got: %v", err)
+		}
+	})
+
+	t.Run("allowed breaking upgrade backs up data", func(t *testing.T) {
+		opts.AllowBreaking = true
+		defer func() { opts.AllowBreaking = false }()
+
+		if err := gateBreakingUpgrade(installPath, "v0.7.1", "v0.7.2", false, []string{"BREAKING: x"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		backups, _ := filepath.Glob(filepath.Join(installPath, "data-backups", "v0.7.1-*", "tree-sitter-typescript.wasm"))
+		if len(backups) != 1 {
+			t.Errorf("Expected one data backup, found %v", backups)
+		}
+	})
+}
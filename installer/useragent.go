@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// USER_AGENT_PRODUCT names the installer in the User-Agent of its requests
+const USER_AGENT_PRODUCT = "dotvibe-installer"
+
+// ORG_TAG_HEADER carries --org-tag, so mirrors and proxies can attribute
+// installer traffic to an organisation without parsing the User-Agent
+const ORG_TAG_HEADER = "X-Dotvibe-Org"
+
+// userAgent identifies the installer build and platform to servers, with
+// the suffix an enterprise configured appended
+func userAgent() string {
+	ua := fmt.Sprintf("%s/%s (%s/%s)", USER_AGENT_PRODUCT, version, runtime.GOOS, runtime.GOARCH)
+	if opts.UserAgentSuffix != "" {
+		ua += " " + opts.UserAgentSuffix
+	}
+	return ua
+}
+
+// tagRequest sets the User-Agent and org tag every installer request
+// carries, to whichever host it goes
+func tagRequest(req *http.Request) {
+	req.Header.Set("User-Agent", userAgent())
+	if opts.OrgTag != "" {
+		req.Header.Set(ORG_TAG_HEADER, opts.OrgTag)
+	}
+}
+
+// validateHeaderValue rejects text that cannot be sent in a header, such as
+// line breaks that would start another one
+func validateHeaderValue(v string) error {
+	if strings.TrimSpace(v) != v {
+		return fmt.Errorf("%q has leading or trailing spaces", v)
+	}
+	for _, r := range v {
+		if r < 0x20 || r == 0x7f || r > 0x7e {
+			return fmt.Errorf("%q contains %q; use printable ASCII", v, r)
+		}
+	}
+	return nil
+}
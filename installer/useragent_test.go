@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRequestsAreTagged(t *testing.T) {
+	savedOpts, savedVersion := opts, version
+	defer func() { opts, version = savedOpts, savedVersion }()
+	version = "v1.2.0"
+	opts.UserAgentSuffix = "acme-build/42"
+	opts.OrgTag = "acme"
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	resp, err := httpGet(server.URL, time.Second)
+	if err != nil {
+		t.Fatalf("httpGet failed: %v", err)
+	}
+	resp.Body.Close()
+
+	want := "dotvibe-installer/v1.2.0 (" + runtime.GOOS + "/" + runtime.GOARCH + ") acme-build/42"
+	if ua := got.Get("User-Agent"); ua != want {
+		t.Errorf("User-Agent = %q, want %q", ua, want)
+	}
+	if tag := got.Get(ORG_TAG_HEADER); tag != "acme" {
+		t.Errorf("%s = %q, want acme", ORG_TAG_HEADER, tag)
+	}
+}
+
+func TestHeaderSetting(t *testing.T) {
+	setting, _ := findSetting("network.user_agent_suffix")
+	if _, err := setting.validate("acme-build/42 (ci)"); err != nil {
+		t.Errorf("a printable suffix was rejected: %v", err)
+	}
+	for _, bad := range []string{"acme\r\nX-Injected: 1", " acme", "açme"} {
+		if _, err := setting.validate(bad); err == nil {
+			t.Errorf("validate(%q) accepted a value that cannot be sent in a header", bad)
+		}
+	}
+}
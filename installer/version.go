@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed vMAJOR.MINOR.PATCH[-PRERELEASE] version tag
+type semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// parseVersion parses a release tag such as "v0.7.27" or "0.8.0-rc.1"
+func parseVersion(tag string) (semver, bool) {
+	s := strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	if s == "" {
+		return semver{}, false
+	}
+
+	var v semver
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+	}
+	// Build metadata never affects precedence
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 depending on whether a is lower than,
+// equal to or greater than b. Prereleases sort before their stable release.
+func compareVersions(a, b semver) int {
+	for _, d := range [][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if d[0] < d[1] {
+			return -1
+		}
+		if d[0] > d[1] {
+			return 1
+		}
+	}
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	case a.Prerelease < b.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// pickLatestVersion returns the highest stable version tag among candidates
+func pickLatestVersion(candidates []string) (string, bool) {
+	return pickVersion(candidates, false)
+}
+
+// pickVersion returns the highest version tag among candidates, skipping
+// prereleases (rc, beta, ...) unless they are included
+func pickVersion(candidates []string, prereleases bool) (string, bool) {
+	var best string
+	var bestVersion semver
+	for _, c := range candidates {
+		v, ok := parseVersion(c)
+		if !ok || (v.Prerelease != "" && !prereleases) {
+			continue
+		}
+		if best == "" || compareVersions(v, bestVersion) > 0 {
+			best, bestVersion = c, v
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DEFAULT_POLL_INTERVAL is how often --wait-for checks the release
+const DEFAULT_POLL_INTERVAL = time.Minute
+
+// releaseMissing returns the platform binaries tag does not publish yet,
+// all of them while the release itself does not exist. Sources that
+// cannot list assets are read through the release manifest.
+func releaseMissing(source Source, tag string) ([]string, error) {
+	expected := expectedAssets(tag)
+	var listed []ReleaseAsset
+	if lister, ok := source.(AssetLister); ok {
+		var err error
+		if listed, err = lister.ReleaseAssets(tag); err != nil {
+			return nil, err
+		}
+	} else {
+		manifest, err := fetchReleaseManifest(source, tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s cannot list assets and has no manifest for %s: %w", source.Name(), tag, err)
+		}
+		for _, a := range manifest.Assets {
+			listed = append(listed, ReleaseAsset{Name: path.Base(a.Path), Size: a.Size})
+		}
+	}
+
+	var missing []string
+	present := map[string]bool{}
+	for _, a := range listed {
+		present[a.Name] = true
+	}
+	for asset := range expected {
+		if !present[asset] {
+			missing = append(missing, asset)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// waitForRelease polls source every interval until tag is published with
+// every platform binary, for pipelines installing right after tagging.
+// Errors, such as the release not existing yet, are shown and retried;
+// the run's --timeout bounds the wait.
+func waitForRelease(source Source, tag string, interval time.Duration) error {
+	start := time.Now()
+	fmt.Printf("⏳ Waiting for %s on %s, checking every %s...\n", tag, source.Name(), interval)
+	status := "not checked"
+	for {
+		previous := status
+		missing, err := releaseMissing(source, tag)
+		switch {
+		case err != nil:
+			status = err.Error()
+		case len(missing) == 0:
+			fmt.Printf("📦 %s is published (waited %s)\n", tag, formatDuration(time.Since(start)))
+			return nil
+		default:
+			status = fmt.Sprintf("%d of %d platform binaries missing: %s", len(missing), len(expectedAssets(tag)), strings.Join(missing, ", "))
+		}
+		// Only changes are shown, so long waits stay readable in CI logs
+		if status != previous {
+			fmt.Printf("   %s\n", status)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-runCtx.Done():
+			return fmt.Errorf("gave up waiting for %s after %s: %s", tag, formatDuration(time.Since(start)), status)
+		}
+	}
+}
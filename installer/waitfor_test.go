@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// publishingSource uploads one more platform binary on each listing, like
+// a release pipeline still running
+type publishingSource struct {
+	fakeSource
+	polls int
+}
+
+func (p *publishingSource) ReleaseAssets(version string) ([]ReleaseAsset, error) {
+	p.polls++
+	if p.polls == 1 {
+		return nil, fmt.Errorf("release %s not found", version)
+	}
+	var names []string
+	for asset := range expectedAssets(version) {
+		names = append(names, asset)
+	}
+	sort.Strings(names)
+	var assets []ReleaseAsset
+	for _, name := range names[:min(p.polls-1, len(names))] {
+		assets = append(assets, ReleaseAsset{Name: name, Size: 1})
+	}
+	return assets, nil
+}
+
+func TestWaitForRelease(t *testing.T) {
+	source := &publishingSource{}
+	if err := waitForRelease(source, "v0.8.0", time.Millisecond); err != nil {
+		t.Fatalf("waitForRelease: %v", err)
+	}
+	if want := len(expectedAssets("v0.8.0")) + 1; source.polls != want {
+		t.Errorf("polled %d times, want %d", source.polls, want)
+	}
+}
+
+func TestWaitForReleaseTimeout(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	runCtx = ctx
+	defer func() { runCtx = context.Background() }()
+
+	err := waitForRelease(manifestSource{}, "v0.8.0", time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "gave up waiting for v0.8.0") {
+		t.Fatalf("waitForRelease = %v, want a timeout", err)
+	}
+}
+
+func TestReleaseMissingFromManifest(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	linux := releaseAssetName("linux", "amd64", "v0.8.0")
+	source := manifestSource{manifests: map[string]MirrorManifest{
+		"v0.8.0": {Version: "v0.8.0", Assets: []MirrorAsset{{Path: "v0.8.0/" + linux, Size: 1}}},
+	}}
+	missing, err := releaseMissing(source, "v0.8.0")
+	if err != nil {
+		t.Fatalf("releaseMissing: %v", err)
+	}
+	if len(missing) != len(expectedAssets("v0.8.0"))-1 {
+		t.Errorf("missing = %v, want every binary but %s", missing, linux)
+	}
+	for _, name := range missing {
+		if name == linux {
+			t.Errorf("%s is published but reported missing", linux)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WHY_MAX_RELEASES caps the releases why lists for vibe
+const WHY_MAX_RELEASES = 10
+
+// settingOrigin tells where the value of a setting came from: the config
+// file when it holds that value, the command line when it differs, or the
+// built-in default when empty
+func settingOrigin(cfg Config, key, value string) string {
+	switch {
+	case value == "":
+		return "default"
+	case cfg[key] == value:
+		return key + " in " + getConfigPath()
+	}
+	return "command line"
+}
+
+// whyRelease says why a release is or is not the one to install; chosen
+// is set once an earlier (newer) release was picked
+func whyRelease(r GitHubRelease, chosen bool) string {
+	v, ok := parseVersion(r.TagName)
+	switch {
+	case r.Draft:
+		return "draft, ignored"
+	case !ok:
+		return "not a version tag, ignored"
+	case (r.Prerelease || v.Prerelease != "") && !opts.IncludePrereleases:
+		return "prerelease, skipped (--include-prereleases to consider it)"
+	}
+	if err := policy.checkVersion(r.TagName); err != nil {
+		return "blocked by policy: " + err.Error()
+	}
+	if chosen {
+		return "older"
+	}
+	return "candidate"
+}
+
+// whyVibe explains which vibe release an install would pick
+func whyVibe(source Source, receipt *Receipt, cfg Config) []string {
+	lines := []string{"vibe:"}
+	installed := receipt.Version
+	if installed == "" {
+		installed = "(none)"
+	}
+	lines = append(lines, "  Installed: "+installed)
+
+	spec := opts.sourceSpec()
+	key := "release.source"
+	if opts.Source == "" {
+		key = "release.base_url"
+	}
+	lines = append(lines, fmt.Sprintf("  Source: %s (%s)", source.Name(), settingOrigin(cfg, key, spec)))
+	for _, mirror := range opts.Mirrors {
+		lines = append(lines, "  Mirror: "+mirror)
+	}
+
+	channel := CHANNEL_STABLE
+	if opts.IncludePrereleases {
+		channel = "stable and prerelease"
+	}
+	lines = append(lines, "  Channel: "+channel)
+	lines = append(lines, "  Policy: "+describePolicy())
+
+	if opts.Version != "" {
+		lines = append(lines, fmt.Sprintf("  Candidate: %s (pinned by %s)", opts.Version, opts.FromSetup))
+		return lines
+	}
+
+	lister, ok := source.(ReleaseLister)
+	if !ok {
+		latest, err := source.LatestVersion()
+		if err != nil {
+			return append(lines, "  Candidate: unknown: "+err.Error())
+		}
+		return append(lines, fmt.Sprintf("  Candidate: %s (the source's latest; it cannot list releases)", latest))
+	}
+	releases, err := lister.ListReleases()
+	if err != nil {
+		return append(lines, "  Candidate: unknown: "+err.Error())
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, okA := parseVersion(releases[i].TagName)
+		b, okB := parseVersion(releases[j].TagName)
+		return okA && (!okB || compareVersions(a, b) > 0)
+	})
+
+	candidate := ""
+	lines = append(lines, "  Releases:")
+	for i, r := range releases {
+		reason := whyRelease(r, candidate != "")
+		if reason == "candidate" {
+			candidate = r.TagName
+		}
+		if i < WHY_MAX_RELEASES {
+			lines = append(lines, fmt.Sprintf("    %-16s %s", r.TagName, reason))
+		}
+	}
+	if len(releases) > WHY_MAX_RELEASES {
+		lines = append(lines, fmt.Sprintf("    ... %d older", len(releases)-WHY_MAX_RELEASES))
+	}
+	if candidate == "" {
+		return append(lines, "  Candidate: none, no release passes the channel and policy")
+	}
+	return append(lines, "  Candidate: "+candidate)
+}
+
+// describePolicy summarizes the policy in force
+func describePolicy() string {
+	var rules []string
+	if len(policy.Channels) > 0 {
+		rules = append(rules, "channels "+strings.Join(policy.Channels, ", "))
+	}
+	if policy.MinVersion != "" {
+		rules = append(rules, "min "+policy.MinVersion)
+	}
+	if policy.MaxVersion != "" {
+		rules = append(rules, "max "+policy.MaxVersion)
+	}
+	if len(policy.Sources) > 0 {
+		rules = append(rules, "sources "+strings.Join(policy.Sources, ", "))
+	}
+	if len(rules) == 0 {
+		return "none (" + policyPath + ")"
+	}
+	return strings.Join(rules, "; ") + " (" + policyPath + ")"
+}
+
+// whyModule explains the version of a module, or of a grammar, to install
+func whyModule(name string, receipt *Receipt) ([]string, bool) {
+	for _, g := range GRAMMARS {
+		if g.Package != name {
+			continue
+		}
+		lines := []string{name + ":", "  Installed: " + installedGrammar(receipt, name)}
+		if isSkipped(name) {
+			return append(lines, "  Candidate: none, deselected in the component menu ("+SKIP_SETTING+")"), true
+		}
+		lines = append(lines, "  Pinned: "+g.Version+" by this installer")
+		if want := receipt.grammar(g); want.Version != g.Version {
+			return append(lines, "  Candidate: "+want.Version+" (newer, recorded by grammars update or --from-setup)"), true
+		}
+		return append(lines, "  Candidate: "+g.Version+" (the pin)"), true
+	}
+
+	if reason, ok := TERMUX_UNSUPPORTED[name]; ok && isTermux() {
+		return []string{name + ":", "  Candidate: none, skipped on Termux: " + reason}, true
+	}
+	for _, m := range candidateModules() {
+		if m.Name != name {
+			continue
+		}
+		lines := []string{name + ":", "  Installed: " + installedPackage(receipt, name)}
+		if m.Optional && isSkipped(name) {
+			return append(lines, "  Candidate: none, deselected in the component menu ("+SKIP_SETTING+")"), true
+		}
+		switch {
+		case isCustomModule(name):
+			lines = append(lines, "  Defined by: [module."+name+"] in "+getConfigPath())
+		case m.Cargo && hasPrebuilt(m):
+			lines = append(lines, "  Method: compiled with cargo (--prebuilt downloads the official binary instead)")
+		case m.Cargo:
+			lines = append(lines, "  Method: compiled with cargo")
+		case opts.Prebuilt && replacedByPrebuilt(name):
+			lines = append(lines, "  Method: official prebuilt binary (--prebuilt)")
+		}
+		if m.Version == "" {
+			return append(lines, "  Candidate: whatever the module installs (no version pinned)"), true
+		}
+		return append(lines, "  Candidate: "+m.Version+" (pinned)"), true
+	}
+	return nil, false
+}
+
+// replacedByPrebuilt reports whether --prebuilt swapped the built-in
+// module name for a download
+func replacedByPrebuilt(name string) bool {
+	for _, m := range MODULES {
+		if m.Name == name && hasPrebuilt(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCustomModule reports whether name is a user-defined module
+func isCustomModule(name string) bool {
+	for _, m := range opts.Modules {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// installedGrammar returns the recorded version of a grammar
+func installedGrammar(receipt *Receipt, pkg string) string {
+	for _, g := range receipt.Grammars {
+		if g.Package == pkg {
+			return g.Version
+		}
+	}
+	return "(none)"
+}
+
+// installedPackage returns the recorded version of a module's package
+func installedPackage(receipt *Receipt, name string) string {
+	for _, p := range receipt.Packages {
+		if p.Name == name {
+			return p.Version + " (" + p.Manager + ")"
+		}
+	}
+	return "(none)"
+}
+
+// whyTargets lists what why can explain
+func whyTargets() []string {
+	targets := []string{"vibe"}
+	for _, m := range MODULES {
+		targets = append(targets, m.Name)
+	}
+	return targets
+}
+
+// runWhy implements `install-dotvibe why [vibe | <module>]`
+func runWhy(args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	target := "vibe"
+	if len(args) == 1 {
+		target = args[0]
+	}
+	receipt, err := loadReceipt()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	if target == "vibe" {
+		source, err := newSources(opts.sourceSpec(), opts.Mirrors)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		lines = whyVibe(source, receipt, cfg)
+	} else {
+		var ok bool
+		if lines, ok = whyModule(target, receipt); !ok {
+			return fmt.Errorf("unknown component %q (known: %s)", target, strings.Join(whyTargets(), ", "))
+		}
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+	return nil
+}
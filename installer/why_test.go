@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// listingSource is a fakeSource that can list its releases
+type listingSource struct {
+	fakeSource
+	releases []GitHubRelease
+}
+
+func (l listingSource) ListReleases() ([]GitHubRelease, error) { return l.releases, nil }
+
+func TestWhyVibe(t *testing.T) {
+	t.Setenv("VIBE_HOME", t.TempDir())
+	savedOpts, savedPolicy := opts, policy
+	defer func() { opts, policy = savedOpts, savedPolicy }()
+	opts = Options{}
+	policy = Policy{MaxVersion: "v1.2.0"}
+
+	source := listingSource{releases: []GitHubRelease{
+		{TagName: "v1.1.0"}, {TagName: "v1.3.0"}, {TagName: "v1.2.0"},
+		{TagName: "v1.4.0-rc.1", Prerelease: true}, {TagName: "nightly"},
+	}}
+	out := strings.Join(whyVibe(source, &Receipt{Version: "v1.1.0"}, Config{}), "\n")
+	for _, want := range []string{
+		"Installed: v1.1.0",
+		"Source: fake (default)",
+		"v1.4.0-rc.1      prerelease, skipped",
+		"v1.3.0           blocked by policy: v1.3.0 is newer than the maximum version v1.2.0",
+		"v1.2.0           candidate",
+		"v1.1.0           older",
+		"nightly          not a version tag",
+		"Candidate: v1.2.0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("why vibe output lacks %q:\n%s", want, out)
+		}
+	}
+
+	opts.Source = "https://mirror.example.com/vibe"
+	cfg := Config{"release.source": opts.Source}
+	if out := strings.Join(whyVibe(source, &Receipt{}, cfg), "\n"); !strings.Contains(out, "release.source in ") {
+		t.Errorf("why vibe does not name the config file as the source's origin:\n%s", out)
+	}
+}
+
+func TestWhyModule(t *testing.T) {
+	receipt := &Receipt{Grammars: []InstalledGrammar{{Package: GRAMMARS[0].Package, Version: "99.0.0"}}}
+	lines, ok := whyModule(GRAMMARS[0].Package, receipt)
+	if out := strings.Join(lines, "\n"); !ok || !strings.Contains(out, "Candidate: 99.0.0 (newer") {
+		t.Errorf("why grammar = %s", out)
+	}
+	if _, ok := whyModule("no-such-module", receipt); ok {
+		t.Error("an unknown module was explained")
+	}
+}
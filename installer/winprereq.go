@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Windows prerequisites of the release binaries
+const (
+	MIN_WINDOWS_BUILD   = 17763 // Windows 10 1809 / Server 2019
+	X64_EMULATION_BUILD = 22000 // Windows 11, first to run x64 binaries on ARM64
+	VC_REDIST_URL       = "https://aka.ms/vs/17/release/vc_redist.x64.exe"
+	VC_RUNTIME_DLL      = "vcruntime140.dll"
+	WINDOWS_11_URL      = "https://www.microsoft.com/software-download/windows11"
+
+	// VC_REDIST_PUBLISHER must have signed the redistributable. The
+	// download is a moving "latest" link, so it cannot be pinned by digest.
+	VC_REDIST_PUBLISHER = "Microsoft Corporation"
+)
+
+// AUTHENTICODE_SCRIPT prints the Authenticode signature status and signer
+// subject of the file named by $env:VIBE_SIGNED_FILE, one per line
+const AUTHENTICODE_SCRIPT = `$s = Get-AuthenticodeSignature -LiteralPath $env:VIBE_SIGNED_FILE; $s.Status; $s.SignerCertificate.Subject`
+
+// WindowsInfo describes the parts of a Windows system the binaries depend on
+type WindowsInfo struct {
+	Build        int    // OS build number, 0 when unknown
+	Arch         string // native GOARCH
+	HasVCRuntime bool   // the Visual C++ runtime is installed
+}
+
+// windowsBuildPattern extracts the build from `ver` output such as
+// "Microsoft Windows [Version 10.0.19045.3803]"
+var windowsBuildPattern = regexp.MustCompile(`\d+\.\d+\.(\d+)`)
+
+// parseWindowsBuild returns the build number in `ver` output
+func parseWindowsBuild(output string) (int, bool) {
+	m := windowsBuildPattern.FindStringSubmatch(output)
+	if m == nil {
+		return 0, false
+	}
+	build, err := strconv.Atoi(m[1])
+	return build, err == nil
+}
+
+// detectWindows inspects the running Windows system
+func detectWindows(goarch string) WindowsInfo {
+	info := WindowsInfo{Arch: goarch}
+	if out, err := exec.Command("cmd", "/c", "ver").Output(); err == nil {
+		info.Build, _ = parseWindowsBuild(string(out))
+	}
+
+	systemRoot := os.Getenv("SystemRoot")
+	if systemRoot == "" {
+		systemRoot = `C:\Windows`
+	}
+	_, err := os.Stat(filepath.Join(systemRoot, "System32", VC_RUNTIME_DLL))
+	info.HasVCRuntime = err == nil
+	return info
+}
+
+// windowsAssetArch returns the architecture of the Windows build to install:
+// the native one when released, otherwise x64 under emulation on ARM64
+func windowsAssetArch(info WindowsInfo) (string, error) {
+	for _, p := range SUPPORTED_PLATFORMS {
+		if p.GOOS == "windows" && p.GOARCH == info.Arch {
+			return info.Arch, nil
+		}
+	}
+	if info.Arch != "arm64" {
+		return "", fmt.Errorf("vibe is not released for windows/%s", info.Arch)
+	}
+	if info.Build != 0 && info.Build < X64_EMULATION_BUILD {
+		return "", fmt.Errorf("vibe has no native ARM64 build and x64 emulation needs Windows 11 (build %d, this is %d); upgrade via %s",
+			X64_EMULATION_BUILD, info.Build, WINDOWS_11_URL)
+	}
+	fmt.Printf("ℹ️  No native ARM64 build, installing the x64 build under emulation\n")
+	return "amd64", nil
+}
+
+// checkWindowsVersion rejects Windows releases older than the binaries support
+func checkWindowsVersion(info WindowsInfo) error {
+	if info.Build == 0 {
+		warnf("Could not determine the Windows version, skipping version check")
+		return nil
+	}
+	if info.Build < MIN_WINDOWS_BUILD {
+		return fmt.Errorf("vibe needs Windows 10 1809 (build %d) or newer, this is build %d", MIN_WINDOWS_BUILD, info.Build)
+	}
+	return nil
+}
+
+// parseAuthenticode checks AUTHENTICODE_SCRIPT output: the signature must
+// be valid and made by a certificate issued to publisher
+func parseAuthenticode(output, publisher string) error {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(output, "\r\n", "\n")), "\n")
+	if strings.TrimSpace(lines[0]) != "Valid" {
+		return fmt.Errorf("signature status is %q", strings.TrimSpace(lines[0]))
+	}
+	if len(lines) > 1 {
+		for _, field := range strings.Split(lines[1], ",") {
+			if strings.TrimSpace(field) == "O="+publisher {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("not signed by %s", publisher)
+}
+
+// verifyAuthenticode checks that path carries a valid Authenticode
+// signature by publisher
+func verifyAuthenticode(path, publisher string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", AUTHENTICODE_SCRIPT)
+	cmd.Env = append(os.Environ(), "VIBE_SIGNED_FILE="+path)
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to check the signature of %s: %w", filepath.Base(path), err)
+	}
+	if err := parseAuthenticode(string(out), publisher); err != nil {
+		return fmt.Errorf("refusing to run %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// installVCRuntime downloads the Visual C++ redistributable into a private
+// quarantine directory and silently runs it once its signature checks out
+func installVCRuntime() error {
+	if err := checkPolicyURL("Visual C++ runtime", VC_REDIST_URL); err != nil {
+		return err
+	}
+	fmt.Printf("📥 Installing the Visual C++ runtime...\n")
+	if err := ensureDir(getQuarantineDir(), MODE_PRIVATE_DIR); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	// A directory of its own keeps the .exe name Windows needs to run it
+	dir, err := os.MkdirTemp(getQuarantineDir(), "vc_redist-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	installer := filepath.Join(dir, "vc_redist.x64.exe")
+	if err := downloadFile(VC_REDIST_URL, installer, opts.DownloadTimeout); err != nil {
+		return err
+	}
+	if err := verifyAuthenticode(installer, VC_REDIST_PUBLISHER); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(installer, "/install", "/quiet", "/norestart")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// 3010 means success, reboot required
+		if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 3010 {
+			return fmt.Errorf("Visual C++ runtime installation failed: %w", err)
+		}
+	}
+	fmt.Printf("✅ Visual C++ runtime installed\n")
+	return nil
+}
+
+// preflightWindows verifies the Windows prerequisites, offering to install
+// the Visual C++ runtime (--check only reports it missing), and returns the
+// architecture to install
+func preflightWindows(goarch string) (string, error) {
+	fmt.Printf("🔍 Checking Windows prerequisites...\n")
+	info := detectWindows(goarch)
+
+	if err := checkWindowsVersion(info); err != nil {
+		return "", err
+	}
+	arch, err := windowsAssetArch(info)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.HasVCRuntime {
+		fmt.Printf("⚠️  The Visual C++ runtime (%s) is not installed\n", VC_RUNTIME_DLL)
+		if opts.Check {
+			fmt.Printf("🔎 Installing would download and run the Visual C++ redistributable from %s\n", VC_REDIST_URL)
+			return arch, nil
+		}
+		if !opts.Yes && !confirm("Download and install it from Microsoft now?") {
+			return "", fmt.Errorf("the Visual C++ runtime is required; install it from %s and rerun", VC_REDIST_URL)
+		}
+		if err := installVCRuntime(); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Printf("✅ Windows prerequisites met\n")
+	return arch, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWindowsBuild(t *testing.T) {
+	tests := []struct {
+		output string
+		want   int
+		ok     bool
+	}{
+		{"\r\nMicrosoft Windows [Version 10.0.19045.3803]\r\n", 19045, true},
+		{"Microsoft Windows [Version 10.0.22631.4169]", 22631, true},
+		{"'ver' is not recognized", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseWindowsBuild(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseWindowsBuild(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckWindowsVersion(t *testing.T) {
+	if err := checkWindowsVersion(WindowsInfo{Build: 19045}); err != nil {
+		t.Errorf("Windows 10 22H2 rejected: %v", err)
+	}
+	if err := checkWindowsVersion(WindowsInfo{Build: 14393}); err == nil {
+		t.Error("Expected Windows 10 1607 to be rejected")
+	}
+	if err := checkWindowsVersion(WindowsInfo{}); err != nil {
+		t.Errorf("Unknown build should only warn: %v", err)
+	}
+}
+
+func TestWindowsAssetArch(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    WindowsInfo
+		want    string
+		wantErr string
+	}{
+		{"native x64", WindowsInfo{Arch: "amd64", Build: 19045}, "amd64", ""},
+		{"arm64 on Windows 11 emulates x64", WindowsInfo{Arch: "arm64", Build: 22631}, "amd64", ""},
+		{"arm64 on Windows 10 has no emulation", WindowsInfo{Arch: "arm64", Build: 19045}, "", "Windows 11"},
+		{"unreleased arch", WindowsInfo{Arch: "386", Build: 19045}, "", "not released"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := windowsAssetArch(tt.info)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("windowsAssetArch() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVCRuntimeChecksPolicy(t *testing.T) {
+	savedPolicy := policy
+	defer func() { policy = savedPolicy }()
+	policy = Policy{Sources: []string{"https://mirror.corp/vibe"}}
+
+	err := installVCRuntime()
+	if err == nil || !strings.Contains(err.Error(), "blocked by the policy") || !strings.Contains(err.Error(), VC_REDIST_URL) {
+		t.Errorf("Visual C++ runtime from a forbidden URL = %v, want a policy error", err)
+	}
+}
+
+func TestParseAuthenticode(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{"valid", "Valid\r\nCN=Microsoft Corporation, O=Microsoft Corporation, L=Redmond, S=Washington, C=US\r\n", ""},
+		{"unsigned", "NotSigned\r\n\r\n", "NotSigned"},
+		{"tampered", "HashMismatch\r\nCN=Microsoft Corporation, O=Microsoft Corporation\r\n", "HashMismatch"},
+		{"other publisher", "Valid\r\nCN=Microsoft Corporation, O=Evil Corp\r\n", "not signed by"},
+		{"no output", "", "status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseAuthenticode(tt.output, VC_REDIST_PUBLISHER)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parseAuthenticode = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseAuthenticode = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// WSL_OSRELEASE holds the kernel release, which names Microsoft under WSL
+const WSL_OSRELEASE = "/proc/sys/kernel/osrelease"
+
+// parseWSLRelease returns the WSL generation of a kernel release string:
+// WSL 2 kernels look like "5.15.90.1-microsoft-standard-WSL2", WSL 1
+// reports the Windows build as in "4.4.0-19041-Microsoft"
+func parseWSLRelease(release string) (int, bool) {
+	lower := strings.ToLower(strings.TrimSpace(release))
+	switch {
+	case strings.Contains(lower, "wsl2") || strings.Contains(lower, "microsoft-standard"):
+		return 2, true
+	case strings.Contains(lower, "microsoft"):
+		return 1, true
+	}
+	return 0, false
+}
+
+// detectWSL reports whether the installer runs under the Windows Subsystem
+// for Linux, and which generation
+func detectWSL() (int, bool) {
+	data, err := os.ReadFile(WSL_OSRELEASE)
+	if err != nil {
+		return 0, false
+	}
+	return parseWSLRelease(string(data))
+}
+
+// windowsDrivePattern matches paths on Windows drives mounted by WSL
+var windowsDrivePattern = regexp.MustCompile(`^/mnt/[a-zA-Z](/|$)`)
+
+// onWindowsDrive reports whether a WSL path lives on the Windows filesystem
+func onWindowsDrive(path string) bool {
+	return windowsDrivePattern.MatchString(filepath.ToSlash(path))
+}
+
+// checkWSLPaths keeps the Linux binary off the Windows filesystem, where it
+// would be slow to start and lose its executable bit on some mounts, and
+// warns when data directories live there: every file access crosses the
+// 9P bridge, which makes indexing much slower
+func checkWSLPaths(installPath string) error {
+	if onWindowsDrive(installPath) {
+		return fmt.Errorf("install path %s is on the Windows filesystem; install the Linux binary inside WSL instead, e.g. XDG_BIN_HOME=$HOME/.local/bin", installPath)
+	}
+	for _, dir := range []string{getVibeHome(), getDataDir(installPath)} {
+		if onWindowsDrive(dir) {
+			warnf("%s is on the Windows filesystem, which is much slower from WSL; set VIBE_HOME to a directory inside WSL", dir)
+		}
+	}
+	return nil
+}
+
+// windowsUserBinDir returns the WSL path of the Windows user's
+// %USERPROFILE%\.local\bin, where the Windows installer puts vibe.exe
+func windowsUserBinDir() (string, error) {
+	out, err := exec.Command("cmd.exe", "/c", "echo %USERPROFILE%").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query the Windows user profile (is interop enabled?): %w", err)
+	}
+	profile := strings.TrimSpace(string(out))
+	if profile == "" || strings.Contains(profile, "%") {
+		return "", fmt.Errorf("the Windows user profile is not set")
+	}
+	out, err = exec.Command("wslpath", "-u", profile).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to translate %s: %w", profile, err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), ".local", "bin"), nil
+}
+
+// installWindowsBinary also installs vibe.exe for the Windows user, for
+// running vibe from Windows tools on the same projects. The copy is
+// recorded in the receipt so uninstall removes it.
+func installWindowsBinary(source Source, version string, receipt *Receipt) error {
+	dir, err := windowsUserBinDir()
+	if err != nil {
+		return err
+	}
+	if err := ensureDir(dir, MODE_DIR); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	dest := filepath.Join(dir, "vibe.exe")
+	asset := releaseAssetName("windows", "amd64", version)
+	tempPath, err := quarantinePath(asset)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempPath)
+	if err := source.FetchAsset(version, asset, tempPath); err != nil {
+		return err
+	}
+	digests, err := releaseDigests(source, version)
+	if err != nil {
+		return err
+	}
+	if err := requireDigest(version, asset, digests[asset]); err != nil {
+		return err
+	}
+	if err := verifyDigest(tempPath, asset, digests[asset]); err != nil {
+		return err
+	}
+	if err := verifySignature(source, version, asset, tempPath); err != nil {
+		return err
+	}
+	if err := installBinary(tempPath, dest); err != nil {
+		return err
+	}
+	receipt.recordChange(EnvChange{Kind: CHANGE_FILE, Path: dest})
+	receipt.recordFile("vibe", version, dest)
+	fmt.Printf("🪟 Windows binary installed to %s; add %s to the Windows PATH to use it there\n", dest, dir)
+	return nil
+}
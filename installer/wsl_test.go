@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestParseWSLRelease(t *testing.T) {
+	tests := []struct {
+		release string
+		version int
+		ok      bool
+	}{
+		{"5.15.153.1-microsoft-standard-WSL2\n", 2, true},
+		{"4.4.0-19041-Microsoft", 1, true},
+		{"6.8.0-45-generic", 0, false},
+	}
+	for _, tt := range tests {
+		version, ok := parseWSLRelease(tt.release)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("parseWSLRelease(%q) = %d, %v; want %d, %v", tt.release, version, ok, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestOnWindowsDrive(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/mnt/c/Users/me/.local/bin": true,
+		"/mnt/d":                     true,
+		"/mnt/data/bin":              false,
+		"/home/me/.local/bin":        false,
+	} {
+		if got := onWindowsDrive(path); got != want {
+			t.Errorf("onWindowsDrive(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
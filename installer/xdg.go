@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// XDG_APP_DIR names vibe's subdirectory of each XDG base directory
+const XDG_APP_DIR = "vibe"
+
+// usesXDG reports whether installer paths follow the XDG Base Directory
+// specification. VIBE_HOME still keeps everything in one directory.
+func usesXDG() bool {
+	return runtime.GOOS == "linux" && os.Getenv("VIBE_HOME") == ""
+}
+
+// xdgDir returns the base directory named by env, or fallback under the
+// home directory. The specification requires absolute paths, so relative
+// values are ignored.
+func xdgDir(env string, fallback ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = os.TempDir()
+	}
+	return filepath.Join(append([]string{homeDir}, fallback...)...)
+}
+
+// xdgDataHome returns $XDG_DATA_HOME, defaulting to ~/.local/share
+func xdgDataHome() string {
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func xdgConfigHome() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// xdgCacheHome returns $XDG_CACHE_HOME, defaulting to ~/.cache
+func xdgCacheHome() string {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// legacyVibeHome returns ~/.vibe, where everything lived before the XDG
+// layout
+func legacyVibeHome() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".vibe")
+	}
+	return filepath.Join(homeDir, ".vibe")
+}
+
+// adjacentDataDir returns the data directory next to the binary, used for
+// grammars outside the XDG layout and by older installers
+func adjacentDataDir(installPath string) string {
+	return filepath.Join(installPath, "data")
+}
+
+// movePath renames from to to, copying across filesystems. An existing
+// destination is never overwritten.
+func movePath(from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s already exists", to)
+	}
+	if err := ensureDir(filepath.Dir(to), MODE_DIR); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = copyDir(from, to)
+	} else {
+		err = copyFile(from, to, info.Mode().Perm())
+	}
+	if err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+// legacyHomeEntries are what the installer kept in ~/.vibe besides the
+// config file and the cache. Nothing else there is moved: ~/.vibe can also
+// be the .vibe directory of a workspace at the home directory.
+var legacyHomeEntries = []string{
+	RECEIPT_FILE, CREDENTIALS_FILE, USER_DATA_DIR, HOOKS_DIR, "grammars", "data-backups", "versions",
+	INSTALL_LOCK_FILE, INSTALL_REPORT_FILE,
+}
+
+// migrateToXDG moves an installation from ~/.vibe and the data directories
+// next to the binary into the XDG locations. Only install and upgrade run
+// it, and only for a ~/.vibe holding an install receipt. It does nothing
+// once the XDG vibe home exists.
+func migrateToXDG() error {
+	if !usesXDG() {
+		return nil
+	}
+	legacy := legacyVibeHome()
+	home := getVibeHome()
+	if _, err := os.Stat(filepath.Join(legacy, RECEIPT_FILE)); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(home); err == nil {
+		return nil
+	}
+
+	fmt.Printf("📦 Moving the installer's files in %s to the XDG base directories...\n", legacy)
+	if err := ensureDir(home, MODE_PRIVATE_DIR); err != nil {
+		return err
+	}
+	moves := [][2]string{
+		{filepath.Join(legacy, CONFIG_FILE), getConfigPath()},
+		{filepath.Join(legacy, CACHE_DIR), getCacheDir()},
+	}
+	for _, entry := range legacyHomeEntries {
+		moves = append(moves, [2]string{filepath.Join(legacy, entry), filepath.Join(home, entry)})
+	}
+	for _, m := range moves {
+		if _, err := os.Lstat(m[0]); err != nil {
+			continue
+		}
+		if err := movePath(m[0], m[1]); err != nil {
+			return fmt.Errorf("failed to move %s: %w", m[0], err)
+		}
+	}
+	os.Remove(legacy) // only once nothing else is left in it
+
+	// Grammars and their backups used to sit next to the binary
+	receipt, err := loadReceipt()
+	if err != nil || receipt.InstallPath == "" {
+		return err
+	}
+	moves = [][2]string{
+		{adjacentDataDir(receipt.InstallPath), getDataDir(receipt.InstallPath)},
+		{filepath.Join(receipt.InstallPath, "data-backups"), getBackupDir(receipt.InstallPath)},
+	}
+	for _, m := range moves {
+		if _, err := os.Stat(m[0]); err != nil {
+			continue
+		}
+		if err := movePath(m[0], m[1]); err != nil {
+			return fmt.Errorf("failed to move %s: %w", m[0], err)
+		}
+	}
+
+	fmt.Printf("✅ Installer state now lives in %s\n", home)
+	return nil
+}
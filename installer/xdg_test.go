@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setupXDG isolates a test in a fresh home directory using the XDG layout
+func setupXDG(t *testing.T) string {
+	if runtime.GOOS != "linux" {
+		t.Skip("the XDG layout is only used on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VIBE_HOME", "")
+	for _, env := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_BIN_HOME"} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+func TestXDGPaths(t *testing.T) {
+	home := setupXDG(t)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"vibe home", getVibeHome(), filepath.Join(home, ".local", "share", "vibe")},
+		{"config", getConfigPath(), filepath.Join(home, ".config", "vibe", CONFIG_FILE)},
+		{"cache", getCacheDir(), filepath.Join(home, ".cache", "vibe")},
+		{"data", getDataDir("/opt/bin"), filepath.Join(home, ".local", "share", "vibe", "grammars")},
+		{"bin", getInstallPathForOS("linux"), filepath.Join(home, ".local", "bin")},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	custom := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(custom, "config"))
+	t.Setenv("XDG_BIN_HOME", filepath.Join(custom, "bin"))
+	t.Setenv("XDG_CACHE_HOME", "relative/cache") // invalid per the spec
+	if got := getConfigPath(); got != filepath.Join(custom, "config", "vibe", CONFIG_FILE) {
+		t.Errorf("config with XDG_CONFIG_HOME = %s", got)
+	}
+	if got := getInstallPathForOS("linux"); got != filepath.Join(custom, "bin") {
+		t.Errorf("bin with XDG_BIN_HOME = %s", got)
+	}
+	if got := getCacheDir(); got != filepath.Join(home, ".cache", "vibe") {
+		t.Errorf("Relative XDG_CACHE_HOME not ignored: %s", got)
+	}
+}
+
+func TestMigrateToXDG(t *testing.T) {
+	home := setupXDG(t)
+	installPath := filepath.Join(home, ".local", "bin")
+	legacy := filepath.Join(home, ".vibe")
+
+	// Without a receipt ~/.vibe is not the installer's, e.g. the .vibe of
+	// a workspace at the home directory
+	workspaceFile := filepath.Join(legacy, "rules", "style.md")
+	os.MkdirAll(filepath.Dir(workspaceFile), 0755)
+	os.WriteFile(workspaceFile, []byte("rules"), 0644)
+	if err := migrateToXDG(); err != nil {
+		t.Fatalf("migrateToXDG failed: %v", err)
+	}
+	if _, err := os.Stat(getVibeHome()); !os.IsNotExist(err) {
+		t.Errorf("a ~/.vibe without a receipt was migrated")
+	}
+
+	files := map[string]string{
+		filepath.Join(legacy, CONFIG_FILE):                    "[upgrade]\n",
+		filepath.Join(legacy, CACHE_DIR, CACHE_INDEX):         "{}",
+		filepath.Join(legacy, "data", "index.db"):             "db",
+		filepath.Join(installPath, "data", GRAMMARS[0].File):  "\x00asm",
+		filepath.Join(installPath, "data-backups", "v1", "x"): "backup",
+	}
+	for path, content := range files {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	receipt := &Receipt{Version: "v0.7.0", InstallPath: installPath}
+	data := `{"version":"v0.7.0","install_path":"` + installPath + `"}`
+	os.WriteFile(filepath.Join(legacy, RECEIPT_FILE), []byte(data), 0644)
+
+	if err := migrateToXDG(); err != nil {
+		t.Fatalf("migrateToXDG failed: %v", err)
+	}
+
+	for _, path := range []string{
+		getConfigPath(),
+		filepath.Join(getCacheDir(), CACHE_INDEX),
+		filepath.Join(getVibeHome(), "data", "index.db"),
+		filepath.Join(getDataDir(installPath), GRAMMARS[0].File),
+		filepath.Join(getBackupDir(installPath), "v1", "x"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s after migration: %v", path, err)
+		}
+	}
+	for _, path := range []string{filepath.Join(legacy, RECEIPT_FILE), filepath.Join(legacy, "data"), filepath.Join(installPath, "data"), filepath.Join(installPath, "data-backups")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Legacy %s still present", path)
+		}
+	}
+	if entries, _ := os.ReadDir(legacy); len(entries) != 1 || entries[0].Name() != "rules" {
+		t.Errorf("~/.vibe holds %v after migration, want only the workspace's rules", entries)
+	}
+
+	loaded, err := loadReceipt()
+	if err != nil || loaded.Version != receipt.Version {
+		t.Errorf("Receipt not carried over: %+v, %v", loaded, err)
+	}
+
+	// A second run has nothing to do
+	if err := migrateToXDG(); err != nil {
+		t.Errorf("Second migration failed: %v", err)
+	}
+}
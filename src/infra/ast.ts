@@ -158,8 +158,21 @@ export const resolveWasmPath = async (language: string): Promise<string> => {
         await Deno.stat(dataPath)
         return dataPath
       } catch (error) {
-        throw new Error(`Failed to find WASM file in data/ directory for ${language}. ` +
-                       `Make sure the installer has downloaded the required files: ${error}`)
+        // Grammars live in the vibe home: the XDG data home on Linux,
+        // ~/.vibe elsewhere, as the installer's getVibeHome decides
+        const xdgDataHome = Deno.env.get('XDG_DATA_HOME')
+        const homeDir = Deno.env.get('HOME') || Deno.env.get('USERPROFILE')
+        const vibeHome = Deno.env.get('VIBE_HOME') || (Deno.build.os === 'linux'
+          ? `${xdgDataHome?.startsWith('/') ? xdgDataHome : `${homeDir}/.local/share`}/vibe`
+          : `${homeDir}/.vibe`)
+        try {
+          const dataPath = `${vibeHome}/grammars/${config.wasmFile}`
+          await Deno.stat(dataPath)
+          return dataPath
+        } catch {
+          throw new Error(`Failed to find WASM file in data/ directory for ${language}. ` +
+                         `Make sure the installer has downloaded the required files: ${error}`)
+        }
       }
     }
   }